@@ -0,0 +1,104 @@
+package tcx
+
+import "time"
+
+// PrivacyZone is a circle, such as around a home address, inside which
+// positions are not shared.
+type PrivacyZone struct {
+	Latitude, Longitude float64 // degrees
+	Radius              float64 // meters
+}
+
+// contains reports whether the position lies inside the zone.
+func (z PrivacyZone) contains(lat, lon float64) bool {
+	return distance(z.Latitude, z.Longitude, lat, lon) <= z.Radius
+}
+
+// AnonymizeOptions configures Anonymize.
+type AnonymizeOptions struct {
+	// StripPositions removes every GPS position.
+	StripPositions bool
+	// PrivacyZones lists the zones whose positions are removed.
+	PrivacyZones []PrivacyZone
+	// ClearCreator zeroes the Creator block, which identifies the device,
+	// and Tcx.Anonymize removes the Author, which identifies the software.
+	ClearCreator bool
+	// ClearNotes removes the free text notes of the laps and metadata.
+	ClearNotes bool
+	// ClearExtensions removes the extension elements the package does not
+	// interpret, which may hold anything the device recorded.
+	ClearExtensions bool
+	// RoundStart rounds the start of the activity to a multiple of it;
+	// every time is shifted by the same amount, so durations are kept.
+	// Zero leaves the times as recorded.
+	RoundStart time.Duration
+}
+
+// DefaultAnonymizeOptions clears the creator, notes and unknown extensions
+// and rounds the start to the quarter hour.
+var DefaultAnonymizeOptions = AnonymizeOptions{
+	ClearCreator:    true,
+	ClearNotes:      true,
+	ClearExtensions: true,
+	RoundStart:      15 * time.Minute,
+}
+
+// Anonymize removes from the activity the data that identifies the athlete
+// before sharing it, as selected by opts. Positions are removed from their
+// trackpoints, whose other values are kept.
+func (a *Activity) Anonymize(opts AnonymizeOptions) {
+	a.Invalidate()
+	for _, p := range a.trackpoints() {
+		if opts.ClearExtensions {
+			p.OtherExtensions = nil
+		}
+		if !p.HasPosition() {
+			continue
+		}
+		if opts.StripPositions {
+			p.ClearPosition()
+			continue
+		}
+		for _, z := range opts.PrivacyZones {
+			if z.contains(p.LatitudeInDegrees, p.LongitudeInDegrees) {
+				p.ClearPosition()
+				break
+			}
+		}
+	}
+	if opts.ClearCreator {
+		a.Creator = Creator{}
+	}
+	for i := range a.Laps {
+		l := &a.Laps[i]
+		if opts.ClearNotes {
+			l.Notes = ""
+		}
+		if opts.ClearExtensions && l.Extensions != nil {
+			l.Extensions.Other = nil
+		}
+	}
+	if x := a.Extensions; x != nil {
+		if opts.ClearNotes && x.Metadata != nil {
+			x.Metadata.Notes = ""
+		}
+		if opts.ClearExtensions {
+			x.Other = nil
+		}
+	}
+	if opts.RoundStart > 0 {
+		start := a.start()
+		a.ShiftTime(start.Round(opts.RoundStart).Sub(start))
+	}
+}
+
+// Anonymize anonymizes every activity of t, multisport legs included, and
+// removes the Author with the creators.
+func (t *Tcx) Anonymize(opts AnonymizeOptions) {
+	for _, a := range t.AllActivities() {
+		a.Anonymize(opts)
+	}
+	if opts.ClearCreator {
+		t.Author = nil
+	}
+}
@@ -0,0 +1,58 @@
+package tcx
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestAnonymize(t *testing.T) {
+	a := testActivity(SportRunning, repeat(4.0, 601), repeat(150, 601))
+	a.Creator = Creator{Name: "Forerunner 935", UnitID: 3912345678}
+	a.ShiftTime(7*time.Minute + 23*time.Second)
+	home := PrivacyZone{Latitude: 47, Longitude: -1.5, Radius: 202}
+	opts := DefaultAnonymizeOptions
+	opts.PrivacyZones = []PrivacyZone{home}
+	duration := a.TotalDuration()
+
+	db := &Tcx{Activities: []Activity{*a.Clone()}, Author: &Author{Name: "Garmin Connect"}}
+	b := &db.Activities[0]
+	b.Laps[0].Notes = "left from home"
+	b.Laps[0].Track[3].OtherExtensions = []ExtensionElement{{XMLName: xml.Name{Local: "Temperature"}, Text: "21"}}
+	db.Anonymize(opts)
+	if b.Creator != (Creator{}) || db.Author != nil {
+		t.Errorf("creator = %+v, author = %+v", b.Creator, db.Author)
+	}
+	if b.Laps[0].Notes != "" || b.Laps[0].Track[3].OtherExtensions != nil {
+		t.Error("Anonymize kept the notes or extensions")
+	}
+	if want := time.Date(2015, 4, 12, 7, 0, 0, 0, time.UTC); !b.ID.Equal(want) || !b.Laps[0].Track[0].Time.Equal(want) {
+		t.Errorf("start = %v, want %v", b.ID, want)
+	}
+	if b.TotalDuration() != duration {
+		t.Errorf("duration changed to %v", b.TotalDuration())
+	}
+	hidden := 0
+	for _, p := range b.Laps[0].Track {
+		if !p.HasPosition() {
+			hidden++
+			if p.HeartRateInBpm != 150 {
+				t.Fatal("Anonymize removed the heart rate")
+			}
+		}
+	}
+	// The first 202 m are covered in 50.5 s at 4 m/s.
+	if hidden != 51 {
+		t.Errorf("hid %d positions, want 51", hidden)
+	}
+
+	a.Anonymize(AnonymizeOptions{StripPositions: true})
+	for _, p := range a.Laps[0].Track {
+		if p.HasPosition() {
+			t.Fatal("StripPositions kept a position")
+		}
+	}
+	if a.Creator.Name == "" {
+		t.Error("ClearCreator unset but the creator was cleared")
+	}
+}
@@ -0,0 +1,61 @@
+package tcx
+
+import (
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// decompress returns a reader of the uncompressed content of r, which may be
+// gzip compressed.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(2)
+	if len(magic) < 2 || magic[0] != 0x1f || magic[1] != 0x8b {
+		return br, nil
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read gzip data: %v", err)
+	}
+	return zr, nil
+}
+
+// isTCXName reports whether the file name is that of a TCX file, possibly
+// gzip compressed.
+func isTCXName(name string) bool {
+	name = strings.ToLower(path.Base(name))
+	return strings.HasSuffix(name, ".tcx") || strings.HasSuffix(name, ".tcx.gz")
+}
+
+// ParseArchive parses every TCX file, plain or gzip compressed, of a zip
+// archive such as the bulk exports of Garmin Connect and Strava. The files
+// are returned in archive order; other files are ignored.
+func ParseArchive(path string) ([]*Tcx, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open archive: %v", err)
+	}
+	defer zr.Close()
+	var files []*Tcx
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !isTCXName(f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't open %s: %v", f.Name, err)
+		}
+		t, err := Parse(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse %s: %v", f.Name, err)
+		}
+		files = append(files, t)
+	}
+	return files, nil
+}
@@ -0,0 +1,90 @@
+package tcx
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func gzipped(t *testing.T, data []byte) []byte {
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestParseGzip(t *testing.T) {
+	data, err := os.ReadFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := Parse(bytes.NewReader(gzipped(t, data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(db.Activities[0].trackpoints()); n != 1937 {
+		t.Errorf("parsed %d trackpoints from gzip data", n)
+	}
+	var n int
+	err = ParseStream(bytes.NewReader(gzipped(t, data)), func(StreamPoint) error { n++; return nil })
+	if err != nil || n != 1937 {
+		t.Errorf("streamed %d trackpoints from gzip data, %v", n, err)
+	}
+	if _, err := Parse(bytes.NewReader([]byte{0x1f, 0x8b, 0})); err == nil {
+		t.Error("parsed truncated gzip data")
+	}
+}
+
+func TestParseArchive(t *testing.T) {
+	data, err := os.ReadFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range map[string][]byte{
+		"activities/1.tcx":    data,
+		"activities/2.TCX.gz": gzipped(t, data),
+		"activities/3.gpx":    []byte("<gpx/>"),
+		"profile.csv":         []byte("name\n"),
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	files, err := ParseArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("parsed %d files, want 2", len(files))
+	}
+	for _, db := range files {
+		if len(db.Activities) != 1 || len(db.Activities[0].Laps) != 25 {
+			t.Errorf("parsed %d activities", len(db.Activities))
+		}
+	}
+	if _, err := ParseArchive("testdata/test1.tcx"); err == nil || !strings.Contains(err.Error(), "archive") {
+		t.Errorf("ParseArchive of a TCX file = %v", err)
+	}
+}
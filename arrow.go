@@ -0,0 +1,160 @@
+package tcx
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// ArrowType is the Arrow data type of a column.
+type ArrowType int
+
+const (
+	ArrowInt32 ArrowType = iota
+	ArrowFloat64
+	// ArrowTimestampMillis is a 64-bit count of milliseconds since the Unix
+	// epoch, in UTC.
+	ArrowTimestampMillis
+)
+
+// byteWidth returns the size of one value.
+func (t ArrowType) byteWidth() int {
+	if t == ArrowInt32 {
+		return 4
+	}
+	return 8
+}
+
+// ArrowField describes a column of a record batch.
+type ArrowField struct {
+	Name     string
+	Type     ArrowType
+	Nullable bool
+}
+
+// ArrowColumn holds a column in the Arrow columnar memory layout: a validity
+// bitmap, least significant bit first and nil when no value is null, and a
+// little-endian values buffer, both padded to 64 bytes. The buffers can be
+// handed as they are to an Arrow implementation, for instance wrapped with
+// memory.NewBufferBytes into an array.Data of the Arrow Go module.
+type ArrowColumn struct {
+	Field     ArrowField
+	Len       int
+	NullCount int
+	Validity  []byte
+	Values    []byte
+}
+
+// IsNull reports whether the i-th value is null.
+func (c *ArrowColumn) IsNull(i int) bool {
+	return c.Validity != nil && c.Validity[i/8]&(1<<(i%8)) == 0
+}
+
+// Int returns the i-th value of an Int32 or timestamp column.
+func (c *ArrowColumn) Int(i int) int64 {
+	if c.Field.Type == ArrowInt32 {
+		return int64(int32(binary.LittleEndian.Uint32(c.Values[4*i:])))
+	}
+	return int64(binary.LittleEndian.Uint64(c.Values[8*i:]))
+}
+
+// Float64 returns the i-th value of a Float64 column.
+func (c *ArrowColumn) Float64(i int) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(c.Values[8*i:]))
+}
+
+// ArrowRecordBatch is a set of equal length columns.
+type ArrowRecordBatch struct {
+	NumRows int
+	Columns []ArrowColumn
+}
+
+// Column returns the column called name, or nil.
+func (b *ArrowRecordBatch) Column(name string) *ArrowColumn {
+	for i := range b.Columns {
+		if b.Columns[i].Field.Name == name {
+			return &b.Columns[i]
+		}
+	}
+	return nil
+}
+
+// arrowSchema is the schema of the record batches of ToArrow.
+var arrowSchema = []ArrowField{
+	{"time", ArrowTimestampMillis, false},
+	{"latitude", ArrowFloat64, true},
+	{"longitude", ArrowFloat64, true},
+	{"altitude", ArrowFloat64, true},
+	{"distance", ArrowFloat64, true},
+	{"heart_rate", ArrowInt32, true},
+	{"cadence", ArrowInt32, true},
+	{"speed", ArrowFloat64, true},
+	{"power", ArrowInt32, true},
+}
+
+// ArrowSchema returns the schema of the record batches of ToArrow.
+func ArrowSchema() []ArrowField {
+	return append([]ArrowField(nil), arrowSchema...)
+}
+
+// arrowPadding is the alignment of Arrow buffers.
+const arrowPadding = 64
+
+func arrowBuffer(n int) []byte {
+	return make([]byte, (n+arrowPadding-1)/arrowPadding*arrowPadding)
+}
+
+// ToArrow returns the trackpoint series of the activity as Arrow record
+// batches, one per lap, following ArrowSchema(). Missing readings are null.
+func (a *Activity) ToArrow() []ArrowRecordBatch {
+	var batches []ArrowRecordBatch
+	for _, l := range a.Laps {
+		n := len(l.Track)
+		b := ArrowRecordBatch{NumRows: n, Columns: make([]ArrowColumn, len(arrowSchema))}
+		for i, f := range arrowSchema {
+			c := &b.Columns[i]
+			c.Field, c.Len = f, n
+			c.Values = arrowBuffer(n * f.Type.byteWidth())
+			if f.Nullable {
+				c.Validity = arrowBuffer((n + 7) / 8)
+			}
+		}
+		for i, p := range l.Track {
+			pos := p.HasPosition()
+			b.Columns[0].set(i, p.Time.UnixMilli(), true)
+			b.Columns[1].set(i, p.LatitudeInDegrees, pos)
+			b.Columns[2].set(i, p.LongitudeInDegrees, pos)
+			b.Columns[3].set(i, p.AltitudeInMeters, p.HasAltitude())
+			b.Columns[4].set(i, p.DistanceInMeters, p.HasDistance())
+			b.Columns[5].set(i, int32(p.HeartRateInBpm), p.HasHeartRate())
+			b.Columns[6].set(i, int32(p.Cadence), p.HasCadence())
+			b.Columns[7].set(i, p.SpeedInMetersPerSec, p.SpeedInMetersPerSec > 0)
+			b.Columns[8].set(i, int32(p.PowerInWatts), p.HasPower())
+		}
+		for i := range b.Columns {
+			if c := &b.Columns[i]; c.Validity != nil && c.NullCount == 0 {
+				c.Validity = nil
+			}
+		}
+		batches = append(batches, b)
+	}
+	return batches
+}
+
+// set stores the i-th value, or a null when ok is false.
+func (c *ArrowColumn) set(i int, v any, ok bool) {
+	if !ok && c.Field.Nullable {
+		c.NullCount++
+		return
+	}
+	if c.Validity != nil {
+		c.Validity[i/8] |= 1 << (i % 8)
+	}
+	switch v := v.(type) {
+	case int32:
+		binary.LittleEndian.PutUint32(c.Values[4*i:], uint32(v))
+	case int64:
+		binary.LittleEndian.PutUint64(c.Values[8*i:], uint64(v))
+	case float64:
+		binary.LittleEndian.PutUint64(c.Values[8*i:], math.Float64bits(v))
+	}
+}
@@ -0,0 +1,56 @@
+package tcx
+
+import "testing"
+
+func TestToArrow(t *testing.T) {
+	tcx, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	a := &tcx.Activities[0]
+	batches := a.ToArrow()
+	if len(batches) != len(a.Laps) {
+		t.Fatalf("got %d batches for %d laps", len(batches), len(a.Laps))
+	}
+	b := batches[2]
+	l := a.Laps[2]
+	if b.NumRows != len(l.Track) {
+		t.Fatalf("got %d rows, want %d", b.NumRows, len(l.Track))
+	}
+	for _, c := range b.Columns {
+		if len(c.Values)%arrowPadding != 0 || len(c.Validity)%arrowPadding != 0 {
+			t.Errorf("column %s buffers are not padded", c.Field.Name)
+		}
+	}
+	hr, tm, lat := b.Column("heart_rate"), b.Column("time"), b.Column("latitude")
+	for i, p := range l.Track {
+		if hr.Int(i) != int64(p.HeartRateInBpm) || tm.Int(i) != p.Time.UnixMilli() || lat.Float64(i) != p.LatitudeInDegrees {
+			t.Fatalf("row %d differs from trackpoint %+v", i, p)
+		}
+	}
+	if b.Column("power").NullCount != b.NumRows || !b.Column("power").IsNull(0) {
+		t.Error("power should be entirely null")
+	}
+	if hr.Validity != nil || hr.IsNull(0) {
+		t.Error("heart rate has no nulls and needs no bitmap")
+	}
+}
+
+func TestToArrowAltitude(t *testing.T) {
+	a := testActivity(SportRunning, repeat(3.0, 3), nil)
+	a.Laps[0].Track[1].ClearAltitude()
+	a.Laps[0].Track[2].SetDistance(6)
+	b := a.ToArrow()[0]
+	alt := b.Column("altitude")
+	if alt.NullCount != 1 || !alt.IsNull(1) || alt.IsNull(0) {
+		t.Errorf("altitude of a positioned trackpoint without one should be null: %d nulls", alt.NullCount)
+	}
+	if d := b.Column("distance"); d.NullCount != 2 || d.IsNull(2) || d.Float64(2) != 6 {
+		t.Errorf("distance column has %d nulls, %v at 2", d.NullCount, d.Float64(2))
+	}
+	schema := ArrowSchema()
+	schema[0].Name = "changed"
+	if ArrowSchema()[0].Name != "time" {
+		t.Error("ArrowSchema() shares its fields")
+	}
+}
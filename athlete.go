@@ -0,0 +1,29 @@
+package tcx
+
+// Sex is the biological sex used by physiological formulas.
+type Sex int
+
+const (
+	SexUnknown Sex = iota
+	SexMale
+	SexFemale
+)
+
+// Athlete describes the physiological profile used by the estimators of this
+// package. Zero values mean unknown.
+type Athlete struct {
+	Weight           float64 // kilograms
+	Age              int     // years
+	Sex              Sex
+	MaxHeartRate     int
+	RestingHeartRate int
+}
+
+// heartRateReserve returns the fraction of heart rate reserve (Karvonen) that
+// hr represents, or false when the profile lacks the needed heart rates.
+func (ath Athlete) heartRateReserve(hr int) (float64, bool) {
+	if ath.MaxHeartRate <= ath.RestingHeartRate || ath.RestingHeartRate <= 0 {
+		return 0, false
+	}
+	return float64(hr-ath.RestingHeartRate) / float64(ath.MaxHeartRate-ath.RestingHeartRate), true
+}
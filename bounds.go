@@ -0,0 +1,72 @@
+package tcx
+
+import (
+	"math"
+	"sort"
+)
+
+// Bounds returns the bounding box of the GPS fixes of the activity, in
+// degrees, to fit a map viewport. It returns zeros when the activity has no
+// fix or is indoor.
+func (a *Activity) Bounds() (minLat, minLon, maxLat, maxLon float64) {
+	first := true
+	for _, p := range a.fixes() {
+		if first {
+			minLat, maxLat = p.LatitudeInDegrees, p.LatitudeInDegrees
+			minLon, maxLon = p.LongitudeInDegrees, p.LongitudeInDegrees
+			first = false
+			continue
+		}
+		minLat, maxLat = math.Min(minLat, p.LatitudeInDegrees), math.Max(maxLat, p.LatitudeInDegrees)
+		minLon, maxLon = math.Min(minLon, p.LongitudeInDegrees), math.Max(maxLon, p.LongitudeInDegrees)
+	}
+	return minLat, minLon, maxLat, maxLon
+}
+
+// Center returns the center of the bounding box of the activity, in
+// degrees. ok is false when the activity has no fix or is indoor. Tracks
+// crossing the antimeridian are centered on the narrowest box holding them,
+// not on the box spanning the rest of the globe.
+func (a *Activity) Center() (lat, lon float64, ok bool) {
+	fixes := a.fixes()
+	if len(fixes) == 0 {
+		return 0, 0, false
+	}
+	minLat, _, maxLat, _ := a.Bounds()
+	lons := make([]float64, len(fixes))
+	for i, p := range fixes {
+		lons[i] = p.LongitudeInDegrees
+	}
+	return (minLat + maxLat) / 2, lonCenter(lons), true
+}
+
+// lonCenter returns the center of the narrowest range of longitudes holding
+// lons: the complement of the widest gap between them, wrapping around the
+// antimeridian.
+func lonCenter(lons []float64) float64 {
+	sort.Float64s(lons)
+	n := len(lons)
+	// The gap across the antimeridian, from the last longitude to the first.
+	gap, west := lons[0]+360-lons[n-1], lons[0]
+	for i := 1; i < n; i++ {
+		if g := lons[i] - lons[i-1]; g > gap {
+			gap, west = g, lons[i]
+		}
+	}
+	center := west + (360-gap)/2
+	if center > 180 {
+		center -= 360
+	}
+	return center
+}
+
+// ContainsPoint reports whether the track passes within radius meters of
+// the position lat, lon, given in degrees.
+func (a *Activity) ContainsPoint(lat, lon, radius float64) bool {
+	for _, p := range a.fixes() {
+		if distance(lat, lon, p.LatitudeInDegrees, p.LongitudeInDegrees) <= radius {
+			return true
+		}
+	}
+	return false
+}
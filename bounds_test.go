@@ -0,0 +1,50 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBounds(t *testing.T) {
+	a := testActivity(SportRunning, repeat(4.0, 101), nil)
+	a.Laps[0].Track[50].ClearPosition()
+	minLat, minLon, maxLat, maxLon := a.Bounds()
+	top := a.Laps[0].Track[100].LatitudeInDegrees
+	if minLat != 47 || maxLat != top || minLon != -1.5 || maxLon != -1.5 {
+		t.Errorf("Bounds() = %v, %v, %v, %v", minLat, minLon, maxLat, maxLon)
+	}
+	lat, lon, ok := a.Center()
+	if !ok || math.Abs(lat-(47+top)/2) > 1e-12 || lon != -1.5 {
+		t.Errorf("Center() = %v, %v, %v", lat, lon, ok)
+	}
+
+	// The track runs 400 m north of its start.
+	if !a.ContainsPoint(47.002, -1.5005, 50) {
+		t.Error("ContainsPoint() missed a point 38 m off the track")
+	}
+	if a.ContainsPoint(47.01, -1.5, 100) {
+		t.Error("ContainsPoint() matched a point 700 m past the end")
+	}
+
+	if _, _, ok := (&Activity{}).Center(); ok {
+		t.Error("Center() of an empty activity")
+	}
+
+	// A track at 0, 0 has a center, and one across the antimeridian is
+	// centered on it.
+	for i := range a.Laps[0].Track {
+		lat := float64(i-50) * 1e-5
+		a.Laps[0].Track[i].SetPosition(lat, 0)
+	}
+	if lat, lon, ok := a.Center(); !ok || math.Abs(lat) > 1e-12 || lon != 0 {
+		t.Errorf("Center() at 0, 0 = %v, %v, %v", lat, lon, ok)
+	}
+	a.Laps[0].Track[0].SetPosition(0, 179.9)
+	a.Laps[0].Track[1].SetPosition(0, -179.7)
+	for i := 2; i < len(a.Laps[0].Track); i++ {
+		a.Laps[0].Track[i].LongitudeInDegrees = -179.8
+	}
+	if _, lon, ok := a.Center(); !ok || math.Abs(lon-(-179.9)) > 1e-9 {
+		t.Errorf("Center() across the antimeridian = %v, %v", lon, ok)
+	}
+}
@@ -0,0 +1,192 @@
+package tcx
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lap intensities of the TCX schema.
+const (
+	IntensityActive  = "Active"
+	IntensityResting = "Resting"
+)
+
+// Lap trigger methods of the TCX schema.
+const (
+	TriggerManual    = "Manual"
+	TriggerDistance  = "Distance"
+	TriggerLocation  = "Location"
+	TriggerTime      = "Time"
+	TriggerHeartRate = "HeartRate"
+)
+
+var (
+	knownSports         = []string{SportRunning, SportBiking, SportOther, SportWalking, SportSwimming}
+	knownIntensities    = []string{IntensityActive, IntensityResting}
+	knownTriggerMethods = []string{TriggerManual, TriggerDistance, TriggerLocation, TriggerTime, TriggerHeartRate}
+)
+
+func oneOf(v string, values []string) bool {
+	for _, s := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ActivityBuilder constructs an activity step by step, filling in lap
+// totals, intensities, trigger methods and namespaces. Each step is checked;
+// the first error stops the build and is returned by Build:
+//
+//	t, err := NewActivityBuilder(SportRunning).
+//		StartLap(start).
+//		AddTrackpoint(p1).
+//		AddTrackpoint(p2).
+//		Build()
+type ActivityBuilder struct {
+	a   Activity
+	err error
+}
+
+// NewActivityBuilder starts building an activity of the given sport.
+func NewActivityBuilder(sport string) *ActivityBuilder {
+	b := &ActivityBuilder{a: Activity{Sport: sport}}
+	if !oneOf(sport, knownSports) {
+		b.err = fmt.Errorf("unknown sport %q", sport)
+	}
+	return b
+}
+
+// Creator sets the device that recorded the activity.
+func (b *ActivityBuilder) Creator(c Creator) *ActivityBuilder {
+	if b.err == nil {
+		b.a.Creator = c
+	}
+	return b
+}
+
+// lap returns the lap in progress, or nil.
+func (b *ActivityBuilder) lap() *Lap {
+	if len(b.a.Laps) == 0 {
+		return nil
+	}
+	return &b.a.Laps[len(b.a.Laps)-1]
+}
+
+// last returns the time of the latest trackpoint or lap start.
+func (b *ActivityBuilder) last() time.Time {
+	l := b.lap()
+	if l == nil {
+		return time.Time{}
+	}
+	if n := len(l.Track); n > 0 {
+		return l.Track[n-1].Time
+	}
+	return l.StartTime
+}
+
+// endLap computes the totals of the lap in progress, which lasts until end.
+func (b *ActivityBuilder) endLap(end time.Time) {
+	l := b.lap()
+	if l == nil {
+		return
+	}
+	l.update()
+	if d := end.Sub(l.StartTime).Seconds(); d > l.TotalTimeInSeconds {
+		l.TotalTimeInSeconds = d
+	}
+}
+
+// StartLap ends the lap in progress, if any, and starts a new active lap at
+// t, triggered manually.
+func (b *ActivityBuilder) StartLap(t time.Time) *ActivityBuilder {
+	if b.err != nil {
+		return b
+	}
+	if last := b.last(); t.Before(last) {
+		b.err = fmt.Errorf("lap start at %v is older than %v", t, last)
+		return b
+	}
+	t = t.UTC()
+	b.endLap(t)
+	if len(b.a.Laps) == 0 {
+		b.a.ID = t
+	}
+	b.a.Laps = append(b.a.Laps, Lap{StartTime: t, Intensity: IntensityActive, TriggerMethod: TriggerManual})
+	return b
+}
+
+// Intensity sets the intensity of the lap in progress, Active or Resting.
+func (b *ActivityBuilder) Intensity(v string) *ActivityBuilder {
+	switch {
+	case b.err != nil:
+	case b.lap() == nil:
+		b.err = fmt.Errorf("intensity %q set before any lap", v)
+	case !oneOf(v, knownIntensities):
+		b.err = fmt.Errorf("unknown intensity %q", v)
+	default:
+		b.lap().Intensity = v
+	}
+	return b
+}
+
+// TriggerMethod sets what ended the lap in progress.
+func (b *ActivityBuilder) TriggerMethod(v string) *ActivityBuilder {
+	switch {
+	case b.err != nil:
+	case b.lap() == nil:
+		b.err = fmt.Errorf("trigger method %q set before any lap", v)
+	case !oneOf(v, knownTriggerMethods):
+		b.err = fmt.Errorf("unknown trigger method %q", v)
+	default:
+		b.lap().TriggerMethod = v
+	}
+	return b
+}
+
+// AddTrackpoint appends p to the lap in progress, starting the first lap
+// at p when none was. Trackpoints must come in time order, with positions
+// within range.
+func (b *ActivityBuilder) AddTrackpoint(p Trackpoint) *ActivityBuilder {
+	if b.err != nil {
+		return b
+	}
+	switch {
+	case p.Time.IsZero():
+		b.err = fmt.Errorf("trackpoint without a time")
+		return b
+	case p.Time.Before(b.last()):
+		b.err = fmt.Errorf("trackpoint at %v is older than %v", p.Time, b.last())
+		return b
+	case p.LatitudeInDegrees < -90 || p.LatitudeInDegrees > 90 || p.LongitudeInDegrees < -180 || p.LongitudeInDegrees > 180:
+		b.err = fmt.Errorf("trackpoint at %v has an invalid position %v, %v", p.Time, p.LatitudeInDegrees, p.LongitudeInDegrees)
+		return b
+	case p.HeartRateInBpm < 0 || p.Cadence < 0 || p.PowerInWatts < 0 || p.SpeedInMetersPerSec < 0:
+		b.err = fmt.Errorf("trackpoint at %v has a negative reading", p.Time)
+		return b
+	}
+	if b.lap() == nil {
+		b.StartLap(p.Time)
+	}
+	p.Time = p.Time.UTC()
+	l := b.lap()
+	l.Track = append(l.Track, p)
+	return b
+}
+
+// Build ends the lap in progress and returns the activity in a Tcx, ready
+// to be written, or the first error met while building.
+func (b *ActivityBuilder) Build() (*Tcx, error) {
+	if b.err != nil {
+		return nil, fmt.Errorf("couldn't build activity: %v", b.err)
+	}
+	if len(b.a.Laps) == 0 {
+		return nil, fmt.Errorf("couldn't build activity: no laps")
+	}
+	b.endLap(b.last())
+	t := NewTcx()
+	t.XMLNs = TrainingCenterNS
+	t.Activities = []Activity{*b.a.Clone()}
+	return t, nil
+}
@@ -0,0 +1,67 @@
+package tcx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActivityBuilder(t *testing.T) {
+	start := time.Date(2015, 4, 12, 7, 0, 0, 0, time.UTC)
+	b := NewActivityBuilder(SportRunning).Creator(Creator{Name: "Forerunner 920XT"})
+	lat := 47.0
+	for i := 0; i <= 200; i++ {
+		if i == 100 {
+			b.TriggerMethod(TriggerDistance).StartLap(start.Add(100 * time.Second)).Intensity(IntensityResting)
+		}
+		b.AddTrackpoint(Trackpoint{
+			Time:               start.Add(time.Duration(i) * time.Second),
+			LatitudeInDegrees:  lat,
+			LongitudeInDegrees: -1.5,
+			HeartRateInBpm:     140 + i%10,
+		})
+		lat += 3 / 111194.93
+	}
+	db, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.XMLNs != TrainingCenterNS || len(db.Activities) != 1 {
+		t.Fatalf("built %+v", db)
+	}
+	a := db.Activities[0]
+	if !a.ID.Equal(start) || len(a.Laps) != 2 || a.Creator.Name != "Forerunner 920XT" {
+		t.Fatalf("activity %v with %d laps", a.ID, len(a.Laps))
+	}
+	l0, l1 := a.Laps[0], a.Laps[1]
+	if l0.TotalTimeInSeconds != 100 || l0.TriggerMethod != TriggerDistance || l0.Intensity != IntensityActive {
+		t.Errorf("first lap = %v s, %s, %s", l0.TotalTimeInSeconds, l0.TriggerMethod, l0.Intensity)
+	}
+	if d := l0.DistanceInMeters; d < 295 || d > 299 {
+		t.Errorf("first lap distance = %v", d)
+	}
+	if l1.Intensity != IntensityResting || l1.TriggerMethod != TriggerManual || l1.MaximumHeartRateInBpm != 149 {
+		t.Errorf("second lap = %+v", l1)
+	}
+	var out bytes.Buffer
+	if err := db.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(&out); err != nil {
+		t.Error(err)
+	}
+
+	for name, b := range map[string]*ActivityBuilder{
+		"sport":     NewActivityBuilder("Rowing"),
+		"order":     NewActivityBuilder(SportBiking).AddTrackpoint(Trackpoint{Time: start.Add(time.Second)}).AddTrackpoint(Trackpoint{Time: start}),
+		"position":  NewActivityBuilder(SportBiking).AddTrackpoint(Trackpoint{Time: start, LatitudeInDegrees: 91}),
+		"intensity": NewActivityBuilder(SportBiking).StartLap(start).Intensity("Easy"),
+		"no lap":    NewActivityBuilder(SportBiking).TriggerMethod(TriggerTime),
+		"empty":     NewActivityBuilder(SportBiking),
+	} {
+		if _, err := b.Build(); err == nil || !strings.HasPrefix(err.Error(), "couldn't build activity") {
+			t.Errorf("%s: Build() error = %v", name, err)
+		}
+	}
+}
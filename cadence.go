@@ -0,0 +1,168 @@
+package tcx
+
+// CadenceUnit is the convention of the Cadence values of an activity.
+type CadenceUnit string
+
+// Cadence conventions.
+const (
+	// CadenceRPM counts crank (or stroke) revolutions per minute.
+	CadenceRPM CadenceUnit = "rpm"
+	// CadenceStrides counts single leg steps per minute, half the step
+	// rate, as Garmin and Suunto watches record running cadence.
+	CadenceStrides CadenceUnit = "strides"
+	// CadenceSteps counts steps of either foot per minute (SPM).
+	CadenceSteps CadenceUnit = "spm"
+)
+
+// Cadence boundaries telling strides from steps: runners take 150 to 200
+// steps a minute and walkers 100 to 130, so strides sit below these values.
+const (
+	runStrideLimit  = 120
+	walkStrideLimit = 80
+)
+
+// CadenceUnit detects the convention of the recorded cadence from the sport,
+// inferred when the Sport attribute is not Running or Biking, and from the
+// median moving cadence, read from the TPX RunCadence when the trackpoints
+// have no Cadence. It returns an empty unit when the activity has no
+// cadence.
+func (a *Activity) CadenceUnit() CadenceUnit {
+	var cadences []float64
+	for _, p := range a.trackpoints() {
+		if c := cadence(p); c > 0 && p.SpeedInMetersPerSec >= inferMovingSpeed {
+			cadences = append(cadences, float64(c))
+		}
+	}
+	if len(cadences) == 0 {
+		for _, p := range a.trackpoints() {
+			if c := cadence(p); c > 0 {
+				cadences = append(cadences, float64(c))
+			}
+		}
+	}
+	if len(cadences) == 0 {
+		return ""
+	}
+	sport := a.Sport
+	if sport != SportRunning && sport != SportBiking {
+		sport = a.InferSport()
+	}
+	cad := median(cadences)
+	switch sport {
+	case SportRunning:
+		if cad < runStrideLimit {
+			return CadenceStrides
+		}
+		return CadenceSteps
+	case SportWalking:
+		if cad < walkStrideLimit {
+			return CadenceStrides
+		}
+		return CadenceSteps
+	}
+	return CadenceRPM
+}
+
+// NormalizeCadence rewrites the trackpoint Cadence of foot activities
+// recorded in strides per minute as steps per minute, so that cadence is
+// always RPM for cycling and swimming and SPM on foot. The TPX RunCadence of
+// the trackpoints and the Cadence of the laps keep the recorded convention;
+// CadenceStats reports them in steps per minute. It returns the unit of the
+// trackpoint cadence after normalization.
+func (a *Activity) NormalizeCadence() CadenceUnit {
+	a.Invalidate()
+	unit := a.CadenceUnit()
+	if unit != CadenceStrides {
+		return unit
+	}
+	for _, p := range a.trackpoints() {
+		if p.HasCadence() {
+			p.Cadence *= 2
+			unit = CadenceSteps
+		}
+	}
+	return unit
+}
+
+// cadence returns the cadence recorded at p: its Cadence, or its TPX
+// RunCadence when it has none.
+func cadence(p *Trackpoint) int {
+	if p.HasCadence() {
+		return p.Cadence
+	}
+	return p.RunCadence
+}
+
+// CadenceStats sums up the cadence of an activity or a lap. Cadence on foot
+// is reported in steps per minute, whatever the recorded convention.
+type CadenceStats struct {
+	Unit CadenceUnit
+	// Average is weighted by time and leaves out stops, where no cadence
+	// is recorded. Max is the highest reading.
+	Average float64
+	Max     int
+	// StrideLength is the distance covered per step on foot, in meters,
+	// the stride length of Garmin devices. It is zero for other sports.
+	StrideLength float64
+}
+
+// cadenceStats computes the cadence statistics of pts, recorded in unit.
+// Readings hold until the next trackpoint, up to 10 s.
+func cadenceStats(pts []*Trackpoint, unit CadenceUnit) CadenceStats {
+	s := CadenceStats{Unit: unit}
+	scale := 1
+	if unit == CadenceStrides {
+		s.Unit, scale = CadenceSteps, 2
+	}
+	var sum, seconds, dist, steps float64
+	for i, p := range pts {
+		c := cadence(p) * scale
+		s.Max = max(s.Max, c)
+		if c <= 0 || i+1 == len(pts) {
+			continue
+		}
+		q := pts[i+1]
+		dt := q.Time.Sub(p.Time).Seconds()
+		if dt <= 0 || dt > maxHoldGap.Seconds() {
+			continue
+		}
+		sum += float64(c) * dt
+		seconds += dt
+		steps += float64(c) / 60 * dt
+		dist += segmentDistance(p, q, true)
+	}
+	if seconds > 0 {
+		s.Average = sum / seconds
+	}
+	if s.Unit == CadenceSteps && steps > 0 {
+		s.StrideLength = dist / steps
+	}
+	return s
+}
+
+// CadenceStats returns the cadence statistics of the activity, read in the
+// unit CadenceUnit detects.
+func (a *Activity) CadenceStats() CadenceStats {
+	return cadenceStats(a.trackpoints(), a.CadenceUnit())
+}
+
+// LapCadenceStats returns the cadence statistics of each lap, in the unit
+// of the whole activity. Laps without a cadence in their track fall back
+// to the lap Cadence.
+func (a *Activity) LapCadenceStats() []CadenceStats {
+	unit := a.CadenceUnit()
+	stats := make([]CadenceStats, len(a.Laps))
+	for i := range a.Laps {
+		l := &a.Laps[i]
+		stats[i] = cadenceStats(pointers(l.Track), unit)
+		if stats[i].Average == 0 && l.Cadence > 0 {
+			c := l.Cadence
+			if unit == CadenceStrides {
+				c *= 2
+			}
+			stats[i].Average = float64(c)
+			stats[i].Max = max(stats[i].Max, c)
+		}
+	}
+	return stats
+}
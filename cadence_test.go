@@ -0,0 +1,77 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeCadence(t *testing.T) {
+	tests := []struct {
+		sport   string
+		speed   float64
+		cadence int
+		want    CadenceUnit
+		wantCad int
+	}{
+		{SportRunning, 3.2, 86, CadenceSteps, 172},
+		{SportRunning, 3.2, 172, CadenceSteps, 172},
+		{SportOther, 1.4, 55, CadenceSteps, 110},
+		{SportOther, 1.4, 112, CadenceSteps, 112},
+		{SportBiking, 8.5, 88, CadenceRPM, 88},
+	}
+	for _, tt := range tests {
+		a := testActivity(tt.sport, repeat(tt.speed, 100), nil)
+		for i := range a.Laps[0].Track {
+			a.Laps[0].Track[i].Cadence = tt.cadence
+		}
+		if got := a.NormalizeCadence(); got != tt.want {
+			t.Errorf("NormalizeCadence() of %s at %d = %q, want %q", tt.sport, tt.cadence, got, tt.want)
+		}
+		if got := a.Laps[0].Track[50].Cadence; got != tt.wantCad {
+			t.Errorf("cadence of %s at %d normalized to %d, want %d", tt.sport, tt.cadence, got, tt.wantCad)
+		}
+	}
+
+	if u := testActivity(SportRunning, repeat(3.2, 100), nil).CadenceUnit(); u != "" {
+		t.Errorf("CadenceUnit() without cadence = %q", u)
+	}
+}
+
+func TestCadenceStats(t *testing.T) {
+	a := testActivity(SportRunning, repeat(3.0, 601), nil)
+	for i := range a.Laps[0].Track {
+		a.Laps[0].Track[i].RunCadence = 85
+	}
+	a.Laps[0].Track[100].RunCadence = 95
+	if err := a.SplitLapAt(a.Laps[0].Track[300].Time); err != nil {
+		t.Fatal(err)
+	}
+
+	// 3 m/s at 170 steps a minute.
+	want := 3 / (170.0 / 60)
+	s := a.CadenceStats()
+	if s.Unit != CadenceSteps || s.Max != 190 || math.Abs(s.Average-170) > 0.1 || math.Abs(s.StrideLength-want) > 0.01 {
+		t.Errorf("CadenceStats() = %+v, want stride length %.3f", s, want)
+	}
+	laps := a.LapCadenceStats()
+	if len(laps) != 2 || laps[0].Max != 190 || laps[1].Max != 170 || math.Abs(laps[1].StrideLength-want) > 0.01 {
+		t.Errorf("LapCadenceStats() = %+v", laps)
+	}
+
+	// The TPX run cadence keeps the Garmin stride convention.
+	a.Laps[1].Cadence = 85
+	if u := a.NormalizeCadence(); u != CadenceStrides || a.Laps[1].Track[0].RunCadence != 85 || a.Laps[1].Cadence != 85 {
+		t.Errorf("NormalizeCadence() = %q, run cadence %d, lap cadence %d", u, a.Laps[1].Track[0].RunCadence, a.Laps[1].Cadence)
+	}
+	if s := a.CadenceStats(); s.Max != 190 || math.Abs(s.StrideLength-want) > 0.01 {
+		t.Errorf("normalized CadenceStats() = %+v", s)
+	}
+
+	ride := testActivity(SportBiking, repeat(8.0, 60), nil)
+	for i := range ride.Laps[0].Track {
+		ride.Laps[0].Track[i].Cadence = 90
+	}
+	if s := ride.CadenceStats(); s.Unit != CadenceRPM || s.Average != 90 || s.StrideLength != 0 {
+		t.Errorf("ride CadenceStats() = %+v", s)
+	}
+}
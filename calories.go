@@ -0,0 +1,189 @@
+package tcx
+
+import "math"
+
+// kcalPerKJ converts kilojoules to kilocalories.
+const kcalPerKJ = 1 / 4.184
+
+// CalorieModel estimates the energy expenditure of an activity, in
+// kilocalories. ok is false when the activity or the athlete profile lacks
+// the data the model needs.
+type CalorieModel interface {
+	Name() string
+	Calories(a *Activity, ath Athlete) (kcal float64, ok bool)
+}
+
+// DeviceCalories reports the calories recorded by the device in the laps.
+type DeviceCalories struct{}
+
+func (DeviceCalories) Name() string { return "device" }
+
+func (DeviceCalories) Calories(a *Activity, ath Athlete) (float64, bool) {
+	var kcal float64
+	for _, l := range a.Laps {
+		kcal += l.Calories
+	}
+	return kcal, kcal > 0
+}
+
+// HeartRateCalories estimates calories from heart rate, weight, age and sex
+// with the Keytel et al. (2005) equations.
+type HeartRateCalories struct{}
+
+func (HeartRateCalories) Name() string { return "heart rate" }
+
+func (HeartRateCalories) Calories(a *Activity, ath Athlete) (float64, bool) {
+	if ath.Weight <= 0 || ath.Age <= 0 || ath.Sex == SexUnknown {
+		return 0, false
+	}
+	var kj, seconds float64
+	for _, iv := range a.intervals() {
+		if iv.p.HeartRateInBpm == 0 {
+			continue
+		}
+		hr, w, age := float64(iv.p.HeartRateInBpm), ath.Weight, float64(ath.Age)
+		var perMin float64
+		if ath.Sex == SexMale {
+			perMin = -55.0969 + 0.6309*hr + 0.1988*w + 0.2017*age
+		} else {
+			perMin = -20.4022 + 0.4472*hr - 0.1263*w + 0.074*age
+		}
+		if perMin > 0 {
+			kj += perMin * iv.dt / 60
+		}
+		seconds += iv.dt
+	}
+	return kj * kcalPerKJ, seconds > 0
+}
+
+// METCalories estimates calories from the metabolic equivalent of the
+// activity and the athlete's weight. When MET is zero, it is derived from
+// speed with the ACSM running and walking equations, or set to 8 for other
+// sports.
+type METCalories struct {
+	MET float64
+}
+
+func (METCalories) Name() string { return "MET" }
+
+func (m METCalories) Calories(a *Activity, ath Athlete) (float64, bool) {
+	if ath.Weight <= 0 {
+		return 0, false
+	}
+	var kcal, seconds float64
+	for _, iv := range a.intervals() {
+		met := m.MET
+		if met == 0 {
+			met = 8
+			v := iv.speed() * 60 // m/min
+			switch {
+			case a.Sport == SportRunning && v > 134:
+				met = (3.5 + 0.2*v) / vo2Rest
+			case a.Sport == SportRunning || a.Sport == SportWalking:
+				met = (3.5 + 0.1*v) / vo2Rest
+			}
+		}
+		kcal += met * ath.Weight * iv.dt / 3600
+		seconds += iv.dt
+	}
+	return kcal, seconds > 0
+}
+
+// PowerCalories converts the mechanical work measured by a power meter into
+// calories, assuming the given gross efficiency (24% when zero).
+type PowerCalories struct {
+	Efficiency float64
+}
+
+func (PowerCalories) Name() string { return "power" }
+
+func (m PowerCalories) Calories(a *Activity, ath Athlete) (float64, bool) {
+	eff := m.Efficiency
+	if eff == 0 {
+		eff = 0.24
+	}
+	var joules float64
+	for _, iv := range a.intervals() {
+		joules += float64(iv.p.PowerInWatts) * iv.dt
+	}
+	return joules / 1000 * kcalPerKJ / eff, joules > 0
+}
+
+// calorieModelName names the model the calories of the lap come from,
+// empty when it has none.
+func (l *Lap) calorieModelName() string {
+	switch {
+	case l.Calories == 0:
+		return ""
+	case l.calorieModel != "":
+		return l.calorieModel
+	}
+	return DeviceCalories{}.Name()
+}
+
+// CalorieEstimate is an energy expenditure along with the model that
+// produced it.
+type CalorieEstimate struct {
+	Calories float64 // kilocalories
+	Model    string
+}
+
+// CalorieModels selects calorie models per sport. Models are tried in order
+// until one of them can estimate the activity.
+type CalorieModels struct {
+	Sport   map[string][]CalorieModel
+	Default []CalorieModel
+}
+
+// DefaultCalorieModels prefers power for rides and otherwise the device
+// figures, falling back to heart rate and MET estimates.
+var DefaultCalorieModels = CalorieModels{
+	Sport: map[string][]CalorieModel{
+		SportBiking: {PowerCalories{}, DeviceCalories{}, HeartRateCalories{}, METCalories{}},
+	},
+	Default: []CalorieModel{DeviceCalories{}, HeartRateCalories{}, METCalories{}},
+}
+
+// Estimate returns the calories of the activity from the first model of its
+// sport able to estimate them. The estimate is zero, with an empty model
+// name, when none can.
+func (m CalorieModels) Estimate(a *Activity, ath Athlete) CalorieEstimate {
+	models, ok := m.Sport[a.Sport]
+	if !ok {
+		models = m.Default
+	}
+	for _, model := range models {
+		if kcal, ok := model.Calories(a, ath); ok {
+			return CalorieEstimate{Calories: kcal, Model: model.Name()}
+		}
+	}
+	return CalorieEstimate{}
+}
+
+// EstimateLaps estimates the calories of each lap of the activity on its
+// own, as Estimate does for the whole activity.
+func (m CalorieModels) EstimateLaps(a *Activity, ath Athlete) []CalorieEstimate {
+	estimates := make([]CalorieEstimate, len(a.Laps))
+	for i := range a.Laps {
+		lap := &Activity{ID: a.ID, Sport: a.Sport, Laps: a.Laps[i : i+1]}
+		estimates[i] = m.Estimate(lap, ath)
+	}
+	return estimates
+}
+
+// FillCalories sets the calories of the laps the device reported as zero
+// to the estimates of m, from duration, heart rate or speed and the
+// athlete profile. It returns the number of laps filled, whose summaries
+// then name the model used.
+func (a *Activity) FillCalories(ath Athlete, m CalorieModels) int {
+	a.Invalidate()
+	n := 0
+	for i, e := range m.EstimateLaps(a, ath) {
+		if a.Laps[i].Calories == 0 && e.Calories > 0 {
+			a.Laps[i].Calories = math.Round(e.Calories)
+			a.Laps[i].calorieModel = e.Model
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,65 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalorieModels(t *testing.T) {
+	ath := Athlete{Weight: 70, Age: 35, Sex: SexMale}
+	run := testActivity(SportRunning, repeat(3.0, 3601), repeat(150, 3601))
+
+	// Keytel: (-55.0969 + 0.6309*150 + 0.1988*70 + 0.2017*35) kJ/min.
+	want := (-55.0969 + 0.6309*150 + 0.1988*70 + 0.2017*35) * 60 / 4.184
+	if kcal, ok := (HeartRateCalories{}).Calories(run, ath); !ok || math.Abs(kcal-want) > 1e-6 {
+		t.Errorf("HeartRateCalories = %v, want %v", kcal, want)
+	}
+	// 180 m/min costs 39.5 ml/kg/min, about 11.3 MET.
+	if kcal, ok := (METCalories{}).Calories(run, ath); !ok || math.Abs(kcal-39.5/3.5*70) > 1e-6 {
+		t.Errorf("METCalories = %v", kcal)
+	}
+
+	est := DefaultCalorieModels.Estimate(run, ath)
+	if est.Model != "heart rate" || math.Abs(est.Calories-want) > 1e-6 {
+		t.Errorf("Estimate() = %+v", est)
+	}
+	run.Laps[0].Calories = 640
+	if est := DefaultCalorieModels.Estimate(run, ath); est.Model != "device" || est.Calories != 640 {
+		t.Errorf("Estimate() = %+v, want device calories", est)
+	}
+
+	ride := withPower(testActivity(SportBiking, repeat(9.0, 3601), nil), repeat(200, 3601))
+	// 720 kJ of work at 24% efficiency.
+	if est := DefaultCalorieModels.Estimate(ride, Athlete{}); est.Model != "power" || math.Abs(est.Calories-720/4.184/0.24) > 1e-6 {
+		t.Errorf("Estimate() = %+v, want power calories", est)
+	}
+	if est := DefaultCalorieModels.Estimate(testActivity(SportOther, repeat(1.0, 10), nil), Athlete{}); est.Model != "" {
+		t.Errorf("Estimate() = %+v, want none", est)
+	}
+}
+
+func TestFillCalories(t *testing.T) {
+	ath := Athlete{Weight: 70, Age: 35, Sex: SexMale}
+	run := testActivity(SportRunning, repeat(3.0, 1201), repeat(150, 1201))
+	if err := run.SplitLapAt(run.Laps[0].Track[600].Time); err != nil {
+		t.Fatal(err)
+	}
+	run.Laps[0].Calories = 0
+	run.Laps[1].Calories = 123
+
+	est := DefaultCalorieModels.EstimateLaps(run, ath)
+	if len(est) != 2 || est[0].Model != "heart rate" || est[1].Model != "device" || est[1].Calories != 123 {
+		t.Fatalf("EstimateLaps() = %+v", est)
+	}
+	if n := run.FillCalories(ath, DefaultCalorieModels); n != 1 {
+		t.Errorf("filled %d laps, want 1", n)
+	}
+	// About ten minutes at 150 bpm.
+	want := (-55.0969 + 0.6309*150 + 0.1988*70 + 0.2017*35) * 10 / 4.184
+	if math.Abs(run.Laps[0].Calories-want) > 1 || run.Laps[1].Calories != 123 {
+		t.Errorf("lap calories = %v, %v, want about %.0f, 123", run.Laps[0].Calories, run.Laps[1].Calories, want)
+	}
+	if n := run.FillCalories(Athlete{}, DefaultCalorieModels); n != 0 {
+		t.Errorf("filled %d laps again", n)
+	}
+}
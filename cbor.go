@@ -0,0 +1,413 @@
+package tcx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// CBOR (RFC 8949) major types.
+const (
+	cborUint   = 0 << 5
+	cborNegInt = 1 << 5
+	cborBytes  = 2 << 5
+	cborText   = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborTag    = 6 << 5
+	cborSimple = 7 << 5
+)
+
+// cborTagTime is the standard tag of RFC 3339 date/time strings.
+const cborTagTime = 0
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	trackpointType = reflect.TypeOf(Trackpoint{})
+)
+
+// cborZerosKey holds the values of a trackpoint recorded as zero.
+const cborZerosKey = "zeros"
+
+// cborMaxDepth bounds the nesting of decoded arrays and maps, deep enough
+// for documents with extensions nested a few dozen levels.
+const cborMaxDepth = 128
+
+// MarshalCBOR encodes the Tcx as CBOR. Structs become maps keyed by field
+// name, zero fields are left out and times are tagged RFC 3339 strings, so
+// the encoding describes itself and stays readable by generic CBOR tools.
+// Trackpoints carry the values recorded as zero under a "zeros" key.
+func (t *Tcx) MarshalCBOR() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := cborEncode(&buf, reflect.ValueOf(t).Elem()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalCBOR decodes CBOR produced by MarshalCBOR into t. Unknown keys are
+// ignored.
+func (t *Tcx) UnmarshalCBOR(data []byte) error {
+	d := cborDecoder{data: data}
+	v, err := d.item()
+	if err != nil {
+		return fmt.Errorf("couldn't parse cbor data: %v", err)
+	}
+	if d.pos != len(data) {
+		return fmt.Errorf("couldn't parse cbor data: %d trailing bytes", len(data)-d.pos)
+	}
+	if err := cborAssign(reflect.ValueOf(t).Elem(), v); err != nil {
+		return fmt.Errorf("couldn't parse cbor data: %v", err)
+	}
+	return nil
+}
+
+// cborHead writes the head of a data item with the smallest argument.
+func cborHead(buf *bytes.Buffer, major byte, n uint64) {
+	switch {
+	case n < 24:
+		buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		buf.Write([]byte{major | 24, byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(major | 27)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+func cborEncode(buf *bytes.Buffer, v reflect.Value) error {
+	if v.Type() == timeType {
+		cborHead(buf, cborTag, cborTagTime)
+		s := v.Interface().(time.Time).Format(time.RFC3339Nano)
+		cborHead(buf, cborText, uint64(len(s)))
+		buf.WriteString(s)
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			buf.WriteByte(cborSimple | 21)
+		} else {
+			buf.WriteByte(cborSimple | 20)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n := v.Int(); n < 0 {
+			cborHead(buf, cborNegInt, uint64(-1-n))
+		} else {
+			cborHead(buf, cborUint, uint64(n))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		cborHead(buf, cborUint, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		// Use single precision when it loses nothing.
+		f := v.Float()
+		if float64(float32(f)) == f {
+			buf.WriteByte(cborSimple | 26)
+			binary.Write(buf, binary.BigEndian, math.Float32bits(float32(f)))
+		} else {
+			buf.WriteByte(cborSimple | 27)
+			binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+		}
+	case reflect.String:
+		cborHead(buf, cborText, uint64(v.Len()))
+		buf.WriteString(v.String())
+	case reflect.Slice:
+		cborHead(buf, cborArray, uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if err := cborEncode(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			buf.WriteByte(cborSimple | 22)
+			return nil
+		}
+		return cborEncode(buf, v.Elem())
+	case reflect.Struct:
+		var fields []int
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.IsExported() && !v.Field(i).IsZero() {
+				fields = append(fields, i)
+			}
+		}
+		var zeros uint64
+		if v.Type() == trackpointType {
+			zeros = v.FieldByName("zeros").Uint()
+		}
+		if zeros != 0 {
+			cborHead(buf, cborMap, uint64(len(fields)+1))
+			cborHead(buf, cborText, uint64(len(cborZerosKey)))
+			buf.WriteString(cborZerosKey)
+			cborHead(buf, cborUint, zeros)
+		} else {
+			cborHead(buf, cborMap, uint64(len(fields)))
+		}
+		for _, i := range fields {
+			name := v.Type().Field(i).Name
+			cborHead(buf, cborText, uint64(len(name)))
+			buf.WriteString(name)
+			if err := cborEncode(buf, v.Field(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cannot encode %s as cbor", v.Type())
+	}
+	return nil
+}
+
+// cborTagged is a decoded tagged data item.
+type cborTagged struct {
+	tag   uint64
+	value any
+}
+
+// cborDecoder decodes data items into generic values: uint64, int64,
+// float64, bool, nil, string, []byte, []any, map[string]any and cborTagged.
+type cborDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (d *cborDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, fmt.Errorf("unexpected end of data")
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *cborDecoder) head() (major, info byte, arg uint64, err error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b[0]&0xE0, b[0]&0x1F
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		b, err := d.next(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		for _, c := range b {
+			arg = arg<<8 | uint64(c)
+		}
+		return major, info, arg, nil
+	}
+	return 0, 0, 0, fmt.Errorf("unsupported additional information %d", info)
+}
+
+func (d *cborDecoder) item() (any, error) {
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUint:
+		return arg, nil
+	case cborNegInt:
+		return -1 - int64(arg), nil
+	case cborBytes, cborText:
+		b, err := d.next(int(arg))
+		if err != nil {
+			return nil, err
+		}
+		if major == cborText {
+			return string(b), nil
+		}
+		return append([]byte(nil), b...), nil
+	case cborArray, cborMap, cborTag:
+		if d.depth++; d.depth > cborMaxDepth {
+			return nil, fmt.Errorf("nesting deeper than %d", cborMaxDepth)
+		}
+		defer func() { d.depth-- }()
+	}
+	switch major {
+	case cborArray:
+		if arg > uint64(len(d.data)) {
+			return nil, fmt.Errorf("array too long")
+		}
+		a := make([]any, arg)
+		for i := range a {
+			if a[i], err = d.item(); err != nil {
+				return nil, err
+			}
+		}
+		return a, nil
+	case cborMap:
+		if arg > uint64(len(d.data)) {
+			return nil, fmt.Errorf("map too long")
+		}
+		m := make(map[string]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			k, err := d.item()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("map key %v is not a string", k)
+			}
+			if m[key], err = d.item(); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case cborTag:
+		v, err := d.item()
+		return cborTagged{arg, v}, err
+	}
+	switch info {
+	case 20, 21:
+		return info == 21, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return halfToFloat(uint16(arg)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case 27:
+		return math.Float64frombits(arg), nil
+	}
+	return nil, fmt.Errorf("unsupported simple value %d", info)
+}
+
+// halfToFloat converts an IEEE 754 half-precision float.
+func halfToFloat(h uint16) float64 {
+	exp, mant := int(h>>10)&0x1F, float64(h&0x3FF)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		f = math.Inf(1)
+		if mant != 0 {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
+
+// cborAssign stores the generic value x into v.
+func cborAssign(v reflect.Value, x any) error {
+	if x == nil {
+		return nil
+	}
+	if v.Type() == timeType {
+		tg, ok := x.(cborTagged)
+		s, isString := tg.value.(string)
+		if !ok || tg.tag != cborTagTime || !isString {
+			return fmt.Errorf("expected a date/time, got %v", x)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	mismatch := fmt.Errorf("cannot store %T in %s", x, v.Type())
+	switch v.Kind() {
+	case reflect.Bool:
+		b, ok := x.(bool)
+		if !ok {
+			return mismatch
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch n := x.(type) {
+		case uint64:
+			v.SetInt(int64(n))
+		case int64:
+			v.SetInt(n)
+		default:
+			return mismatch
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := x.(uint64)
+		if !ok {
+			return mismatch
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		switch n := x.(type) {
+		case float64:
+			v.SetFloat(n)
+		case uint64:
+			v.SetFloat(float64(n))
+		case int64:
+			v.SetFloat(float64(n))
+		default:
+			return mismatch
+		}
+	case reflect.String:
+		s, ok := x.(string)
+		if !ok {
+			return mismatch
+		}
+		v.SetString(s)
+	case reflect.Slice:
+		a, ok := x.([]any)
+		if !ok {
+			return mismatch
+		}
+		s := reflect.MakeSlice(v.Type(), len(a), len(a))
+		for i := range a {
+			if err := cborAssign(s.Index(i), a[i]); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Pointer:
+		p := reflect.New(v.Type().Elem())
+		if err := cborAssign(p.Elem(), x); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Struct:
+		m, ok := x.(map[string]any)
+		if !ok {
+			return mismatch
+		}
+		for k, fx := range m {
+			if k == cborZerosKey && v.Type() == trackpointType && v.CanAddr() {
+				n, ok := fx.(uint64)
+				if !ok {
+					return mismatch
+				}
+				v.Addr().Interface().(*Trackpoint).zeros = presence(n)
+				continue
+			}
+			f, ok := v.Type().FieldByName(k)
+			if !ok || !f.IsExported() || len(f.Index) != 1 {
+				continue
+			}
+			if err := cborAssign(v.Field(f.Index[0]), fx); err != nil {
+				return fmt.Errorf("%s: %v", k, err)
+			}
+		}
+	default:
+		return mismatch
+	}
+	return nil
+}
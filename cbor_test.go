@@ -0,0 +1,92 @@
+package tcx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCBOR(t *testing.T) {
+	orig, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	data, err := orig.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(data)) > info.Size()/3 {
+		t.Errorf("cbor takes %d bytes for %d bytes of xml", len(data), info.Size())
+	}
+
+	var back Tcx
+	if err := back.UnmarshalCBOR(data); err != nil {
+		t.Fatal(err)
+	}
+	if back.XMLNs != orig.XMLNs || !reflect.DeepEqual(back.Activities, orig.Activities) {
+		t.Error("cbor round trip changed the activities")
+	}
+
+	if err := back.UnmarshalCBOR(data[:len(data)-1]); err == nil {
+		t.Error("expected an error for truncated data")
+	}
+}
+
+func TestUnmarshalCBORUnknownKeys(t *testing.T) {
+	// {"XMLNs": "x", "Future": [1, -2, 1.5], "Activities": [{"Sport": "Running"}]}
+	data := []byte{0xa3,
+		0x65, 'X', 'M', 'L', 'N', 's', 0x61, 'x',
+		0x66, 'F', 'u', 't', 'u', 'r', 'e', 0x83, 0x01, 0x21, 0xf9, 0x3e, 0x00,
+		0x6a, 'A', 'c', 't', 'i', 'v', 'i', 't', 'i', 'e', 's', 0x81, 0xa1,
+		0x65, 'S', 'p', 'o', 'r', 't', 0x67, 'R', 'u', 'n', 'n', 'i', 'n', 'g',
+	}
+	var tcx Tcx
+	if err := tcx.UnmarshalCBOR(data); err != nil {
+		t.Fatal(err)
+	}
+	if tcx.XMLNs != "x" || len(tcx.Activities) != 1 || tcx.Activities[0].Sport != "Running" {
+		t.Errorf("decoded %+v", tcx)
+	}
+}
+
+func TestCBORPresenceAndExtensions(t *testing.T) {
+	a := testActivity(SportRunning, repeat(3.0, 3), []int{0, 120, 121})
+	p := &a.Laps[0].Track[0]
+	p.SetHeartRate(0)
+	p.SetCadence(0)
+	p.SetDistance(0)
+	p.OtherExtensions = []ExtensionElement{{
+		XMLName: xml.Name{Space: "http://example.com/ext", Local: "Power"},
+		Attrs:   []xml.Attr{{Name: xml.Name{Local: "unit"}, Value: "W"}},
+		Text:    "0",
+	}}
+	orig := &Tcx{Activities: []Activity{*a}}
+	data, err := orig.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Tcx
+	if err := back.UnmarshalCBOR(data); err != nil {
+		t.Fatal(err)
+	}
+	q := back.Activities[0].Laps[0].Track[0]
+	if !q.HasHeartRate() || !q.HasCadence() || !q.HasDistance() || q.HasPosition() != p.HasPosition() {
+		t.Errorf("recorded zeros lost: %+v", q)
+	}
+	if !reflect.DeepEqual(back.Activities, orig.Activities) {
+		t.Errorf("extensions = %+v, want %+v", q.OtherExtensions, p.OtherExtensions)
+	}
+
+	// Nesting beyond the limit fails instead of exhausting the stack.
+	deep := append(bytes.Repeat([]byte{0x81}, cborMaxDepth+1), 0x01)
+	if err := back.UnmarshalCBOR(deep); err == nil || !strings.Contains(err.Error(), "nesting") {
+		t.Errorf("deeply nested data = %v", err)
+	}
+}
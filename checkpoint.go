@@ -0,0 +1,176 @@
+package tcx
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// PaceModel predicts the time from the start of a course to each of its
+// trackpoints.
+type PaceModel interface {
+	Predict(c *Course) ([]time.Duration, error)
+}
+
+// TargetPace plans the course to finish in Target with Strategy, as
+// PacingPlan does.
+type TargetPace struct {
+	Target   time.Duration
+	Strategy PacingStrategy
+}
+
+func (p TargetPace) Predict(c *Course) ([]time.Duration, error) {
+	var start time.Time
+	plan, err := PacingPlan(c, start, p.Target, p.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	times := make([]time.Duration, len(plan.Track))
+	for i, tp := range plan.Track {
+		times[i] = tp.Time.Sub(start)
+	}
+	return times, nil
+}
+
+// HistoricalPace predicts times from the flat-equivalent moving speed of
+// past activities, adjusted for the grade of every course segment with the
+// energy cost of running on grades. Slowdown multiplies the predicted times,
+// such as 1.1 for a race longer than the history.
+type HistoricalPace struct {
+	Activities []*Activity
+	Slowdown   float64
+}
+
+// flatSpeed returns the grade-adjusted moving speed, in m/s, over the
+// activities.
+func (p HistoricalPace) flatSpeed() float64 {
+	var dist, secs float64
+	for _, a := range p.Activities {
+		pts := a.trackpoints()
+		for i := 1; i < len(pts); i++ {
+			q, r := pts[i-1], pts[i]
+			dt := r.Time.Sub(q.Time).Seconds()
+			if dt <= 0 || dt > maxHoldGap.Seconds() || !q.HasPosition() || !r.HasPosition() {
+				continue
+			}
+			d := distance(q.LatitudeInDegrees, q.LongitudeInDegrees, r.LatitudeInDegrees, r.LongitudeInDegrees)
+			if d/dt < inferMovingSpeed {
+				continue
+			}
+			dist += d * minettiCost((r.AltitudeInMeters-q.AltitudeInMeters)/d) / minettiCost(0)
+			secs += dt
+		}
+	}
+	if secs == 0 {
+		return 0
+	}
+	return dist / secs
+}
+
+func (p HistoricalPace) Predict(c *Course) ([]time.Duration, error) {
+	speed := p.flatSpeed()
+	if speed <= 0 {
+		return nil, fmt.Errorf("no moving history to predict %q from", c.Name)
+	}
+	slowdown := p.Slowdown
+	if slowdown <= 0 {
+		slowdown = 1
+	}
+	dist := c.Distances()
+	times := make([]time.Duration, len(dist))
+	elapsed := 0.0
+	for i := 1; i < len(dist); i++ {
+		if d := dist[i] - dist[i-1]; d > 0 {
+			grade := (c.Track[i].AltitudeInMeters - c.Track[i-1].AltitudeInMeters) / d
+			elapsed += d * minettiCost(grade) / minettiCost(0) / speed * slowdown
+		}
+		times[i] = time.Duration(elapsed * float64(time.Second))
+	}
+	return times, nil
+}
+
+// CheckpointETA is the predicted and, after the event, actual passing time
+// at a course point.
+type CheckpointETA struct {
+	Point    CoursePoint
+	Distance float64 // meters from the start of the course
+	ETA      time.Time
+	// Actual is zero until filled in by CompareCheckpoints, and stays zero
+	// when the activity did not pass the checkpoint.
+	Actual time.Time
+	// Delta is Actual - ETA: positive when late.
+	Delta time.Duration
+}
+
+// nearestFrom returns the index of the position of pts passing closest to
+// lat, lon from index from on, and its distance in meters. The first pass
+// within 50 m wins over later, closer ones; without any, the nearest
+// position does.
+func nearestFrom(pts []*Trackpoint, from int, lat, lon float64) (int, float64) {
+	best, bestD := -1, math.Inf(1)
+	for i := from; i < len(pts); i++ {
+		if !pts[i].HasPosition() {
+			continue
+		}
+		d := distance(lat, lon, pts[i].LatitudeInDegrees, pts[i].LongitudeInDegrees)
+		if bestD <= checkpointRadius && d > checkpointRadius {
+			break
+		}
+		if d < bestD {
+			best, bestD = i, d
+		}
+	}
+	return best, bestD
+}
+
+// CheckpointETAs returns the estimated arrival time at each course point,
+// in order, when starting at start with pace model m. Course points are
+// located on the track in order, so loops and out-and-back courses resolve
+// to the right passage. Checkpoints beyond the last time predicted by m are
+// left out.
+func (c *Course) CheckpointETAs(start time.Time, m PaceModel) ([]CheckpointETA, error) {
+	times, err := m.Predict(c)
+	if err != nil {
+		return nil, err
+	}
+	pts := pointers(c.Track)
+	dist := c.Distances()
+	var etas []CheckpointETA
+	from := 0
+	for _, cp := range c.CoursePoints {
+		i, _ := nearestFrom(pts, from, cp.LatitudeInDegrees, cp.LongitudeInDegrees)
+		if i < 0 {
+			return nil, fmt.Errorf("course %q has no track to place %q on", c.Name, cp.Name)
+		}
+		if i >= len(times) {
+			continue
+		}
+		etas = append(etas, CheckpointETA{Point: cp, Distance: dist[i], ETA: start.Add(times[i])})
+		from = i
+	}
+	return etas, nil
+}
+
+// checkpointRadius is the distance, in meters, within which an activity
+// passes a checkpoint.
+const checkpointRadius = 50.0
+
+// CompareCheckpoints returns a copy of etas with the times at which the
+// activity passed each checkpoint, within 50 m, and the difference to the
+// estimate.
+func CompareCheckpoints(etas []CheckpointETA, a *Activity) []CheckpointETA {
+	out := append([]CheckpointETA(nil), etas...)
+	pts := a.trackpoints()
+	from := 0
+	for k := range out {
+		cp := out[k].Point
+		i, d := nearestFrom(pts, from, cp.LatitudeInDegrees, cp.LongitudeInDegrees)
+		if i < 0 || d > checkpointRadius {
+			continue
+		}
+		out[k].Actual = pts[i].Time
+		out[k].Delta = out[k].Actual.Sub(out[k].ETA)
+		from = i
+	}
+	return out
+}
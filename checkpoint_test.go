@@ -0,0 +1,95 @@
+package tcx
+
+import (
+	"testing"
+	"time"
+)
+
+// outAndBack returns a flat track running 1000 m north and back at speed.
+func outAndBack(speed float64) []Trackpoint {
+	n := int(1000/speed) + 1
+	track := testActivity(SportRunning, repeat(speed, n), nil).Laps[0].Track
+	for i := n - 2; i >= 0; i-- {
+		p := track[i]
+		p.Time = track[len(track)-1].Time.Add(time.Second)
+		track = append(track, p)
+	}
+	return track
+}
+
+func TestCheckpointETAs(t *testing.T) {
+	c := &Course{Name: "Out and back", Track: outAndBack(5)}
+	aid, turn := c.Track[100], c.Track[200]
+	for _, p := range []Trackpoint{aid, turn, aid} {
+		c.CoursePoints = append(c.CoursePoints, CoursePoint{
+			Name: "Aid", LatitudeInDegrees: p.LatitudeInDegrees, LongitudeInDegrees: p.LongitudeInDegrees, PointType: "Food",
+		})
+	}
+	start := time.Date(2015, 4, 12, 9, 0, 0, 0, time.UTC)
+
+	etas, err := c.CheckpointETAs(start, TargetPace{Target: 20 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []time.Duration{5 * time.Minute, 10 * time.Minute, 15 * time.Minute} {
+		if d := etas[i].ETA.Sub(start) - want; d < -time.Second || d > time.Second {
+			t.Errorf("checkpoint %d at %.f m due after %v, want %v", i, etas[i].Distance, etas[i].ETA.Sub(start), want)
+		}
+	}
+
+	history := testActivity(SportRunning, repeat(4.0, 600), nil)
+	etas, err = c.CheckpointETAs(start, HistoricalPace{Activities: []*Activity{history}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := etas[2].ETA.Sub(start) - 375*time.Second; d < -time.Second || d > time.Second {
+		t.Errorf("historical ETA at 1500 m = %v, want 6m15s", etas[2].ETA.Sub(start))
+	}
+
+	// The race itself is run at 5 m/s, starting at 09:00 like the plan.
+	race := &Activity{Sport: SportRunning, Laps: []Lap{{Track: outAndBack(5)}}}
+	for i := range race.Laps[0].Track {
+		race.Laps[0].Track[i].Time = start.Add(time.Duration(i) * time.Second)
+	}
+	got := CompareCheckpoints(etas, race)
+	for i, want := range []time.Duration{100 * time.Second, 200 * time.Second, 300 * time.Second} {
+		if got[i].Actual.Sub(start) != want {
+			t.Errorf("checkpoint %d passed after %v, want %v", i, got[i].Actual.Sub(start), want)
+		}
+	}
+	if d := got[1].Delta; d < -51*time.Second || d > -49*time.Second {
+		t.Errorf("turnaround delta = %v, want -50s", d)
+	}
+	if !etas[0].Actual.IsZero() {
+		t.Error("CompareCheckpoints modified its input")
+	}
+}
+
+// partialPace predicts the first n trackpoints of a course at 1 s each.
+type partialPace int
+
+func (n partialPace) Predict(c *Course) ([]time.Duration, error) {
+	times := make([]time.Duration, min(int(n), len(c.Track)))
+	for i := range times {
+		times[i] = time.Duration(i) * time.Second
+	}
+	return times, nil
+}
+
+func TestCheckpointETAsUnreached(t *testing.T) {
+	c := &Course{Name: "Out and back", Track: outAndBack(5)}
+	for _, i := range []int{100, 180} {
+		p := c.Track[i]
+		c.CoursePoints = append(c.CoursePoints, CoursePoint{
+			Name: "Aid", LatitudeInDegrees: p.LatitudeInDegrees, LongitudeInDegrees: p.LongitudeInDegrees, PointType: "Food",
+		})
+	}
+	start := time.Date(2015, 4, 12, 9, 0, 0, 0, time.UTC)
+	etas, err := c.CheckpointETAs(start, partialPace(150))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(etas) != 1 || etas[0].ETA.Sub(start) != 100*time.Second {
+		t.Errorf("CheckpointETAs() = %+v, want the first checkpoint only", etas)
+	}
+}
@@ -0,0 +1,128 @@
+package tcx
+
+// Clone returns a deep copy of the document, sharing no memory with it.
+func (t *Tcx) Clone() *Tcx {
+	c := *t
+	c.Activities = cloneSlice(t.Activities, func(a *Activity) Activity { return *a.Clone() })
+	c.MultiSportSessions = cloneSlice(t.MultiSportSessions, func(s *MultiSportSession) MultiSportSession {
+		m := *s
+		m.FirstSport = *s.FirstSport.Clone()
+		m.NextSports = cloneSlice(s.NextSports, func(n *NextSport) NextSport {
+			next := NextSport{Activity: *n.Activity.Clone()}
+			if n.Transition != nil {
+				l := n.Transition.clone()
+				next.Transition = &l
+			}
+			return next
+		})
+		return m
+	})
+	c.Workouts = cloneSlice(t.Workouts, func(w *Workout) Workout {
+		c := *w
+		c.Steps = cloneSteps(w.Steps)
+		return c
+	})
+	c.Courses = cloneSlice(t.Courses, func(course *Course) Course {
+		c := *course
+		c.Laps = cloneSlice(course.Laps, func(l *CourseLap) CourseLap { return *l })
+		c.Track = cloneTrack(course.Track)
+		c.CoursePoints = cloneSlice(course.CoursePoints, func(p *CoursePoint) CoursePoint { return *p })
+		return c
+	})
+	c.Author = clonePtr(t.Author)
+	c.Warnings = cloneSlice(t.Warnings, func(w *Warning) Warning { return *w })
+	return &c
+}
+
+// Clone returns a deep copy of the activity, sharing no memory with it,
+// which may be changed while a is still read from other goroutines.
+func (a *Activity) Clone() *Activity {
+	statsMu.RLock()
+	c := *a
+	statsMu.RUnlock()
+	c.stats = nil
+	c.Laps = cloneSlice(a.Laps, (*Lap).clone)
+	if a.Extensions != nil {
+		ext := *a.Extensions
+		if ext.Metadata != nil {
+			m := *ext.Metadata
+			m.Tags = append([]string(nil), m.Tags...)
+			ext.Metadata = &m
+		}
+		ext.Other = cloneElements(ext.Other)
+		c.Extensions = &ext
+	}
+	return &c
+}
+
+// clone returns a deep copy of the lap.
+func (l *Lap) clone() Lap {
+	c := *l
+	c.Track = cloneTrack(l.Track)
+	if l.Extensions != nil {
+		ext := *l.Extensions
+		if ext.LX != nil {
+			lx := *ext.LX
+			lx.AvgSpeed = clonePtr(lx.AvgSpeed)
+			lx.MaxBikeCadence = clonePtr(lx.MaxBikeCadence)
+			lx.AvgRunCadence = clonePtr(lx.AvgRunCadence)
+			lx.MaxRunCadence = clonePtr(lx.MaxRunCadence)
+			lx.Steps = clonePtr(lx.Steps)
+			lx.AvgWatts = clonePtr(lx.AvgWatts)
+			lx.MaxWatts = clonePtr(lx.MaxWatts)
+			ext.LX = &lx
+		}
+		ext.FatCalories = clonePtr(ext.FatCalories)
+		ext.Other = cloneElements(ext.Other)
+		c.Extensions = &ext
+	}
+	return c
+}
+
+func cloneTrack(track []Trackpoint) []Trackpoint {
+	return cloneSlice(track, func(p *Trackpoint) Trackpoint {
+		c := *p
+		if p.RRIntervals != nil {
+			c.RRIntervals = append([]int(nil), p.RRIntervals...)
+		}
+		c.OtherExtensions = cloneElements(p.OtherExtensions)
+		return c
+	})
+}
+
+func cloneSteps(steps []WorkoutStep) []WorkoutStep {
+	return cloneSlice(steps, func(s *WorkoutStep) WorkoutStep {
+		c := *s
+		c.Duration = clonePtr(s.Duration)
+		if s.Target != nil {
+			target := *s.Target
+			target.SpeedZone = clonePtr(target.SpeedZone)
+			target.HeartRateZone = clonePtr(target.HeartRateZone)
+			target.CadenceZone = clonePtr(target.CadenceZone)
+			c.Target = &target
+		}
+		c.Children = cloneSteps(s.Children)
+		return c
+	})
+}
+
+// cloneSlice returns a copy of s with each element copied by clone, or nil
+// when s is nil.
+func cloneSlice[T any](s []T, clone func(*T) T) []T {
+	if s == nil {
+		return nil
+	}
+	c := make([]T, len(s))
+	for i := range s {
+		c[i] = clone(&s[i])
+	}
+	return c
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
@@ -0,0 +1,63 @@
+package tcx
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTcxClone(t *testing.T) {
+	db, err := Parse(strings.NewReader(multisportTCX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := Parse(strings.NewReader(`<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"><Workouts>` +
+		strings.Replace(testWorkout, ` xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"`, "", 1) + `</Workouts></TrainingCenterDatabase>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Workouts = w.Workouts
+	db.Activities = append(db.Activities, *testActivity(SportRunning, repeat(4.0, 10), nil))
+	db.Courses = append(db.Courses, Course{Name: "Loop", Track: db.Activities[0].Laps[0].Track})
+	db.Author = &Author{Name: "go-tcx"}
+
+	c := db.Clone()
+	if !reflect.DeepEqual(c, db) {
+		t.Fatal("clone differs from its source")
+	}
+	c.Activities[0].Laps[0].Track[0].HeartRateInBpm = 1
+	c.MultiSportSessions[0].NextSports[0].Transition.TotalTimeInSeconds = 1
+	c.Workouts[0].Steps[1].Children[0].Target.SpeedZone.High = 1
+	c.Courses[0].Track[0].Cadence = 1
+	c.Author.Name = "other"
+	if !reflect.DeepEqual(db.Activities[0].Laps[0].Track[0], db.Courses[0].Track[0]) ||
+		db.MultiSportSessions[0].NextSports[0].Transition.TotalTimeInSeconds != 120 ||
+		db.Workouts[0].Steps[1].Children[0].Target.SpeedZone.High != 4.5 ||
+		db.Courses[0].Track[0].Cadence == 1 || db.Author.Name != "go-tcx" {
+		t.Error("clone shares memory with its source")
+	}
+}
+
+func TestConcurrentReaders(t *testing.T) {
+	db, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &db.Activities[0]
+	want := a.Summary()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s := a.Stats(); !reflect.DeepEqual(s, want) {
+				t.Errorf("Stats = %+v, want %+v", s, want)
+			}
+			a.MeanMaxSpeed()
+			c := a.Clone()
+			c.ShiftTime(60e9)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,269 @@
+// Command tcx inspects and converts TCX files from the command line:
+//
+//	tcx info file.tcx...
+//	tcx convert -to gpx|geojson|csv|tcx [-o out] file.tcx
+//	tcx merge [-o out] a.tcx b.tcx...
+//	tcx crop [-start t] [-end t] [-o out] file.tcx
+//
+// Files may be gzip compressed; "-" reads the standard input. Output goes
+// to the standard output unless -o is given. Crop times are RFC 3339
+// timestamps or durations from the start of each activity, such as 10m.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+const usage = `usage:
+  tcx info file.tcx...
+  tcx convert -to gpx|geojson|csv|tcx [-o out] file.tcx
+  tcx merge [-o out] a.tcx b.tcx...
+  tcx crop [-start t] [-end t] [-o out] file.tcx
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "tcx:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command of args, reading "-" from stdin and writing to
+// stdout.
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", usage)
+	}
+	commands := map[string]func([]string, io.Reader, io.Writer) error{
+		"info":    info,
+		"convert": convert,
+		"merge":   merge,
+		"crop":    crop,
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+	return cmd(args[1:], stdin, stdout)
+}
+
+// parse reads the TCX file path, or stdin for "-".
+func parse(path string, stdin io.Reader) (*tcx.Tcx, error) {
+	if path == "-" {
+		return tcx.Parse(stdin)
+	}
+	return tcx.ParseFile(path)
+}
+
+// output returns the writer of the -o flag value path, stdout when empty,
+// and a function closing it.
+func output(path string, stdout io.Writer) (io.Writer, func() error, error) {
+	if path == "" {
+		return stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+// flags parses the flags of a command expecting at least min files.
+func flags(fs *flag.FlagSet, args []string, min int) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("%s: %v", fs.Name(), err)
+	}
+	if fs.NArg() < min {
+		return nil, fmt.Errorf("%s: missing file\n%s", fs.Name(), usage)
+	}
+	return fs.Args(), nil
+}
+
+func info(args []string, stdin io.Reader, stdout io.Writer) error {
+	files, err := flags(flag.NewFlagSet("info", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		t, err := parse(path, stdin)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		for i, a := range t.AllActivities() {
+			s := a.Stats()
+			trackpoints := 0
+			for _, l := range a.Laps {
+				trackpoints += len(l.Track)
+			}
+			fmt.Fprintf(stdout, "%s activity %d: %s, %s\n", path, i+1, a.Sport, a.ID.Format(time.RFC3339))
+			fmt.Fprintf(stdout, "  laps          %d, %d trackpoints\n", len(a.Laps), trackpoints)
+			fmt.Fprintf(stdout, "  duration      %v (moving %v)\n", s.Duration.Round(time.Second), s.MovingTime.Round(time.Second))
+			fmt.Fprintf(stdout, "  distance      %.2f km\n", s.Distance/1000)
+			fmt.Fprintf(stdout, "  speed         %.1f km/h average, %.1f km/h max, pace %v\n", s.AverageSpeed*3.6, s.MaxSpeed*3.6, s.Pace)
+			if s.MaxHeartRate > 0 {
+				fmt.Fprintf(stdout, "  heart rate    %.0f bpm average, %d bpm max\n", s.AverageHeartRate, s.MaxHeartRate)
+			}
+			if s.AverageCadence > 0 {
+				fmt.Fprintf(stdout, "  cadence       %.0f\n", s.AverageCadence)
+			}
+			fmt.Fprintf(stdout, "  elevation     +%.0f m, -%.0f m\n", s.Ascent, s.Descent)
+			if s.Calories > 0 {
+				fmt.Fprintf(stdout, "  calories      %.0f kcal\n", s.Calories)
+			}
+		}
+	}
+	return nil
+}
+
+func convert(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	to := fs.String("to", "", "output format: gpx, geojson, csv or tcx")
+	out := fs.String("o", "", "output file")
+	files, err := flags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	format := strings.ToLower(*to)
+	switch format {
+	case "gpx", "geojson", "csv", "tcx":
+	default:
+		return fmt.Errorf("convert: unknown format %q\n%s", *to, usage)
+	}
+	t, err := parse(files[0], stdin)
+	if err != nil {
+		return fmt.Errorf("%s: %v", files[0], err)
+	}
+	w, done, err := output(*out, stdout)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "gpx":
+		err = t.ToGPX(w)
+	case "geojson":
+		err = t.ToGeoJSON(w, tcx.GeoJSONOptions{})
+	case "csv":
+		// One table: the header comes with the first activity only.
+		opts := tcx.DefaultCSVOptions
+		for _, a := range t.AllActivities() {
+			if err = a.WriteCSV(w, opts); err != nil {
+				break
+			}
+			opts.NoHeader = true
+		}
+	case "tcx":
+		err = t.Write(w)
+	}
+	if cerr := done(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func merge(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	out := fs.String("o", "", "output file")
+	paths, err := flags(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	var files []*tcx.Tcx
+	for _, path := range paths {
+		t, err := parse(path, stdin)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		files = append(files, t)
+	}
+	m, err := tcx.MergeFiles(files...)
+	if err != nil {
+		return err
+	}
+	return write(m, *out, stdout)
+}
+
+// cropTime parses the -start or -end flag value s, a timestamp or an
+// offset from start.
+func cropTime(s string, start time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("crop: %q is neither a time nor a duration", s)
+	}
+	return start.Add(d), nil
+}
+
+func crop(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("crop", flag.ContinueOnError)
+	startFlag := fs.String("start", "", "first time kept")
+	endFlag := fs.String("end", "", "last time kept")
+	out := fs.String("o", "", "output file")
+	files, err := flags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	t, err := parse(files[0], stdin)
+	if err != nil {
+		return fmt.Errorf("%s: %v", files[0], err)
+	}
+	// Offsets count from the start of each activity, or of each multisport
+	// session for its legs.
+	cropAll := func(activities []*tcx.Activity) error {
+		if len(activities) == 0 {
+			return nil
+		}
+		origin := activities[0].ID
+		if len(activities[0].Laps) > 0 {
+			origin = activities[0].Laps[0].StartTime
+		}
+		start, err := cropTime(*startFlag, origin)
+		if err != nil {
+			return err
+		}
+		end, err := cropTime(*endFlag, origin)
+		if err != nil {
+			return err
+		}
+		for _, a := range activities {
+			*a = *a.Crop(start, end)
+		}
+		return nil
+	}
+	for i := range t.Activities {
+		if err := cropAll([]*tcx.Activity{&t.Activities[i]}); err != nil {
+			return err
+		}
+	}
+	for i := range t.MultiSportSessions {
+		if err := cropAll(t.MultiSportSessions[i].Legs()); err != nil {
+			return err
+		}
+	}
+	return write(t, *out, stdout)
+}
+
+// write writes t as TCX to the -o flag value path, or stdout.
+func write(t *tcx.Tcx, path string, stdout io.Writer) error {
+	w, done, err := output(path, stdout)
+	if err != nil {
+		return err
+	}
+	err = t.WriteIndent(w, "", "  ")
+	if cerr := done(); err == nil {
+		err = cerr
+	}
+	return err
+}
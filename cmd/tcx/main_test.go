@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+const testFile = "../../testdata/test1.tcx"
+
+func TestInfo(t *testing.T) {
+	var out bytes.Buffer
+	if err := run([]string{"info", testFile}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"activity 1: Running", "25, 1937 trackpoints", "distance", "heart rate"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("info output lacks %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	for format, want := range map[string]string{
+		"gpx":     "<gpx",
+		"geojson": `"FeatureCollection"`,
+		"csv":     "time,",
+		"tcx":     "<TrainingCenterDatabase",
+	} {
+		var out bytes.Buffer
+		if err := run([]string{"convert", "-to", format, testFile}, nil, &out); err != nil {
+			t.Errorf("%s: %v", format, err)
+			continue
+		}
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("%s output lacks %q", format, want)
+		}
+	}
+	if err := run([]string{"convert", "-to", "fit", testFile}, nil, &bytes.Buffer{}); err == nil {
+		t.Error("converted to an unknown format")
+	}
+}
+
+func TestMergeAndCrop(t *testing.T) {
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := tcx.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := db.Activities[0].Laps[0].StartTime
+
+	// Crop the file in two halves read from stdin, then merge them back.
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.tcx"), filepath.Join(dir, "second.tcx")
+	mid := start.Add(20 * time.Minute).Format("2006-01-02T15:04:05Z07:00")
+	if err := run([]string{"crop", "-end", "20m", "-o", first, "-"}, bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := run([]string{"crop", "-start", mid, "-o", second, testFile}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := run([]string{"merge", first, second}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	merged, err := tcx.Parse(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, l := range merged.Activities[0].Laps {
+		n += len(l.Track)
+	}
+	// Merging drops the trackpoint the halves share, and one the original
+	// repeats.
+	if len(merged.Activities) != 1 || n != 1936 {
+		t.Errorf("merged %d activities, %d trackpoints", len(merged.Activities), n)
+	}
+
+	if err := run([]string{"crop", "-start", "soon", testFile}, nil, &out); err == nil {
+		t.Error("cropped with an invalid start")
+	}
+	if err := run([]string{"split"}, nil, &out); err == nil {
+		t.Error("ran an unknown command")
+	}
+}
+
+func TestConvertChecksFormatFirst(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.fit")
+	if err := run([]string{"convert", "-to", "fit", "-o", out, testFile}, nil, nil); err == nil {
+		t.Fatal("converted to an unknown format")
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("created %s for an unknown format", out)
+	}
+}
+
+func TestMultiSport(t *testing.T) {
+	db, err := tcx.ParseFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run1, bike := db.Activities[0], db.Activities[0]
+	bike.Sport = tcx.SportBiking
+	db.Activities = []tcx.Activity{run1}
+	db.MultiSportSessions = []tcx.MultiSportSession{{
+		ID: run1.ID, FirstSport: run1, NextSports: []tcx.NextSport{{Activity: bike}},
+	}}
+	path := filepath.Join(t.TempDir(), "multisport.tcx")
+	if err := db.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"convert", "-to", "csv", path}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(out.String(), "time,"); n != 1 {
+		t.Errorf("CSV has %d header rows, want 1", n)
+	}
+	if rows := strings.Count(out.String(), "\n"); rows != 3*1937+1 {
+		t.Errorf("CSV has %d rows", rows)
+	}
+
+	out.Reset()
+	if err := run([]string{"crop", "-end", "10m", path}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	cropped, err := tcx.Parse(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	end := run1.Laps[0].StartTime.Add(10 * time.Minute)
+	kept := 0
+	for _, a := range cropped.AllActivities() {
+		for _, l := range a.Laps {
+			kept += len(l.Track)
+			if n := len(l.Track); n > 0 && l.Track[n-1].Time.After(end) {
+				t.Fatalf("%s leg kept a trackpoint at %v", a.Sport, l.Track[n-1].Time)
+			}
+		}
+	}
+	if kept == 0 {
+		t.Error("crop kept no trackpoint")
+	}
+}
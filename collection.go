@@ -0,0 +1,178 @@
+package tcx
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Collection is a set of activities, such as a training log, sorted by
+// start time.
+type Collection struct {
+	Activities []*Activity
+	// Location is the time zone weeks and months are counted in; nil is
+	// UTC.
+	Location *time.Location
+}
+
+// NewCollection returns a collection of the activities.
+func NewCollection(activities []*Activity) *Collection {
+	c := &Collection{Activities: append([]*Activity(nil), activities...)}
+	sort.SliceStable(c.Activities, func(i, j int) bool {
+		return c.Activities[i].start().Before(c.Activities[j].start())
+	})
+	return c
+}
+
+// LoadDir parses every TCX file, plain or gzip compressed, under dir and its
+// subdirectories, several at a time, and returns their activities,
+// multisport legs included, as a collection. Files failing to parse are
+// skipped: the collection of the others is returned along with an error
+// joining theirs.
+func LoadDir(dir string) (*Collection, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && isTCXName(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list %s: %v", dir, err)
+	}
+
+	files := make([]*Tcx, len(paths))
+	errs := make([]error, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(runtime.GOMAXPROCS(0), len(paths)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				files[i], errs[i] = ParseFile(paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var activities []*Activity
+	var failed []error
+	for i, f := range files {
+		if errs[i] != nil {
+			failed = append(failed, fmt.Errorf("couldn't load %s: %v", paths[i], errs[i]))
+			continue
+		}
+		activities = append(activities, f.AllActivities()...)
+	}
+	return NewCollection(activities), errors.Join(failed...)
+}
+
+// Totals sums the activities of a collection.
+type Totals struct {
+	Activities int
+	Distance   float64 // meters
+	Duration   time.Duration
+	Ascent     float64 // meters
+}
+
+func (t *Totals) add(a *Activity) {
+	t.Activities++
+	t.Distance += a.TotalDistance()
+	t.Duration += a.TotalDuration()
+	t.Ascent += a.TotalAscent()
+}
+
+// PeriodTotals sums the activities started in the period beginning at Start.
+type PeriodTotals struct {
+	Start time.Time
+	Totals
+}
+
+// Total returns the totals of every activity of the collection.
+func (c *Collection) Total() Totals {
+	var t Totals
+	for _, a := range c.Activities {
+		t.add(a)
+	}
+	return t
+}
+
+// BySport returns the totals of the collection for each sport.
+func (c *Collection) BySport() map[string]Totals {
+	m := make(map[string]Totals)
+	for _, a := range c.Activities {
+		t := m[a.Sport]
+		t.add(a)
+		m[a.Sport] = t
+	}
+	return m
+}
+
+// ByWeek returns the totals of the weeks, starting on Monday, in which
+// activities were done, in order.
+func (c *Collection) ByWeek() []PeriodTotals {
+	return c.byPeriod(weekStart)
+}
+
+// ByMonth returns the totals of the months in which activities were done,
+// in order.
+func (c *Collection) ByMonth() []PeriodTotals {
+	return c.byPeriod(monthStart)
+}
+
+// dayStart returns the midnight starting the day of t.
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// weekStart returns the midnight starting the ISO week of t, on Monday.
+func weekStart(t time.Time) time.Time {
+	day := dayStart(t)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// monthStart returns the midnight starting the month of t.
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// location returns the time zone of the collection.
+func (c *Collection) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+	return c.Location
+}
+
+// byPeriod groups the activities by the start of the period they started
+// in, given by period.
+func (c *Collection) byPeriod(period func(time.Time) time.Time) []PeriodTotals {
+	loc := c.location()
+	var periods []PeriodTotals
+	index := make(map[time.Time]int)
+	for _, a := range c.Activities {
+		start := period(a.start().In(loc))
+		i, ok := index[start]
+		if !ok {
+			i = len(periods)
+			index[start] = i
+			periods = append(periods, PeriodTotals{Start: start})
+		}
+		periods[i].add(a)
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Start.Before(periods[j].Start) })
+	return periods
+}
@@ -0,0 +1,83 @@
+package tcx
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadDir(t *testing.T) {
+	data, err := os.ReadFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "2015"), 0o755)
+	for path, content := range map[string][]byte{
+		"a.tcx":         data,
+		"2015/b.tcx.gz": gzipped(t, data),
+		"notes.txt":     []byte("not an activity"),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, path), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Activities) != 2 || c.Total().Activities != 2 {
+		t.Fatalf("loaded %d activities, want 2", len(c.Activities))
+	}
+	one := c.Activities[0]
+	if got := c.Total().Distance; got != 2*one.TotalDistance() {
+		t.Errorf("total distance = %v", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, "broken.tcx"), []byte("<TrainingCenterDatabase>"), 0o644)
+	c, err = LoadDir(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.tcx") {
+		t.Errorf("LoadDir with a broken file = %v", err)
+	}
+	if c == nil || len(c.Activities) != 2 {
+		t.Error("LoadDir dropped the good files along with a broken one")
+	}
+}
+
+func TestCollectionTotals(t *testing.T) {
+	day := func(d, h int, sport string) *Activity {
+		a := testActivity(sport, repeat(4.0, 601), nil)
+		a.Laps[0].update()
+		a.ShiftTime(time.Date(2015, 4, d, h, 0, 0, 0, time.UTC).Sub(a.ID))
+		return a
+	}
+	// Sunday 12 and Monday 13 April; the last run is on 1 May in Paris.
+	c := NewCollection([]*Activity{
+		day(13, 7, SportRunning), day(12, 7, SportRunning), day(12, 9, SportBiking), day(30, 23, SportRunning),
+	})
+	if !c.Activities[0].ID.Equal(time.Date(2015, 4, 12, 7, 0, 0, 0, time.UTC)) {
+		t.Error("collection not sorted by start")
+	}
+	c.Location = time.FixedZone("CEST", 2*3600)
+
+	weeks := c.ByWeek()
+	if len(weeks) != 3 || weeks[0].Activities != 2 || weeks[1].Activities != 1 {
+		t.Fatalf("weeks = %+v", weeks)
+	}
+	if want := time.Date(2015, 4, 6, 0, 0, 0, 0, c.Location); !weeks[0].Start.Equal(want) || weeks[1].Start.Weekday() != time.Monday {
+		t.Errorf("first week starts %v, want %v", weeks[0].Start, want)
+	}
+	months := c.ByMonth()
+	if len(months) != 2 || months[0].Activities != 3 || months[1].Start.Month() != time.May {
+		t.Errorf("months = %+v", months)
+	}
+	if weeks[0].Duration != 20*time.Minute {
+		t.Errorf("week duration = %v", weeks[0].Duration)
+	}
+	sports := c.BySport()
+	if sports[SportRunning].Activities != 3 || sports[SportBiking].Activities != 1 {
+		t.Errorf("sports = %+v", sports)
+	}
+}
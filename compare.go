@@ -0,0 +1,87 @@
+package tcx
+
+import (
+	"math"
+	"time"
+)
+
+// compareStep is the distance, in meters, between the points of Diff.Gaps.
+const compareStep = 100.0
+
+// SplitDiff compares one kilometer split of two activities.
+type SplitDiff struct {
+	A, B Split
+	// Delta is the time of B over the split less that of A: negative when B
+	// was faster.
+	Delta time.Duration
+}
+
+// Gap is the time between two activities at a distance along their route.
+type Gap struct {
+	Distance float64 // meters from the start
+	// Delta is how far B is behind A at that distance: negative when B is
+	// ahead.
+	Delta time.Duration
+	// PaceA and PaceB are the paces of each activity over the last 100 m.
+	PaceA, PaceB Pace
+}
+
+// Diff is the comparison of two efforts on the same route.
+type Diff struct {
+	// Duration and Distance are the totals of B less those of A.
+	Duration time.Duration
+	Distance float64 // meters
+	// Splits compares the kilometer splits both activities cover.
+	Splits []SplitDiff
+	// Gaps follows the gap between the activities every 100 m along the
+	// distance both cover, like a virtual partner.
+	Gaps []Gap
+}
+
+// Compare compares b to a, two efforts on the same route such as repeats
+// of a standard loop. Times are matched by the distance covered since the
+// start, following GPS positions or the speed sensor without them.
+func Compare(a, b *Activity) Diff {
+	d := Diff{
+		Duration: b.TotalDuration() - a.TotalDuration(),
+		Distance: b.TotalDistance() - a.TotalDistance(),
+	}
+	sa, sb := a.Splits(SplitKilometer), b.Splits(SplitKilometer)
+	for i := 0; i < len(sa) && i < len(sb); i++ {
+		d.Splits = append(d.Splits, SplitDiff{A: sa[i], B: sb[i], Delta: sb[i].Duration - sa[i].Duration})
+	}
+
+	ta, da := a.cumulativeTimeDistance()
+	tb, db := b.cumulativeTimeDistance()
+	if len(da) == 0 || len(db) == 0 {
+		return d
+	}
+	common := math.Min(da[len(da)-1], db[len(db)-1])
+	var ia, ib int
+	var prevA, prevB float64
+	for x := compareStep; x <= common; x += compareStep {
+		atA, atB := timeAtDistance(ta, da, x, &ia), timeAtDistance(tb, db, x, &ib)
+		d.Gaps = append(d.Gaps, Gap{
+			Distance: x,
+			Delta:    time.Duration((atB - atA) * float64(time.Second)),
+			PaceA:    PaceFromSpeed(compareStep / (atA - prevA)),
+			PaceB:    PaceFromSpeed(compareStep / (atB - prevB)),
+		})
+		prevA, prevB = atA, atB
+	}
+	return d
+}
+
+// timeAtDistance interpolates the time at which the cumulative distances d
+// reach x. Successive calls must ask for increasing distances; i keeps the
+// position reached between calls.
+func timeAtDistance(t, d []float64, x float64, i *int) float64 {
+	for *i+1 < len(d) && d[*i+1] < x {
+		*i++
+	}
+	j := *i
+	if j+1 == len(d) || d[j+1] == d[j] {
+		return t[j]
+	}
+	return t[j] + (t[j+1]-t[j])*(x-d[j])/(d[j+1]-d[j])
+}
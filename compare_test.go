@@ -0,0 +1,38 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	// A steady 4 m/s run against one starting at 3.5 m/s and finishing at
+	// 5 m/s.
+	a := testActivity(SportRunning, repeat(4.0, 601), nil)
+	speeds := append(repeat(3.5, 400), repeat(5.0, 201)...)
+	b := testActivity(SportRunning, speeds, nil)
+	a.Laps[0].TotalTimeInSeconds, b.Laps[0].TotalTimeInSeconds = 600, 560
+
+	d := Compare(a, b)
+	if d.Duration != -40*time.Second {
+		t.Errorf("duration delta = %v", d.Duration)
+	}
+	if len(d.Splits) != 3 || d.Splits[0].Delta <= 0 || d.Splits[2].Delta >= 0 {
+		t.Errorf("splits = %+v", d.Splits)
+	}
+	if len(d.Gaps) != 24 {
+		t.Fatalf("%d gaps, want 24", len(d.Gaps))
+	}
+	// B is 100/3.5 - 100/4 s behind after 100 m, and catches up at the end.
+	g := d.Gaps[0]
+	if math.Abs(g.Delta.Seconds()-(100/3.5-25)) > 0.1 || math.Abs(g.PaceA.Speed()-4) > 0.01 || math.Abs(g.PaceB.Speed()-3.5) > 0.01 {
+		t.Errorf("gap at 100 m = %+v", g)
+	}
+	if last := d.Gaps[23]; last.Delta >= d.Gaps[13].Delta {
+		t.Errorf("B didn't catch up: %v at 1400 m, %v at 2400 m", d.Gaps[13].Delta, last.Delta)
+	}
+	if d := Compare(a, &Activity{}); d.Gaps != nil || d.Splits != nil {
+		t.Errorf("Compare() with an empty activity = %+v", d)
+	}
+}
@@ -0,0 +1,160 @@
+package tcx
+
+import (
+	"math"
+	"time"
+)
+
+// ComplianceOptions supplies the athlete's zones, needed to evaluate targets
+// that refer to the device's predefined zones by number.
+type ComplianceOptions struct {
+	HeartRateZones Zones
+	SpeedZones     Zones
+}
+
+// StepCompliance reports how one executed step matched its plan.
+type StepCompliance struct {
+	Step     WorkoutStep
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+	Distance float64 // meters
+	// InTarget is the fraction of the step's time spent inside its target,
+	// 1 for steps without a target.
+	InTarget float64
+	// Score combines InTarget with how closely the step's duration or
+	// distance matched the plan, between 0 and 1.
+	Score float64
+}
+
+// Compliance is the outcome of comparing an activity with a workout.
+type Compliance struct {
+	Steps []StepCompliance
+	// Score is the duration-weighted mean of the step scores, between 0
+	// and 100.
+	Score float64
+}
+
+// targetRange returns the range of the metric targeted by a step, the metric
+// being read from trackpoints by value. ok is false for steps without a
+// usable target.
+func targetRange(t *StepTarget, opts ComplianceOptions) (lo, hi float64, value func(*Trackpoint) float64, ok bool) {
+	if t == nil {
+		return 0, 0, nil, false
+	}
+	zone := func(zones Zones, n int) (float64, float64, bool) {
+		if n < 1 || n > len(zones) {
+			return 0, 0, false
+		}
+		return zones[n-1].Min, zones[n-1].Max, true
+	}
+	switch {
+	case t.Type == TargetHeartRate && t.HeartRateZone != nil:
+		value = func(p *Trackpoint) float64 { return float64(p.HeartRateInBpm) }
+		z := t.HeartRateZone
+		if z.Type == PredefinedHeartRateZone {
+			lo, hi, ok = zone(opts.HeartRateZones, z.Number)
+			return lo, hi, value, ok
+		}
+		return float64(z.Low), float64(z.High), value, true
+	case t.Type == TargetSpeed && t.SpeedZone != nil:
+		value = func(p *Trackpoint) float64 { return p.SpeedInMetersPerSec }
+		z := t.SpeedZone
+		if z.Type == PredefinedSpeedZone {
+			lo, hi, ok = zone(opts.SpeedZones, z.Number)
+			return lo, hi, value, ok
+		}
+		return z.Low, z.High, value, true
+	case t.Type == TargetCadence && t.CadenceZone != nil:
+		value = func(p *Trackpoint) float64 { return float64(p.Cadence) }
+		return t.CadenceZone.Low, t.CadenceZone.High, value, true
+	}
+	return 0, 0, nil, false
+}
+
+// ScoreCompliance compares an executed activity with the planned workout.
+// Steps are matched in order against the activity's trackpoints: time and
+// distance steps last as planned, heart rate steps until the heart rate
+// crosses their threshold, and other steps until the end of the current lap.
+func ScoreCompliance(w *Workout, a *Activity, opts ComplianceOptions) Compliance {
+	pts := a.trackpoints()
+	dist := cumulativeDistances(pts)
+	lapEnd := make([]int, 0, len(pts))
+	n := 0
+	for _, l := range a.Laps {
+		n += len(l.Track)
+		for range l.Track {
+			lapEnd = append(lapEnd, n-1)
+		}
+	}
+
+	var c Compliance
+	var weighted, totalTime float64
+	i := 0
+	for _, step := range w.FlattenSteps() {
+		if i >= len(pts)-1 {
+			break
+		}
+		start := i
+		d := step.Duration
+		for i < len(pts)-1 {
+			if d == nil || d.Type == DurationUserInitiated || d.Type == DurationCaloriesBurned {
+				i = lapEnd[i]
+				if i == start {
+					i++
+				}
+				break
+			}
+			i++
+			p := pts[i]
+			if (d.Type == DurationTime && p.Time.Sub(pts[start].Time) >= time.Duration(d.Seconds)*time.Second) ||
+				(d.Type == DurationDistance && dist[i]-dist[start] >= float64(d.Meters)) ||
+				(d.Type == DurationHeartRateAbove && p.HeartRateInBpm > d.HeartRate) ||
+				(d.Type == DurationHeartRateBelow && p.HeartRateInBpm > 0 && p.HeartRateInBpm < d.HeartRate) {
+				break
+			}
+		}
+
+		sc := StepCompliance{
+			Step:     step,
+			Start:    pts[start].Time,
+			End:      pts[i].Time,
+			Distance: dist[i] - dist[start],
+			InTarget: 1,
+		}
+		sc.Duration = sc.End.Sub(sc.Start)
+		if lo, hi, value, ok := targetRange(step.Target, opts); ok {
+			var in, all float64
+			for j := start; j < i; j++ {
+				dt := pts[j+1].Time.Sub(pts[j].Time).Seconds()
+				all += dt
+				if v := value(pts[j]); v >= lo && v <= hi {
+					in += dt
+				}
+			}
+			if all > 0 {
+				sc.InTarget = in / all
+			}
+		}
+		sc.Score = sc.InTarget
+		if d != nil {
+			var planned, actual float64
+			switch d.Type {
+			case DurationTime:
+				planned, actual = float64(d.Seconds), sc.Duration.Seconds()
+			case DurationDistance:
+				planned, actual = float64(d.Meters), sc.Distance
+			}
+			if planned > 0 {
+				sc.Score *= math.Min(planned, actual) / math.Max(planned, actual)
+			}
+		}
+		c.Steps = append(c.Steps, sc)
+		weighted += sc.Score * sc.Duration.Seconds()
+		totalTime += sc.Duration.Seconds()
+	}
+	if totalTime > 0 {
+		c.Score = 100 * weighted / totalTime
+	}
+	return c
+}
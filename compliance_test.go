@@ -0,0 +1,82 @@
+package tcx
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+const testWorkout = `<Workout Sport="Running" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <Name>Intervals</Name>
+  <Step xsi:type="Step_t">
+    <StepId>1</StepId>
+    <Duration xsi:type="Time_t"><Seconds>300</Seconds></Duration>
+    <Intensity>Active</Intensity>
+    <Target xsi:type="HeartRate_t">
+      <HeartRateZone xsi:type="CustomHeartRateZone_t">
+        <Low xsi:type="HeartRateInBeatsPerMinute_t"><Value>120</Value></Low>
+        <High xsi:type="HeartRateInBeatsPerMinute_t"><Value>140</Value></High>
+      </HeartRateZone>
+    </Target>
+  </Step>
+  <Step xsi:type="Repeat_t">
+    <StepId>4</StepId>
+    <Repetitions>2</Repetitions>
+    <Child xsi:type="Step_t">
+      <StepId>2</StepId>
+      <Duration xsi:type="Distance_t"><Meters>400</Meters></Duration>
+      <Intensity>Active</Intensity>
+      <Target xsi:type="Speed_t">
+        <SpeedZone xsi:type="CustomSpeedZone_t">
+          <LowInMetersPerSecond>4.0</LowInMetersPerSecond>
+          <HighInMetersPerSecond>4.5</HighInMetersPerSecond>
+        </SpeedZone>
+      </Target>
+    </Child>
+    <Child xsi:type="Step_t">
+      <StepId>3</StepId>
+      <Duration xsi:type="Time_t"><Seconds>120</Seconds></Duration>
+      <Intensity>Resting</Intensity>
+      <Target xsi:type="None_t"/>
+    </Child>
+  </Step>
+</Workout>`
+
+func TestScoreCompliance(t *testing.T) {
+	var w Workout
+	if err := xml.Unmarshal([]byte(testWorkout), &w); err != nil {
+		t.Fatal(err)
+	}
+	if steps := w.FlattenSteps(); len(steps) != 5 || steps[1].Duration.Meters != 400 {
+		t.Fatalf("FlattenSteps() = %+v", steps)
+	}
+
+	var speeds []float64
+	var hrs []int
+	speeds = append(speeds, repeat(3.0, 300)...)
+	hrs = append(hrs, repeat(130, 300)...)
+	for i := 0; i < 2; i++ {
+		speeds = append(speeds, repeat(4.2, 96)...)
+		speeds = append(speeds, repeat(2.5, 120)...)
+	}
+	speeds = append(speeds, 2.5)
+	a := testActivity("Running", speeds, hrs)
+
+	c := ScoreCompliance(&w, a, ComplianceOptions{})
+	if len(c.Steps) != 5 {
+		t.Fatalf("got %d steps, want 5", len(c.Steps))
+	}
+	for i, s := range c.Steps {
+		if s.InTarget < 0.95 {
+			t.Errorf("step %d: %.2f in target", i, s.InTarget)
+		}
+	}
+	if c.Score < 95 {
+		t.Errorf("Score = %v, want at least 95", c.Score)
+	}
+
+	// Running the intervals too slowly misses their targets.
+	slow := testActivity("Running", append(repeat(3.0, 300), repeat(3.0, 800)...), hrs)
+	if got := ScoreCompliance(&w, slow, ComplianceOptions{}).Score; got >= c.Score {
+		t.Errorf("slow Score = %v, want below %v", got, c.Score)
+	}
+}
@@ -0,0 +1,184 @@
+package tcx
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Course is a route to follow, as defined by the Courses element of the TCX
+// schema.
+type Course struct {
+	Name         string        `xml:"Name" json:"name"`
+	Laps         []CourseLap   `xml:"Lap" json:"laps,omitempty"`
+	Track        []Trackpoint  `xml:"Track>Trackpoint" json:"track,omitempty"`
+	Notes        string        `xml:"Notes,omitempty" json:"notes,omitempty"`
+	CoursePoints []CoursePoint `xml:"CoursePoint" json:"coursePoints,omitempty"`
+}
+
+// CourseLap summarizes one lap of a course.
+type CourseLap struct {
+	TotalTimeInSeconds      float64 `xml:"TotalTimeSeconds" json:"totalTimeSeconds"`
+	DistanceInMeters        float64 `xml:"DistanceMeters" json:"distanceMeters"`
+	BeginLatitudeInDegrees  float64 `xml:"BeginPosition>LatitudeDegrees" json:"beginLatitude,omitempty"`
+	BeginLongitudeInDegrees float64 `xml:"BeginPosition>LongitudeDegrees" json:"beginLongitude,omitempty"`
+	EndLatitudeInDegrees    float64 `xml:"EndPosition>LatitudeDegrees" json:"endLatitude,omitempty"`
+	EndLongitudeInDegrees   float64 `xml:"EndPosition>LongitudeDegrees" json:"endLongitude,omitempty"`
+	Intensity               string  `xml:"Intensity" json:"intensity,omitempty"`
+}
+
+// CoursePoint is a named point of interest along a course.
+type CoursePoint struct {
+	Name               string    `xml:"Name" json:"name"`
+	Time               time.Time `xml:"Time" json:"time"`
+	LatitudeInDegrees  float64   `xml:"Position>LatitudeDegrees" json:"latitude,omitempty"`
+	LongitudeInDegrees float64   `xml:"Position>LongitudeDegrees" json:"longitude,omitempty"`
+	AltitudeInMeters   float64   `xml:"AltitudeMeters,omitempty" json:"altitudeMeters,omitempty"`
+	PointType          string    `xml:"PointType" json:"pointType,omitempty"`
+	Notes              string    `xml:"Notes,omitempty" json:"notes,omitempty"`
+}
+
+// Course returns the course of t named name, or nil.
+func (t *Tcx) Course(name string) *Course {
+	for i := range t.Courses {
+		if t.Courses[i].Name == name {
+			return &t.Courses[i]
+		}
+	}
+	return nil
+}
+
+// TotalDistance returns the length of the course track in meters.
+func (c *Course) TotalDistance() float64 {
+	d := c.Distances()
+	if len(d) == 0 {
+		return 0
+	}
+	return d[len(d)-1]
+}
+
+// turnLookaround is the distance, in meters, before and after a trackpoint
+// over which headings are measured to detect turns.
+const turnLookaround = 25.0
+
+// bearing returns the initial bearing in degrees from the first position to
+// the second one.
+func bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := deg2rad(lat1), deg2rad(lat2)
+	dl := deg2rad(lon2 - lon1)
+	y := math.Sin(dl) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dl)
+	return rad2deg(math.Atan2(y, x))
+}
+
+// TurnPoints detects the turns sharper than minAngle degrees along track and
+// returns them as Left or Right course points. Headings are measured over 25
+// meters on each side of a point, and turns closer than twice that distance
+// are merged into the sharpest one.
+func TurnPoints(track []Trackpoint, minAngle float64) []CoursePoint {
+	var pts []*Trackpoint
+	for _, p := range pointers(track) {
+		if p.HasPosition() {
+			pts = append(pts, p)
+		}
+	}
+	dist := cumulativeDistances(pts)
+
+	var turns []CoursePoint
+	lastDist := math.Inf(-1)
+	lastAngle := 0.0
+	back, ahead := 0, 0
+	for i, p := range pts {
+		for back+1 < i && dist[i]-dist[back+1] >= turnLookaround {
+			back++
+		}
+		if ahead < i {
+			ahead = i
+		}
+		for ahead < len(pts)-1 && dist[ahead]-dist[i] < turnLookaround {
+			ahead++
+		}
+		if dist[i]-dist[back] < turnLookaround || dist[ahead]-dist[i] < turnLookaround {
+			continue
+		}
+		in := bearing(pts[back].LatitudeInDegrees, pts[back].LongitudeInDegrees, p.LatitudeInDegrees, p.LongitudeInDegrees)
+		out := bearing(p.LatitudeInDegrees, p.LongitudeInDegrees, pts[ahead].LatitudeInDegrees, pts[ahead].LongitudeInDegrees)
+		angle := math.Mod(out-in+540, 360) - 180
+		if math.Abs(angle) < minAngle {
+			continue
+		}
+		cp := CoursePoint{
+			Name:               "Right",
+			Time:               p.Time,
+			LatitudeInDegrees:  p.LatitudeInDegrees,
+			LongitudeInDegrees: p.LongitudeInDegrees,
+			AltitudeInMeters:   p.AltitudeInMeters,
+			PointType:          "Right",
+		}
+		if angle < 0 {
+			cp.Name, cp.PointType = "Left", "Left"
+		}
+		if dist[i]-lastDist < 2*turnLookaround {
+			if math.Abs(angle) > math.Abs(lastAngle) {
+				turns[len(turns)-1] = cp
+				lastAngle = angle
+			}
+			continue
+		}
+		turns = append(turns, cp)
+		lastDist, lastAngle = dist[i], angle
+	}
+	return turns
+}
+
+// Distances returns the distance in meters from the start of the course at
+// each of its trackpoints.
+func (c *Course) Distances() []float64 {
+	return cumulativeDistances(pointers(c.Track))
+}
+
+// maxCourseName is the longest course name allowed by the schema.
+const maxCourseName = 15
+
+// ActivityToCourse builds a course following the route of a: its GPS fixes,
+// with their times and altitudes, and a single lap spanning the activity.
+// The name is cut to the 15 characters allowed by the schema. Course points
+// can be added with AddTurnPoints.
+func ActivityToCourse(a *Activity, name string) *Course {
+	if r := []rune(name); len(r) > maxCourseName {
+		name = string(r[:maxCourseName])
+	}
+	c := &Course{Name: name}
+	for _, p := range a.trackpoints() {
+		if !p.HasPosition() {
+			continue
+		}
+		q := Trackpoint{Time: p.Time}
+		q.SetPosition(p.LatitudeInDegrees, p.LongitudeInDegrees)
+		if p.HasAltitude() {
+			q.SetAltitude(p.AltitudeInMeters)
+		}
+		c.Track = append(c.Track, q)
+	}
+	lap := CourseLap{
+		TotalTimeInSeconds: a.TotalDuration().Seconds(),
+		DistanceInMeters:   c.TotalDistance(),
+		Intensity:          IntensityActive,
+	}
+	if lap.DistanceInMeters == 0 {
+		lap.DistanceInMeters = a.TotalDistance()
+	}
+	if n := len(c.Track); n > 0 {
+		lap.BeginLatitudeInDegrees, lap.BeginLongitudeInDegrees = c.Track[0].LatitudeInDegrees, c.Track[0].LongitudeInDegrees
+		lap.EndLatitudeInDegrees, lap.EndLongitudeInDegrees = c.Track[n-1].LatitudeInDegrees, c.Track[n-1].LongitudeInDegrees
+	}
+	c.Laps = []CourseLap{lap}
+	return c
+}
+
+// AddTurnPoints adds the turns of the course track sharper than minAngle
+// degrees to its course points, as found by TurnPoints.
+func (c *Course) AddTurnPoints(minAngle float64) {
+	c.CoursePoints = append(c.CoursePoints, TurnPoints(c.Track, minAngle)...)
+	sort.SliceStable(c.CoursePoints, func(i, j int) bool { return c.CoursePoints[i].Time.Before(c.CoursePoints[j].Time) })
+}
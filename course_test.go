@@ -0,0 +1,113 @@
+package tcx
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const courseTCX = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+    xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <Folders><Courses><CourseFolder Name="Courses"><CourseNameRef><Id>River loop</Id></CourseNameRef></CourseFolder></Courses></Folders>
+  <Courses>
+    <Course>
+      <Name>River loop</Name>
+      <Lap>
+        <TotalTimeSeconds>600.0</TotalTimeSeconds>
+        <DistanceMeters>2000.0</DistanceMeters>
+        <BeginPosition><LatitudeDegrees>47.0</LatitudeDegrees><LongitudeDegrees>-1.5</LongitudeDegrees></BeginPosition>
+        <EndPosition><LatitudeDegrees>47.0</LatitudeDegrees><LongitudeDegrees>-1.5</LongitudeDegrees></EndPosition>
+        <Intensity>Active</Intensity>
+      </Lap>
+      <Track>
+        <Trackpoint><Time>2015-04-12T07:00:00Z</Time><Position><LatitudeDegrees>47.0</LatitudeDegrees><LongitudeDegrees>-1.5</LongitudeDegrees></Position><AltitudeMeters>12.0</AltitudeMeters></Trackpoint>
+        <Trackpoint><Time>2015-04-12T07:05:00Z</Time><Position><LatitudeDegrees>47.009</LatitudeDegrees><LongitudeDegrees>-1.5</LongitudeDegrees></Position><AltitudeMeters>15.0</AltitudeMeters></Trackpoint>
+        <Trackpoint><Time>2015-04-12T07:10:00Z</Time><Position><LatitudeDegrees>47.0</LatitudeDegrees><LongitudeDegrees>-1.5</LongitudeDegrees></Position><AltitudeMeters>12.0</AltitudeMeters></Trackpoint>
+      </Track>
+      <CoursePoint>
+        <Name>Bridge</Name>
+        <Time>2015-04-12T07:05:00Z</Time>
+        <Position><LatitudeDegrees>47.009</LatitudeDegrees><LongitudeDegrees>-1.5</LongitudeDegrees></Position>
+        <PointType>Generic</PointType>
+        <Notes>Turn around</Notes>
+      </CoursePoint>
+    </Course>
+  </Courses>
+</TrainingCenterDatabase>`
+
+func TestCourses(t *testing.T) {
+	db, err := Parse(strings.NewReader(courseTCX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Activities) != 0 || len(db.Courses) != 1 {
+		t.Fatalf("parsed %d activities, %d courses", len(db.Activities), len(db.Courses))
+	}
+	c := db.Course("River loop")
+	if c == nil || db.Course("Hill repeats") != nil {
+		t.Fatal("Course() lookup failed")
+	}
+	if len(c.Laps) != 1 || c.Laps[0].DistanceInMeters != 2000 || len(c.Track) != 3 {
+		t.Errorf("course = %+v", c)
+	}
+	if len(c.CoursePoints) != 1 || c.CoursePoints[0].Name != "Bridge" || c.CoursePoints[0].Notes != "Turn around" {
+		t.Errorf("course points = %+v", c.CoursePoints)
+	}
+	if d := c.TotalDistance(); math.Abs(d-2001.5) > 1 {
+		t.Errorf("TotalDistance() = %v", d)
+	}
+
+	var b bytes.Buffer
+	if err := db.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	back, err := Parse(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.Courses, db.Courses) {
+		t.Errorf("courses changed through Write: %+v", back.Courses)
+	}
+}
+
+func TestActivityToCourse(t *testing.T) {
+	// North for 200 s at 4 m/s, then east: a right turn after 800 m.
+	a := testActivity(SportRunning, repeat(4.0, 401), repeat(150, 401))
+	track := a.Laps[0].Track
+	for i := 201; i < len(track); i++ {
+		track[i].LatitudeInDegrees = track[200].LatitudeInDegrees
+		track[i].LongitudeInDegrees = track[200].LongitudeInDegrees + float64(i-200)*4/(111194.93*math.Cos(47*math.Pi/180))
+	}
+	track[5].ClearPosition()
+
+	c := ActivityToCourse(a, "Saturday morning run")
+	if c.Name != "Saturday mornin" {
+		t.Errorf("name = %q", c.Name)
+	}
+	if len(c.Track) != 400 || c.Track[0].HasHeartRate() || !c.Track[0].HasAltitude() {
+		t.Fatalf("course track = %d trackpoints, first %+v", len(c.Track), c.Track[0])
+	}
+	l := c.Laps[0]
+	if len(c.Laps) != 1 || l.TotalTimeInSeconds != 400 || math.Abs(l.DistanceInMeters-1600) > 2 {
+		t.Errorf("course lap = %+v", l)
+	}
+	if l.BeginLatitudeInDegrees != 47 || l.EndLongitudeInDegrees != track[400].LongitudeInDegrees {
+		t.Errorf("lap ends = %+v", l)
+	}
+	c.AddTurnPoints(45)
+	if len(c.CoursePoints) != 1 || c.CoursePoints[0].PointType != "Right" || !c.CoursePoints[0].Time.Equal(track[200].Time) {
+		t.Errorf("turns = %+v", c.CoursePoints)
+	}
+
+	db := &Tcx{Courses: []Course{*c}}
+	var b bytes.Buffer
+	if err := db.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	if errs := db.Validate(); len(errs) > 0 {
+		t.Errorf("course fails validation: %v", errs)
+	}
+}
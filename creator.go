@@ -0,0 +1,112 @@
+package tcx
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// Author is the application that wrote a file, as defined by the Author
+// element of the TCX schema.
+type Author struct {
+	Name         string `xml:"Name" json:"name"`
+	VersionMajor int    `xml:"Build>Version>VersionMajor" json:"versionMajor,omitempty"`
+	VersionMinor int    `xml:"Build>Version>VersionMinor" json:"versionMinor,omitempty"`
+	BuildMajor   int    `xml:"Build>Version>BuildMajor" json:"buildMajor,omitempty"`
+	BuildMinor   int    `xml:"Build>Version>BuildMinor" json:"buildMinor,omitempty"`
+	// BuildType is one of Internal, Alpha, Beta and Release.
+	BuildType  string `xml:"Build>Type" json:"buildType,omitempty"`
+	BuildTime  string `xml:"Build>Time" json:"buildTime,omitempty"`
+	Builder    string `xml:"Build>Builder" json:"builder,omitempty"`
+	LangID     string `xml:"LangID" json:"langId,omitempty"`
+	PartNumber string `xml:"PartNumber" json:"partNumber,omitempty"`
+}
+
+// authorXML is an Author written as an Application_t.
+type authorXML struct {
+	Type         string `xml:"xsi:type,attr"`
+	Name         string `xml:"Name"`
+	VersionMajor int    `xml:"Build>Version>VersionMajor"`
+	VersionMinor int    `xml:"Build>Version>VersionMinor"`
+	BuildMajor   int    `xml:"Build>Version>BuildMajor,omitempty"`
+	BuildMinor   int    `xml:"Build>Version>BuildMinor,omitempty"`
+	BuildType    string `xml:"Build>Type,omitempty"`
+	BuildTime    string `xml:"Build>Time,omitempty"`
+	Builder      string `xml:"Build>Builder,omitempty"`
+	LangID       string `xml:"LangID"`
+	PartNumber   string `xml:"PartNumber,omitempty"`
+}
+
+// MarshalXML writes the author as an application, defaulting its language
+// to English. An empty part number is left out rather than written invalid.
+func (a Author) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	v := authorXML{
+		Type:         "Application_t",
+		Name:         a.Name,
+		VersionMajor: a.VersionMajor,
+		VersionMinor: a.VersionMinor,
+		BuildMajor:   a.BuildMajor,
+		BuildMinor:   a.BuildMinor,
+		BuildType:    a.BuildType,
+		BuildTime:    a.BuildTime,
+		Builder:      a.Builder,
+		LangID:       a.LangID,
+		PartNumber:   a.PartNumber,
+	}
+	if v.LangID == "" {
+		v.LangID = "en"
+	}
+	return e.EncodeElement(v, start)
+}
+
+// Version returns the version of the author, such as 4.1.2.0.
+func (a Author) Version() string {
+	return fmt.Sprintf("%d.%d.%d.%d", a.VersionMajor, a.VersionMinor, a.BuildMajor, a.BuildMinor)
+}
+
+// Version returns the firmware version of the device, such as 4.1.2.0.
+func (c Creator) Version() string {
+	return fmt.Sprintf("%d.%d.%d.%d", c.VersionMajor, c.VersionMinor, c.BuildMajor, c.BuildMinor)
+}
+
+// garminProducts maps Garmin product ids to device names.
+var garminProducts = map[int]string{
+	717:   "Forerunner 405",
+	782:   "Forerunner 50",
+	988:   "Forerunner 60",
+	1018:  "Forerunner 310XT",
+	1036:  "Edge 500",
+	1124:  "Forerunner 110",
+	1169:  "Edge 800",
+	1325:  "Edge 200",
+	1328:  "Forerunner 910XT",
+	1345:  "Forerunner 610",
+	1436:  "Forerunner 70",
+	1561:  "Edge 510",
+	1567:  "Edge 810",
+	1623:  "Forerunner 620",
+	1632:  "Forerunner 220",
+	1765:  "Forerunner 920XT",
+	1836:  "Edge 1000",
+	2067:  "Edge 520",
+	2153:  "Forerunner 225",
+	2156:  "Forerunner 630",
+	2157:  "Forerunner 230",
+	2431:  "Forerunner 235",
+	2691:  "Forerunner 935",
+	2697:  "fenix 5",
+	3076:  "Forerunner 245",
+	3113:  "Forerunner 945",
+	3121:  "Edge 530",
+	3122:  "Edge 830",
+	20119: "Training Center",
+	65534: "Garmin Connect",
+}
+
+// DeviceName returns the name of the device: the Garmin model matching its
+// product id when known, its recorded name otherwise.
+func (c Creator) DeviceName() string {
+	if name, ok := garminProducts[c.ProductID]; ok {
+		return "Garmin " + name
+	}
+	return c.Name
+}
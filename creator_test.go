@@ -0,0 +1,82 @@
+package tcx
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testAuthor = `<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+    xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <Activities>
+    <Activity Sport="Biking">
+      <Id>2015-04-12T07:28:19Z</Id>
+      <Lap StartTime="2015-04-12T07:28:19Z">
+        <TotalTimeSeconds>300</TotalTimeSeconds>
+        <DistanceMeters>2500</DistanceMeters>
+        <Calories>70</Calories>
+        <Intensity>Active</Intensity>
+        <TriggerMethod>Manual</TriggerMethod>
+      </Lap>
+      <Creator xsi:type="Device_t">
+        <Name>Edge 500</Name>
+        <UnitId>3826148726</UnitId>
+        <ProductID>1036</ProductID>
+        <Version><VersionMajor>3</VersionMajor><VersionMinor>30</VersionMinor><BuildMajor>0</BuildMajor><BuildMinor>0</BuildMinor></Version>
+      </Creator>
+    </Activity>
+  </Activities>
+  <Author xsi:type="Application_t">
+    <Name>Garmin Connect API</Name>
+    <Build>
+      <Version><VersionMajor>16</VersionMajor><VersionMinor>20</VersionMinor><BuildMajor>0</BuildMajor><BuildMinor>0</BuildMinor></Version>
+      <Type>Release</Type>
+    </Build>
+    <LangID>en</LangID>
+    <PartNumber>006-D2449-00</PartNumber>
+  </Author>
+</TrainingCenterDatabase>`
+
+func TestAuthorAndCreator(t *testing.T) {
+	db, err := Parse(strings.NewReader(testAuthor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	au := db.Author
+	if au == nil || au.Name != "Garmin Connect API" || au.Version() != "16.20.0.0" || au.BuildType != "Release" || au.PartNumber != "006-D2449-00" {
+		t.Fatalf("author = %+v", au)
+	}
+	c := db.Activities[0].Creator
+	if c.Version() != "3.30.0.0" || c.DeviceName() != "Garmin Edge 500" {
+		t.Errorf("creator %+v: version %s, device %s", c, c.Version(), c.DeviceName())
+	}
+	if name := (Creator{Name: "Suunto Ambit2 S"}).DeviceName(); name != "Suunto Ambit2 S" {
+		t.Errorf("DeviceName() of an unknown product = %q", name)
+	}
+
+	var b bytes.Buffer
+	if err := db.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if !strings.Contains(out, `<Author xsi:type="Application_t">`) || strings.Index(out, "<Author") < strings.Index(out, "</Activities>") {
+		t.Errorf("author not written after the activities:\n%s", out)
+	}
+	back, err := Parse(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.Author, db.Author) || back.Activities[0].Creator != c {
+		t.Errorf("author changed through Write: %+v", back.Author)
+	}
+
+	db.Author.PartNumber = ""
+	b.Reset()
+	if err := db.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "<PartNumber>") {
+		t.Error("empty PartNumber written")
+	}
+}
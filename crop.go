@@ -0,0 +1,60 @@
+package tcx
+
+import "time"
+
+// Crop returns a copy of the activity restricted to the trackpoints between
+// start and end, inclusive. A zero start or end leaves that side open. Lap
+// totals are recomputed from the remaining trackpoints as in refit, and laps
+// left without trackpoints are dropped. a is not modified.
+func (a *Activity) Crop(start, end time.Time) *Activity {
+	c := a.Clone()
+	inside := func(t time.Time) bool {
+		return (start.IsZero() || !t.Before(start)) && (end.IsZero() || !t.After(end))
+	}
+	laps := c.Laps[:0]
+	for _, l := range c.Laps {
+		if len(l.Track) == 0 {
+			if inside(l.StartTime) {
+				laps = append(laps, l)
+			}
+			continue
+		}
+		track := l.Track[:0]
+		for _, p := range l.Track {
+			if inside(p.Time) {
+				track = append(track, p)
+			}
+		}
+		if len(track) == 0 {
+			continue
+		}
+		if len(track) < len(l.Track) {
+			l.refit(track)
+		}
+		laps = append(laps, l)
+	}
+	c.Laps = laps
+	if len(c.Laps) > 0 && !start.IsZero() && c.ID.Before(start) {
+		c.ID = c.Laps[0].StartTime
+	}
+	return c
+}
+
+// refit restricts l to track, a part of its own, and recomputes its totals.
+// The distance follows the recorded trackpoint distances, GPS or speed as
+// in Lap.update; when the track measures none, the recorded distance is
+// scaled to the time kept, as are the calories.
+func (l *Lap) refit(track []Trackpoint) {
+	before, dist := l.TotalTimeInSeconds, l.DistanceInMeters
+	l.Track = track
+	if l.StartTime.Before(track[0].Time) {
+		l.StartTime = track[0].Time
+	}
+	l.update()
+	if before > 0 {
+		l.Calories *= l.TotalTimeInSeconds / before
+		if l.DistanceInMeters == 0 {
+			l.DistanceInMeters = dist * l.TotalTimeInSeconds / before
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCrop(t *testing.T) {
+	a := testActivity(SportRunning, repeat(4.0, 601), repeat(150, 601))
+	a.Laps[0].update()
+	a.Laps[0].Calories = 60
+	start := a.ID.Add(100 * time.Second)
+	c := a.Crop(start, a.ID.Add(400*time.Second))
+	if len(c.Laps) != 1 || len(c.Laps[0].Track) != 301 {
+		t.Fatalf("cropped to %d laps", len(c.Laps))
+	}
+	l := c.Laps[0]
+	if !l.StartTime.Equal(start) || !c.ID.Equal(start) || l.TotalTimeInSeconds != 300 {
+		t.Errorf("cropped lap starts %v, id %v, lasts %v s", l.StartTime, c.ID, l.TotalTimeInSeconds)
+	}
+	if math.Abs(l.DistanceInMeters-1200) > 0.01 || l.Calories != 30 || l.AverageHeartRateInBpm != 150 {
+		t.Errorf("cropped lap totals = %v m, %v kcal, %v bpm", l.DistanceInMeters, l.Calories, l.AverageHeartRateInBpm)
+	}
+	if len(a.Laps[0].Track) != 601 || a.Laps[0].Calories != 60 {
+		t.Error("Crop modified the source activity")
+	}
+	if open := a.Crop(time.Time{}, start); !open.ID.Equal(a.ID) || len(open.Laps[0].Track) != 101 {
+		t.Errorf("open start crop kept %d trackpoints", len(open.Laps[0].Track))
+	}
+
+	db, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := &db.Activities[0]
+	from, to := full.Laps[5].StartTime.Add(30*time.Second), full.Laps[8].StartTime.Add(10*time.Second)
+	var want int
+	for _, p := range full.trackpoints() {
+		if !p.Time.Before(from) && !p.Time.After(to) {
+			want++
+		}
+	}
+	c = full.Crop(from, to)
+	if got := len(c.trackpoints()); got != want || len(c.Laps) != 4 {
+		t.Errorf("cropped to %d laps, %d trackpoints, want 4 laps, %d trackpoints", len(c.Laps), got, want)
+	}
+	if c.Laps[0].StartTime.Before(from) || c.Laps[0].TotalTimeInSeconds >= full.Laps[5].TotalTimeInSeconds {
+		t.Errorf("first lap = %v, %v s", c.Laps[0].StartTime, c.Laps[0].TotalTimeInSeconds)
+	}
+	if c.Laps[1].TotalTimeInSeconds != full.Laps[6].TotalTimeInSeconds {
+		t.Error("Crop changed a lap inside the window")
+	}
+	if e := full.Crop(to, from); len(e.Laps) != 0 {
+		t.Errorf("empty window kept %d laps", len(e.Laps))
+	}
+}
+
+func TestCropTreadmill(t *testing.T) {
+	db, err := ParseFile("testdata/treadmill.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &db.Activities[0]
+	c := a.Crop(a.ID.Add(300*time.Second), time.Time{})
+	if l := c.Laps[0]; l.DistanceInMeters != 900 || l.TotalTimeInSeconds != 300 || l.Calories != 60 {
+		t.Errorf("cropped lap = %v m, %v s, %v kcal", l.DistanceInMeters, l.TotalTimeInSeconds, l.Calories)
+	}
+
+	// Without trackpoint distances, the lap distance is scaled to the time
+	// kept.
+	for i := range a.Laps[0].Track {
+		a.Laps[0].Track[i].ClearDistance()
+	}
+	c = a.Crop(time.Time{}, a.ID.Add(150*time.Second))
+	if l := c.Laps[0]; l.DistanceInMeters != 450 {
+		t.Errorf("cropped lap without distances = %v m, want 450", l.DistanceInMeters)
+	}
+}
@@ -0,0 +1,138 @@
+package tcx
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/rdifrango/go-tcx/units"
+)
+
+// CSVColumn is a column of WriteCSV.
+type CSVColumn int
+
+const (
+	CSVTime CSVColumn = iota
+	CSVLatitude
+	CSVLongitude
+	CSVAltitude
+	CSVDistance
+	CSVHeartRate
+	CSVCadence
+	CSVSpeed
+	CSVPower
+)
+
+// CSVOptions configures WriteCSV.
+type CSVOptions struct {
+	Columns []CSVColumn
+	Units   UnitSystem
+	// NoHeader leaves out the header row, to append the rows of further
+	// activities to a file.
+	NoHeader bool
+}
+
+// DefaultCSVOptions writes every column in metric units.
+var DefaultCSVOptions = CSVOptions{
+	Columns: []CSVColumn{CSVTime, CSVLatitude, CSVLongitude, CSVAltitude, CSVDistance, CSVHeartRate, CSVCadence, CSVSpeed, CSVPower},
+	Units:   MetricUnits,
+}
+
+// header returns the name of the column, with its unit.
+func (c CSVColumn) header(u UnitSystem) string {
+	switch c {
+	case CSVTime:
+		return "time"
+	case CSVLatitude:
+		return "latitude"
+	case CSVLongitude:
+		return "longitude"
+	case CSVAltitude:
+		return "altitude_" + u.ElevationUnit()
+	case CSVDistance:
+		return "distance_" + u.DistanceUnit()
+	case CSVHeartRate:
+		return "heart_rate_bpm"
+	case CSVCadence:
+		return "cadence"
+	case CSVSpeed:
+		return "speed_" + u.SpeedUnit()
+	case CSVPower:
+		return "power_w"
+	}
+	return ""
+}
+
+// WriteCSV writes the trackpoints of the activity as CSV, one row per
+// trackpoint after a header row, unless opts.NoHeader. Missing values are left empty. Distances
+// are those recorded by the trackpoints or, where missing, cumulative from
+// the start, following GPS positions or the speed sensor without them.
+func (a *Activity) WriteCSV(w io.Writer, opts CSVOptions) error {
+	cw := csv.NewWriter(w)
+	row := make([]string, len(opts.Columns))
+	if !opts.NoHeader {
+		for i, c := range opts.Columns {
+			row[i] = c.header(opts.Units)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	u := opts.Units
+	_, dist := a.cumulativeTimeDistance()
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	for k, p := range a.trackpoints() {
+		for i, c := range opts.Columns {
+			v := ""
+			switch c {
+			case CSVTime:
+				v = p.Time.UTC().Format(time.RFC3339Nano)
+			case CSVLatitude:
+				if p.HasPosition() {
+					v = f(p.LatitudeInDegrees)
+				}
+			case CSVLongitude:
+				if p.HasPosition() {
+					v = f(p.LongitudeInDegrees)
+				}
+			case CSVAltitude:
+				if p.HasAltitude() {
+					v = f(u.Elevation(units.Meters(p.AltitudeInMeters)))
+				}
+			case CSVDistance:
+				switch {
+				case p.HasDistance():
+					v = f(u.Distance(units.Meters(p.DistanceInMeters)))
+				case k < len(dist):
+					v = f(u.Distance(units.Meters(dist[k])))
+				default:
+					v = "0"
+				}
+			case CSVHeartRate:
+				if p.HasHeartRate() {
+					v = strconv.Itoa(p.HeartRateInBpm)
+				}
+			case CSVCadence:
+				if p.HasCadence() {
+					v = strconv.Itoa(p.Cadence)
+				}
+			case CSVSpeed:
+				if p.SpeedInMetersPerSec > 0 {
+					v = f(u.Speed(units.MetersPerSecond(p.SpeedInMetersPerSec)))
+				}
+			case CSVPower:
+				if p.HasPower() {
+					v = strconv.Itoa(p.PowerInWatts)
+				}
+			}
+			row[i] = v
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,63 @@
+package tcx
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	a := testActivity(SportRunning, repeat(2.5, 401), repeat(150, 401))
+	a.Laps[0].Track[10].HeartRateInBpm = 0
+
+	var b bytes.Buffer
+	if err := a.WriteCSV(&b, DefaultCSVOptions); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 402 || len(rows[0]) != 9 || rows[0][4] != "distance_km" {
+		t.Fatalf("%d rows, header %v", len(rows), rows[0])
+	}
+	last := rows[401]
+	if last[0] != "2015-04-12T07:06:40Z" || last[5] != "150" || last[7] != "9" || last[8] != "" {
+		t.Errorf("last row = %v", last)
+	}
+	if d, _ := strconv.ParseFloat(last[4], 64); math.Abs(d-1) > 0.001 {
+		t.Errorf("distance = %v km, want 1", d)
+	}
+	if rows[11][5] != "" {
+		t.Errorf("missing heart rate written as %q", rows[11][5])
+	}
+
+	// Recorded distances are written as they are.
+	recorded := testActivity(SportRunning, repeat(2.5, 3), nil)
+	recorded.Laps[0].Track[2].SetDistance(1234)
+	b.Reset()
+	if err := recorded.WriteCSV(&b, CSVOptions{Columns: []CSVColumn{CSVDistance}, Units: MetricUnits}); err != nil {
+		t.Fatal(err)
+	}
+	rows, _ = csv.NewReader(&b).ReadAll()
+	if d, _ := strconv.ParseFloat(rows[2][0], 64); rows[3][0] != "1.234" || math.Abs(d-0.0025) > 1e-6 {
+		t.Errorf("distances = %v", rows)
+	}
+
+	b.Reset()
+	opts := CSVOptions{Columns: []CSVColumn{CSVDistance, CSVSpeed, CSVAltitude}, Units: ImperialUnits}
+	if err := a.WriteCSV(&b, opts); err != nil {
+		t.Fatal(err)
+	}
+	rows, _ = csv.NewReader(&b).ReadAll()
+	if h := rows[0]; h[0] != "distance_mi" || h[1] != "speed_mph" || h[2] != "altitude_ft" {
+		t.Errorf("imperial header = %v", h)
+	}
+	mph, _ := strconv.ParseFloat(rows[1][1], 64)
+	ft, _ := strconv.ParseFloat(rows[1][2], 64)
+	if math.Abs(mph-5.592) > 0.001 || math.Abs(ft-32.808) > 0.001 {
+		t.Errorf("imperial row = %v", rows[1])
+	}
+}
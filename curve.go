@@ -0,0 +1,206 @@
+package tcx
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// maxHoldGap is the longest gap between trackpoints over which a value is
+// held when resampling to 1 Hz. Longer gaps are pauses and count as zero.
+const maxHoldGap = 10 * time.Second
+
+// resample1Hz returns value sampled once per second from the first to the last
+// trackpoint, each value holding until the next trackpoint. The series has one
+// sample per elapsed second.
+func resample1Hz(pts []*Trackpoint, value func(*Trackpoint) float64) []float64 {
+	if len(pts) == 0 {
+		return nil
+	}
+	start := pts[0].Time
+	n := int(pts[len(pts)-1].Time.Sub(start) / time.Second)
+	if n <= 0 {
+		return nil
+	}
+	series := make([]float64, n)
+	for i, p := range pts {
+		from := int(p.Time.Sub(start) / time.Second)
+		to := n
+		if i+1 < len(pts) {
+			next := pts[i+1].Time
+			to = int(next.Sub(start) / time.Second)
+			if next.Sub(p.Time) > maxHoldGap {
+				to = from + 1
+			}
+		}
+		v := value(p)
+		for s := max(from, 0); s < to && s < n; s++ {
+			series[s] = v
+		}
+	}
+	return series
+}
+
+// CurvePoint is the best mean value sustained over Duration.
+type CurvePoint struct {
+	Duration time.Duration
+	Value    float64
+}
+
+// Curve is a mean-maximal curve: the best mean of a metric for increasing
+// durations.
+type Curve []CurvePoint
+
+// curveDurations returns the durations, in seconds, evaluated on a series of
+// n samples: every second up to a minute, then durations growing by 2%, so
+// that the number of durations grows with the logarithm of n.
+func curveDurations(n int) []int {
+	var ds []int
+	for d := 1; d <= n; {
+		ds = append(ds, d)
+		if d < 60 {
+			d++
+		} else {
+			d = int(math.Ceil(float64(d) * 1.02))
+		}
+	}
+	if len(ds) > 0 && ds[len(ds)-1] != n {
+		ds = append(ds, n)
+	}
+	return ds
+}
+
+// meanMaxCurve computes the mean-maximal curve of a 1 Hz series at the
+// durations of curveDurations. Each is a single sliding-window pass over
+// prefix sums, for O(n log n) overall.
+func meanMaxCurve(series []float64) Curve {
+	prefix := prefixSums(series)
+	var c Curve
+	for _, d := range curveDurations(len(series)) {
+		c = append(c, CurvePoint{Duration: time.Duration(d) * time.Second, Value: bestMean(prefix, d)})
+	}
+	return c
+}
+
+// prefixSums returns the sums of the first i samples of series.
+func prefixSums(series []float64) []float64 {
+	prefix := make([]float64, len(series)+1)
+	for i, v := range series {
+		prefix[i+1] = prefix[i] + v
+	}
+	return prefix
+}
+
+// bestMean returns the best mean over d samples, sliding a window over the
+// prefix sums of a series.
+func bestMean(prefix []float64, d int) float64 {
+	best := math.Inf(-1)
+	for end := d; end < len(prefix); end++ {
+		best = math.Max(best, prefix[end]-prefix[end-d])
+	}
+	return best / float64(d)
+}
+
+// bestMeans returns the best mean of a 1 Hz series over each of durations,
+// rounded down to the second. Durations under a second or longer than the
+// series are left out.
+func bestMeans(series []float64, durations []time.Duration) map[time.Duration]float64 {
+	prefix := prefixSums(series)
+	best := make(map[time.Duration]float64, len(durations))
+	for _, d := range durations {
+		n := int(d / time.Second)
+		if n < 1 || n > len(series) {
+			continue
+		}
+		best[d] = bestMean(prefix, n)
+	}
+	return best
+}
+
+// PowerCurve returns the best average power of the activity, in watts, over
+// each of durations. Durations longer than the activity are left out.
+func (a *Activity) PowerCurve(durations []time.Duration) map[time.Duration]float64 {
+	return bestMeans(a.powerSeries(), durations)
+}
+
+// HeartRateCurve returns the best average heart rate of the activity, in
+// bpm, over each of durations. Durations longer than the activity are left
+// out.
+func (a *Activity) HeartRateCurve(durations []time.Duration) map[time.Duration]float64 {
+	series := resample1Hz(a.trackpoints(), func(p *Trackpoint) float64 { return float64(p.HeartRateInBpm) })
+	return bestMeans(series, durations)
+}
+
+// PaceCurve returns the best average pace of the activity over each of
+// durations, from its speed. Durations longer than the activity are left
+// out.
+func (a *Activity) PaceCurve(durations []time.Duration) map[time.Duration]Pace {
+	series := resample1Hz(a.trackpoints(), func(p *Trackpoint) float64 { return p.SpeedInMetersPerSec })
+	paces := make(map[time.Duration]Pace)
+	for d, speed := range bestMeans(series, durations) {
+		paces[d] = PaceFromSpeed(speed)
+	}
+	return paces
+}
+
+// At returns the curve value at duration d, interpolating between the
+// evaluated durations. It returns 0 beyond the longest duration.
+func (c Curve) At(d time.Duration) float64 {
+	i := sort.Search(len(c), func(i int) bool { return c[i].Duration >= d })
+	switch {
+	case i == len(c):
+		return 0
+	case c[i].Duration == d || i == 0:
+		return c[i].Value
+	}
+	lo, hi := c[i-1], c[i]
+	frac := float64(d-lo.Duration) / float64(hi.Duration-lo.Duration)
+	return lo.Value + frac*(hi.Value-lo.Value)
+}
+
+// MergeCurves returns the best value of the curves at every duration any of
+// them evaluates.
+func MergeCurves(curves ...Curve) Curve {
+	set := make(map[time.Duration]bool)
+	for _, c := range curves {
+		for _, p := range c {
+			set[p.Duration] = true
+		}
+	}
+	durations := make([]time.Duration, 0, len(set))
+	for d := range set {
+		durations = append(durations, d)
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	merged := make(Curve, len(durations))
+	for i, d := range durations {
+		merged[i].Duration = d
+		for _, c := range curves {
+			merged[i].Value = math.Max(merged[i].Value, c.At(d))
+		}
+	}
+	return merged
+}
+
+// MeanMaxPower returns the power-duration curve of the activity: the best
+// average power, in watts, at every second up to a minute, then at
+// durations 2% apart up to the whole activity. Use At to interpolate
+// between them.
+func (a *Activity) MeanMaxPower() Curve {
+	series := resample1Hz(a.trackpoints(), func(p *Trackpoint) float64 { return float64(p.PowerInWatts) })
+	return meanMaxCurve(series)
+}
+
+// MeanMaxPowerRange merges the power-duration curves of the activities
+// started within [from, to).
+func MeanMaxPowerRange(activities []*Activity, from, to time.Time) Curve {
+	var curves []Curve
+	for _, a := range activities {
+		if a.ID.Before(from) || !a.ID.Before(to) {
+			continue
+		}
+		curves = append(curves, a.MeanMaxPower())
+	}
+	return MergeCurves(curves...)
+}
@@ -0,0 +1,77 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// withPower sets the power of the activity's trackpoints from watts.
+func withPower(a *Activity, watts []int) *Activity {
+	for i := range a.Laps[0].Track {
+		a.Laps[0].Track[i].PowerInWatts = watts[i]
+	}
+	return a
+}
+
+func TestMeanMaxPower(t *testing.T) {
+	watts := append(repeat(200, 600), repeat(300, 300)...)
+	watts = append(watts, repeat(150, 301)...)
+	watts[100] = 800
+	a := withPower(testActivity("Biking", repeat(9.0, len(watts)), nil), watts)
+
+	c := a.MeanMaxPower()
+	if c[0].Duration != time.Second || c[0].Value != 800 {
+		t.Errorf("1 s power = %+v, want 800", c[0])
+	}
+	if got := c.At(5 * time.Minute); math.Abs(got-300) > 1 {
+		t.Errorf("5 min power = %v, want 300", got)
+	}
+	if last := c[len(c)-1]; last.Duration != 1200*time.Second {
+		t.Errorf("longest duration = %v, want 20m0s", last.Duration)
+	}
+	for i := 1; i < len(c); i++ {
+		if c[i].Value > c[i-1].Value+1e-9 {
+			t.Fatalf("curve increases at %v", c[i].Duration)
+		}
+	}
+
+	b := withPower(testActivity("Biking", repeat(9.0, 601), nil), repeat(320, 601))
+	b.ID = b.ID.Add(24 * time.Hour)
+	m := MeanMaxPowerRange([]*Activity{a, b}, a.ID, a.ID.Add(48*time.Hour))
+	if got := m.At(5 * time.Minute); math.Abs(got-320) > 1 {
+		t.Errorf("merged 5 min power = %v, want 320", got)
+	}
+	if got := m.At(time.Second); got != 800 {
+		t.Errorf("merged 1 s power = %v, want 800", got)
+	}
+	if got := MeanMaxPowerRange([]*Activity{a, b}, b.ID, b.ID.Add(time.Hour)).At(time.Second); got != 320 {
+		t.Errorf("range 1 s power = %v, want 320", got)
+	}
+}
+
+func TestPowerCurve(t *testing.T) {
+	// 10 minutes at 200 W with a minute at 400 W, and 5 m/s during it.
+	speeds := repeat(4.0, 601)
+	a := testActivity(SportBiking, speeds, repeat(140, 601))
+	track := a.Laps[0].Track
+	for i := range track {
+		track[i].PowerInWatts = 200
+		if i >= 300 && i < 360 {
+			track[i].PowerInWatts = 400
+			track[i].SpeedInMetersPerSec = 5
+			track[i].SetHeartRate(170)
+		}
+	}
+	durations := []time.Duration{time.Second, time.Minute, 2 * time.Minute, time.Hour}
+	curve := a.PowerCurve(durations)
+	if len(curve) != 3 || curve[time.Second] != 400 || curve[time.Minute] != 400 || curve[2*time.Minute] != 300 {
+		t.Errorf("PowerCurve() = %v", curve)
+	}
+	if hr := a.HeartRateCurve(durations); hr[time.Minute] != 170 {
+		t.Errorf("HeartRateCurve() = %v", hr)
+	}
+	if pace := a.PaceCurve(durations); pace[time.Minute] != PaceFromSpeed(5) || pace[2*time.Minute] != PaceFromSpeed(4.5) {
+		t.Errorf("PaceCurve() = %v", pace)
+	}
+}
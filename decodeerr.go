@@ -0,0 +1,103 @@
+package tcx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors wrapped by the DecodeError of documents with broken values.
+var (
+	ErrMalformedTimestamp = errors.New("malformed timestamp")
+	ErrInvalidCoordinate  = errors.New("invalid coordinate")
+	ErrTruncated          = errors.New("truncated document")
+)
+
+// DecodeError locates a problem of a document: the activity, lap and
+// trackpoint it occurred in, counted from 0 in document order, or -1 when
+// outside of one, and its line. It wraps ErrMalformedTimestamp,
+// ErrInvalidCoordinate or ErrTruncated.
+type DecodeError struct {
+	Activity, Lap, Trackpoint int
+	Line                      int
+	Err                       error
+}
+
+func (e *DecodeError) Error() string {
+	path := ""
+	if e.Activity >= 0 {
+		path = fmt.Sprintf("Activities[%d]", e.Activity)
+		if e.Lap >= 0 {
+			path += fmt.Sprintf(".Laps[%d]", e.Lap)
+			if e.Trackpoint >= 0 {
+				path += fmt.Sprintf(".Track[%d]", e.Trackpoint)
+			}
+		}
+		path += " "
+	}
+	return fmt.Sprintf("%sline %d: %v", path, e.Line, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// WithBestEffort keeps what can be read of broken documents: malformed
+// timestamps and invalid positions are left out, and truncated documents
+// end where they stop, as do those exceeding the limits of the parse or
+// whose context is done. The parse then returns the partial document
+// along with the DecodeErrors met, joined.
+func WithBestEffort() Option {
+	return func(c *parseConfig) { c.bestEffort = true }
+}
+
+// checkedElement reports whether the value of the element starting with
+// start, in the element named parent, is checked while decoding.
+func checkedElement(start xml.StartElement, parent string) bool {
+	switch start.Name.Local {
+	case "Time":
+		return parent == "Trackpoint" || parent == "CoursePoint"
+	case "Id":
+		return parent == "Activity" || parent == "MultiSportSession"
+	case "Position":
+		return parent == "Trackpoint" || parent == "CoursePoint"
+	}
+	return false
+}
+
+// checkElement checks the tokens of a checked element, from its start to
+// its end, returning an error wrapping ErrMalformedTimestamp or
+// ErrInvalidCoordinate for broken values. When lenient, out-of-range
+// positions are left for checkMode to drop.
+func checkElement(tokens []xml.Token, lenient bool) error {
+	var text string
+	var element string
+	for _, tok := range tokens {
+		switch t := tok.(type) {
+		case xml.StartElement:
+			element, text = t.Name.Local, ""
+		case xml.CharData:
+			text += string(t)
+		case xml.EndElement:
+			switch element {
+			case "Time", "Id":
+				if _, err := time.Parse(time.RFC3339, text); err != nil {
+					return fmt.Errorf("%w %q", ErrMalformedTimestamp, text)
+				}
+			case "LatitudeDegrees", "LongitudeDegrees":
+				limit := 90.0
+				if element == "LongitudeDegrees" {
+					limit = 180
+				}
+				v, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+				if err != nil || math.IsNaN(v) || (!lenient && math.Abs(v) > limit) {
+					return fmt.Errorf("%w: %s %q", ErrInvalidCoordinate, element, text)
+				}
+			}
+			element, text = "", ""
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package tcx
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDecodeErrors(t *testing.T) {
+	data, err := os.ReadFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := string(data)
+	// The second trackpoint of the lap at index 2, the first with a track.
+	second := strings.Index(doc, "<Time>2015-04-12T07:28:23Z</Time>")
+	for _, tc := range []struct {
+		name string
+		doc  string
+		want error
+	}{
+		{"timestamp", doc[:second] + "<Time>yesterday</Time>" + doc[second+33:], ErrMalformedTimestamp},
+		{"coordinate", strings.Replace(doc, "<LatitudeDegrees>47.231456</LatitudeDegrees>", "<LatitudeDegrees>NaN</LatitudeDegrees>", 1), ErrInvalidCoordinate},
+		{"range", strings.Replace(doc, "<LongitudeDegrees>-1.555713</LongitudeDegrees>", "<LongitudeDegrees>-181.5</LongitudeDegrees>", 1), ErrInvalidCoordinate},
+		{"truncated", doc[:len(doc)/2], ErrTruncated},
+	} {
+		db, err := Parse(strings.NewReader(tc.doc))
+		var de *DecodeError
+		if db != nil || !errors.Is(err, tc.want) || !errors.As(err, &de) {
+			t.Errorf("%s: Parse = %v", tc.name, err)
+			continue
+		}
+		if tc.name != "truncated" && (de.Activity != 0 || de.Lap != 2 || de.Trackpoint > 1 || de.Line < 30) {
+			t.Errorf("%s: error located at %+v", tc.name, de)
+		}
+
+		db, err = Parse(strings.NewReader(tc.doc), WithBestEffort())
+		if db == nil || !errors.Is(err, tc.want) {
+			t.Fatalf("%s: best effort Parse = %v", tc.name, err)
+		}
+		if n := len(db.Activities[0].trackpoints()); n < 900 {
+			t.Errorf("%s: best effort kept %d trackpoints", tc.name, n)
+		}
+	}
+
+	if _, err := Parse(strings.NewReader(strings.Replace(doc, `<Lap StartTime="2015-04-12T07:28:19Z">`, `<Lap StartTime="noon">`, 1))); !errors.Is(err, ErrMalformedTimestamp) {
+		t.Errorf("Parse with a broken lap start = %v", err)
+	}
+	db, err := Parse(strings.NewReader(strings.Replace(doc, "-1.555713", "-181.5", 1)), WithLenient())
+	if err != nil || len(db.Warnings) == 0 {
+		t.Errorf("lenient Parse of an out of range position = %v, %v", err, db.Warnings)
+	}
+	db, err = Parse(strings.NewReader(doc), WithBestEffort(), WithMaxTrackpoints(100))
+	if !errors.Is(err, ErrTooManyTrackpoints) || len(db.Activities[0].trackpoints()) != 100 {
+		t.Errorf("best effort Parse over the trackpoint limit = %v", err)
+	}
+}
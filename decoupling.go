@@ -0,0 +1,103 @@
+package tcx
+
+import "time"
+
+// DecouplingOptions configures Decoupling.
+type DecouplingOptions struct {
+	// Warmup is the time left out at the start of the activity.
+	Warmup time.Duration
+	// ExcludeStops leaves out the stops and pauses detected with Moving,
+	// or, for Pw:Hr, the time without power.
+	ExcludeStops bool
+	Moving       MovingOptions
+}
+
+// DefaultDecouplingOptions leaves out a 10 minute warmup and the stops.
+var DefaultDecouplingOptions = DecouplingOptions{
+	Warmup:       10 * time.Minute,
+	ExcludeStops: true,
+	Moving:       DefaultMovingOptions,
+}
+
+// Decoupling compares the efficiency, output over heart rate, of the two
+// halves of an activity.
+type Decoupling struct {
+	// Metric is the output compared to heart rate: MetricPower (Pw:Hr) when
+	// the activity has power data, MetricSpeed (Pa:Hr) otherwise.
+	Metric Metric
+	// FirstHalf and SecondHalf are the average output over the average
+	// heart rate of each half.
+	FirstHalf, SecondHalf float64
+	// Percent is the loss of efficiency from the first half to the second.
+	// Under 5% is usually read as a sound aerobic base.
+	Percent float64
+}
+
+// Decoupling measures the aerobic decoupling, or cardiac drift, of the
+// activity: how much heart rate rises relative to power, or to speed, from
+// the first half to the second. Only time with a heart rate reading counts.
+// ok is false when either half has no data.
+func (a *Activity) Decoupling(opts DecouplingOptions) (d Decoupling, ok bool) {
+	d.Metric = MetricSpeed
+	if a.powerSeries() != nil {
+		d.Metric = MetricPower
+	}
+	ivs := a.intervals()
+	if len(ivs) == 0 {
+		return d, false
+	}
+
+	type sample struct{ dt, output, hr float64 }
+	var samples []sample
+	var total, elapsed float64
+	for _, iv := range ivs {
+		elapsed += iv.dt
+		if iv.dt == 0 || elapsed <= opts.Warmup.Seconds() || !iv.p.HasHeartRate() {
+			continue
+		}
+		speed := iv.p.SpeedInMetersPerSec
+		if speed == 0 {
+			speed = iv.dist / iv.dt
+		}
+		// Stops are detected by power for Pw:Hr, as trainers may not
+		// record speed.
+		stopped := speed < opts.Moving.MinSpeed
+		if d.Metric == MetricPower {
+			stopped = iv.p.PowerInWatts == 0
+		}
+		if opts.ExcludeStops && (iv.dt > opts.Moving.MaxGap.Seconds() || stopped) {
+			continue
+		}
+		output := speed
+		if d.Metric == MetricPower {
+			output = float64(iv.p.PowerInWatts)
+		}
+		samples = append(samples, sample{iv.dt, output, float64(iv.p.HeartRateInBpm)})
+		total += iv.dt
+	}
+
+	var halves [2]struct{ t, output, hr float64 }
+	var t float64
+	for _, s := range samples {
+		h := &halves[0]
+		if t+s.dt/2 > total/2 {
+			h = &halves[1]
+		}
+		t += s.dt
+		h.t += s.dt
+		h.output += s.output * s.dt
+		h.hr += s.hr * s.dt
+	}
+	for _, h := range halves {
+		if h.t == 0 || h.hr == 0 {
+			return d, false
+		}
+	}
+	d.FirstHalf = halves[0].output / halves[0].hr
+	d.SecondHalf = halves[1].output / halves[1].hr
+	if d.FirstHalf == 0 {
+		return d, false
+	}
+	d.Percent = (d.FirstHalf - d.SecondHalf) / d.FirstHalf * 100
+	return d, true
+}
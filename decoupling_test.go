@@ -0,0 +1,58 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDecoupling(t *testing.T) {
+	// An hour at 3 m/s, heart rate 150 then 165 in the second half of the
+	// time after warmup.
+	hrs := repeat(150, 3601)
+	for i := 2100; i < len(hrs); i++ {
+		hrs[i] = 165
+	}
+	a := testActivity(SportRunning, repeat(3.0, 3601), hrs)
+	d, ok := a.Decoupling(DefaultDecouplingOptions)
+	if !ok || d.Metric != MetricSpeed {
+		t.Fatalf("Decoupling() = %+v, %v", d, ok)
+	}
+	if math.Abs(d.FirstHalf-3.0/150) > 1e-9 || math.Abs(d.Percent-100.0/11) > 0.01 {
+		t.Errorf("Decoupling() = %+v, want 9.09%%", d)
+	}
+
+	// Stopped time counts as zero speed unless excluded.
+	for i := 3000; i < 3300; i++ {
+		a.Laps[0].Track[i].SpeedInMetersPerSec = 0
+		a.Laps[0].Track[i+1].LatitudeInDegrees = a.Laps[0].Track[i].LatitudeInDegrees
+	}
+	if d, _ := a.Decoupling(DefaultDecouplingOptions); d.SecondHalf < 3.0/165 || d.SecondHalf > 3.0/150 {
+		t.Errorf("Decoupling() without stops = %+v", d)
+	}
+	withStops := DefaultDecouplingOptions
+	withStops.ExcludeStops = false
+	if d, _ := a.Decoupling(withStops); d.SecondHalf >= 3.0/165 {
+		t.Errorf("Decoupling() with stops = %+v", d)
+	}
+
+	for i := range a.Laps[0].Track {
+		a.Laps[0].Track[i].PowerInWatts = 200
+	}
+	opts := DefaultDecouplingOptions
+	opts.Warmup = time.Duration(0)
+	if d, ok := a.Decoupling(opts); !ok || d.Metric != MetricPower || d.FirstHalf != 200.0/150 {
+		t.Errorf("Pw:Hr decoupling = %+v, %v", d, ok)
+	}
+	// A trainer ride records power without speed.
+	trainer := withPower(testActivity(SportBiking, repeat(0.0, 1201), repeat(140, 1201)), repeat(180, 1201))
+	for i := 600; i < 700; i++ {
+		trainer.Laps[0].Track[i].PowerInWatts = 0
+	}
+	if d, ok := trainer.Decoupling(opts); !ok || d.FirstHalf != 180.0/140 || d.SecondHalf != d.FirstHalf {
+		t.Errorf("trainer Pw:Hr decoupling = %+v, %v", d, ok)
+	}
+	if _, ok := (&Activity{}).Decoupling(opts); ok {
+		t.Error("Decoupling() of an empty activity")
+	}
+}
@@ -0,0 +1,123 @@
+package tcx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ElevationProvider gives the ground elevation above mean sea level, in
+// meters, at a position, such as from a digital elevation model. ok is
+// false where the provider has no data.
+type ElevationProvider interface {
+	Elevation(lat, lon float64) (meters float64, ok bool)
+}
+
+// NoElevation is an ElevationProvider without data: correcting with it
+// leaves altitudes as recorded.
+type NoElevation struct{}
+
+// Elevation reports no data.
+func (NoElevation) Elevation(lat, lon float64) (float64, bool) { return 0, false }
+
+// CorrectElevation replaces the altitudes of the activity with the ground
+// elevation p gives at their positions, removing the drift of barometric
+// altimeters and the noise of GPS ones. Trackpoints without a position or
+// where p has no data keep their altitude. It returns the number of
+// altitudes replaced.
+func (a *Activity) CorrectElevation(p ElevationProvider) int {
+	a.Invalidate()
+	n := 0
+	for _, pt := range a.trackpoints() {
+		if !pt.HasPosition() {
+			continue
+		}
+		if e, ok := p.Elevation(pt.LatitudeInDegrees, pt.LongitudeInDegrees); ok {
+			pt.SetAltitude(e)
+			n++
+		}
+	}
+	return n
+}
+
+// hgtVoid marks the samples of SRTM tiles without data.
+const hgtVoid = -32768
+
+// SRTM is an ElevationProvider reading the SRTM tiles of a directory: HGT
+// files such as N47W002.hgt, squares of 1201 (3 arc-second) or 3601
+// (1 arc-second) rows of big-endian 16-bit elevations from the northwest
+// corner. Tiles are loaded on first use and kept in memory.
+type SRTM struct {
+	dir   string
+	mu    sync.Mutex
+	tiles map[string][]int16
+}
+
+// NewSRTM returns a provider reading the tiles in dir.
+func NewSRTM(dir string) *SRTM {
+	return &SRTM{dir: dir, tiles: make(map[string][]int16)}
+}
+
+// hgtName returns the name of the tile whose southwest corner is at lat,
+// lon.
+func hgtName(lat, lon int) string {
+	ns, ew := 'N', 'E'
+	if lat < 0 {
+		ns, lat = 'S', -lat
+	}
+	if lon < 0 {
+		ew, lon = 'W', -lon
+	}
+	return fmt.Sprintf("%c%02d%c%03d.hgt", ns, lat, ew, lon)
+}
+
+// tile returns the samples of the tile named name, or nil when it is
+// missing or unreadable.
+func (s *SRTM) tile(name string) []int16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.tiles[name]; ok {
+		return t
+	}
+	var t []int16
+	if data, err := os.ReadFile(filepath.Join(s.dir, name)); err == nil {
+		side := int(math.Sqrt(float64(len(data) / 2)))
+		if side > 1 && side*side*2 == len(data) {
+			t = make([]int16, side*side)
+			for i := range t {
+				t[i] = int16(binary.BigEndian.Uint16(data[2*i:]))
+			}
+		}
+	}
+	s.tiles[name] = t
+	return t
+}
+
+// Elevation interpolates the tile covering the position bilinearly. There
+// is no data where the tile is missing or next to a void.
+func (s *SRTM) Elevation(lat, lon float64) (float64, bool) {
+	south, west := math.Floor(lat), math.Floor(lon)
+	t := s.tile(hgtName(int(south), int(west)))
+	if t == nil {
+		return 0, false
+	}
+	side := int(math.Sqrt(float64(len(t))))
+	y := (south + 1 - lat) * float64(side-1)
+	x := (lon - west) * float64(side-1)
+	r0, c0 := min(int(y), side-2), min(int(x), side-2)
+	fy, fx := y-float64(r0), x-float64(c0)
+	var corners [4]float64
+	for i, rc := range [4][2]int{{r0, c0}, {r0, c0 + 1}, {r0 + 1, c0}, {r0 + 1, c0 + 1}} {
+		v := t[rc[0]*side+rc[1]]
+		if v == hgtVoid {
+			return 0, false
+		}
+		corners[i] = float64(v)
+	}
+	top := corners[0]*(1-fx) + corners[1]*fx
+	bottom := corners[2]*(1-fx) + corners[3]*fx
+	return top*(1-fy) + bottom*fy, true
+}
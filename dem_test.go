@@ -0,0 +1,57 @@
+package tcx
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCorrectElevation(t *testing.T) {
+	a := testActivity(SportRunning, repeat(4.0, 10), nil)
+	if n := a.CorrectElevation(NoElevation{}); n != 0 || a.Laps[0].Track[0].AltitudeInMeters != 10 {
+		t.Errorf("NoElevation corrected %d altitudes", n)
+	}
+
+	// A 3x3 tile rising 100 m per sample eastwards, with a void in its
+	// northeast corner.
+	dir := t.TempDir()
+	samples := []int16{0, 100, hgtVoid, 0, 100, 200, 0, 100, 200}
+	data := make([]byte, 2*len(samples))
+	for i, v := range samples {
+		binary.BigEndian.PutUint16(data[2*i:], uint16(v))
+	}
+	if err := os.WriteFile(filepath.Join(dir, "N47W002.hgt"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	srtm := NewSRTM(dir)
+	for _, tt := range []struct {
+		lat, lon float64
+		want     float64
+		ok       bool
+	}{
+		{47, -2, 0, true},
+		{47.25, -1.75, 50, true},
+		{47.1, -1.5, 100, true},
+		{47.9, -1.1, 0, false},
+		{48.5, -1.5, 0, false},
+	} {
+		got, ok := srtm.Elevation(tt.lat, tt.lon)
+		if ok != tt.ok || math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("Elevation(%v, %v) = %v, %v, want %v, %v", tt.lat, tt.lon, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	// The test activity runs north from 47°N 1.5°W, in the middle of the
+	// tile.
+	if n := a.CorrectElevation(srtm); n != 10 {
+		t.Errorf("corrected %d altitudes, want 10", n)
+	}
+	if got := a.Laps[0].Track[5].AltitudeInMeters; math.Abs(got-100) > 1e-6 {
+		t.Errorf("altitude = %v, want 100", got)
+	}
+	if hgtName(-1, 10) != "S01E010.hgt" {
+		t.Errorf("hgtName(-1, 10) = %s", hgtName(-1, 10))
+	}
+}
@@ -0,0 +1,80 @@
+package tcx
+
+// ComputedDistance returns the distance covered by the lap, in meters,
+// measured between its GPS fixes with the DefaultEarthModel.
+func (l *Lap) ComputedDistance() float64 {
+	d := cumulativeDistances(pointers(l.Track))
+	if len(d) == 0 {
+		return 0
+	}
+	return d[len(d)-1]
+}
+
+// ComputedDistance returns the distance covered by the activity, in meters,
+// measured between its GPS fixes with the DefaultEarthModel, lap boundaries
+// included. The distance of an indoor activity follows its speed sensor.
+func (a *Activity) ComputedDistance() float64 {
+	d := a.distances(a.trackpoints())
+	if len(d) == 0 {
+		return 0
+	}
+	return d[len(d)-1]
+}
+
+// RepairDistances rewrites the DistanceInMeters of every lap holding GPS
+// fixes with the distance measured from them. Each lap runs up to the first
+// trackpoint of the next one, so the laps add up to ComputedDistance. Laps
+// without fixes are left as recorded. Indoors, distances follow the speed
+// sensor instead and laps without speeds are left as recorded. It returns
+// the number of laps rewritten.
+func (a *Activity) RepairDistances() int {
+	a.Invalidate()
+	pts := a.trackpoints()
+	indoor := a.IsIndoor()
+	cum := a.distances(pts)
+	var n, first int
+	for i := range a.Laps {
+		l := &a.Laps[i]
+		last := first + len(l.Track)
+		fixes := 0
+		for _, p := range l.Track {
+			if p.HasPosition() {
+				fixes++
+			}
+		}
+		if (indoor || fixes > 0) && last > first {
+			end := last
+			if end == len(pts) {
+				end--
+			}
+			if d := cum[end] - cum[first]; d != l.DistanceInMeters && (!indoor || d > 0) {
+				l.DistanceInMeters = d
+				n++
+			}
+		}
+		first = last
+	}
+	return n
+}
+
+// FillDistances sets the DistanceInMeters of the trackpoints lacking one to
+// the distance covered since the start of the activity, measured between
+// GPS fixes or, indoors, from the speed sensor. Filled distances carry on
+// from the closest recorded one before them, so that they keep increasing.
+// It returns the number of trackpoints filled.
+func (a *Activity) FillDistances() int {
+	a.Invalidate()
+	pts := a.trackpoints()
+	cum := a.distances(pts)
+	var n int
+	var offset float64
+	for i, p := range pts {
+		if p.HasDistance() {
+			offset = p.DistanceInMeters - cum[i]
+			continue
+		}
+		p.SetDistance(cum[i] + offset)
+		n++
+	}
+	return n
+}
@@ -0,0 +1,80 @@
+package tcx
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestComputedDistance(t *testing.T) {
+	a := testActivity(SportRunning, repeat(3.0, 201), nil)
+	if d := a.ComputedDistance(); math.Abs(d-600) > 0.5 {
+		t.Errorf("ComputedDistance() = %v, want 600", d)
+	}
+	if d := a.Laps[0].ComputedDistance(); d != a.ComputedDistance() {
+		t.Errorf("lap ComputedDistance() = %v", d)
+	}
+
+	// Split into two laps with bogus totals, and a third without GPS.
+	track := a.Laps[0].Track
+	a.Laps = []Lap{
+		{StartTime: track[0].Time, DistanceInMeters: 0, Track: track[:100]},
+		{StartTime: track[100].Time, DistanceInMeters: 9999, Track: track[100:]},
+		{StartTime: track[200].Time, DistanceInMeters: 42, Track: []Trackpoint{{Time: track[200].Time.Add(60e9)}}},
+	}
+	if n := a.RepairDistances(); n != 2 {
+		t.Errorf("RepairDistances() rewrote %d laps, want 2", n)
+	}
+	if d0, d1 := a.Laps[0].DistanceInMeters, a.Laps[1].DistanceInMeters; math.Abs(d0-300) > 0.5 || math.Abs(d1-300) > 0.5 {
+		t.Errorf("repaired laps = %v, %v, want 300 each", d0, d1)
+	}
+	if a.Laps[2].DistanceInMeters != 42 {
+		t.Errorf("lap without GPS rewritten to %v", a.Laps[2].DistanceInMeters)
+	}
+	if n := a.RepairDistances(); n != 0 {
+		t.Errorf("second RepairDistances() rewrote %d laps", n)
+	}
+}
+
+func TestTrackpointDistances(t *testing.T) {
+	a := testActivity(SportRunning, repeat(3.0, 401), nil)
+	// GPS measures 1200 m; the footpod records 1000 m, the first point at 0.
+	for i := range a.Laps[0].Track {
+		a.Laps[0].Track[i].SetDistance(2.5 * float64(i))
+	}
+	if s := a.Splits(SplitKilometer); len(s) != 1 || math.Abs(s[0].Distance-1000) > 1e-6 || s[0].Duration != 400e9 {
+		t.Errorf("splits = %+v, want one of 1000 m", s)
+	}
+
+	var b bytes.Buffer
+	if err := (&Tcx{Activities: []Activity{*a}}).Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "<DistanceMeters>0</DistanceMeters>") {
+		t.Error("recorded zero distance not written")
+	}
+	db, err := Parse(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := db.Activities[0].Laps[0].Track[0]
+	if !p.HasDistance() || db.Activities[0].Laps[0].Track[400].DistanceInMeters != 1000 {
+		t.Errorf("distances lost through Write: %+v", p)
+	}
+
+	// Without distances from 200 on, filling goes on from 500 m by GPS.
+	track := a.Laps[0].Track
+	for i := 200; i < len(track); i++ {
+		track[i].ClearDistance()
+	}
+	if n := a.FillDistances(); n != 201 {
+		t.Errorf("FillDistances() filled %d trackpoints, want 201", n)
+	}
+	if d := track[400].DistanceInMeters; math.Abs(d-1100) > 1 {
+		t.Errorf("last distance = %v, want 1100", d)
+	}
+	if n := a.FillDistances(); n != 0 {
+		t.Errorf("second FillDistances() filled %d trackpoints", n)
+	}
+}
@@ -0,0 +1,186 @@
+package tcx
+
+import (
+	"sort"
+	"time"
+)
+
+// DuplicateOptions configures duplicate detection.
+type DuplicateOptions struct {
+	// MinOverlap is the fraction of the shorter activity that must overlap
+	// the other one in time.
+	MinOverlap float64
+	// MaxDistance is the largest median distance, in meters, between the
+	// positions the two activities recorded at the same instants.
+	MaxDistance float64
+}
+
+// DefaultDuplicateOptions flags activities overlapping by half their time
+// within 100 meters of each other.
+var DefaultDuplicateOptions = DuplicateOptions{MinOverlap: 0.5, MaxDistance: 100}
+
+// Duplicate is a pair of activities recorded at the same time and place,
+// typically by a watch and a phone both recording the same session.
+type Duplicate struct {
+	A, B    *Activity
+	Overlap time.Duration
+	// Distance is the median distance in meters between the positions
+	// recorded at the same instants, or -1 when either activity has no
+	// GPS data.
+	Distance float64
+}
+
+// span returns the times of the first and last trackpoints.
+func span(pts []*Trackpoint) (start, end time.Time) {
+	if len(pts) == 0 {
+		return
+	}
+	return pts[0].Time, pts[len(pts)-1].Time
+}
+
+// positionAt returns the last position recorded at or before t.
+func positionAt(pts []*Trackpoint, t time.Time) (lat, lon float64, ok bool) {
+	i := sort.Search(len(pts), func(i int) bool { return pts[i].Time.After(t) })
+	for i--; i >= 0; i-- {
+		if pts[i].HasPosition() {
+			return pts[i].LatitudeInDegrees, pts[i].LongitudeInDegrees, true
+		}
+	}
+	return 0, 0, false
+}
+
+// FindDuplicates returns the pairs of activities that overlap in time and
+// space.
+func FindDuplicates(activities []*Activity, opts DuplicateOptions) []Duplicate {
+	pts := make([][]*Trackpoint, len(activities))
+	for i, a := range activities {
+		pts[i] = a.trackpoints()
+	}
+
+	var dups []Duplicate
+	for i := range activities {
+		for j := i + 1; j < len(activities); j++ {
+			s1, e1 := span(pts[i])
+			s2, e2 := span(pts[j])
+			from, to := s1, e1
+			if s2.After(from) {
+				from = s2
+			}
+			if e2.Before(to) {
+				to = e2
+			}
+			shorter := e1.Sub(s1)
+			if d := e2.Sub(s2); d < shorter {
+				shorter = d
+			}
+			overlap := to.Sub(from)
+			if overlap <= 0 || shorter <= 0 || overlap.Seconds()/shorter.Seconds() < opts.MinOverlap {
+				continue
+			}
+
+			var gaps []float64
+			for _, p := range pts[i] {
+				if p.Time.Before(from) || p.Time.After(to) || !p.HasPosition() {
+					continue
+				}
+				if lat, lon, ok := positionAt(pts[j], p.Time); ok {
+					gaps = append(gaps, distance(p.LatitudeInDegrees, p.LongitudeInDegrees, lat, lon))
+				}
+			}
+			d := Duplicate{A: activities[i], B: activities[j], Overlap: overlap, Distance: -1}
+			if len(gaps) > 0 {
+				d.Distance = median(gaps)
+				if d.Distance > opts.MaxDistance {
+					continue
+				}
+			}
+			dups = append(dups, d)
+		}
+	}
+	return dups
+}
+
+// richness scores how much sensor data an activity carries.
+func richness(a *Activity) int {
+	score := 0
+	for _, p := range a.trackpoints() {
+		score++
+		if p.HasPosition() {
+			score++
+		}
+		if p.HasHeartRate() {
+			score++
+		}
+		if p.HasCadence() {
+			score++
+		}
+		if p.SpeedInMetersPerSec > 0 {
+			score++
+		}
+	}
+	return score
+}
+
+// ChoosePrimary returns the recording of the pair carrying the most sensor
+// data as primary, and the other one as secondary.
+func (d Duplicate) ChoosePrimary() (primary, secondary *Activity) {
+	if richness(d.B) > richness(d.A) {
+		return d.B, d.A
+	}
+	return d.A, d.B
+}
+
+// FillGaps completes the trackpoints of a with the heart rate, cadence and
+// position recorded by other at the same instants (within tolerance), when a
+// lacks them. It is meant to merge a duplicate into its primary recording.
+func (a *Activity) FillGaps(other *Activity, tolerance time.Duration) {
+	a.Invalidate()
+	src := other.trackpoints()
+	for _, p := range a.trackpoints() {
+		i := sort.Search(len(src), func(i int) bool { return !src[i].Time.Before(p.Time) })
+		var q *Trackpoint
+		for _, k := range []int{i - 1, i} {
+			if k < 0 || k >= len(src) {
+				continue
+			}
+			if d := src[k].Time.Sub(p.Time); d <= tolerance && d >= -tolerance &&
+				(q == nil || d.Abs() < q.Time.Sub(p.Time).Abs()) {
+				q = src[k]
+			}
+		}
+		if q == nil {
+			continue
+		}
+		if !p.HasHeartRate() && q.HasHeartRate() {
+			p.SetHeartRate(q.HeartRateInBpm)
+		}
+		if !p.HasCadence() && q.HasCadence() {
+			p.SetCadence(q.Cadence)
+		}
+		if !p.HasPosition() {
+			p.LatitudeInDegrees, p.LongitudeInDegrees = q.LatitudeInDegrees, q.LongitudeInDegrees
+		}
+	}
+}
+
+// RemoveDuplicates returns activities without the secondary recording of
+// every duplicate pair, after filling the gaps of each primary from its
+// secondary.
+func RemoveDuplicates(activities []*Activity, dups []Duplicate) []*Activity {
+	drop := make(map[*Activity]bool)
+	for _, d := range dups {
+		if drop[d.A] || drop[d.B] {
+			continue
+		}
+		primary, secondary := d.ChoosePrimary()
+		primary.FillGaps(secondary, time.Second)
+		drop[secondary] = true
+	}
+	var kept []*Activity
+	for _, a := range activities {
+		if !drop[a] {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
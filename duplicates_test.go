@@ -0,0 +1,44 @@
+package tcx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	watch := testActivity("Running", repeat(3.0, 600), repeat(150, 600))
+	phone := testActivity("Running", repeat(3.0, 600), nil)
+	for i := range phone.Laps[0].Track {
+		phone.Laps[0].Track[i].Time = phone.Laps[0].Track[i].Time.Add(30 * time.Second)
+	}
+	later := testActivity("Running", repeat(3.0, 600), nil)
+	for i := range later.Laps[0].Track {
+		later.Laps[0].Track[i].Time = later.Laps[0].Track[i].Time.Add(2 * time.Hour)
+	}
+
+	dups := FindDuplicates([]*Activity{watch, phone, later}, DefaultDuplicateOptions)
+	if len(dups) != 1 {
+		t.Fatalf("got %d duplicates, want 1", len(dups))
+	}
+	d := dups[0]
+	if d.Overlap != 569*time.Second || d.Distance < 80 || d.Distance > 100 {
+		t.Errorf("duplicate overlap %v, distance %v", d.Overlap, d.Distance)
+	}
+	if p, _ := d.ChoosePrimary(); p != watch {
+		t.Error("expected the watch recording as primary")
+	}
+
+	kept := RemoveDuplicates([]*Activity{phone, watch, later}, dups)
+	if len(kept) != 2 || kept[0] != watch {
+		t.Errorf("RemoveDuplicates() kept %d activities", len(kept))
+	}
+}
+
+func TestFillGaps(t *testing.T) {
+	a := testActivity("Running", repeat(3.0, 10), nil)
+	b := testActivity("Running", repeat(3.0, 10), repeat(140, 10))
+	a.FillGaps(b, time.Second)
+	if hr := a.Laps[0].Track[5].HeartRateInBpm; hr != 140 {
+		t.Errorf("FillGaps() heart rate = %d, want 140", hr)
+	}
+}
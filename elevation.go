@@ -0,0 +1,69 @@
+package tcx
+
+// DefaultElevationHysteresis is the altitude change, in meters, that
+// TotalAscent and TotalDescent require before counting a climb or a drop, so
+// that barometric and GPS noise on flat ground doesn't add up.
+var DefaultElevationHysteresis = 3.0
+
+// elevationChange sums the climbs and drops of a track, counting a change
+// only once the altitude has moved by threshold meters from the last
+// counted level. Trackpoints without altitude are skipped.
+func elevationChange(pts []*Trackpoint, threshold float64) (ascent, descent float64) {
+	var ref float64
+	var started bool
+	for _, p := range pts {
+		if !p.HasAltitude() {
+			continue
+		}
+		alt := p.AltitudeInMeters
+		if !started {
+			ref, started = alt, true
+			continue
+		}
+		switch {
+		case alt-ref >= threshold:
+			ascent += alt - ref
+			ref = alt
+		case ref-alt >= threshold:
+			descent += ref - alt
+			ref = alt
+		}
+	}
+	return ascent, descent
+}
+
+// Elevation returns the elevation gain and loss of the activity, in meters,
+// ignoring altitude changes under threshold meters.
+func (a *Activity) Elevation(threshold float64) (ascent, descent float64) {
+	return elevationChange(a.trackpoints(), threshold)
+}
+
+// TotalAscent returns the elevation gain of the activity, in meters.
+func (a *Activity) TotalAscent() float64 {
+	ascent, _ := a.Elevation(DefaultElevationHysteresis)
+	return ascent
+}
+
+// TotalDescent returns the elevation loss of the activity, in meters.
+func (a *Activity) TotalDescent() float64 {
+	_, descent := a.Elevation(DefaultElevationHysteresis)
+	return descent
+}
+
+// Elevation returns the elevation gain and loss of the lap, in meters,
+// ignoring altitude changes under threshold meters.
+func (l *Lap) Elevation(threshold float64) (ascent, descent float64) {
+	return elevationChange(pointers(l.Track), threshold)
+}
+
+// TotalAscent returns the elevation gain of the lap, in meters.
+func (l *Lap) TotalAscent() float64 {
+	ascent, _ := l.Elevation(DefaultElevationHysteresis)
+	return ascent
+}
+
+// TotalDescent returns the elevation loss of the lap, in meters.
+func (l *Lap) TotalDescent() float64 {
+	_, descent := l.Elevation(DefaultElevationHysteresis)
+	return descent
+}
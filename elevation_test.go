@@ -0,0 +1,38 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestElevation(t *testing.T) {
+	// A 50 m climb and a 30 m drop, with a meter of noise on every point.
+	var alts []float64
+	for i := 0; i <= 100; i++ {
+		alts = append(alts, 100+float64(i)/2)
+	}
+	for i := 1; i <= 60; i++ {
+		alts = append(alts, 150-float64(i)/2)
+	}
+	a := testActivity(SportRunning, repeat(3.0, len(alts)), nil)
+	track := a.Laps[0].Track
+	for i := range track {
+		track[i].AltitudeInMeters = alts[i] + float64(i%2)
+	}
+	if up, down := a.TotalAscent(), a.TotalDescent(); math.Abs(up-50) > 3 || math.Abs(down-30) > 3 {
+		t.Errorf("ascent %v, descent %v, want about 50 and 30", up, down)
+	}
+	if up, _ := a.Elevation(0); up < 75 {
+		t.Errorf("ascent without hysteresis = %v, want the noise counted", up)
+	}
+
+	// Missing altitudes are skipped rather than read as sea level.
+	track[50].AltitudeInMeters = 0
+	if up := a.Laps[0].TotalAscent(); math.Abs(up-50) > 3 {
+		t.Errorf("lap ascent with a gap = %v", up)
+	}
+	flat := testActivity(SportRunning, repeat(3.0, 100), nil)
+	if up, down := flat.Elevation(DefaultElevationHysteresis); up != 0 || down != 0 {
+		t.Errorf("flat activity: ascent %v, descent %v", up, down)
+	}
+}
@@ -0,0 +1,73 @@
+package tcx
+
+// Namespaces of the Garmin extension schemas.
+const (
+	ActivityExtensionNS = "http://www.garmin.com/xmlschemas/ActivityExtension/v2"
+	FatCaloriesNS       = "http://www.garmin.com/xmlschemas/FatCalories/v1"
+)
+
+// LapExtensions holds the lap-level data of the Garmin extension schemas,
+// usually written with the ns3 prefix. Fields are nil when absent from the
+// file.
+type LapExtensions struct {
+	LX          *LapExtension `xml:"LX" json:"lx,omitempty"`
+	FatCalories *int          `xml:"FatCalories>Value" json:"fatCalories,omitempty"`
+	// Other holds the extension elements the package does not interpret.
+	Other []ExtensionElement `xml:",any" json:"-"`
+}
+
+// LapExtension is the ActivityExtension v2 lap summary (LX element), in
+// schema order. AvgSpeed is in m/s, AvgWatts and MaxWatts in watts.
+type LapExtension struct {
+	AvgSpeed       *float64 `xml:"AvgSpeed" json:"avgSpeed,omitempty"`
+	MaxBikeCadence *int     `xml:"MaxBikeCadence" json:"maxBikeCadence,omitempty"`
+	AvgRunCadence  *int     `xml:"AvgRunCadence" json:"avgRunCadence,omitempty"`
+	MaxRunCadence  *int     `xml:"MaxRunCadence" json:"maxRunCadence,omitempty"`
+	Steps          *int     `xml:"Steps" json:"steps,omitempty"`
+	AvgWatts       *int     `xml:"AvgWatts" json:"avgWatts,omitempty"`
+	MaxWatts       *int     `xml:"MaxWatts" json:"maxWatts,omitempty"`
+}
+
+// AverageRunCadence returns the average running cadence of the lap recorded
+// in its extensions, in strides per minute.
+func (l *Lap) AverageRunCadence() (int, bool) {
+	if l.Extensions == nil || l.Extensions.LX == nil || l.Extensions.LX.AvgRunCadence == nil {
+		return 0, false
+	}
+	return *l.Extensions.LX.AvgRunCadence, true
+}
+
+// AveragePower returns the average power of the lap recorded in its
+// extensions, in watts.
+func (l *Lap) AveragePower() (int, bool) {
+	if l.Extensions == nil || l.Extensions.LX == nil || l.Extensions.LX.AvgWatts == nil {
+		return 0, false
+	}
+	return *l.Extensions.LX.AvgWatts, true
+}
+
+// MaxPower returns the maximum power of the lap recorded in its extensions,
+// in watts.
+func (l *Lap) MaxPower() (int, bool) {
+	if l.Extensions == nil || l.Extensions.LX == nil || l.Extensions.LX.MaxWatts == nil {
+		return 0, false
+	}
+	return *l.Extensions.LX.MaxWatts, true
+}
+
+// Steps returns the number of steps of the lap recorded in its extensions.
+func (l *Lap) Steps() (int, bool) {
+	if l.Extensions == nil || l.Extensions.LX == nil || l.Extensions.LX.Steps == nil {
+		return 0, false
+	}
+	return *l.Extensions.LX.Steps, true
+}
+
+// FatCalories returns the fat calories of the lap recorded in its
+// extensions.
+func (l *Lap) FatCalories() (int, bool) {
+	if l.Extensions == nil || l.Extensions.FatCalories == nil {
+		return 0, false
+	}
+	return *l.Extensions.FatCalories, true
+}
@@ -0,0 +1,167 @@
+package tcx
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testLapExtensions = `<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+    xmlns:ns3="http://www.garmin.com/xmlschemas/ActivityExtension/v2">
+  <Activities>
+    <Activity Sport="Running">
+      <Id>2015-04-12T07:28:19Z</Id>
+      <Lap StartTime="2015-04-12T07:28:19Z">
+        <TotalTimeSeconds>300</TotalTimeSeconds>
+        <Extensions>
+          <ns3:LX>
+            <ns3:AvgRunCadence>86</ns3:AvgRunCadence>
+            <ns3:MaxRunCadence>94</ns3:MaxRunCadence>
+          </ns3:LX>
+          <FatCalories xmlns="http://www.garmin.com/xmlschemas/FatCalories/v1">
+            <Value>21</Value>
+          </FatCalories>
+        </Extensions>
+      </Lap>
+      <Lap StartTime="2015-04-12T07:33:19Z">
+        <TotalTimeSeconds>300</TotalTimeSeconds>
+      </Lap>
+      <Lap StartTime="2015-04-12T07:38:19Z">
+        <TotalTimeSeconds>300</TotalTimeSeconds>
+        <Extensions>
+          <LX xmlns="http://www.garmin.com/xmlschemas/ActivityExtension/v2">
+            <AvgSpeed>3.25</AvgSpeed>
+            <MaxBikeCadence>102</MaxBikeCadence>
+            <Steps>912</Steps>
+            <AvgWatts>231</AvgWatts>
+            <MaxWatts>410</MaxWatts>
+          </LX>
+        </Extensions>
+      </Lap>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestLapExtensions(t *testing.T) {
+	tcx, err := Parse(strings.NewReader(testLapExtensions))
+	if err != nil {
+		t.Fatal("Error parsing TCX data: ", err)
+	}
+	laps := tcx.Activities[0].Laps
+	if c, ok := laps[0].AverageRunCadence(); !ok || c != 86 {
+		t.Errorf("AverageRunCadence() = %d, %v", c, ok)
+	}
+	if m := laps[0].Extensions.LX.MaxRunCadence; m == nil || *m != 94 {
+		t.Errorf("MaxRunCadence = %v", m)
+	}
+	if f, ok := laps[0].FatCalories(); !ok || f != 21 {
+		t.Errorf("FatCalories() = %d, %v", f, ok)
+	}
+	if _, ok := laps[1].AverageRunCadence(); ok || laps[1].Extensions != nil {
+		t.Error("expected no extensions on the second lap")
+	}
+	if p, ok := laps[2].AveragePower(); !ok || p != 231 {
+		t.Errorf("AveragePower() = %d, %v", p, ok)
+	}
+	if p, ok := laps[2].MaxPower(); !ok || p != 410 {
+		t.Errorf("MaxPower() = %d, %v", p, ok)
+	}
+	if n, ok := laps[2].Steps(); !ok || n != 912 {
+		t.Errorf("Steps() = %d, %v", n, ok)
+	}
+	if lx := laps[2].Extensions.LX; lx.AvgSpeed == nil || *lx.AvgSpeed != 3.25 || lx.MaxBikeCadence == nil || *lx.MaxBikeCadence != 102 {
+		t.Errorf("LX = %+v", lx)
+	}
+	if _, ok := laps[0].AveragePower(); ok {
+		t.Error("expected no power on the first lap")
+	}
+
+	var b bytes.Buffer
+	if err := tcx.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	back, err := Parse(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.Activities[0].Laps[2].Extensions, laps[2].Extensions) {
+		t.Errorf("extensions changed through Write:\n%s", b.String())
+	}
+}
+
+const testVendorExtensions = `<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+    xmlns:ns3="http://www.garmin.com/xmlschemas/ActivityExtension/v2" xmlns:v="http://example.com/vendor/v1">
+  <Activities>
+    <Activity Sport="Running">
+      <Id>2015-04-12T07:28:19Z</Id>
+      <Lap StartTime="2015-04-12T07:28:19Z">
+        <TotalTimeSeconds>300</TotalTimeSeconds>
+        <DistanceMeters>1000</DistanceMeters>
+        <Calories>70</Calories>
+        <Intensity>Active</Intensity>
+        <TriggerMethod>Manual</TriggerMethod>
+        <Track>
+          <Trackpoint>
+            <Time>2015-04-12T07:28:19Z</Time>
+            <Extensions>
+              <ns3:TPX><ns3:Speed>3.5</ns3:Speed></ns3:TPX>
+              <v:Stryd v:unit="mm"><v:Oscillation>82</v:Oscillation><v:GroundTime>241</v:GroundTime></v:Stryd>
+            </Extensions>
+          </Trackpoint>
+        </Track>
+        <Extensions>
+          <ns3:LX><ns3:Steps>900</ns3:Steps></ns3:LX>
+          <v:Effort>7</v:Effort>
+        </Extensions>
+      </Lap>
+      <Extensions>
+        <v:Weather>sunny, <v:Temperature>12.5</v:Temperature> and dry<v:Wind>3 <v:Unit>m/s</v:Unit></v:Wind></v:Weather>
+      </Extensions>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestUnknownExtensionsRoundTrip(t *testing.T) {
+	db, err := Parse(strings.NewReader(testVendorExtensions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &db.Activities[0]
+	p := a.Laps[0].Track[0]
+	if p.SpeedInMetersPerSec != 3.5 || len(p.OtherExtensions) != 1 {
+		t.Fatalf("trackpoint = %+v", p)
+	}
+	stryd := p.OtherExtensions[0]
+	if stryd.XMLName.Space != "http://example.com/vendor/v1" || stryd.XMLName.Local != "Stryd" ||
+		len(stryd.Attrs) != 1 || stryd.Attrs[0].Value != "mm" || len(stryd.Children) != 2 || stryd.Children[1].Text != "241" {
+		t.Errorf("trackpoint extension = %+v", stryd)
+	}
+	if steps, ok := a.Laps[0].Steps(); !ok || steps != 900 || len(a.Laps[0].Extensions.Other) != 1 {
+		t.Errorf("lap extensions = %+v", a.Laps[0].Extensions)
+	}
+	if ext := a.Extensions; ext == nil || len(ext.Other) != 1 || ext.Other[0].Children[0].Text != "12.5" ||
+		ext.Other[0].Text != "sunny, " || ext.Other[0].Children[0].Tail != " and dry" {
+		t.Errorf("activity extensions = %+v", a.Extensions)
+	}
+
+	var b bytes.Buffer
+	if err := db.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	if want := `>sunny, <Temperature xmlns="http://example.com/vendor/v1">12.5</Temperature> and dry<Wind`; !strings.Contains(b.String(), want) {
+		t.Errorf("mixed content indented:\n%s", b.String())
+	}
+	back, err := Parse(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.Activities, db.Activities) {
+		t.Errorf("extensions changed through Write")
+	}
+	if c := a.Clone(); !reflect.DeepEqual(c, a) {
+		t.Error("clone lost extensions")
+	} else if c.Laps[0].Track[0].OtherExtensions[0].Children[0].Text = "0"; stryd.Children[0].Text != "82" {
+		t.Error("clone shares extensions with its source")
+	}
+}
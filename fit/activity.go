@@ -0,0 +1,291 @@
+package fit
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+// Event values of activity files.
+const (
+	eventSession       byte = 8
+	eventLap           byte = 9
+	eventActivity      byte = 26
+	eventTypeStop      byte = 1
+	eventTypeStopAll   byte = 4
+	activityTypeManual byte = 0
+)
+
+// Lap intensities, by TCX Intensity.
+var intensities = map[string]byte{
+	"Active":  0,
+	"Resting": 1,
+}
+
+// Lap triggers, by TCX TriggerMethod. FIT has no heart rate trigger, so
+// those laps are written as manual.
+var lapTriggers = map[string]byte{
+	"Manual":   0,
+	"Time":     1,
+	"Distance": 2,
+	"Location": 4,
+}
+
+var (
+	recordDef = &msgDef{local: 6, global: mesgRecord, fields: []fieldDef{
+		{fieldTimestamp, 4, typeUint32},
+		{0, 4, typeSint32}, // position_lat
+		{1, 4, typeSint32}, // position_long
+		{2, 2, typeUint16}, // altitude, 5 * (m + 500)
+		{3, 1, typeUint8},  // heart_rate
+		{4, 1, typeUint8},  // cadence
+		{5, 4, typeUint32}, // distance, cm
+		{6, 2, typeUint16}, // speed, mm/s
+		{7, 2, typeUint16}, // power
+	}}
+	lapDef = &msgDef{local: 7, global: mesgLap, fields: []fieldDef{
+		{fieldTimestamp, 4, typeUint32},
+		{fieldMessageIndex, 2, typeUint16},
+		{0, 1, typeEnum},    // event
+		{1, 1, typeEnum},    // event_type
+		{2, 4, typeUint32},  // start_time
+		{3, 4, typeSint32},  // start_position_lat
+		{4, 4, typeSint32},  // start_position_long
+		{5, 4, typeSint32},  // end_position_lat
+		{6, 4, typeSint32},  // end_position_long
+		{7, 4, typeUint32},  // total_elapsed_time, ms
+		{8, 4, typeUint32},  // total_timer_time, ms
+		{9, 4, typeUint32},  // total_distance, cm
+		{11, 2, typeUint16}, // total_calories
+		{13, 2, typeUint16}, // avg_speed, mm/s
+		{14, 2, typeUint16}, // max_speed, mm/s
+		{15, 1, typeUint8},  // avg_heart_rate
+		{16, 1, typeUint8},  // max_heart_rate
+		{17, 1, typeUint8},  // avg_cadence
+		{19, 2, typeUint16}, // avg_power
+		{20, 2, typeUint16}, // max_power
+		{23, 1, typeEnum},   // intensity
+		{24, 1, typeEnum},   // lap_trigger
+		{25, 1, typeEnum},   // sport
+	}}
+	sessionDef = &msgDef{local: 8, global: mesgSession, fields: []fieldDef{
+		{fieldTimestamp, 4, typeUint32},
+		{fieldMessageIndex, 2, typeUint16},
+		{0, 1, typeEnum},    // event
+		{1, 1, typeEnum},    // event_type
+		{2, 4, typeUint32},  // start_time
+		{3, 4, typeSint32},  // start_position_lat
+		{4, 4, typeSint32},  // start_position_long
+		{5, 1, typeEnum},    // sport
+		{7, 4, typeUint32},  // total_elapsed_time, ms
+		{8, 4, typeUint32},  // total_timer_time, ms
+		{9, 4, typeUint32},  // total_distance, cm
+		{11, 2, typeUint16}, // total_calories
+		{14, 2, typeUint16}, // avg_speed, mm/s
+		{15, 2, typeUint16}, // max_speed, mm/s
+		{16, 1, typeUint8},  // avg_heart_rate
+		{17, 1, typeUint8},  // max_heart_rate
+		{18, 1, typeUint8},  // avg_cadence
+		{20, 2, typeUint16}, // avg_power
+		{21, 2, typeUint16}, // max_power
+		{22, 2, typeUint16}, // total_ascent
+		{23, 2, typeUint16}, // total_descent
+		{25, 2, typeUint16}, // first_lap_index
+		{26, 2, typeUint16}, // num_laps
+	}}
+	activityDef = &msgDef{local: 9, global: mesgActivity, fields: []fieldDef{
+		{fieldTimestamp, 4, typeUint32},
+		{0, 4, typeUint32}, // total_timer_time, ms
+		{1, 2, typeUint16}, // num_sessions
+		{2, 1, typeEnum},   // type
+		{3, 1, typeEnum},   // event
+		{4, 1, typeEnum},   // event_type
+	}}
+)
+
+// uint8Value encodes v, or the invalid value when v is not positive or
+// doesn't fit.
+func uint8Value(v int) uint8 {
+	if v <= 0 || v >= invalidUint8 {
+		return invalidUint8
+	}
+	return uint8(v)
+}
+
+// uint16Value encodes v times scale, or the invalid value when v is not
+// positive or doesn't fit.
+func uint16Value(v, scale float64) uint16 {
+	v = math.Round(v * scale)
+	if v <= 0 || v >= invalidUint16 {
+		return invalidUint16
+	}
+	return uint16(v)
+}
+
+// recordedUint8 encodes v, a recorded value zero included, or the invalid
+// value when v is negative or doesn't fit.
+func recordedUint8(v int) uint8 {
+	if v < 0 || v >= invalidUint8 {
+		return invalidUint8
+	}
+	return uint8(v)
+}
+
+// recordedUint16 encodes v times scale, a recorded value zero included, or
+// the invalid value when v is negative or doesn't fit.
+func recordedUint16(v, scale float64) uint16 {
+	v = math.Round(v * scale)
+	if v < 0 || v >= invalidUint16 {
+		return invalidUint16
+	}
+	return uint16(v)
+}
+
+// uint32Value encodes v times scale, or the invalid value when v is
+// negative or doesn't fit.
+func uint32Value(v, scale float64) uint32 {
+	v = math.Round(v * scale)
+	if v < 0 || v >= invalidUint32 {
+		return invalidUint32
+	}
+	return uint32(v)
+}
+
+// position encodes the position of p, or invalid values when it has none.
+func position(p *tcx.Trackpoint) (lat, lon int32) {
+	if p == nil || !p.HasPosition() {
+		return invalidSint32, invalidSint32
+	}
+	return semicircles(p.LatitudeInDegrees), semicircles(p.LongitudeInDegrees)
+}
+
+// ends returns the first and last trackpoints of track with a position.
+func ends(track []tcx.Trackpoint) (first, last *tcx.Trackpoint) {
+	for i := range track {
+		if track[i].HasPosition() {
+			if first == nil {
+				first = &track[i]
+			}
+			last = &track[i]
+		}
+	}
+	return first, last
+}
+
+// EncodeActivity writes the activity as a FIT activity file: a record per
+// trackpoint, a lap message per lap and a session summing them up, the
+// layout devices write and training platforms import.
+func EncodeActivity(w io.Writer, a *tcx.Activity) error {
+	if len(a.Laps) == 0 {
+		return fmt.Errorf("activity %v has no laps to export", a.ID)
+	}
+	start := a.Laps[0].StartTime
+	if start.IsZero() {
+		start = a.ID
+	}
+	sport := sports[a.Sport]
+
+	var track []tcx.Trackpoint
+	for _, l := range a.Laps {
+		track = append(track, l.Track...)
+	}
+	dist := (&tcx.Course{Track: track}).Distances()
+	hasDist := len(dist) > 0 && dist[len(dist)-1] > 0
+
+	e := newEncoder()
+	e.write(fileIDDef, fileActivity, manufacturerDevelopment, uint16(0), uint32(1), timestamp(start))
+	e.write(eventDef, timestamp(start), eventTimer, eventTypeStart, uint8(0))
+	for i := range track {
+		p := &track[i]
+		lat, lon := position(p)
+		alt := uint16(invalidUint16)
+		if p.HasAltitude() {
+			alt = altitude(p.AltitudeInMeters)
+		}
+		hr := uint8(invalidUint8)
+		if p.HasHeartRate() {
+			hr = recordedUint8(p.HeartRateInBpm)
+		}
+		cad := uint8(invalidUint8)
+		if p.HasCadence() {
+			cad = recordedUint8(p.Cadence)
+		} else if p.RunCadence > 0 {
+			cad = uint8Value(p.RunCadence)
+		}
+		power := uint16(invalidUint16)
+		if p.HasPower() {
+			power = recordedUint16(float64(p.PowerInWatts), 1)
+		}
+		d := uint32(invalidUint32)
+		if p.HasDistance() {
+			d = uint32Value(p.DistanceInMeters, 100)
+		} else if hasDist {
+			d = uint32Value(dist[i], 100)
+		}
+		e.write(recordDef, timestamp(p.Time), lat, lon, alt, hr, cad, d,
+			uint16Value(p.SpeedInMetersPerSec, 1000), power)
+	}
+
+	var timer, distance, calories, maxSpeed float64
+	var maxPower int
+	end := start
+	for i := range a.Laps {
+		l := &a.Laps[i]
+		lapEnd := l.StartTime.Add(time.Duration(l.TotalTimeInSeconds * float64(time.Second)))
+		if n := len(l.Track); n > 0 && l.Track[n-1].Time.After(lapEnd) {
+			lapEnd = l.Track[n-1].Time
+		}
+		end = lapEnd
+		first, last := ends(l.Track)
+		startLat, startLon := position(first)
+		endLat, endLon := position(last)
+		var avgSpeed float64
+		if l.TotalTimeInSeconds > 0 {
+			avgSpeed = l.DistanceInMeters / l.TotalTimeInSeconds
+		}
+		cadence := l.Cadence
+		if c, ok := l.AverageRunCadence(); cadence == 0 && ok {
+			cadence = c
+		}
+		avgPower, _ := l.AveragePower()
+		lapMaxPower, _ := l.MaxPower()
+		maxPower = max(maxPower, lapMaxPower)
+
+		e.write(lapDef, timestamp(lapEnd), uint16(i), eventLap, eventTypeStop, timestamp(l.StartTime),
+			startLat, startLon, endLat, endLon,
+			uint32Value(lapEnd.Sub(l.StartTime).Seconds(), 1000), uint32Value(l.TotalTimeInSeconds, 1000),
+			uint32Value(l.DistanceInMeters, 100), uint16Value(l.Calories, 1),
+			uint16Value(avgSpeed, 1000), uint16Value(l.MaximumSpeedInMetersPerSec, 1000),
+			uint8Value(l.AverageHeartRateInBpm), uint8Value(l.MaximumHeartRateInBpm), uint8Value(cadence),
+			uint16Value(float64(avgPower), 1), uint16Value(float64(lapMaxPower), 1),
+			intensities[l.Intensity], lapTriggers[l.TriggerMethod], sport)
+
+		timer += l.TotalTimeInSeconds
+		distance += l.DistanceInMeters
+		calories += l.Calories
+		maxSpeed = math.Max(maxSpeed, l.MaximumSpeedInMetersPerSec)
+	}
+
+	first, _ := ends(track)
+	startLat, startLon := position(first)
+	var avgSpeed float64
+	if timer > 0 {
+		avgSpeed = distance / timer
+	}
+	avgHR, _ := a.AverageHeartbeat()
+	e.write(sessionDef, timestamp(end), uint16(0), eventSession, eventTypeStop, timestamp(start),
+		startLat, startLon, sport,
+		uint32Value(end.Sub(start).Seconds(), 1000), uint32Value(timer, 1000),
+		uint32Value(distance, 100), uint16Value(calories, 1),
+		uint16Value(avgSpeed, 1000), uint16Value(maxSpeed, 1000),
+		uint8Value(int(math.Round(avgHR))), uint8Value(a.MaxHeartRate()), uint8Value(int(math.Round(a.AverageCadence()))),
+		uint16Value(a.AveragePower(), 1), uint16Value(float64(maxPower), 1),
+		uint16Value(a.TotalAscent(), 1), uint16Value(a.TotalDescent(), 1),
+		uint16(0), uint16(len(a.Laps)))
+	e.write(eventDef, timestamp(end), eventTimer, eventTypeStopAll, uint8(0))
+	e.write(activityDef, timestamp(end), uint32Value(timer, 1000), uint16(1), activityTypeManual, eventActivity, eventTypeStop)
+	return e.flush(w)
+}
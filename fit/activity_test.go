@@ -0,0 +1,67 @@
+package fit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+func TestEncodeActivity(t *testing.T) {
+	db, err := tcx.ParseFile("../testdata/test1.tcx")
+	if err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	var buf bytes.Buffer
+	if err := EncodeActivity(&buf, &db.Activities[0]); err != nil {
+		t.Fatal(err)
+	}
+	counts, err := countMessages(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[uint16]int{mesgFileID: 1, mesgEvent: 2, mesgRecord: 1937, mesgLap: 25, mesgSession: 1, mesgActivity: 1}
+	for m, n := range want {
+		if counts[m] != n {
+			t.Errorf("message %d: got %d, want %d", m, counts[m], n)
+		}
+	}
+
+	if err := EncodeActivity(&buf, &tcx.Activity{Sport: "Running"}); err == nil {
+		t.Error("expected an error for an activity without laps")
+	}
+}
+
+func TestEncodeRecordedZeros(t *testing.T) {
+	start := time.Date(2015, 4, 12, 7, 0, 0, 0, time.UTC)
+	a := &tcx.Activity{Sport: "Biking", ID: start}
+	l := tcx.Lap{StartTime: start, TotalTimeInSeconds: 2}
+	for i := 0; i < 3; i++ {
+		p := tcx.Trackpoint{Time: start.Add(time.Duration(i) * time.Second)}
+		if i < 2 {
+			// Coasting: a recorded zero power and cadence.
+			p.SetPower(0)
+			p.SetCadence(0)
+		}
+		l.Track = append(l.Track, p)
+	}
+	a.Laps = []tcx.Lap{l}
+	var buf bytes.Buffer
+	if err := EncodeActivity(&buf, a); err != nil {
+		t.Fatal(err)
+	}
+	db, err := FromFIT(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	track := db.Activities[0].Laps[0].Track
+	if len(track) != 3 {
+		t.Fatalf("read back %d trackpoints", len(track))
+	}
+	for i, p := range track {
+		if want := i < 2; p.HasPower() != want || p.HasCadence() != want {
+			t.Errorf("trackpoint %d: power %v, cadence %v, want %v", i, p.HasPower(), p.HasCadence(), want)
+		}
+	}
+}
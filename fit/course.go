@@ -0,0 +1,182 @@
+package fit
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+// CourseOptions configures course export.
+type CourseOptions struct {
+	// Sport is the TCX sport name of the course.
+	Sport string
+	// Speed, in m/s, times tracks recorded without timestamps.
+	Speed float64
+	// TurnAngle is the heading change, in degrees, above which turns are
+	// added as course points. Zero disables turn detection.
+	TurnAngle float64
+}
+
+// DefaultCourseOptions adds turns sharper than 45 degrees to running
+// courses.
+var DefaultCourseOptions = CourseOptions{Sport: "Running", Speed: 3, TurnAngle: 45}
+
+// Course point types, by TCX PointType.
+var coursePointTypes = map[string]byte{
+	"Generic":       0,
+	"Summit":        1,
+	"Valley":        2,
+	"Water":         3,
+	"Food":          4,
+	"Danger":        5,
+	"Left":          6,
+	"Right":         7,
+	"Straight":      8,
+	"First Aid":     9,
+	"4th Category":  10,
+	"3rd Category":  11,
+	"2nd Category":  12,
+	"1st Category":  13,
+	"Hors Category": 14,
+	"Sprint":        15,
+}
+
+// Event values.
+const (
+	eventTimer              byte = 0
+	eventTypeStart          byte = 0
+	eventTypeStopDisableAll byte = 9
+)
+
+var (
+	fileIDDef = &msgDef{local: 0, global: mesgFileID, fields: []fieldDef{
+		{0, 1, typeEnum},    // type
+		{1, 2, typeUint16},  // manufacturer
+		{2, 2, typeUint16},  // product
+		{3, 4, typeUint32z}, // serial_number
+		{4, 4, typeUint32},  // time_created
+	}}
+	courseDef = &msgDef{local: 1, global: mesgCourse, fields: []fieldDef{
+		{4, 1, typeEnum},    // sport
+		{5, 16, typeString}, // name
+	}}
+	courseLapDef = &msgDef{local: 2, global: mesgLap, fields: []fieldDef{
+		{fieldTimestamp, 4, typeUint32},
+		{2, 4, typeUint32}, // start_time
+		{3, 4, typeSint32}, // start_position_lat
+		{4, 4, typeSint32}, // start_position_long
+		{5, 4, typeSint32}, // end_position_lat
+		{6, 4, typeSint32}, // end_position_long
+		{7, 4, typeUint32}, // total_elapsed_time, ms
+		{8, 4, typeUint32}, // total_timer_time, ms
+		{9, 4, typeUint32}, // total_distance, cm
+	}}
+	eventDef = &msgDef{local: 3, global: mesgEvent, fields: []fieldDef{
+		{fieldTimestamp, 4, typeUint32},
+		{0, 1, typeEnum},  // event
+		{1, 1, typeEnum},  // event_type
+		{4, 1, typeUint8}, // event_group
+	}}
+	courseRecordDef = &msgDef{local: 4, global: mesgRecord, fields: []fieldDef{
+		{fieldTimestamp, 4, typeUint32},
+		{0, 4, typeSint32}, // position_lat
+		{1, 4, typeSint32}, // position_long
+		{2, 2, typeUint16}, // altitude, 5 * (m + 500)
+		{5, 4, typeUint32}, // distance, cm
+	}}
+	coursePointDef = &msgDef{local: 5, global: mesgCoursePoint, fields: []fieldDef{
+		{fieldMessageIndex, 2, typeUint16},
+		{1, 4, typeUint32},  // timestamp
+		{2, 4, typeSint32},  // position_lat
+		{3, 4, typeSint32},  // position_long
+		{4, 4, typeUint32},  // distance, cm
+		{5, 1, typeEnum},    // type
+		{6, 16, typeString}, // name
+	}}
+)
+
+// altitude encodes an altitude in meters.
+func altitude(m float64) uint16 {
+	return uint16(math.Round((m + 500) * 5))
+}
+
+// EncodeCourse writes the course as a FIT course file, for navigation on
+// Garmin units that no longer accept TCX courses. The course points of the
+// TCX course are kept and, unless disabled, turns detected along the track
+// are added.
+func EncodeCourse(w io.Writer, c *tcx.Course, opts CourseOptions) error {
+	var track []tcx.Trackpoint
+	for _, p := range c.Track {
+		if p.LatitudeInDegrees != 0 || p.LongitudeInDegrees != 0 {
+			track = append(track, p)
+		}
+	}
+	if len(track) < 2 {
+		return fmt.Errorf("course %q has no track to export", c.Name)
+	}
+	dist := (&tcx.Course{Track: track}).Distances()
+
+	// Time untimed tracks at the given speed from now.
+	if track[0].Time.IsZero() {
+		if opts.Speed <= 0 {
+			return fmt.Errorf("course %q has no timestamps and no speed to time it", c.Name)
+		}
+		start := time.Now().UTC().Truncate(time.Second)
+		for i := range track {
+			track[i].Time = start.Add(time.Duration(dist[i] / opts.Speed * float64(time.Second)))
+		}
+	}
+	first, last := track[0], track[len(track)-1]
+	elapsed := uint32(last.Time.Sub(first.Time) / time.Millisecond)
+
+	e := newEncoder()
+	e.write(fileIDDef, fileCourse, manufacturerDevelopment, uint16(0), uint32(1), timestamp(first.Time))
+	e.write(courseDef, sports[opts.Sport], c.Name)
+	e.write(courseLapDef, timestamp(last.Time), timestamp(first.Time),
+		semicircles(first.LatitudeInDegrees), semicircles(first.LongitudeInDegrees),
+		semicircles(last.LatitudeInDegrees), semicircles(last.LongitudeInDegrees),
+		elapsed, elapsed, uint32(math.Round(dist[len(dist)-1]*100)))
+	e.write(eventDef, timestamp(first.Time), eventTimer, eventTypeStart, uint8(0))
+	for i, p := range track {
+		e.write(courseRecordDef, timestamp(p.Time),
+			semicircles(p.LatitudeInDegrees), semicircles(p.LongitudeInDegrees),
+			altitude(p.AltitudeInMeters), uint32(math.Round(dist[i]*100)))
+	}
+
+	points := append([]tcx.CoursePoint(nil), c.CoursePoints...)
+	if opts.TurnAngle > 0 {
+		points = append(points, tcx.TurnPoints(track, opts.TurnAngle)...)
+	}
+	for i, cp := range points {
+		// Locate the course point on the track by its nearest trackpoint.
+		nearest, best := 0, math.Inf(1)
+		for j, p := range track {
+			dlat := p.LatitudeInDegrees - cp.LatitudeInDegrees
+			dlon := (p.LongitudeInDegrees - cp.LongitudeInDegrees) * math.Cos(cp.LatitudeInDegrees*math.Pi/180)
+			if d := dlat*dlat + dlon*dlon; d < best {
+				nearest, best = j, d
+			}
+		}
+		e.write(coursePointDef, uint16(i), timestamp(track[nearest].Time),
+			semicircles(cp.LatitudeInDegrees), semicircles(cp.LongitudeInDegrees),
+			uint32(math.Round(dist[nearest]*100)), coursePointTypes[cp.PointType], cp.Name)
+	}
+	e.write(eventDef, timestamp(last.Time), eventTimer, eventTypeStopDisableAll, uint8(0))
+	return e.flush(w)
+}
+
+// EncodeActivityCourse writes the track of a recorded activity as a FIT
+// course named name, so a past route can be followed again.
+func EncodeActivityCourse(w io.Writer, a *tcx.Activity, name string, opts CourseOptions) error {
+	c := &tcx.Course{Name: name}
+	for _, l := range a.Laps {
+		c.Track = append(c.Track, l.Track...)
+	}
+	if opts.Sport == "" {
+		opts.Sport = a.Sport
+	}
+	return EncodeCourse(w, c, opts)
+}
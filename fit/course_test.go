@@ -0,0 +1,129 @@
+package fit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+// countMessages checks the header and CRCs of a FIT file and counts its data
+// messages by global message number.
+func countMessages(data []byte) (map[uint16]int, error) {
+	if len(data) < headerSize+2 || string(data[8:12]) != ".FIT" {
+		return nil, fmt.Errorf("not a FIT file")
+	}
+	if crc16(0, data[:12]) != binary.LittleEndian.Uint16(data[12:]) {
+		return nil, fmt.Errorf("bad header CRC")
+	}
+	if crc16(0, data) != 0 {
+		return nil, fmt.Errorf("bad file CRC")
+	}
+	size := int(binary.LittleEndian.Uint32(data[4:]))
+	body := data[headerSize : headerSize+size]
+
+	counts := make(map[uint16]int)
+	defs := make(map[byte]struct {
+		global uint16
+		size   int
+	})
+	for i := 0; i < len(body); {
+		h := body[i]
+		i++
+		local := h & 0x0F
+		if h&0x40 != 0 {
+			global := binary.LittleEndian.Uint16(body[i+2:])
+			n := int(body[i+4])
+			i += 5
+			s := 0
+			for f := 0; f < n; f++ {
+				s += int(body[i+3*f+1])
+			}
+			i += 3 * n
+			defs[local] = struct {
+				global uint16
+				size   int
+			}{global, s}
+			continue
+		}
+		d, ok := defs[local]
+		if !ok {
+			return nil, fmt.Errorf("undefined local message %d", local)
+		}
+		counts[d.global]++
+		i += d.size
+	}
+	return counts, nil
+}
+
+func TestEncodeCourse(t *testing.T) {
+	start := time.Date(2015, 4, 12, 9, 0, 0, 0, time.UTC)
+	c := &tcx.Course{Name: "Square loop"}
+	// Head north for 500 m, then turn right and head east.
+	for i := 0; i <= 200; i++ {
+		lat, lon := 47.0+float64(min(i, 100))*5/111195, -1.5+float64(max(i-100, 0))*5/75800
+		c.Track = append(c.Track, tcx.Trackpoint{Time: start.Add(time.Duration(i) * 2 * time.Second),
+			LatitudeInDegrees: lat, LongitudeInDegrees: lon, AltitudeInMeters: 20})
+	}
+	c.CoursePoints = []tcx.CoursePoint{{Name: "Water", LatitudeInDegrees: 47.0 + 250.0/111195, LongitudeInDegrees: -1.5, PointType: "Water"}}
+
+	var buf bytes.Buffer
+	if err := EncodeCourse(&buf, c, DefaultCourseOptions); err != nil {
+		t.Fatal(err)
+	}
+	counts, err := countMessages(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[uint16]int{mesgFileID: 1, mesgCourse: 1, mesgLap: 1, mesgEvent: 2, mesgRecord: 201, mesgCoursePoint: 2}
+	for m, n := range want {
+		if counts[m] != n {
+			t.Errorf("message %d: got %d, want %d", m, counts[m], n)
+		}
+	}
+
+	if err := EncodeCourse(&buf, &tcx.Course{Name: "Empty"}, DefaultCourseOptions); err == nil {
+		t.Error("expected an error for an empty course")
+	}
+}
+
+func TestEncodeActivityCourse(t *testing.T) {
+	a, err := tcx.ParseFile("../testdata/test1.tcx")
+	if err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	var buf bytes.Buffer
+	if err := EncodeActivityCourse(&buf, &a.Activities[0], "Nantes", CourseOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	counts, err := countMessages(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts[mesgRecord] != 1937 {
+		t.Errorf("got %d records, want 1937", counts[mesgRecord])
+	}
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	for _, c := range []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"Loop", 15, "Loop"},
+		{"Col du Galibier", 6, "Col du"},
+		{"Tour du Léman", 10, "Tour du L"},
+		{"Tour du Léman", 11, "Tour du Lé"},
+		{"日本", 2, ""},
+	} {
+		got := truncateUTF8(c.s, c.n)
+		if got != c.want || !utf8.ValidString(got) {
+			t.Errorf("truncateUTF8(%q, %d) = %q, want %q", c.s, c.n, got, c.want)
+		}
+	}
+}
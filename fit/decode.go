@@ -0,0 +1,428 @@
+package fit
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+// manufacturerGarmin is the manufacturer id of Garmin devices.
+const manufacturerGarmin = 1
+
+// Invalid values of the base types, by base type number.
+var invalidValues = map[byte]uint64{
+	0x00: 0xFF,               // enum
+	0x01: 0x7F,               // sint8
+	0x02: 0xFF,               // uint8
+	0x03: 0x7FFF,             // sint16
+	0x04: 0xFFFF,             // uint16
+	0x05: 0x7FFFFFFF,         // sint32
+	0x06: 0xFFFFFFFF,         // uint32
+	0x0A: 0,                  // uint8z
+	0x0B: 0,                  // uint16z
+	0x0C: 0,                  // uint32z
+	0x0D: 0xFF,               // byte
+	0x0E: 0x7FFFFFFFFFFFFFFF, // sint64
+	0x0F: 0xFFFFFFFFFFFFFFFF, // uint64
+	0x10: 0,                  // uint64z
+}
+
+// message is a decoded data message: its valid integer fields by number.
+type message struct {
+	global uint16
+	fields map[byte]int64
+}
+
+func (m message) has(num byte) bool {
+	_, ok := m.fields[num]
+	return ok
+}
+
+// time returns field num as a time, zero when missing.
+func (m message) time(num byte) time.Time {
+	v, ok := m.fields[num]
+	if !ok {
+		return time.Time{}
+	}
+	return fitEpoch.Add(time.Duration(v) * time.Second)
+}
+
+// scaled returns field num divided by scale, zero when missing.
+func (m message) scaled(num byte, scale float64) float64 {
+	return float64(m.fields[num]) / scale
+}
+
+// decodeMessages checks the header and CRC of a FIT file and decodes its
+// data messages in order.
+func decodeMessages(data []byte) ([]message, error) {
+	if len(data) < 12 || string(data[8:12]) != ".FIT" {
+		return nil, fmt.Errorf("not a FIT file")
+	}
+	hsize := int(data[0])
+	if hsize < 12 || len(data) < hsize {
+		return nil, fmt.Errorf("bad FIT header size %d", hsize)
+	}
+	if hsize >= 14 {
+		if crc := binary.LittleEndian.Uint16(data[12:]); crc != 0 && crc != crc16(0, data[:12]) {
+			return nil, fmt.Errorf("bad FIT header CRC")
+		}
+	}
+	size := int(binary.LittleEndian.Uint32(data[4:]))
+	if len(data) < hsize+size+2 {
+		return nil, fmt.Errorf("truncated FIT file")
+	}
+	if crc16(0, data[:hsize+size+2]) != 0 {
+		return nil, fmt.Errorf("bad FIT file CRC")
+	}
+	body := data[hsize : hsize+size]
+
+	type definition struct {
+		global  uint16
+		order   binary.ByteOrder
+		fields  []fieldDef
+		devSize int
+	}
+	var (
+		defs     = make(map[byte]*definition)
+		messages []message
+		last     uint32
+	)
+	for i := 0; i < len(body); {
+		h := body[i]
+		i++
+		var local byte
+		compressed := h&0x80 != 0
+		switch {
+		case compressed:
+			// Compressed timestamp header: a 5 bit offset from the last
+			// timestamp.
+			local = (h >> 5) & 0x03
+			offset := uint32(h & 0x1F)
+			ts := last&^0x1F | offset
+			if offset < last&0x1F {
+				ts += 0x20
+			}
+			last = ts
+		case h&0x40 != 0:
+			local = h & 0x0F
+			if i+5 > len(body) {
+				return nil, fmt.Errorf("truncated FIT definition")
+			}
+			d := &definition{order: binary.LittleEndian}
+			if body[i+1] == 1 {
+				d.order = binary.BigEndian
+			}
+			d.global = d.order.Uint16(body[i+2:])
+			n := int(body[i+4])
+			i += 5
+			if i+3*n > len(body) {
+				return nil, fmt.Errorf("truncated FIT definition")
+			}
+			for f := 0; f < n; f++ {
+				d.fields = append(d.fields, fieldDef{body[i], body[i+1], body[i+2]})
+				i += 3
+			}
+			if h&0x20 != 0 {
+				// Developer fields are skipped.
+				if i >= len(body) {
+					return nil, fmt.Errorf("truncated FIT definition")
+				}
+				n := int(body[i])
+				i++
+				if i+3*n > len(body) {
+					return nil, fmt.Errorf("truncated FIT definition")
+				}
+				for f := 0; f < n; f++ {
+					d.devSize += int(body[i+1])
+					i += 3
+				}
+			}
+			defs[local] = d
+			continue
+		default:
+			local = h & 0x0F
+		}
+
+		d, ok := defs[local]
+		if !ok {
+			return nil, fmt.Errorf("undefined FIT local message %d", local)
+		}
+		m := message{global: d.global, fields: make(map[byte]int64)}
+		for _, f := range d.fields {
+			if i+int(f.size) > len(body) {
+				return nil, fmt.Errorf("truncated FIT message")
+			}
+			if v, ok := fieldValue(body[i:i+int(f.size)], f.base, d.order); ok {
+				m.fields[f.num] = v
+			}
+			i += int(f.size)
+		}
+		i += d.devSize
+		if i > len(body) {
+			return nil, fmt.Errorf("truncated FIT message")
+		}
+		if ts, ok := m.fields[fieldTimestamp]; ok {
+			last = uint32(ts)
+		} else if compressed {
+			m.fields[fieldTimestamp] = int64(last)
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// fieldValue decodes an integer field. ok is false for invalid values and
+// for strings, floats and arrays, which aren't needed.
+func fieldValue(b []byte, base byte, order binary.ByteOrder) (v int64, ok bool) {
+	num := base & 0x1F
+	invalid, known := invalidValues[num]
+	if !known {
+		return 0, false
+	}
+	var u uint64
+	switch len(b) {
+	case 1:
+		u = uint64(b[0])
+	case 2:
+		u = uint64(order.Uint16(b))
+	case 4:
+		u = uint64(order.Uint32(b))
+	case 8:
+		u = order.Uint64(b)
+	default:
+		return 0, false
+	}
+	if u == invalid {
+		return 0, false
+	}
+	switch num {
+	case 0x01:
+		return int64(int8(u)), true
+	case 0x03:
+		return int64(int16(u)), true
+	case 0x05:
+		return int64(int32(u)), true
+	}
+	return int64(u), true
+}
+
+// degrees converts FIT semicircles to degrees.
+func degrees(s int64) float64 {
+	return float64(s) * 180 / (1 << 31)
+}
+
+// tcxSport returns the TCX sport of a FIT sport.
+func tcxSport(sport int64) string {
+	switch sport {
+	case 1:
+		return "Running"
+	case 2:
+		return "Biking"
+	}
+	return "Other"
+}
+
+// tcxTrigger returns the TCX trigger method of a FIT lap trigger.
+func tcxTrigger(trigger int64) string {
+	switch trigger {
+	case 1:
+		return "Time"
+	case 2:
+		return "Distance"
+	case 3, 4, 5, 6:
+		return "Location"
+	}
+	return "Manual"
+}
+
+// trackpoint builds a trackpoint from a record message.
+func trackpoint(m message, sport string) tcx.Trackpoint {
+	p := tcx.Trackpoint{Time: m.time(fieldTimestamp)}
+	if m.has(0) && m.has(1) {
+		p.SetPosition(degrees(m.fields[0]), degrees(m.fields[1]))
+	}
+	switch {
+	case m.has(78): // enhanced_altitude
+		p.SetAltitude(m.scaled(78, 5) - 500)
+	case m.has(2):
+		p.SetAltitude(m.scaled(2, 5) - 500)
+	}
+	if m.has(5) {
+		p.SetDistance(m.scaled(5, 100))
+	}
+	if m.has(3) {
+		p.SetHeartRate(int(m.fields[3]))
+	}
+	if m.has(4) {
+		if sport == "Running" {
+			p.RunCadence = int(m.fields[4])
+		} else {
+			p.SetCadence(int(m.fields[4]))
+		}
+	}
+	if m.has(73) { // enhanced_speed
+		p.SpeedInMetersPerSec = m.scaled(73, 1000)
+	} else {
+		p.SpeedInMetersPerSec = m.scaled(6, 1000)
+	}
+	if m.has(7) {
+		p.SetPower(int(m.fields[7]))
+	}
+	return p
+}
+
+// lap builds a lap from a lap message, without its track.
+func lap(m message) tcx.Lap {
+	l := tcx.Lap{
+		StartTime:                  m.time(2),
+		TotalTimeInSeconds:         m.scaled(8, 1000),
+		DistanceInMeters:           m.scaled(9, 100),
+		MaximumSpeedInMetersPerSec: m.scaled(14, 1000),
+		Calories:                   float64(m.fields[11]),
+		AverageHeartRateInBpm:      int(m.fields[15]),
+		MaximumHeartRateInBpm:      int(m.fields[16]),
+		Cadence:                    int(m.fields[17]),
+		Intensity:                  "Active",
+		TriggerMethod:              tcxTrigger(m.fields[24]),
+	}
+	if !m.has(8) {
+		l.TotalTimeInSeconds = m.scaled(7, 1000)
+	}
+	if m.has(112) { // enhanced_max_speed
+		l.MaximumSpeedInMetersPerSec = m.scaled(112, 1000)
+	}
+	if m.fields[23] == 1 {
+		l.Intensity = "Resting"
+	}
+	return l
+}
+
+// FromFIT reads a FIT activity file into the tcx model: an activity per
+// session, with its laps and their records as trackpoints. Files without
+// session or lap messages get a single activity or lap spanning their
+// records.
+func FromFIT(r io.Reader) (*tcx.Tcx, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read fit data: %v", err)
+	}
+	messages, err := decodeMessages(data)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse fit data: %v", err)
+	}
+
+	var (
+		creator  tcx.Creator
+		sessions []message
+		laps     []message
+		records  []message
+	)
+	for _, m := range messages {
+		switch m.global {
+		case mesgFileID:
+			if m.fields[1] == manufacturerGarmin {
+				creator.ProductID = int(m.fields[2])
+				creator.UnitID = int(m.fields[3])
+				creator.Name = strings.TrimPrefix(creator.DeviceName(), "Garmin ")
+			}
+		case mesgSession:
+			sessions = append(sessions, m)
+		case mesgLap:
+			laps = append(laps, m)
+		case mesgRecord:
+			if m.has(fieldTimestamp) {
+				records = append(records, m)
+			}
+		}
+	}
+	if len(records) == 0 && len(laps) == 0 {
+		return nil, fmt.Errorf("fit file has no activity data")
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].fields[fieldTimestamp] < records[j].fields[fieldTimestamp]
+	})
+
+	if len(sessions) == 0 {
+		s := message{global: mesgSession, fields: map[byte]int64{}}
+		if len(laps) > 0 {
+			s.fields[2] = laps[0].fields[2]
+		} else {
+			s.fields[2] = records[0].fields[fieldTimestamp]
+		}
+		sessions = append(sessions, s)
+	}
+	if len(laps) == 0 {
+		first, last := records[0], records[len(records)-1]
+		l := message{global: mesgLap, fields: map[byte]int64{
+			2: first.fields[fieldTimestamp],
+			8: (last.fields[fieldTimestamp] - first.fields[fieldTimestamp]) * 1000,
+		}}
+		if d, ok := last.fields[5]; ok {
+			l.fields[9] = d
+		}
+		laps = append(laps, l)
+	}
+
+	t := &tcx.Tcx{XMLNs: tcx.TrainingCenterNS}
+	for si, s := range sessions {
+		a := tcx.Activity{Sport: tcxSport(s.fields[5]), ID: s.time(2), Creator: creator}
+
+		// Take the session's laps by index when given, or else by time.
+		first, n := 0, len(laps)
+		if s.has(25) && s.has(26) {
+			first, n = int(s.fields[25]), int(s.fields[26])
+		} else if len(sessions) > 1 {
+			first, n = -1, 0
+			for i, l := range laps {
+				if l.fields[2] < s.fields[2] {
+					continue
+				}
+				if si+1 < len(sessions) && l.fields[2] >= sessions[si+1].fields[2] {
+					break
+				}
+				if first < 0 {
+					first = i
+				}
+				n++
+			}
+		}
+		if first < 0 || first+n > len(laps) {
+			first, n = 0, 0
+		}
+		for _, m := range laps[first : first+n] {
+			a.Laps = append(a.Laps, lap(m))
+		}
+
+		// Give each record of the session to the last lap started at or
+		// before it.
+		for _, m := range records {
+			ts := m.fields[fieldTimestamp]
+			if n == 0 || len(sessions) > 1 && (ts < s.fields[2] || si+1 < len(sessions) && ts >= sessions[si+1].fields[2]) {
+				continue
+			}
+			li := 0
+			for i, l := range laps[first : first+n] {
+				if l.fields[2] <= ts {
+					li = i
+				}
+			}
+			p := trackpoint(m, a.Sport)
+			l := &a.Laps[li]
+			l.Track = append(l.Track, p)
+			if !laps[first+li].has(14) && !laps[first+li].has(112) {
+				l.MaximumSpeedInMetersPerSec = math.Max(l.MaximumSpeedInMetersPerSec, p.SpeedInMetersPerSec)
+			}
+		}
+		if a.ID.IsZero() && len(a.Laps) > 0 {
+			a.ID = a.Laps[0].StartTime
+		}
+		t.Activities = append(t.Activities, a)
+	}
+	return t, nil
+}
@@ -0,0 +1,58 @@
+package fit
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+func TestFromFIT(t *testing.T) {
+	db, err := tcx.ParseFile("../testdata/test1.tcx")
+	if err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	src := &db.Activities[0]
+	var buf bytes.Buffer
+	if err := EncodeActivity(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	back, err := FromFIT(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back.Activities) != 1 {
+		t.Fatalf("got %d activities, want 1", len(back.Activities))
+	}
+	a := &back.Activities[0]
+	if a.Sport != src.Sport || !a.ID.Equal(src.Laps[0].StartTime) || len(a.Laps) != 25 {
+		t.Fatalf("activity = %v %v with %d laps", a.Sport, a.ID, len(a.Laps))
+	}
+	var got, track []tcx.Trackpoint
+	for i, l := range a.Laps {
+		got = append(got, l.Track...)
+		track = append(track, src.Laps[i].Track...)
+		if want := src.Laps[i]; math.Abs(l.DistanceInMeters-want.DistanceInMeters) > 0.01 || math.Abs(l.TotalTimeInSeconds-want.TotalTimeInSeconds) > 0.001 {
+			t.Errorf("lap %d = %v m in %v s, want %v m in %v s", i, l.DistanceInMeters, l.TotalTimeInSeconds, want.DistanceInMeters, want.TotalTimeInSeconds)
+		}
+	}
+	if len(got) != 1937 {
+		t.Fatalf("got %d trackpoints, want 1937", len(got))
+	}
+	p, want := got[100], track[100]
+	if !p.Time.Equal(want.Time) || p.HeartRateInBpm != want.HeartRateInBpm ||
+		math.Abs(p.LatitudeInDegrees-want.LatitudeInDegrees) > 1e-6 || math.Abs(p.AltitudeInMeters-want.AltitudeInMeters) > 0.1 {
+		t.Errorf("trackpoint = %+v, want %+v", p, want)
+	}
+
+	data[len(data)/2] ^= 0xFF
+	if _, err := FromFIT(bytes.NewReader(data)); err == nil {
+		t.Error("expected an error for a corrupted file")
+	}
+	if _, err := FromFIT(bytes.NewReader([]byte("not a fit file"))); err == nil {
+		t.Error("expected an error for a non FIT file")
+	}
+}
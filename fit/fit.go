@@ -0,0 +1,193 @@
+// Package fit converts between the tcx model and Garmin's FIT binary
+// format. It implements the subset of the FIT protocol needed for activity
+// and course files with no dependency beyond the standard library.
+package fit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+// FIT protocol and profile versions written in file headers.
+const (
+	protocolVersion = 0x20
+	profileVersion  = 2132
+	headerSize      = 14
+)
+
+// fitEpoch is the origin of FIT timestamps.
+var fitEpoch = time.Date(1989, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// Base types of FIT fields.
+const (
+	typeEnum    byte = 0x00
+	typeSint8   byte = 0x01
+	typeUint8   byte = 0x02
+	typeSint16  byte = 0x83
+	typeUint16  byte = 0x84
+	typeSint32  byte = 0x85
+	typeUint32  byte = 0x86
+	typeString  byte = 0x07
+	typeUint32z byte = 0x8C
+)
+
+// Global message numbers.
+const (
+	mesgFileID      uint16 = 0
+	mesgSession     uint16 = 18
+	mesgLap         uint16 = 19
+	mesgRecord      uint16 = 20
+	mesgEvent       uint16 = 21
+	mesgActivity    uint16 = 34
+	mesgCourse      uint16 = 31
+	mesgCoursePoint uint16 = 32
+)
+
+// Field numbers shared by most messages.
+const (
+	fieldTimestamp    byte = 253
+	fieldMessageIndex byte = 254
+)
+
+// File types of the file_id message.
+const (
+	fileActivity byte = 4
+	fileCourse   byte = 6
+)
+
+// manufacturerDevelopment identifies files written by this package.
+const manufacturerDevelopment uint16 = 255
+
+// Invalid values mark fields without data.
+const (
+	invalidUint8  = 0xFF
+	invalidUint16 = 0xFFFF
+	invalidSint32 = 0x7FFFFFFF
+	invalidUint32 = 0xFFFFFFFF
+)
+
+// Sport enum values.
+var sports = map[string]byte{
+	"Other":    0,
+	"Running":  1,
+	"Biking":   2,
+	"Swimming": 5,
+	"Walking":  11,
+}
+
+var crcTable = [16]uint16{
+	0x0000, 0xCC01, 0xD801, 0x1400, 0xF001, 0x3C00, 0x2800, 0xE401,
+	0xA001, 0x6C00, 0x7800, 0xB401, 0x5000, 0x9C01, 0x8801, 0x4400,
+}
+
+// crc16 updates the FIT CRC with data.
+func crc16(crc uint16, data []byte) uint16 {
+	for _, b := range data {
+		tmp := crcTable[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ crcTable[b&0xF]
+		tmp = crcTable[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ crcTable[(b>>4)&0xF]
+	}
+	return crc
+}
+
+// semicircles converts degrees to FIT semicircles.
+func semicircles(deg float64) int32 {
+	return int32(math.Round(deg * (1 << 31) / 180))
+}
+
+// timestamp converts t to a FIT timestamp.
+func timestamp(t time.Time) uint32 {
+	return uint32(t.Sub(fitEpoch) / time.Second)
+}
+
+// fieldDef describes one field of a message definition.
+type fieldDef struct {
+	num  byte
+	size byte
+	base byte
+}
+
+// msgDef is the layout of a message, bound to a local message type.
+type msgDef struct {
+	local  byte
+	global uint16
+	fields []fieldDef
+}
+
+// encoder accumulates FIT records and writes them out with the file header
+// and CRC.
+type encoder struct {
+	data    bytes.Buffer
+	defined map[byte]bool
+}
+
+func newEncoder() *encoder {
+	return &encoder{defined: make(map[byte]bool)}
+}
+
+// write writes a data message, preceded by its definition the first time
+// its local type is used. values must match the definition's fields.
+func (e *encoder) write(def *msgDef, values ...interface{}) {
+	if !e.defined[def.local] {
+		e.data.WriteByte(0x40 | def.local)
+		e.data.WriteByte(0) // reserved
+		e.data.WriteByte(0) // little endian
+		binary.Write(&e.data, binary.LittleEndian, def.global)
+		e.data.WriteByte(byte(len(def.fields)))
+		for _, f := range def.fields {
+			e.data.Write([]byte{f.num, f.size, f.base})
+		}
+		e.defined[def.local] = true
+	}
+	e.data.WriteByte(def.local)
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			b := make([]byte, def.fields[i].size)
+			copy(b, truncateUTF8(s, len(b)-1))
+			e.data.Write(b)
+			continue
+		}
+		binary.Write(&e.data, binary.LittleEndian, v)
+	}
+}
+
+// truncateUTF8 returns the longest prefix of s of at most n bytes that does
+// not cut a character in two.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// flush writes the FIT file to w.
+func (e *encoder) flush(w io.Writer) error {
+	header := make([]byte, headerSize)
+	header[0] = headerSize
+	header[1] = protocolVersion
+	binary.LittleEndian.PutUint16(header[2:], profileVersion)
+	binary.LittleEndian.PutUint32(header[4:], uint32(e.data.Len()))
+	copy(header[8:], ".FIT")
+	binary.LittleEndian.PutUint16(header[12:], crc16(0, header[:12]))
+
+	crc := crc16(crc16(0, header), e.data.Bytes())
+	trailer := make([]byte, 2)
+	binary.LittleEndian.PutUint16(trailer, crc)
+
+	for _, b := range [][]byte{header, e.data.Bytes(), trailer} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package fit
+
+import (
+	"bytes"
+	"testing"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+// FuzzFromFIT checks that no input panics the FIT decoder.
+func FuzzFromFIT(f *testing.F) {
+	db, err := tcx.ParseFile("../testdata/test1.tcx")
+	if err != nil {
+		f.Fatal(err)
+	}
+	a := db.Activities[0]
+	a.Laps = a.Laps[2:4]
+	var buf bytes.Buffer
+	if err := EncodeActivity(&buf, &a); err != nil {
+		f.Fatal(err)
+	}
+	f.Add(buf.Bytes())
+	f.Add(buf.Bytes()[:buf.Len()/2])
+	f.Fuzz(func(t *testing.T, data []byte) {
+		db, err := FromFIT(bytes.NewReader(data))
+		if err == nil && db == nil {
+			t.Fatal("nil document without error")
+		}
+	})
+}
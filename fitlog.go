@@ -0,0 +1,247 @@
+package tcx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FitlogNS is the namespace of SportTracks fitlog files.
+const FitlogNS = "http://www.zonefivesoftware.com/xmlschemas/FitnessLogbook/v2"
+
+// fitlogWorkbook is the root of a SportTracks fitlog file.
+type fitlogWorkbook struct {
+	XMLName    xml.Name         `xml:"FitnessWorkbook"`
+	XMLNs      string           `xml:"xmlns,attr,omitempty"`
+	Activities []fitlogActivity `xml:"AthleteLog>Activity"`
+}
+
+type fitlogActivity struct {
+	StartTime time.Time `xml:"StartTime,attr"`
+	ID        string    `xml:"Id,attr,omitempty"`
+	Duration  *struct {
+		TotalSeconds float64 `xml:"TotalSeconds,attr"`
+	} `xml:"Duration"`
+	Distance *struct {
+		TotalMeters float64 `xml:"TotalMeters,attr"`
+	} `xml:"Distance"`
+	Calories *struct {
+		TotalCal float64 `xml:"TotalCal,attr"`
+	} `xml:"Calories"`
+	Category *struct {
+		Name string `xml:"Name,attr"`
+	} `xml:"Category"`
+	Laps  []fitlogLap  `xml:"Laps>Lap"`
+	Track *fitlogTrack `xml:"Track"`
+}
+
+type fitlogLap struct {
+	StartTime       time.Time `xml:"StartTime,attr"`
+	DurationSeconds float64   `xml:"DurationSeconds,attr"`
+	Calories        *struct {
+		TotalCal float64 `xml:"TotalCal,attr"`
+	} `xml:"Calories"`
+}
+
+type fitlogTrack struct {
+	StartTime time.Time     `xml:"StartTime,attr"`
+	Points    []fitlogPoint `xml:"pt"`
+}
+
+type fitlogPoint struct {
+	Tm      float64  `xml:"tm,attr"`
+	Lat     *float64 `xml:"lat,attr"`
+	Lon     *float64 `xml:"lon,attr"`
+	Ele     *float64 `xml:"ele,attr"`
+	Dist    *float64 `xml:"dist,attr"`
+	HR      *int     `xml:"hr,attr"`
+	Cadence *int     `xml:"cadence,attr"`
+	Power   *int     `xml:"power,attr"`
+}
+
+// fitlogSport maps a SportTracks category name, or a GPX track type, to a
+// TCX sport.
+func fitlogSport(category string) string {
+	c := strings.ToLower(category)
+	switch {
+	case strings.Contains(c, "run"):
+		return SportRunning
+	case strings.Contains(c, "bik"), strings.Contains(c, "cycl"), strings.Contains(c, "ride"):
+		return SportBiking
+	}
+	return SportOther
+}
+
+// ParseFitlog reads a SportTracks fitlog file into a Tcx, one activity per
+// logbook entry.
+func ParseFitlog(r io.Reader) (*Tcx, error) {
+	var wb fitlogWorkbook
+	if err := xml.NewDecoder(r).Decode(&wb); err != nil {
+		return nil, fmt.Errorf("couldn't parse fitlog data: %v", err)
+	}
+	t := NewTcx()
+	t.XMLNs = TrainingCenterNS
+	for _, fa := range wb.Activities {
+		t.Activities = append(t.Activities, fa.activity())
+	}
+	return t, nil
+}
+
+func (fa *fitlogActivity) activity() Activity {
+	a := Activity{Sport: SportOther, ID: fa.StartTime}
+	if fa.Category != nil {
+		a.Sport = fitlogSport(fa.Category.Name)
+	}
+
+	var track []Trackpoint
+	var dists []float64
+	if fa.Track != nil {
+		start := fa.Track.StartTime
+		if start.IsZero() {
+			start = fa.StartTime
+		}
+		for _, pt := range fa.Track.Points {
+			p := Trackpoint{Time: start.Add(time.Duration(pt.Tm * float64(time.Second)))}
+			if pt.Lat != nil && pt.Lon != nil {
+				p.LatitudeInDegrees, p.LongitudeInDegrees = *pt.Lat, *pt.Lon
+			}
+			if pt.Ele != nil {
+				p.AltitudeInMeters = *pt.Ele
+			}
+			if pt.HR != nil {
+				p.HeartRateInBpm = *pt.HR
+			}
+			if pt.Cadence != nil {
+				p.Cadence = *pt.Cadence
+			}
+			if pt.Power != nil {
+				p.PowerInWatts = *pt.Power
+			}
+			track = append(track, p)
+			if pt.Dist != nil {
+				dists = append(dists, *pt.Dist)
+			}
+		}
+	}
+	if len(dists) != len(track) {
+		dists = cumulativeDistances(pointers(track))
+	}
+
+	laps := fa.Laps
+	if len(laps) == 0 {
+		l := fitlogLap{StartTime: fa.StartTime}
+		if fa.Duration != nil {
+			l.DurationSeconds = fa.Duration.TotalSeconds
+		}
+		l.Calories = fa.Calories
+		laps = []fitlogLap{l}
+	}
+	sort.Slice(laps, func(i, j int) bool { return laps[i].StartTime.Before(laps[j].StartTime) })
+
+	j := 0
+	for i, fl := range laps {
+		lap := Lap{
+			StartTime:          fl.StartTime,
+			TotalTimeInSeconds: fl.DurationSeconds,
+			Intensity:          "Active",
+			TriggerMethod:      "Manual",
+		}
+		if fl.Calories != nil {
+			lap.Calories = fl.Calories.TotalCal
+		}
+		from := j
+		for j < len(track) && (i == len(laps)-1 || track[j].Time.Before(laps[i+1].StartTime)) {
+			j++
+		}
+		lap.Track = track[from:j]
+		if j > from {
+			lap.DistanceInMeters = dists[j-1] - dists[from]
+			if j < len(track) {
+				lap.DistanceInMeters = dists[j] - dists[from]
+			}
+		}
+		a.Laps = append(a.Laps, lap)
+	}
+	if len(laps) == 1 && fa.Distance != nil && fa.Distance.TotalMeters > 0 {
+		a.Laps[0].DistanceInMeters = fa.Distance.TotalMeters
+	}
+	return a
+}
+
+// WriteFitlog writes the activities of t as a SportTracks fitlog file.
+func (t *Tcx) WriteFitlog(w io.Writer) error {
+	wb := fitlogWorkbook{XMLNs: FitlogNS}
+	for i := range t.Activities {
+		a := &t.Activities[i]
+		fa := fitlogActivity{StartTime: a.ID.UTC(), ID: a.ID.UTC().Format(time.RFC3339)}
+		fa.Duration = &struct {
+			TotalSeconds float64 `xml:"TotalSeconds,attr"`
+		}{a.TotalDuration().Seconds()}
+		fa.Distance = &struct {
+			TotalMeters float64 `xml:"TotalMeters,attr"`
+		}{a.TotalDistance()}
+		var kcal float64
+		for _, l := range a.Laps {
+			kcal += l.Calories
+			fa.Laps = append(fa.Laps, fitlogLap{
+				StartTime:       l.StartTime.UTC(),
+				DurationSeconds: l.TotalTimeInSeconds,
+				Calories: &struct {
+					TotalCal float64 `xml:"TotalCal,attr"`
+				}{l.Calories},
+			})
+		}
+		fa.Calories = &struct {
+			TotalCal float64 `xml:"TotalCal,attr"`
+		}{kcal}
+		fa.Category = &struct {
+			Name string `xml:"Name,attr"`
+		}{a.Sport}
+
+		pts := a.trackpoints()
+		if len(pts) > 0 {
+			fa.Track = &fitlogTrack{StartTime: pts[0].Time.UTC()}
+			dists := cumulativeDistances(pts)
+			for k, p := range pts {
+				pt := fitlogPoint{Tm: p.Time.Sub(pts[0].Time).Seconds()}
+				if p.HasPosition() {
+					lat, lon := p.LatitudeInDegrees, p.LongitudeInDegrees
+					pt.Lat, pt.Lon = &lat, &lon
+				}
+				ele := p.AltitudeInMeters
+				pt.Ele = &ele
+				dist := math.Round(dists[k]*100) / 100
+				pt.Dist = &dist
+				if p.HasHeartRate() {
+					hr := p.HeartRateInBpm
+					pt.HR = &hr
+				}
+				if p.HasCadence() {
+					cad := p.Cadence
+					pt.Cadence = &cad
+				}
+				if p.HasPower() {
+					pw := p.PowerInWatts
+					pt.Power = &pw
+				}
+				fa.Track.Points = append(fa.Track.Points, pt)
+			}
+		}
+		wb.Activities = append(wb.Activities, fa)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(wb); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
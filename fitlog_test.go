@@ -0,0 +1,79 @@
+package tcx
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testFitlog = `<?xml version="1.0" encoding="utf-8"?>
+<FitnessWorkbook xmlns="http://www.zonefivesoftware.com/xmlschemas/FitnessLogbook/v2">
+  <AthleteLog>
+    <Activity StartTime="2010-06-05T08:00:00Z" Id="a1">
+      <Duration TotalSeconds="20" />
+      <Distance TotalMeters="60" />
+      <Calories TotalCal="5" />
+      <Category Name="My Running" />
+      <Laps>
+        <Lap StartTime="2010-06-05T08:00:00Z" DurationSeconds="10"><Calories TotalCal="2" /></Lap>
+        <Lap StartTime="2010-06-05T08:00:10Z" DurationSeconds="10"><Calories TotalCal="3" /></Lap>
+      </Laps>
+      <Track StartTime="2010-06-05T08:00:00Z">
+        <pt tm="0" lat="47.0" lon="-1.5" ele="10" dist="0" hr="120" />
+        <pt tm="5" lat="47.0001" lon="-1.5" ele="11" dist="15" hr="125" />
+        <pt tm="10" lat="47.0002" lon="-1.5" ele="12" dist="30" hr="130" cadence="85" />
+        <pt tm="20" lat="47.0004" lon="-1.5" ele="12" dist="60" hr="140" />
+      </Track>
+    </Activity>
+  </AthleteLog>
+</FitnessWorkbook>`
+
+func TestParseFitlog(t *testing.T) {
+	tcx, err := ParseFitlog(strings.NewReader(testFitlog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := tcx.Activities[0]
+	if a.Sport != SportRunning || len(a.Laps) != 2 {
+		t.Fatalf("got sport %q with %d laps", a.Sport, len(a.Laps))
+	}
+	if len(a.Laps[0].Track) != 2 || len(a.Laps[1].Track) != 2 {
+		t.Errorf("laps hold %d and %d trackpoints", len(a.Laps[0].Track), len(a.Laps[1].Track))
+	}
+	if a.Laps[0].DistanceInMeters != 30 || a.Laps[1].Calories != 3 {
+		t.Errorf("first lap %v m, second lap %v kcal", a.Laps[0].DistanceInMeters, a.Laps[1].Calories)
+	}
+	p := a.Laps[1].Track[0]
+	if !p.Time.Equal(time.Date(2010, 6, 5, 8, 0, 10, 0, time.UTC)) || p.HeartRateInBpm != 130 || p.Cadence != 85 {
+		t.Errorf("trackpoint = %+v", p)
+	}
+}
+
+func TestWriteFitlog(t *testing.T) {
+	orig, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	var buf bytes.Buffer
+	if err := orig.WriteFitlog(&buf); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ParseFitlog(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := orig.Activities[0], back.Activities[0]
+	if len(a.Laps) != len(b.Laps) || len(a.trackpoints()) != len(b.trackpoints()) {
+		t.Fatalf("round trip changed the structure")
+	}
+	if b.Sport != a.Sport || b.TotalDuration() != a.TotalDuration() {
+		t.Errorf("round trip: sport %q, duration %v", b.Sport, b.TotalDuration())
+	}
+	ahr, _ := a.AverageHeartbeat()
+	bhr, _ := b.AverageHeartbeat()
+	if d := math.Abs(bhr - ahr); d > 1e-9 {
+		t.Errorf("round trip changed average heart rate by %v", d)
+	}
+}
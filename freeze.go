@@ -0,0 +1,170 @@
+package tcx
+
+import "time"
+
+// TcxView is an immutable snapshot of a Tcx. Views share no memory with the
+// data they were frozen from, and nothing can modify them, so they may be
+// read from any number of goroutines without locking.
+type TcxView struct {
+	t          *Tcx
+	activities []ActivityView
+}
+
+// ActivityView is an immutable snapshot of an Activity.
+type ActivityView struct {
+	a *Activity
+}
+
+// LapView is an immutable snapshot of a Lap.
+type LapView struct {
+	l *Lap
+}
+
+// Freeze returns an immutable snapshot of t, with its activities,
+// multisport sessions, courses, workouts and author.
+func (t *Tcx) Freeze() TcxView {
+	c := t.Clone()
+	v := TcxView{t: c, activities: make([]ActivityView, len(c.Activities))}
+	for i := range c.Activities {
+		v.activities[i] = ActivityView{a: &c.Activities[i]}
+	}
+	return v
+}
+
+// Freeze returns an immutable snapshot of a.
+func (a *Activity) Freeze() ActivityView {
+	return ActivityView{a: a.Clone()}
+}
+
+// NumActivities returns the number of activities.
+func (v TcxView) NumActivities() int { return len(v.activities) }
+
+// Activity returns the i-th activity.
+func (v TcxView) Activity(i int) ActivityView { return v.activities[i] }
+
+// Thaw returns a mutable deep copy of the snapshot.
+func (v TcxView) Thaw() *Tcx {
+	if v.t == nil {
+		t := NewTcx()
+		t.XMLNs = TrainingCenterNS
+		return t
+	}
+	return v.t.Clone()
+}
+
+// Sport returns the sport of the activity.
+func (v ActivityView) Sport() string { return v.a.Sport }
+
+// ID returns the identifier, the start time, of the activity.
+func (v ActivityView) ID() time.Time { return v.a.ID }
+
+// Creator returns the device that recorded the activity.
+func (v ActivityView) Creator() Creator { return v.a.Creator }
+
+// NumLaps returns the number of laps.
+func (v ActivityView) NumLaps() int { return len(v.a.Laps) }
+
+// Lap returns the i-th lap.
+func (v ActivityView) Lap(i int) LapView { return LapView{&v.a.Laps[i]} }
+
+// Thaw returns a mutable deep copy of the snapshot.
+func (v ActivityView) Thaw() *Activity { return v.a.Clone() }
+
+// The analyses below only read the activity and are safe to run
+// concurrently on a view.
+
+func (v ActivityView) TotalDuration() time.Duration     { return v.a.TotalDuration() }
+func (v ActivityView) TotalDistance() float64           { return v.a.TotalDistance() }
+func (v ActivityView) AverageHeartbeat() (float64, int) { return v.a.AverageHeartbeat() }
+func (v ActivityView) MaxHeartRate() int                { return v.a.MaxHeartRate() }
+func (v ActivityView) AverageCadence() float64          { return v.a.AverageCadence() }
+func (v ActivityView) AveragePower() float64            { return v.a.AveragePower() }
+func (v ActivityView) NormalizedPower() float64         { return v.a.NormalizedPower() }
+func (v ActivityView) TotalAscent() float64             { return v.a.TotalAscent() }
+func (v ActivityView) TotalDescent() float64            { return v.a.TotalDescent() }
+func (v ActivityView) ComputedDistance() float64        { return v.a.ComputedDistance() }
+func (v ActivityView) AveragePace() (Pace, int)         { return v.a.AveragePace() }
+func (v ActivityView) InferSport() string               { return v.a.InferSport() }
+func (v ActivityView) MeanMaxPower() Curve              { return v.a.MeanMaxPower() }
+func (v ActivityView) MeanMaxSpeed() Curve              { return v.a.MeanMaxSpeed() }
+func (v ActivityView) Summary() Summary                 { return v.a.Summary() }
+
+func (v ActivityView) MovingTime(opts MovingOptions) time.Duration {
+	return v.a.MovingTime(opts)
+}
+
+func (v ActivityView) TimeInZones(metric Metric, z Zones) ZoneBreakdown {
+	return v.a.TimeInZones(metric, z)
+}
+
+func (v ActivityView) Splits(unit SplitUnit) []Split { return v.a.Splits(unit) }
+
+func (v ActivityView) BestEfforts(distances []float64) []Effort {
+	return v.a.BestEfforts(distances)
+}
+
+func (v ActivityView) EstimateThresholds() (Thresholds, bool) {
+	return v.a.EstimateThresholds()
+}
+
+func (v ActivityView) ClassifySession(th Thresholds) SessionType {
+	return v.a.ClassifySession(th)
+}
+
+// StartTime returns the start time of the lap.
+func (v LapView) StartTime() time.Time { return v.l.StartTime }
+
+// TotalTimeInSeconds returns the duration of the lap.
+func (v LapView) TotalTimeInSeconds() float64 { return v.l.TotalTimeInSeconds }
+
+// DistanceInMeters returns the distance of the lap.
+func (v LapView) DistanceInMeters() float64 { return v.l.DistanceInMeters }
+
+// MaximumSpeedInMetersPerSec returns the maximum speed of the lap.
+func (v LapView) MaximumSpeedInMetersPerSec() float64 { return v.l.MaximumSpeedInMetersPerSec }
+
+// Calories returns the energy spent in the lap.
+func (v LapView) Calories() float64 { return v.l.Calories }
+
+// AverageHeartRateInBpm returns the average heart rate of the lap.
+func (v LapView) AverageHeartRateInBpm() int { return v.l.AverageHeartRateInBpm }
+
+// MaximumHeartRateInBpm returns the maximum heart rate of the lap.
+func (v LapView) MaximumHeartRateInBpm() int { return v.l.MaximumHeartRateInBpm }
+
+// Cadence returns the average cadence of the lap.
+func (v LapView) Cadence() int { return v.l.Cadence }
+
+// Notes returns the notes of the lap.
+func (v LapView) Notes() string { return v.l.Notes }
+
+// Intensity returns the intensity of the lap, Active or Resting.
+func (v LapView) Intensity() string { return v.l.Intensity }
+
+// TriggerMethod returns what ended the lap.
+func (v LapView) TriggerMethod() string { return v.l.TriggerMethod }
+
+// AverageRunCadence returns the average running cadence of the lap
+// extensions.
+func (v LapView) AverageRunCadence() (int, bool) { return v.l.AverageRunCadence() }
+
+// Summary returns the summary of the lap.
+func (v LapView) Summary() Summary { return v.l.Summary() }
+
+// AveragePower returns the average power of the lap extensions.
+func (v LapView) AveragePower() (int, bool) { return v.l.AveragePower() }
+
+// MaxPower returns the maximum power of the lap extensions.
+func (v LapView) MaxPower() (int, bool) { return v.l.MaxPower() }
+
+// Steps returns the step count of the lap extensions.
+func (v LapView) Steps() (int, bool) { return v.l.Steps() }
+
+// FatCalories returns the fat calories of the lap extensions.
+func (v LapView) FatCalories() (int, bool) { return v.l.FatCalories() }
+
+// NumTrackpoints returns the number of trackpoints of the lap.
+func (v LapView) NumTrackpoints() int { return len(v.l.Track) }
+
+// Trackpoint returns a copy of the i-th trackpoint.
+func (v LapView) Trackpoint(i int) Trackpoint { return v.l.Track[i] }
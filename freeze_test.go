@@ -0,0 +1,79 @@
+package tcx
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	tcx, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	view := tcx.Freeze()
+	a := &tcx.Activities[0]
+	distance := a.TotalDistance()
+	hr, _ := a.AverageHeartbeat()
+
+	// Changes to the source must not reach the snapshot.
+	a.Laps[2].DistanceInMeters = 0
+	a.Laps[2].Track[0].HeartRateInBpm = 250
+	a.Laps = a.Laps[:3]
+
+	v := view.Activity(0)
+	if vhr, _ := v.AverageHeartbeat(); v.TotalDistance() != distance || vhr != hr {
+		t.Errorf("snapshot changed: %v m, %v bpm", v.TotalDistance(), vhr)
+	}
+	if v.Lap(2).Trackpoint(0).HeartRateInBpm == 250 {
+		t.Error("snapshot shares trackpoints with its source")
+	}
+
+	// Nor changes to thawed copies.
+	thawed := v.Thaw()
+	thawed.Laps[2].Track[0].HeartRateInBpm = 250
+	if v.Lap(2).Trackpoint(0).HeartRateInBpm == 250 {
+		t.Error("snapshot shares trackpoints with its thawed copy")
+	}
+
+	var wg sync.WaitGroup
+	results := make([]float64, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = view.Activity(0).MeanMaxSpeed().At(60)
+		}(i)
+	}
+	wg.Wait()
+	for _, r := range results[1:] {
+		if r != results[0] {
+			t.Fatalf("concurrent analyses disagree: %v", results)
+		}
+	}
+}
+
+func TestFreezeDocument(t *testing.T) {
+	src := &Tcx{
+		MultiSportSessions: []MultiSportSession{{FirstSport: *testActivity(SportBiking, repeat(8.0, 10), nil)}},
+		Courses:            []Course{{Name: "Loop", Track: testActivity(SportRunning, repeat(3.0, 10), nil).Laps[0].Track}},
+		Workouts:           []Workout{{Sport: SportRunning, Name: "Intervals"}},
+		Author:             &Author{Name: "go-tcx"},
+	}
+	view := src.Freeze()
+	src.MultiSportSessions[0].FirstSport.Laps[0].Track[0].HeartRateInBpm = 250
+	src.Courses[0].Track[0].HeartRateInBpm = 250
+	src.Author.Name = "changed"
+
+	thawed := view.Thaw()
+	if len(thawed.MultiSportSessions) != 1 || len(thawed.Courses) != 1 || len(thawed.Workouts) != 1 || thawed.Author == nil {
+		t.Fatalf("thawed document = %+v", thawed)
+	}
+	if thawed.MultiSportSessions[0].FirstSport.Laps[0].Track[0].HeartRateInBpm == 250 ||
+		thawed.Courses[0].Track[0].HeartRateInBpm == 250 || thawed.Author.Name != "go-tcx" {
+		t.Error("snapshot shares memory with its source")
+	}
+	thawed.Courses[0].Track[1].HeartRateInBpm = 250
+	if view.Thaw().Courses[0].Track[1].HeartRateInBpm == 250 {
+		t.Error("snapshot shares memory with its thawed copy")
+	}
+}
@@ -0,0 +1,46 @@
+package tcx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// FuzzParse checks that no input panics a parse, in any mode, and that
+// successful and best effort parses return a document that can be analyzed
+// and written.
+func FuzzParse(f *testing.F) {
+	data, err := os.ReadFile("testdata/test1.tcx")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(data[:4000])
+	f.Add([]byte(multisportTCX))
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(multisportTCX))
+	zw.Close()
+	f.Add(gz.Bytes())
+	f.Add([]byte(strings.Replace(multisportTCX, "2015-04-12T07:32:00Z", "07:32", 1)))
+	f.Add([]byte(`<TrainingCenterDatabase><Activities><Activity><Lap><Track><Trackpoint><Position><LatitudeDegrees>1e999`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, opts := range [][]Option{nil, {WithStrict()}, {WithLenient()}, {WithBestEffort()}, {WithLenient(), WithBestEffort()}} {
+			opts = append(opts, WithMaxBytes(1<<20), WithMaxTrackpoints(10000))
+			db, err := Parse(bytes.NewReader(data), opts...)
+			if err == nil && db == nil {
+				t.Fatal("nil document without error")
+			}
+			if db == nil {
+				continue
+			}
+			for _, a := range db.AllActivities() {
+				a.Summary()
+			}
+			db.Validate()
+			db.Write(io.Discard)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package tcx
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// earthRadius is the mean Earth radius in meters.
+const earthRadius = 6371008.8
+
+// WGS84 ellipsoid parameters.
+const (
+	wgs84A = 6378137.0
+	wgs84F = 1 / 298.257223563
+	wgs84B = wgs84A * (1 - wgs84F)
+)
+
+// EarthModel selects how distances between positions are computed.
+type EarthModel int
+
+const (
+	// Spherical uses the haversine formula on a sphere of the mean Earth
+	// radius. It is the fastest model, and errs by up to 0.5% depending on
+	// latitude and heading, an error that accumulates over a track.
+	Spherical EarthModel = iota
+	// WGS84 solves the geodesic on the WGS84 ellipsoid with Vincenty's
+	// inverse formula, accurate to the millimeter at three to four times the
+	// cost of Spherical.
+	WGS84
+)
+
+// earthModel holds the EarthModel of every distance computation.
+var earthModel atomic.Int64
+
+// DefaultEarthModel returns the model used by every distance computation of
+// the package, Spherical unless changed with SetDefaultEarthModel.
+func DefaultEarthModel() EarthModel {
+	return EarthModel(earthModel.Load())
+}
+
+// SetDefaultEarthModel changes the model of every distance computation of
+// the package and discards the summaries cached by Stats, which were
+// measured with the previous one. Computations running meanwhile may mix
+// both models; set it before processing activities.
+func SetDefaultEarthModel(m EarthModel) {
+	statsMu.Lock()
+	earthModel.Store(int64(m))
+	statsGen++
+	statsMu.Unlock()
+}
+
+// Distance returns the distance in meters between two positions given in
+// degrees.
+func (m EarthModel) Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	if m == WGS84 {
+		if d, ok := vincenty(lat1, lon1, lat2, lon2); ok {
+			return d
+		}
+	}
+	return haversine(lat1, lon1, lat2, lon2)
+}
+
+// distance returns the distance in meters between two positions given in
+// degrees, following DefaultEarthModel.
+func distance(lat1, lon1, lat2, lon2 float64) float64 {
+	return DefaultEarthModel().Distance(lat1, lon1, lat2, lon2)
+}
+
+// haversine returns the great-circle distance in meters between two
+// positions given in degrees.
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := deg2rad(lat1), deg2rad(lat2)
+	dphi := phi2 - phi1
+	dlambda := deg2rad(lon2 - lon1)
+	h := math.Sin(dphi/2)*math.Sin(dphi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dlambda/2)*math.Sin(dlambda/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+// vincenty returns the geodesic distance in meters between two positions on
+// the WGS84 ellipsoid. ok is false when the iteration does not converge, as
+// for nearly antipodal points.
+func vincenty(lat1, lon1, lat2, lon2 float64) (d float64, ok bool) {
+	if lat1 == lat2 && lon1 == lon2 {
+		return 0, true
+	}
+	u1 := math.Atan((1 - wgs84F) * math.Tan(deg2rad(lat1)))
+	u2 := math.Atan((1 - wgs84F) * math.Tan(deg2rad(lat2)))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+	l := deg2rad(lon2 - lon1)
+	lambda := l
+	var sinSigma, cosSigma, sigma, cos2Alpha, cos2SigmaM float64
+	for i := 0; i < 200; i++ {
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return 0, true
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0
+		if cos2Alpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+		c := wgs84F / 16 * cos2Alpha * (4 + wgs84F*(4-3*cos2Alpha))
+		prev := lambda
+		lambda = l + (1-c)*wgs84F*sinAlpha*
+			(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-prev) < 1e-12 {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	u := cos2Alpha * (wgs84A*wgs84A - wgs84B*wgs84B) / (wgs84B * wgs84B)
+	a := 1 + u/16384*(4096+u*(-768+u*(320-175*u)))
+	b := u / 1024 * (256 + u*(-128+u*(74-47*u)))
+	deltaSigma := b * sinSigma * (cos2SigmaM + b/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		b/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+	return wgs84B * a * (sigma - deltaSigma), true
+}
+
+// pointers returns pointers to the trackpoints of track.
+func pointers(track []Trackpoint) []*Trackpoint {
+	pts := make([]*Trackpoint, len(track))
+	for i := range track {
+		pts[i] = &track[i]
+	}
+	return pts
+}
+
+// cumulativeDistances returns the distance in meters covered at each point of
+// track, following GPS positions. Points without a fix add no distance.
+func cumulativeDistances(track []*Trackpoint) []float64 {
+	d := make([]float64, len(track))
+	last := -1
+	for i := range track {
+		if i > 0 {
+			d[i] = d[i-1]
+		}
+		if !track[i].HasPosition() {
+			continue
+		}
+		if last >= 0 {
+			d[i] += distance(track[last].LatitudeInDegrees, track[last].LongitudeInDegrees,
+				track[i].LatitudeInDegrees, track[i].LongitudeInDegrees)
+		}
+		last = i
+	}
+	return d
+}
+
+// interpolate returns the y value at x along the piecewise linear function
+// through (xs[i], ys[i]). xs must be sorted in increasing order.
+func interpolate(xs, ys []float64, x float64) float64 {
+	n := len(xs)
+	if n == 0 {
+		return 0
+	}
+	if x <= xs[0] {
+		return ys[0]
+	}
+	for i := 1; i < n; i++ {
+		if x <= xs[i] {
+			if xs[i] == xs[i-1] {
+				return ys[i]
+			}
+			return ys[i-1] + (ys[i]-ys[i-1])*(x-xs[i-1])/(xs[i]-xs[i-1])
+		}
+	}
+	return ys[n-1]
+}
@@ -0,0 +1,41 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEarthModels(t *testing.T) {
+	// Flinders Peak to Buninyong, the classic geodesic test case.
+	lat1, lon1 := -(37 + 57/60.0 + 3.72030/3600), 144+25/60.0+29.52440/3600
+	lat2, lon2 := -(37 + 39/60.0 + 10.15610/3600), 143+55/60.0+35.38390/3600
+	if d := WGS84.Distance(lat1, lon1, lat2, lon2); math.Abs(d-54972.271) > 0.001 {
+		t.Errorf("WGS84 distance = %.4f, want 54972.271", d)
+	}
+	if d := Spherical.Distance(lat1, lon1, lat2, lon2); math.Abs(d-54972.271) > 0.005*54972.271 {
+		t.Errorf("spherical distance = %.4f, not within 0.5%%", d)
+	}
+	// One degree of latitude at the equator is shorter on the ellipsoid.
+	if d := WGS84.Distance(0, 0, 1, 0); math.Abs(d-110574.4) > 0.5 {
+		t.Errorf("WGS84 degree of latitude = %.1f, want 110574.4", d)
+	}
+	// Antipodal points do not converge and fall back to the sphere.
+	if d := WGS84.Distance(0, 0, 0.5, 179.7); math.IsNaN(d) || d < 19e6 {
+		t.Errorf("nearly antipodal distance = %v", d)
+	}
+
+	a := testActivity(SportRunning, repeat(3.0, 101), nil)
+	defer SetDefaultEarthModel(DefaultEarthModel())
+	sphere := cumulativeDistances(a.trackpoints())[100]
+	// Measure the track rather than use the lap distance.
+	a.Laps[0].DistanceInMeters = 0
+	cached := a.Stats().Distance
+	SetDefaultEarthModel(WGS84)
+	ellipsoid := cumulativeDistances(a.trackpoints())[100]
+	if d := a.Stats().Distance; d == cached || math.Abs(d-ellipsoid) > 1e-9 {
+		t.Errorf("cached distance %.3f m not recomputed on the ellipsoid: %.3f m", cached, d)
+	}
+	if math.Abs(sphere-300) > 0.01 || math.Abs(ellipsoid-sphere) < 0.01 || math.Abs(ellipsoid-sphere) > 1.5 {
+		t.Errorf("track length %.3f m on the sphere, %.3f m on the ellipsoid", sphere, ellipsoid)
+	}
+}
@@ -0,0 +1,139 @@
+package tcx
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// GeoidModel gives the height of the geoid above the WGS84 ellipsoid, the
+// undulation, in meters.
+type GeoidModel interface {
+	Undulation(lat, lon float64) float64
+}
+
+// GeoidGrid is a geoid model interpolated bilinearly in a regular grid
+// running from 90°N to 90°S and eastwards from 0°E, as distributed for
+// EGM96 and EGM2008.
+type GeoidGrid struct {
+	rows, cols int
+	// step is the grid spacing in degrees.
+	step float64
+	data []float32
+}
+
+func newGeoidGrid(rows, cols int) (*GeoidGrid, error) {
+	if rows < 2 || cols < 2 || math.Abs(float64(rows-1)/180-float64(cols)/360) > 1e-9 {
+		return nil, fmt.Errorf("%dx%d is not a global geoid grid", cols, rows)
+	}
+	return &GeoidGrid{rows: rows, cols: cols, step: 180 / float64(rows-1), data: make([]float32, rows*cols)}, nil
+}
+
+// Undulation interpolates the grid at the position.
+func (g *GeoidGrid) Undulation(lat, lon float64) float64 {
+	y := (90 - math.Max(-90, math.Min(90, lat))) / g.step
+	x := math.Mod(lon+360, 360) / g.step
+	r0, c0 := int(y), int(x)
+	r1, c1 := min(r0+1, g.rows-1), (c0+1)%g.cols
+	fy, fx := y-float64(r0), x-float64(c0)
+	at := func(r, c int) float64 { return float64(g.data[r*g.cols+c%g.cols]) }
+	top := at(r0, c0)*(1-fx) + at(r0, c1)*fx
+	bottom := at(r1, c0)*(1-fx) + at(r1, c1)*fx
+	return top*(1-fy) + bottom*fy
+}
+
+// LoadGeoidPGM reads a geoid grid in the PGM format of GeographicLib, such
+// as egm96-5.pgm or egm2008-1.pgm: 16-bit samples scaled by the Offset and
+// Scale given in the header comments.
+func LoadGeoidPGM(r io.Reader) (*GeoidGrid, error) {
+	br := bufio.NewReader(r)
+	offset, scale := 0.0, 1.0
+	var fields []string
+	for len(fields) < 4 {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse geoid header: %v", err)
+		}
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			f := strings.Fields(strings.TrimPrefix(line, "#"))
+			if len(f) == 2 {
+				v, err := strconv.ParseFloat(f[1], 64)
+				switch {
+				case err != nil:
+				case f[0] == "Offset":
+					offset = v
+				case f[0] == "Scale":
+					scale = v
+				}
+			}
+			continue
+		}
+		fields = append(fields, strings.Fields(line)...)
+	}
+	if fields[0] != "P5" || fields[3] != "65535" {
+		return nil, fmt.Errorf("not a 16-bit PGM geoid grid")
+	}
+	cols, err1 := strconv.Atoi(fields[1])
+	rows, err2 := strconv.Atoi(fields[2])
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("couldn't parse geoid grid size")
+	}
+	g, err := newGeoidGrid(rows, cols)
+	if err != nil {
+		return nil, err
+	}
+	raw := make([]uint16, rows*cols)
+	if err := binary.Read(br, binary.BigEndian, raw); err != nil {
+		return nil, fmt.Errorf("couldn't read geoid grid: %v", err)
+	}
+	for i, v := range raw {
+		g.data[i] = float32(offset + scale*float64(v))
+	}
+	return g, nil
+}
+
+// LoadGeoidDAC reads the 15' EGM96 grid of the NGA file WW15MGH.DAC:
+// 721 rows of 1440 big-endian 16-bit undulations in centimeters.
+func LoadGeoidDAC(r io.Reader) (*GeoidGrid, error) {
+	g, err := newGeoidGrid(721, 1440)
+	if err != nil {
+		return nil, err
+	}
+	return g, g.readCentimeters(r)
+}
+
+func (g *GeoidGrid) readCentimeters(r io.Reader) error {
+	raw := make([]int16, len(g.data))
+	if err := binary.Read(bufio.NewReader(r), binary.BigEndian, raw); err != nil {
+		return fmt.Errorf("couldn't read geoid grid: %v", err)
+	}
+	for i, v := range raw {
+		g.data[i] = float32(v) / 100
+	}
+	return nil
+}
+
+// CorrectGeoid converts the altitudes of the activity from heights above
+// the WGS84 ellipsoid, as recorded by some GPS units, to heights above mean
+// sea level by subtracting the geoid undulation. Trackpoints without a
+// position are corrected with the undulation of the nearest fix before
+// them. It returns the number of altitudes corrected.
+func (a *Activity) CorrectGeoid(m GeoidModel) int {
+	a.Invalidate()
+	n, undulation, known := 0, 0.0, false
+	for _, p := range a.trackpoints() {
+		if p.HasPosition() {
+			undulation, known = m.Undulation(p.LatitudeInDegrees, p.LongitudeInDegrees), true
+		}
+		if known && p.HasAltitude() {
+			p.AltitudeInMeters -= undulation
+			n++
+		}
+	}
+	return n
+}
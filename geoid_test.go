@@ -0,0 +1,67 @@
+package tcx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// testGeoidPGM is a 45° grid whose undulation is the longitude / 10 at the
+// equator and zero elsewhere, scaled like the GeographicLib files.
+func testGeoidPGM() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "P5\n# Geoid test grid\n# Offset -108\n# Scale 0.003\n8 5\n65535\n")
+	for r := 0; r < 5; r++ {
+		for c := 0; c < 8; c++ {
+			n := 0.0
+			if r == 2 {
+				n = float64(c*45) / 10
+			}
+			binary.Write(&b, binary.BigEndian, uint16(math.Round((n+108)/0.003)))
+		}
+	}
+	return b.Bytes()
+}
+
+func TestLoadGeoidPGM(t *testing.T) {
+	g, err := LoadGeoidPGM(bytes.NewReader(testGeoidPGM()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct{ lat, lon, want float64 }{
+		{0, 90, 9},
+		{0, 112.5, 11.25},
+		{22.5, 90, 4.5},
+		{0, -45, 31.5},
+		{0, 337.5, 15.75},
+		{90, 10, 0},
+	} {
+		if got := g.Undulation(tc.lat, tc.lon); math.Abs(got-tc.want) > 0.01 {
+			t.Errorf("Undulation(%v, %v) = %v, want %v", tc.lat, tc.lon, got, tc.want)
+		}
+	}
+
+	if _, err := LoadGeoidPGM(bytes.NewReader([]byte("P5\n10 10\n65535\n"))); err == nil {
+		t.Error("expected an error for a grid that is not global")
+	}
+}
+
+type constantGeoid float64
+
+func (c constantGeoid) Undulation(lat, lon float64) float64 { return float64(c) }
+
+func TestCorrectGeoid(t *testing.T) {
+	a := testActivity(SportRunning, repeat(3.0, 10), repeat(140, 10))
+	a.Laps[0].Track[0].LatitudeInDegrees, a.Laps[0].Track[0].LongitudeInDegrees = 0, 0
+	if n := a.CorrectGeoid(constantGeoid(49.5)); n != 9 {
+		t.Errorf("corrected %d altitudes, want 9", n)
+	}
+	if alt := a.Laps[0].Track[5].AltitudeInMeters; alt != 10-49.5 {
+		t.Errorf("altitude = %v, want %v", alt, 10-49.5)
+	}
+	if alt := a.Laps[0].Track[0].AltitudeInMeters; alt != 10 {
+		t.Errorf("altitude before the first fix changed to %v", alt)
+	}
+}
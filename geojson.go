@@ -0,0 +1,98 @@
+package tcx
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// GeoJSONOptions configures ToGeoJSON. The zero value writes the geometry
+// and lap summaries only.
+type GeoJSONOptions struct {
+	// PointProperties adds the time, heart rate and altitude of every
+	// position as coordinateProperties arrays, parallel to the
+	// coordinates, as read by geojson.io and Mapbox.
+	PointProperties bool
+}
+
+type geoJSONCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type     string `json:"type"`
+	Geometry struct {
+		Type        string      `json:"type"`
+		Coordinates [][]float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties geoJSONLap `json:"properties"`
+}
+
+type geoJSONLap struct {
+	Sport     string    `json:"sport,omitempty"`
+	Activity  int       `json:"activity"`
+	Lap       int       `json:"lap"`
+	StartTime time.Time `json:"startTime"`
+	Duration  float64   `json:"duration"`
+	Distance  float64   `json:"distance"`
+
+	Points *geoJSONPoints `json:"coordinateProperties,omitempty"`
+}
+
+type geoJSONPoints struct {
+	Times      []time.Time `json:"times"`
+	HeartRates []int       `json:"heartRates"`
+	Altitudes  []float64   `json:"altitudes"`
+}
+
+// ToGeoJSON writes the activities of t as a GeoJSON FeatureCollection
+// holding a LineString feature per lap, with the lap summary in its
+// properties. Positions carry the altitude as a third coordinate when every
+// fix of the lap has one. Trackpoints without a GPS fix and laps without
+// any are left out.
+func (t *Tcx) ToGeoJSON(w io.Writer, opts GeoJSONOptions) error {
+	fc := geoJSONCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	for i, a := range t.AllActivities() {
+		for j, l := range a.Laps {
+			var fixes []*Trackpoint
+			hasAltitude := true
+			for k := range l.Track {
+				if p := &l.Track[k]; p.HasPosition() {
+					fixes = append(fixes, p)
+					hasAltitude = hasAltitude && p.HasAltitude()
+				}
+			}
+			if len(fixes) == 0 {
+				continue
+			}
+			f := geoJSONFeature{Type: "Feature"}
+			f.Geometry.Type = "LineString"
+			f.Properties = geoJSONLap{
+				Sport:     a.Sport,
+				Activity:  i,
+				Lap:       j,
+				StartTime: l.StartTime,
+				Duration:  l.TotalTimeInSeconds,
+				Distance:  l.DistanceInMeters,
+			}
+			if opts.PointProperties {
+				f.Properties.Points = &geoJSONPoints{}
+			}
+			for _, p := range fixes {
+				c := []float64{p.LongitudeInDegrees, p.LatitudeInDegrees}
+				if hasAltitude {
+					c = append(c, p.AltitudeInMeters)
+				}
+				f.Geometry.Coordinates = append(f.Geometry.Coordinates, c)
+				if pp := f.Properties.Points; pp != nil {
+					pp.Times = append(pp.Times, p.Time)
+					pp.HeartRates = append(pp.HeartRates, p.HeartRateInBpm)
+					pp.Altitudes = append(pp.Altitudes, p.AltitudeInMeters)
+				}
+			}
+			fc.Features = append(fc.Features, f)
+		}
+	}
+	return json.NewEncoder(w).Encode(fc)
+}
@@ -0,0 +1,69 @@
+package tcx
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestToGeoJSON(t *testing.T) {
+	db, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fc struct {
+		Type     string
+		Features []struct {
+			Type     string
+			Geometry struct {
+				Type        string
+				Coordinates [][]float64
+			}
+			Properties struct {
+				Lap                  int
+				Distance             float64
+				CoordinateProperties *struct {
+					Times      []string
+					HeartRates []int
+				}
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	if err := db.ToGeoJSON(&b, GeoJSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b.Bytes(), &fc); err != nil {
+		t.Fatal(err)
+	}
+	// Three laps of test1.tcx are empty.
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 22 {
+		t.Fatalf("%s with %d features", fc.Type, len(fc.Features))
+	}
+	f := fc.Features[0]
+	lap := db.Activities[0].Laps[2]
+	p := lap.Track[0]
+	if f.Geometry.Type != "LineString" || f.Properties.Lap != 2 || f.Properties.Distance != lap.DistanceInMeters {
+		t.Errorf("first feature = %+v", f.Properties)
+	}
+	if c := f.Geometry.Coordinates[0]; c[0] != p.LongitudeInDegrees || c[1] != p.LatitudeInDegrees {
+		t.Errorf("first position = %v, want lon/lat of %+v", c, p)
+	}
+	if f.Properties.CoordinateProperties != nil {
+		t.Error("point properties written without being asked for")
+	}
+
+	b.Reset()
+	fc.Features = nil
+	if err := db.ToGeoJSON(&b, GeoJSONOptions{PointProperties: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b.Bytes(), &fc); err != nil {
+		t.Fatal(err)
+	}
+	pp := fc.Features[0].Properties.CoordinateProperties
+	if pp == nil || len(pp.Times) != len(fc.Features[0].Geometry.Coordinates) || pp.HeartRates[0] != p.HeartRateInBpm {
+		t.Errorf("point properties = %+v", pp)
+	}
+}
@@ -0,0 +1,210 @@
+package tcx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// gcTimeLayout is the format of GoldenCheetah start times.
+const gcTimeLayout = "2006/01/02 15:04:05 MST"
+
+// gcFile is a GoldenCheetah JSON ride file.
+type gcFile struct {
+	Ride gcRide `json:"RIDE"`
+}
+
+type gcRide struct {
+	StartTime  string            `json:"STARTTIME"`
+	RecIntSecs float64           `json:"RECINTSECS"`
+	DeviceType string            `json:"DEVICETYPE,omitempty"`
+	Identifier string            `json:"IDENTIFIER"`
+	Tags       map[string]string `json:"TAGS,omitempty"`
+	Intervals  []gcInterval      `json:"INTERVALS,omitempty"`
+	Samples    []gcSample        `json:"SAMPLES"`
+}
+
+type gcInterval struct {
+	Name  string  `json:"NAME"`
+	Start float64 `json:"START"`
+	Stop  float64 `json:"STOP"`
+}
+
+type gcSample struct {
+	Secs  float64 `json:"SECS"`
+	KM    float64 `json:"KM"`
+	Watts float64 `json:"WATTS,omitempty"`
+	Cad   float64 `json:"CAD,omitempty"`
+	KPH   float64 `json:"KPH,omitempty"`
+	HR    float64 `json:"HR,omitempty"`
+	Alt   float64 `json:"ALT"`
+	Lat   float64 `json:"LAT,omitempty"`
+	Lon   float64 `json:"LON,omitempty"`
+}
+
+// GoldenCheetah sports, by TCX sport.
+var gcSports = map[string]string{
+	SportRunning:  "Run",
+	SportBiking:   "Bike",
+	SportSwimming: "Swim",
+	SportWalking:  "Walk",
+}
+
+// ParseGoldenCheetah reads a GoldenCheetah JSON ride file into a Tcx holding
+// one activity. Intervals become laps; a file without intervals gives a
+// single lap. Samples belong to the interval they follow, so recording
+// past the end of a lap stays with it.
+func ParseGoldenCheetah(r io.Reader) (*Tcx, error) {
+	var f gcFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("couldn't parse goldencheetah data: %v", err)
+	}
+	ride := f.Ride
+	start, err := time.Parse(gcTimeLayout, strings.TrimSpace(ride.StartTime))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse goldencheetah start time: %v", err)
+	}
+	start = start.UTC()
+
+	a := Activity{Sport: SportOther, ID: start}
+	sport := strings.TrimSpace(ride.Tags["Sport"])
+	for s, name := range gcSports {
+		if strings.EqualFold(sport, name) {
+			a.Sport = s
+		}
+	}
+	a.Creator.Name = strings.TrimSpace(ride.DeviceType)
+
+	intervals := ride.Intervals
+	if len(intervals) == 0 && len(ride.Samples) > 0 {
+		last := ride.Samples[len(ride.Samples)-1].Secs
+		intervals = []gcInterval{{Start: ride.Samples[0].Secs, Stop: last + ride.RecIntSecs}}
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start < intervals[j].Start })
+	j := 0
+	for i, iv := range intervals {
+		lap := Lap{
+			StartTime:          start.Add(time.Duration(iv.Start * float64(time.Second))),
+			TotalTimeInSeconds: iv.Stop - iv.Start,
+			Intensity:          "Active",
+			TriggerMethod:      "Manual",
+		}
+		for j < len(ride.Samples) && ride.Samples[j].Secs < iv.Start {
+			j++
+		}
+		from := j
+		for j < len(ride.Samples) && (i == len(intervals)-1 || ride.Samples[j].Secs < intervals[i+1].Start) {
+			s := ride.Samples[j]
+			lap.Track = append(lap.Track, Trackpoint{
+				Time:                start.Add(time.Duration(s.Secs * float64(time.Second))),
+				LatitudeInDegrees:   s.Lat,
+				LongitudeInDegrees:  s.Lon,
+				AltitudeInMeters:    s.Alt,
+				HeartRateInBpm:      int(math.Round(s.HR)),
+				Cadence:             int(math.Round(s.Cad)),
+				SpeedInMetersPerSec: s.KPH / 3.6,
+				PowerInWatts:        int(math.Round(s.Watts)),
+			})
+			lap.MaximumSpeedInMetersPerSec = math.Max(lap.MaximumSpeedInMetersPerSec, s.KPH/3.6)
+			j++
+		}
+		if j > from {
+			end := ride.Samples[j-1].KM
+			if j < len(ride.Samples) {
+				end = ride.Samples[j].KM
+			}
+			lap.DistanceInMeters = (end - ride.Samples[from].KM) * 1000
+		}
+		a.Laps = append(a.Laps, lap)
+	}
+
+	t := NewTcx()
+	t.XMLNs = TrainingCenterNS
+	t.Activities = []Activity{a}
+	return t, nil
+}
+
+// WriteGoldenCheetah writes the activity as a GoldenCheetah JSON ride file,
+// one interval per lap. The cumulative sample distance is scaled within each
+// lap to match its recorded distance.
+func (a *Activity) WriteGoldenCheetah(w io.Writer) error {
+	pts := a.trackpoints()
+	if len(pts) == 0 {
+		return fmt.Errorf("activity %v has no trackpoints", a.ID)
+	}
+	start := a.ID.UTC()
+	if start.IsZero() || pts[0].Time.Before(start) {
+		start = pts[0].Time.UTC()
+	}
+	ride := gcRide{
+		StartTime:  start.Format(gcTimeLayout) + " ",
+		RecIntSecs: 1,
+		DeviceType: a.Creator.Name,
+		Tags:       map[string]string{"Sport": gcSports[a.Sport]},
+	}
+	if ride.Tags["Sport"] == "" {
+		delete(ride.Tags, "Sport")
+	}
+
+	// Recording interval is the most frequent gap between samples.
+	gaps := make(map[float64]int)
+	for i := 1; i < len(pts); i++ {
+		gaps[pts[i].Time.Sub(pts[i-1].Time).Seconds()]++
+	}
+	for g, n := range gaps {
+		if g > 0 && n > gaps[ride.RecIntSecs] {
+			ride.RecIntSecs = g
+		}
+	}
+
+	secs := func(t time.Time) float64 { return t.Sub(start).Seconds() }
+	var km float64
+	for li, l := range a.Laps {
+		ride.Intervals = append(ride.Intervals, gcInterval{
+			Name:  fmt.Sprintf("Lap %d", li+1),
+			Start: secs(l.StartTime),
+			Stop:  secs(l.StartTime) + l.TotalTimeInSeconds,
+		})
+		lapStart := km
+		for k := range l.Track {
+			p := &l.Track[k]
+			if k > 0 {
+				prev := &l.Track[k-1]
+				if p.HasPosition() && prev.HasPosition() {
+					km += distance(prev.LatitudeInDegrees, prev.LongitudeInDegrees, p.LatitudeInDegrees, p.LongitudeInDegrees) / 1000
+				} else {
+					km += p.SpeedInMetersPerSec * p.Time.Sub(prev.Time).Seconds() / 1000
+				}
+			}
+			ride.Samples = append(ride.Samples, gcSample{
+				Secs:  secs(p.Time),
+				KM:    km,
+				Watts: float64(p.PowerInWatts),
+				Cad:   float64(p.Cadence),
+				KPH:   p.SpeedInMetersPerSec * 3.6,
+				HR:    float64(p.HeartRateInBpm),
+				Alt:   p.AltitudeInMeters,
+				Lat:   p.LatitudeInDegrees,
+				Lon:   p.LongitudeInDegrees,
+			})
+		}
+		// Keep the recorded lap distance when the track disagrees with it.
+		if l.DistanceInMeters > 0 && len(l.Track) > 0 {
+			first := len(ride.Samples) - len(l.Track)
+			scale := l.DistanceInMeters / 1000 / math.Max(km-lapStart, 1e-9)
+			for k := first; k < len(ride.Samples); k++ {
+				ride.Samples[k].KM = lapStart + (ride.Samples[k].KM-lapStart)*scale
+			}
+			km = lapStart + l.DistanceInMeters/1000
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	return enc.Encode(gcFile{Ride: ride})
+}
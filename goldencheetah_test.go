@@ -0,0 +1,81 @@
+package tcx
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testGoldenCheetah = `{
+"RIDE":{
+ "STARTTIME":"2016/05/01 06:30:00 UTC ",
+ "RECINTSECS":1,
+ "DEVICETYPE":"Garmin Edge 520 ",
+ "IDENTIFIER":"",
+ "TAGS":{ "Sport":"Bike " },
+ "INTERVALS":[
+  { "NAME":"Lap 1 ", "START": 0, "STOP": 2 },
+  { "NAME":"Lap 2 ", "START": 2, "STOP": 4 }
+ ],
+ "SAMPLES":[
+  { "SECS":0, "KM":0, "WATTS":200, "CAD":90, "KPH":36, "HR":130, "ALT":100 },
+  { "SECS":1, "KM":0.01, "WATTS":210, "CAD":91, "KPH":36, "HR":131, "ALT":100 },
+  { "SECS":2, "KM":0.02, "WATTS":300, "CAD":95, "KPH":36, "HR":135, "ALT":101 },
+  { "SECS":3, "KM":0.03, "WATTS":310, "CAD":96, "KPH":36, "HR":138, "ALT":101 }
+ ]
+}
+}`
+
+func TestParseGoldenCheetah(t *testing.T) {
+	tcx, err := ParseGoldenCheetah(strings.NewReader(testGoldenCheetah))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := tcx.Activities[0]
+	if a.Sport != SportBiking || a.Creator.Name != "Garmin Edge 520" {
+		t.Errorf("got %s activity from %q", a.Sport, a.Creator.Name)
+	}
+	if !a.ID.Equal(time.Date(2016, 5, 1, 6, 30, 0, 0, time.UTC)) || len(a.Laps) != 2 {
+		t.Fatalf("got %d laps starting at %v", len(a.Laps), a.ID)
+	}
+	l := a.Laps[1]
+	if len(l.Track) != 2 || math.Abs(l.DistanceInMeters-10) > 1e-9 || l.Track[1].PowerInWatts != 310 {
+		t.Errorf("second lap = %+v", l)
+	}
+	if l.Track[0].SpeedInMetersPerSec != 10 {
+		t.Errorf("speed = %v, want 10 m/s", l.Track[0].SpeedInMetersPerSec)
+	}
+}
+
+func TestWriteGoldenCheetah(t *testing.T) {
+	orig, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	a := &orig.Activities[0]
+	var buf bytes.Buffer
+	if err := a.WriteGoldenCheetah(&buf); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ParseGoldenCheetah(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &back.Activities[0]
+	if len(b.Laps) != len(a.Laps) || len(b.trackpoints()) != len(a.trackpoints()) {
+		t.Fatalf("round trip changed the structure")
+	}
+	for i := range a.Laps {
+		if d := math.Abs(b.Laps[i].DistanceInMeters - a.Laps[i].DistanceInMeters); d > 0.01 {
+			t.Errorf("lap %d distance off by %v m", i, d)
+		}
+	}
+	pa, pb := a.trackpoints(), b.trackpoints()
+	for i := range pa {
+		if !pa[i].Time.Equal(pb[i].Time) || pa[i].HeartRateInBpm != pb[i].HeartRateInBpm {
+			t.Fatalf("trackpoint %d changed: %+v", i, pb[i])
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package tcx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// GPX namespaces.
+const (
+	GPXNS       = "http://www.topografix.com/GPX/1/1"
+	GPXTPXNS    = "http://www.garmin.com/xmlschemas/TrackPointExtension/v1"
+	gpxSchemaNS = "http://www.topografix.com/GPX/1/1 http://www.topografix.com/GPX/1/1/gpx.xsd"
+)
+
+type gpxDocument struct {
+	XMLName   xml.Name   `xml:"gpx"`
+	XMLNs     string     `xml:"xmlns,attr"`
+	XMLNsTPX  string     `xml:"xmlns:gpxtpx,attr"`
+	XMLNsXsi  string     `xml:"xmlns:xsi,attr"`
+	SchemaLoc string     `xml:"xsi:schemaLocation,attr"`
+	Version   string     `xml:"version,attr"`
+	Creator   string     `xml:"creator,attr"`
+	Time      *time.Time `xml:"metadata>time,omitempty"`
+	Tracks    []gpxTrack `xml:"trk"`
+}
+
+type gpxTrack struct {
+	Name     string       `xml:"name,omitempty"`
+	Type     string       `xml:"type,omitempty"`
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat        float64        `xml:"lat,attr"`
+	Lon        float64        `xml:"lon,attr"`
+	Ele        float64        `xml:"ele,omitempty"`
+	Time       time.Time      `xml:"time"`
+	Extensions *gpxExtensions `xml:"extensions,omitempty"`
+}
+
+type gpxExtensions struct {
+	HR  int `xml:"gpxtpx:TrackPointExtension>gpxtpx:hr,omitempty"`
+	Cad int `xml:"gpxtpx:TrackPointExtension>gpxtpx:cad,omitempty"`
+}
+
+// ToGPX writes the activities of t as a GPX 1.1 document: a track per
+// activity and a segment per lap, with heart rate and cadence in Garmin
+// TrackPointExtension elements. GPX points need a position, so trackpoints
+// without a GPS fix and laps without any are left out.
+func (t *Tcx) ToGPX(w io.Writer) error {
+	doc := gpxDocument{
+		XMLNs:     GPXNS,
+		XMLNsTPX:  GPXTPXNS,
+		XMLNsXsi:  "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLoc: gpxSchemaNS,
+		Version:   "1.1",
+		Creator:   "go-tcx",
+	}
+	for _, a := range t.AllActivities() {
+		trk := gpxTrack{Type: a.Sport}
+		if !a.ID.IsZero() {
+			trk.Name = a.ID.UTC().Format(time.RFC3339)
+			if doc.Time == nil {
+				id := a.ID.UTC()
+				doc.Time = &id
+			}
+		}
+		for _, l := range a.Laps {
+			var seg gpxSegment
+			for _, p := range l.Track {
+				if !p.HasPosition() {
+					continue
+				}
+				pt := gpxPoint{Lat: p.LatitudeInDegrees, Lon: p.LongitudeInDegrees, Ele: p.AltitudeInMeters, Time: p.Time.UTC()}
+				if p.HasHeartRate() || p.HasCadence() {
+					pt.Extensions = &gpxExtensions{HR: p.HeartRateInBpm, Cad: p.Cadence}
+				}
+				seg.Points = append(seg.Points, pt)
+			}
+			if len(seg.Points) > 0 {
+				trk.Segments = append(trk.Segments, seg)
+			}
+		}
+		doc.Tracks = append(doc.Tracks, trk)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// gpxFile is the subset of a GPX document read by FromGPX. Names carry no
+// namespace so that GPX 1.0 files and any extension prefix decode too.
+type gpxFile struct {
+	Creator string `xml:"creator,attr"`
+	Tracks  []struct {
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []struct {
+				Lat  float64   `xml:"lat,attr"`
+				Lon  float64   `xml:"lon,attr"`
+				Ele  float64   `xml:"ele"`
+				Time time.Time `xml:"time"`
+				HR   int       `xml:"extensions>TrackPointExtension>hr"`
+				Cad  int       `xml:"extensions>TrackPointExtension>cad"`
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+// FromGPX reads a GPX document into a Tcx holding one activity, with a lap
+// per track segment. Lap durations, distances and heart rates are computed
+// from the points, and the sport is taken from the type of the first track.
+func FromGPX(r io.Reader) (*Tcx, error) {
+	var f gpxFile
+	if err := xml.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("couldn't parse gpx data: %v", err)
+	}
+	a := Activity{Sport: SportOther, Creator: Creator{Name: f.Creator}}
+	for i, trk := range f.Tracks {
+		if i == 0 && trk.Type != "" {
+			a.Sport = fitlogSport(trk.Type)
+		}
+		for _, seg := range trk.Segments {
+			if len(seg.Points) == 0 {
+				continue
+			}
+			lap := Lap{StartTime: seg.Points[0].Time, Intensity: "Active", TriggerMethod: "Manual"}
+			for _, pt := range seg.Points {
+				lap.Track = append(lap.Track, Trackpoint{
+					Time:               pt.Time,
+					LatitudeInDegrees:  pt.Lat,
+					LongitudeInDegrees: pt.Lon,
+					AltitudeInMeters:   pt.Ele,
+					HeartRateInBpm:     pt.HR,
+					Cadence:            pt.Cad,
+				})
+			}
+			lap.update()
+			lap.MaximumSpeedInMetersPerSec = maxSegmentSpeed(lap.Track)
+			a.Laps = append(a.Laps, lap)
+		}
+	}
+	if len(a.Laps) > 0 {
+		a.ID = a.Laps[0].StartTime
+	}
+	t := NewTcx()
+	t.XMLNs = TrainingCenterNS
+	t.Activities = []Activity{a}
+	return t, nil
+}
+
+// maxSegmentSpeed returns the highest speed between consecutive GPS fixes
+// of a track, in m/s.
+func maxSegmentSpeed(track []Trackpoint) float64 {
+	var v float64
+	for i := 1; i < len(track); i++ {
+		p, q := &track[i-1], &track[i]
+		dt := q.Time.Sub(p.Time).Seconds()
+		if dt <= 0 || !p.HasPosition() || !q.HasPosition() {
+			continue
+		}
+		v = max(v, distance(p.LatitudeInDegrees, p.LongitudeInDegrees, q.LatitudeInDegrees, q.LongitudeInDegrees)/dt)
+	}
+	return v
+}
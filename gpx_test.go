@@ -0,0 +1,100 @@
+package tcx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestToGPX(t *testing.T) {
+	db, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := db.ToGPX(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if !strings.Contains(out, `xmlns="http://www.topografix.com/GPX/1/1"`) || !strings.Contains(out, `version="1.1"`) {
+		t.Errorf("missing GPX root attributes:\n%.400s", out)
+	}
+
+	var doc struct {
+		Tracks []struct {
+			Type     string `xml:"type"`
+			Segments []struct {
+				Points []struct {
+					Lat float64 `xml:"lat,attr"`
+					HR  int     `xml:"extensions>TrackPointExtension>hr"`
+					Cad int     `xml:"extensions>TrackPointExtension>cad"`
+				} `xml:"trkpt"`
+			} `xml:"trkseg"`
+		} `xml:"trk"`
+	}
+	if err := xml.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Tracks) != 1 || doc.Tracks[0].Type != SportRunning {
+		t.Fatalf("tracks = %+v", doc.Tracks)
+	}
+	// Three laps of test1.tcx are empty.
+	segs := doc.Tracks[0].Segments
+	if len(segs) != 22 {
+		t.Errorf("%d segments, want 22", len(segs))
+	}
+	var n int
+	want := db.Activities[0].Laps[2].Track[0]
+	for _, s := range segs {
+		n += len(s.Points)
+	}
+	if n != 1937 {
+		t.Errorf("%d points, want 1937", n)
+	}
+	if p := segs[0].Points[0]; p.Lat != want.LatitudeInDegrees || p.HR != want.HeartRateInBpm || p.Cad != want.Cadence {
+		t.Errorf("first point = %+v, want %+v", p, want)
+	}
+}
+
+func TestFromGPX(t *testing.T) {
+	orig, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := orig.ToGPX(&b); err != nil {
+		t.Fatal(err)
+	}
+	db, err := FromGPX(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Activities) != 1 {
+		t.Fatalf("%d activities, want 1", len(db.Activities))
+	}
+	a, want := &db.Activities[0], &orig.Activities[0]
+	if a.Sport != SportRunning || len(a.Laps) != 22 || len(a.trackpoints()) != 1937 {
+		t.Fatalf("sport %q, %d laps, %d trackpoints", a.Sport, len(a.Laps), len(a.trackpoints()))
+	}
+	if !a.ID.Equal(want.Laps[2].Track[0].Time) || a.Creator.Name != "go-tcx" {
+		t.Errorf("id %v, creator %q", a.ID, a.Creator.Name)
+	}
+	if d := a.TotalDistance(); math.Abs(d-want.TotalDistance())/want.TotalDistance() > 0.02 {
+		t.Errorf("TotalDistance() = %v, want about %v", d, want.TotalDistance())
+	}
+	l := a.Laps[0]
+	if l.AverageHeartRateInBpm == 0 || l.MaximumHeartRateInBpm < l.AverageHeartRateInBpm || l.MaximumSpeedInMetersPerSec == 0 {
+		t.Errorf("lap totals = %+v", l)
+	}
+	hr, _ := a.AverageHeartbeat()
+	wanthr, _ := want.AverageHeartbeat()
+	if d := math.Abs(hr - wanthr); d > 1e-9 {
+		t.Errorf("conversion changed average heart rate by %v", d)
+	}
+
+	if _, err := FromGPX(strings.NewReader("<gpx><trk>")); err == nil {
+		t.Error("expected an error on truncated GPX")
+	}
+}
@@ -0,0 +1,77 @@
+package tcx
+
+import "math"
+
+// Grades returns the grade, in percent, at each trackpoint of the activity,
+// measured back over at least 20 m of track to keep altitude noise in
+// check. Grades are zero until 20 m have been covered.
+func (a *Activity) Grades() []float64 {
+	ivs := a.intervals()
+	if len(ivs) == 0 {
+		return make([]float64, len(a.trackpoints()))
+	}
+	grades := make([]float64, len(ivs)+1)
+	for i, iv := range ivs {
+		grades[i+1] = iv.grade
+	}
+	return grades
+}
+
+// AverageGrade returns the distance weighted average grade of the activity,
+// in percent.
+func (a *Activity) AverageGrade() float64 {
+	var sum, dist float64
+	for _, iv := range a.intervals() {
+		sum += iv.grade * iv.dist
+		dist += iv.dist
+	}
+	if dist == 0 {
+		return 0
+	}
+	return sum / dist
+}
+
+// MaxGrade returns the steepest uphill grade of the activity, in percent.
+func (a *Activity) MaxGrade() float64 {
+	var steepest float64
+	for _, iv := range a.intervals() {
+		steepest = math.Max(steepest, iv.grade)
+	}
+	return steepest
+}
+
+// CostModel returns the energy cost of running at a grade, in percent,
+// relative to running on the flat.
+type CostModel func(grade float64) float64
+
+// MinettiCost is the cost of running measured by Minetti et al. (2002),
+// valid for grades between -45% and 45%; steeper grades are clamped.
+func MinettiCost(grade float64) float64 {
+	return minettiCost(grade/100) / minettiCost(0)
+}
+
+// DefaultCostModel is the cost model of GradeAdjustedPace when none is
+// given.
+var DefaultCostModel CostModel = MinettiCost
+
+// GradeAdjustedPace returns the grade adjusted pace of the activity: the
+// pace on flat ground for the same effort, with each stretch of track
+// weighted by the cost of its grade under m. Stops are excluded. A nil m
+// uses DefaultCostModel.
+func (a *Activity) GradeAdjustedPace(m CostModel) Pace {
+	if m == nil {
+		m = DefaultCostModel
+	}
+	var flat, moving float64
+	for _, iv := range a.intervals() {
+		if iv.dist <= 0 || iv.dt <= 0 {
+			continue
+		}
+		flat += iv.dist * m(iv.grade)
+		moving += iv.dt
+	}
+	if moving == 0 {
+		return 0
+	}
+	return PaceFromSpeed(flat / moving)
+}
@@ -0,0 +1,46 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGrades(t *testing.T) {
+	// 4 m/s for 100 s on the flat, then 100 s climbing 0.4 m a second: a
+	// 10% grade.
+	a := testActivity(SportRunning, repeat(4.0, 201), nil)
+	track := a.Laps[0].Track
+	for i := 101; i < len(track); i++ {
+		track[i].SetAltitude(10 + float64(i-100)*0.4)
+	}
+	grades := a.Grades()
+	if len(grades) != 201 || grades[50] != 0 || math.Abs(grades[150]-10) > 0.01 {
+		t.Errorf("grades = %d values, %v at 150", len(grades), grades[150])
+	}
+	if g := a.MaxGrade(); math.Abs(g-10) > 0.01 {
+		t.Errorf("MaxGrade() = %v, want 10", g)
+	}
+	if g := a.AverageGrade(); g < 4.5 || g > 5.5 {
+		t.Errorf("AverageGrade() = %v, want about 5", g)
+	}
+	// An altitude dropout is not a plunge to sea level.
+	track[160].ClearAltitude()
+	if g := a.MaxGrade(); math.Abs(g-10) > 0.01 {
+		t.Errorf("MaxGrade() with a dropout = %v, want 10", g)
+	}
+
+	if c := MinettiCost(0); c != 1 {
+		t.Errorf("MinettiCost(0) = %v, want 1", c)
+	}
+	if MinettiCost(10) <= 1 || MinettiCost(-10) >= 1 {
+		t.Errorf("MinettiCost(10) = %v, MinettiCost(-10) = %v", MinettiCost(10), MinettiCost(-10))
+	}
+	gap, pace := a.GradeAdjustedPace(nil), PaceFromSpeed(4)
+	if gap >= pace {
+		t.Errorf("GradeAdjustedPace() = %v, not faster than %v uphill", gap, pace)
+	}
+	flat := func(float64) float64 { return 1 }
+	if gap := a.GradeAdjustedPace(flat); math.Abs(gap.Speed()-4) > 0.01 {
+		t.Errorf("GradeAdjustedPace(flat) = %v, want %v", gap, pace)
+	}
+}
@@ -0,0 +1,60 @@
+package tcx
+
+import (
+	"encoding/xml"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// heartRateIn is a heart rate element as read. Besides the schema's
+// <Value> child, writers emit the value as the element's text or as a
+// Value attribute, sometimes with decimals; all of these are accepted.
+// bpm is meaningful only when ok.
+type heartRateIn struct {
+	bpm int
+	ok  bool
+}
+
+// UnmarshalXML reads a heart rate element, leaving it unset when it holds
+// no number.
+func (h *heartRateIn) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v struct {
+		Attr  string `xml:"Value,attr"`
+		Value string `xml:"Value"`
+		Text  string `xml:",chardata"`
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*h = heartRateIn{}
+	for _, s := range []string{v.Value, v.Attr, v.Text} {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+			*h = heartRateIn{bpm: int(math.Round(f)), ok: true}
+			return nil
+		}
+	}
+	return nil
+}
+
+// lapAlias has the fields of Lap without its methods.
+type lapAlias Lap
+
+// lapIn is a Lap as read, with heart rates in any of their variants.
+type lapIn struct {
+	lapAlias
+	AverageHeartRate heartRateIn `xml:"AverageHeartRateBpm"`
+	MaximumHeartRate heartRateIn `xml:"MaximumHeartRateBpm"`
+}
+
+// UnmarshalXML reads a lap, tolerating the heart rate variants of
+// heartRateIn.
+func (l *Lap) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v lapIn
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*l = Lap(v.lapAlias)
+	l.AverageHeartRateInBpm, l.MaximumHeartRateInBpm = v.AverageHeartRate.bpm, v.MaximumHeartRate.bpm
+	return nil
+}
@@ -0,0 +1,39 @@
+package tcx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeartRateVariants(t *testing.T) {
+	db, err := Parse(strings.NewReader(`<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+<Activities><Activity Sport="Running"><Id>2015-04-12T07:00:00Z</Id>
+<Lap StartTime="2015-04-12T07:00:00Z">
+<TotalTimeSeconds>5</TotalTimeSeconds><DistanceMeters>20</DistanceMeters>
+<AverageHeartRateBpm xsi:type="HeartRateInBeatsPerMinute_t"><Value>141</Value></AverageHeartRateBpm>
+<MaximumHeartRateBpm>150.4</MaximumHeartRateBpm>
+<Track>
+<Trackpoint><Time>2015-04-12T07:00:00Z</Time><HeartRateBpm xsi:type="HeartRateInBeatsPerMinute_t"><Value>140</Value></HeartRateBpm></Trackpoint>
+<Trackpoint><Time>2015-04-12T07:00:01Z</Time><HeartRateBpm> 142 </HeartRateBpm></Trackpoint>
+<Trackpoint><Time>2015-04-12T07:00:02Z</Time><HeartRateBpm Value="144"/></Trackpoint>
+<Trackpoint><Time>2015-04-12T07:00:03Z</Time><HeartRateBpm><Value>145.6</Value></HeartRateBpm></Trackpoint>
+<Trackpoint><Time>2015-04-12T07:00:04Z</Time><HeartRateBpm><Value>0</Value></HeartRateBpm></Trackpoint>
+<Trackpoint><Time>2015-04-12T07:00:05Z</Time><HeartRateBpm/></Trackpoint>
+</Track></Lap></Activity></Activities></TrainingCenterDatabase>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := db.Activities[0].Laps[0]
+	if l.AverageHeartRateInBpm != 141 || l.MaximumHeartRateInBpm != 150 || l.DistanceInMeters != 20 {
+		t.Errorf("lap heart rates = %d, %d", l.AverageHeartRateInBpm, l.MaximumHeartRateInBpm)
+	}
+	for i, want := range []struct {
+		bpm int
+		ok  bool
+	}{{140, true}, {142, true}, {144, true}, {146, true}, {0, true}, {0, false}} {
+		p := &l.Track[i]
+		if p.HeartRateInBpm != want.bpm || p.HasHeartRate() != want.ok {
+			t.Errorf("trackpoint %d: heart rate = %d, %v, want %d, %v", i, p.HeartRateInBpm, p.HasHeartRate(), want.bpm, want.ok)
+		}
+	}
+}
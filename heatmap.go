@@ -0,0 +1,105 @@
+package tcx
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"math"
+	"slices"
+)
+
+// HeatCell is a cell of a heatmap, bounded by the given latitudes and
+// longitudes in degrees.
+type HeatCell struct {
+	South, West, North, East float64
+	// Points counts the trackpoints in the cell and Activities the
+	// activities having at least one.
+	Points, Activities int
+}
+
+// Heatmap counts the visits of a collection to the cells of a geographic
+// grid.
+type Heatmap struct {
+	// CellSize is the side of the cells in meters.
+	CellSize float64
+	// Cells lists the visited cells, from south to north and west to east.
+	Cells []HeatCell
+	// MaxPoints and MaxActivities are the largest counts of a cell, to scale
+	// colors.
+	MaxPoints, MaxActivities int
+}
+
+// Heatmap bins the GPS fixes of all the activities of the collection into a
+// grid of cells about cellSize meters wide: rows of constant latitude,
+// each split in cells of constant longitude sized for the middle of the
+// row, so that the grid is the same across activities and nearly square
+// everywhere but at the poles.
+func (c *Collection) Heatmap(cellSize float64) *Heatmap {
+	h := &Heatmap{CellSize: cellSize}
+	if cellSize <= 0 {
+		return h
+	}
+	dLat := cellSize / (earthRadius * math.Pi / 180)
+	cells := make(map[[2]int64]*HeatCell)
+	for _, a := range c.Activities {
+		visited := make(map[*HeatCell]bool)
+		for _, p := range a.fixes() {
+			row := int64(math.Floor(p.LatitudeInDegrees / dLat))
+			south := float64(row) * dLat
+			dLon := dLat / math.Max(math.Cos(deg2rad(south+dLat/2)), 1e-6)
+			col := int64(math.Floor(p.LongitudeInDegrees / dLon))
+			cell := cells[[2]int64{row, col}]
+			if cell == nil {
+				west := float64(col) * dLon
+				cell = &HeatCell{South: south, West: west, North: south + dLat, East: west + dLon}
+				cells[[2]int64{row, col}] = cell
+			}
+			cell.Points++
+			if !visited[cell] {
+				visited[cell] = true
+				cell.Activities++
+			}
+		}
+	}
+	for _, cell := range cells {
+		h.Cells = append(h.Cells, *cell)
+		h.MaxPoints = max(h.MaxPoints, cell.Points)
+		h.MaxActivities = max(h.MaxActivities, cell.Activities)
+	}
+	slices.SortFunc(h.Cells, func(a, b HeatCell) int {
+		return cmp.Or(cmp.Compare(a.South, b.South), cmp.Compare(a.West, b.West))
+	})
+	return h
+}
+
+type heatmapFeature struct {
+	Type     string `json:"type"`
+	Geometry struct {
+		Type        string         `json:"type"`
+		Coordinates [][][2]float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties struct {
+		Points     int `json:"points"`
+		Activities int `json:"activities"`
+	} `json:"properties"`
+}
+
+// WriteGeoJSON writes the heatmap as a GeoJSON FeatureCollection holding a
+// Polygon feature per cell, with its counts in its properties.
+func (h *Heatmap) WriteGeoJSON(w io.Writer) error {
+	fc := struct {
+		Type     string           `json:"type"`
+		Features []heatmapFeature `json:"features"`
+	}{Type: "FeatureCollection", Features: []heatmapFeature{}}
+	for _, c := range h.Cells {
+		f := heatmapFeature{Type: "Feature"}
+		f.Geometry.Type = "Polygon"
+		f.Geometry.Coordinates = [][][2]float64{{
+			{c.West, c.South}, {c.East, c.South}, {c.East, c.North}, {c.West, c.North}, {c.West, c.South},
+		}}
+		f.Properties.Points = c.Points
+		f.Properties.Activities = c.Activities
+		fc.Features = append(fc.Features, f)
+	}
+	return json.NewEncoder(w).Encode(fc)
+}
@@ -0,0 +1,59 @@
+package tcx
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestHeatmap(t *testing.T) {
+	// Two runs 1 km north on the same line.
+	a, b := testActivity(SportRunning, repeat(4.0, 251), nil), testActivity(SportRunning, repeat(4.0, 251), nil)
+	b.ShiftTime(86400e9)
+	h := NewCollection([]*Activity{a, b}).Heatmap(100)
+	if n := len(h.Cells); n < 10 || n > 12 {
+		t.Fatalf("%d cells, want about 10", n)
+	}
+	points := 0
+	for i, c := range h.Cells {
+		points += c.Points
+		if c.Activities != 2 || (i > 0 && c.South <= h.Cells[i-1].South) {
+			t.Errorf("cell %d = %+v", i, c)
+		}
+		if h := distance(c.South, c.West, c.North, c.West); math.Abs(h-100) > 0.1 {
+			t.Errorf("cell height = %v", h)
+		}
+		if w := distance(c.South, c.West, c.South, c.East); math.Abs(w-100) > 1 {
+			t.Errorf("cell width = %v", w)
+		}
+		if lat := a.Laps[0].Track[0].LatitudeInDegrees; i == 0 && (lat < c.South || lat >= c.North) {
+			t.Errorf("first cell %+v misses the start at %v", c, lat)
+		}
+	}
+	if points != 502 || h.MaxActivities != 2 {
+		t.Errorf("%d points binned, max activities %d", points, h.MaxActivities)
+	}
+
+	var buf bytes.Buffer
+	if err := h.WriteGeoJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var fc struct {
+		Features []struct {
+			Geometry struct {
+				Coordinates [][][]float64
+			}
+			Properties struct{ Points, Activities int }
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &fc); err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.Features) != len(h.Cells) || len(fc.Features[0].Geometry.Coordinates[0]) != 5 || fc.Features[0].Properties.Activities != 2 {
+		t.Errorf("GeoJSON = %s", buf.Bytes())
+	}
+	if len(NewCollection(nil).Heatmap(100).Cells) != 0 {
+		t.Error("heatmap of an empty collection")
+	}
+}
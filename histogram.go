@@ -0,0 +1,66 @@
+package tcx
+
+import (
+	"math"
+	"time"
+)
+
+// Bin is one bucket of a histogram, covering metric values in [Min, Max).
+type Bin struct {
+	Min      float64
+	Max      float64
+	Time     time.Duration
+	Distance float64 // meters
+}
+
+// maxHistogramBins caps the bins of a histogram, which a spike in the metric
+// or a tiny bin width would otherwise blow up.
+const maxHistogramBins = 10000
+
+// Histogram returns the distribution of metric over the activity in bins of
+// binWidth, weighted both by time and by distance. Bins are contiguous from
+// the lowest to the highest recorded value; spans where the metric was not
+// recorded are left out. When the range would need more than 10000 bins,
+// binWidth is multiplied by the smallest integer bringing them under.
+func Histogram(a *Activity, metric Metric, binWidth float64) []Bin {
+	if binWidth <= 0 {
+		return nil
+	}
+	ivs := a.intervals()
+	var values []float64
+	var kept []interval
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, iv := range ivs {
+		v, ok := metric.value(iv)
+		if !ok || iv.dt == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		values = append(values, v)
+		kept = append(kept, iv)
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	nbins := func(w float64) float64 { return math.Floor(hi/w) - math.Floor(lo/w) + 1 }
+	if n := nbins(binWidth); n > maxHistogramBins {
+		k := math.Ceil(n / maxHistogramBins)
+		for nbins(binWidth*k) > maxHistogramBins {
+			k++
+		}
+		binWidth *= k
+	}
+	first := math.Floor(lo / binWidth)
+	bins := make([]Bin, int(math.Floor(hi/binWidth)-first)+1)
+	for i := range bins {
+		bins[i].Min = (first + float64(i)) * binWidth
+		bins[i].Max = bins[i].Min + binWidth
+	}
+	for i, v := range values {
+		b := &bins[int(math.Floor(v/binWidth)-first)]
+		b.Time += time.Duration(kept[i].dt * float64(time.Second))
+		b.Distance += kept[i].dist
+	}
+	return bins
+}
@@ -0,0 +1,46 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	a := testActivity("Running", repeat(3.0, 301), append(repeat(135, 100), repeat(155, 201)...))
+
+	bins := Histogram(a, MetricHeartRate, 10)
+	if len(bins) != 3 {
+		t.Fatalf("got %d bins, want 3", len(bins))
+	}
+	if bins[0].Min != 130 || bins[0].Time != 100*time.Second || bins[1].Time != 0 || bins[2].Time != 200*time.Second {
+		t.Errorf("Histogram() = %+v", bins)
+	}
+	if math.Abs(bins[2].Distance-600) > 0.5 {
+		t.Errorf("distance in top bin = %v, want 600", bins[2].Distance)
+	}
+
+	// A steady 5% climb.
+	for i := range a.Laps[0].Track {
+		a.Laps[0].Track[i].AltitudeInMeters = 0.15 * float64(i)
+	}
+	grades := Histogram(a, MetricGrade, 1)
+	var top Bin
+	for _, b := range grades {
+		if b.Time > top.Time {
+			top = b
+		}
+	}
+	if top.Min != 5 && top.Max != 5 {
+		t.Errorf("most common grade bin = %+v, want around 5%%", top)
+	}
+	if Histogram(a, MetricCadence, 5) != nil {
+		t.Error("expected no cadence histogram without cadence data")
+	}
+
+	// A sensor spike does not allocate billions of bins.
+	a.Laps[0].Track[50].HeartRateInBpm = 1 << 30
+	if bins := Histogram(a, MetricHeartRate, 1); len(bins) > maxHistogramBins || bins[0].Min != 0 {
+		t.Errorf("Histogram() with a spike gave %d bins from %v", len(bins), bins[0].Min)
+	}
+}
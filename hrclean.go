@@ -0,0 +1,111 @@
+package tcx
+
+import (
+	"math"
+	"time"
+)
+
+// HeartRateCleanOptions configures heart rate artifact correction.
+type HeartRateCleanOptions struct {
+	// Window is the number of samples either side of a sample used for its
+	// median reference.
+	Window int
+	// MaxRate is the fastest physiological change of heart rate, in bpm
+	// per second.
+	MaxRate float64
+	// MaxDeviation is the distance from the median reference, in bpm,
+	// beyond which a sample changing faster than MaxRate is an artifact.
+	MaxDeviation float64
+	// Min and Max bound plausible heart rates.
+	Min, Max int
+	// MaxGap is the longest strap dropout filled by interpolation.
+	MaxGap time.Duration
+}
+
+// DefaultHeartRateCleanOptions suits chest straps recording every second or
+// with smart recording.
+var DefaultHeartRateCleanOptions = HeartRateCleanOptions{
+	Window:       5,
+	MaxRate:      5,
+	MaxDeviation: 15,
+	Min:          30,
+	Max:          230,
+	MaxGap:       30 * time.Second,
+}
+
+// HeartRateCleanup reports the samples changed by CleanHeartRate.
+type HeartRateCleanup struct {
+	// Spikes is the number of samples replaced by their median reference,
+	// such as doubled readings from muscle (EMG) interference.
+	Spikes int
+	// Dropouts is the number of missing samples filled in.
+	Dropouts int
+}
+
+// CleanHeartRate repairs strap artifacts in the heart rate of the activity:
+// samples out of the plausible range, or jumping away from the median of
+// their neighbours faster than the heart can, are replaced by that median,
+// and short dropouts to zero between two readings are interpolated. Run it
+// before computing heart rate based metrics.
+func (a *Activity) CleanHeartRate(opts HeartRateCleanOptions) HeartRateCleanup {
+	a.Invalidate()
+	var c HeartRateCleanup
+	var pts []*Trackpoint
+	for _, p := range a.trackpoints() {
+		if p.HeartRateInBpm > 0 {
+			pts = append(pts, p)
+		}
+	}
+	if len(pts) == 0 {
+		return c
+	}
+
+	raw := make([]float64, len(pts))
+	for i, p := range pts {
+		raw[i] = float64(p.HeartRateInBpm)
+	}
+	plausible := func(v float64) bool { return v >= float64(opts.Min) && v <= float64(opts.Max) }
+	reference := func(i int) float64 {
+		var window []float64
+		for j := max(0, i-opts.Window); j <= min(len(raw)-1, i+opts.Window); j++ {
+			if plausible(raw[j]) {
+				window = append(window, raw[j])
+			}
+		}
+		return median(window)
+	}
+	prev, prevTime := math.NaN(), time.Time{}
+	for i, p := range pts {
+		v := raw[i]
+		ref := reference(i)
+		artifact := !plausible(v)
+		if !artifact && !math.IsNaN(prev) && math.Abs(v-ref) > opts.MaxDeviation {
+			dt := p.Time.Sub(prevTime).Seconds()
+			artifact = dt <= 0 || math.Abs(v-prev)/dt > opts.MaxRate
+		}
+		if artifact && ref > 0 {
+			v = ref
+			p.HeartRateInBpm = int(math.Round(ref))
+			c.Spikes++
+		}
+		prev, prevTime = v, p.Time
+	}
+
+	all := a.trackpoints()
+	last := -1
+	for i, p := range all {
+		if p.HeartRateInBpm == 0 {
+			continue
+		}
+		if last >= 0 && i-last > 1 && p.Time.Sub(all[last].Time) <= opts.MaxGap {
+			from, span := all[last], p.Time.Sub(all[last].Time).Seconds()
+			for _, q := range all[last+1 : i] {
+				f := q.Time.Sub(from.Time).Seconds() / span
+				q.HeartRateInBpm = int(math.Round(float64(from.HeartRateInBpm)*(1-f) + float64(p.HeartRateInBpm)*f))
+				c.Dropouts++
+			}
+		}
+		last = i
+	}
+	return c
+}
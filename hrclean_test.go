@@ -0,0 +1,36 @@
+package tcx
+
+import "testing"
+
+func TestCleanHeartRate(t *testing.T) {
+	hrs := repeat(140, 120)
+	hrs[10] = 280 // doubled reading
+	hrs[40] = 72  // halved reading
+	hrs[41] = 70
+	for i := 60; i < 70; i++ {
+		hrs[i] = 0 // strap dropout
+	}
+	for i := 80; i < 120; i++ {
+		hrs[i] = 140 + (i-80)/2 // a genuine climb
+	}
+	a := testActivity(SportRunning, repeat(3.0, 120), hrs)
+	a.Laps[0].Track[0].HeartRateInBpm = 0
+
+	c := a.CleanHeartRate(DefaultHeartRateCleanOptions)
+	if c.Spikes != 3 || c.Dropouts != 10 {
+		t.Errorf("cleanup = %+v, want 3 spikes and 10 dropouts", c)
+	}
+	for i, p := range a.Laps[0].Track[1:80] {
+		if p.HeartRateInBpm != 140 {
+			t.Errorf("heart rate %d = %d, want 140", i+1, p.HeartRateInBpm)
+		}
+	}
+	for i := 80; i < 120; i++ {
+		if got := a.Laps[0].Track[i].HeartRateInBpm; got != hrs[i] {
+			t.Errorf("heart rate %d changed to %d, want %d", i, got, hrs[i])
+		}
+	}
+	if a.Laps[0].Track[0].HeartRateInBpm != 0 {
+		t.Error("missing leading heart rate was filled")
+	}
+}
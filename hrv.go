@@ -0,0 +1,112 @@
+package tcx
+
+import (
+	"math"
+	"time"
+)
+
+// R-R intervals are stored in Trackpoint.RRIntervals, in milliseconds, as
+// the beats ending between the previous trackpoint and this one. The TCX
+// schema has no element for them; they are read from and written to RR
+// elements of an HRV extension of trackpoints, in the HRVNS namespace.
+
+// HRVNS is the namespace of the HRV element written in trackpoint
+// extensions.
+const HRVNS = "https://github.com/rdifrango/go-tcx/hrv/v1"
+
+// Bounds of plausible R-R intervals, in milliseconds, and the largest
+// departure from the local median not treated as an ectopic beat or
+// artifact. The median is taken over rrMedianSpan plausible beats centred on
+// the beat tested.
+const (
+	minRR        = 300
+	maxRR        = 2000
+	maxRRChange  = 0.2
+	rrMedianSpan = 5
+)
+
+// HRV holds heart rate variability metrics over a window.
+type HRV struct {
+	// Beats is the number of R-R intervals kept after artifact removal.
+	Beats int
+	// Rejected is the number of intervals dropped as artifacts.
+	Rejected int
+	// MeanRR, SDNN and RMSSD are in milliseconds.
+	MeanRR float64
+	SDNN   float64
+	RMSSD  float64
+}
+
+// RRIntervals returns the R-R intervals recorded by trackpoints timed within
+// [from, to). Zero times leave the window open on that side.
+func (a *Activity) RRIntervals(from, to time.Time) []time.Duration {
+	var rr []time.Duration
+	for _, p := range a.trackpoints() {
+		if (!from.IsZero() && p.Time.Before(from)) || (!to.IsZero() && !p.Time.Before(to)) {
+			continue
+		}
+		for _, ms := range p.RRIntervals {
+			rr = append(rr, time.Duration(ms)*time.Millisecond)
+		}
+	}
+	return rr
+}
+
+// HRV computes SDNN and RMSSD over the R-R intervals recorded within
+// [from, to), such as a morning readiness test or a recovery window. Beats
+// out of the physiological range or differing by more than 20% from the
+// median of their neighbours are rejected first, and RMSSD only differences
+// beats that were both kept and adjacent. ok is false with fewer than two
+// beats.
+func (a *Activity) HRV(from, to time.Time) (h HRV, ok bool) {
+	// Index the plausible beats so the local median is not skewed by beats
+	// out of range.
+	var rr []float64
+	var pos []int
+	for i, d := range a.RRIntervals(from, to) {
+		ms := float64(d) / float64(time.Millisecond)
+		if ms < minRR || ms > maxRR {
+			h.Rejected++
+			continue
+		}
+		rr = append(rr, ms)
+		pos = append(pos, i)
+	}
+
+	var beats []float64
+	var sq float64
+	diffs, last := 0, -2
+	for i, ms := range rr {
+		lo, hi := max(0, i-rrMedianSpan/2), min(len(rr), i+rrMedianSpan/2+1)
+		if m := median(append([]float64(nil), rr[lo:hi]...)); math.Abs(ms-m) > maxRRChange*m {
+			h.Rejected++
+			continue
+		}
+		if pos[i] == last+1 {
+			d := ms - beats[len(beats)-1]
+			sq += d * d
+			diffs++
+		}
+		beats = append(beats, ms)
+		last = pos[i]
+	}
+	h.Beats = len(beats)
+	if h.Beats < 2 {
+		return h, false
+	}
+
+	var sum float64
+	for _, b := range beats {
+		sum += b
+	}
+	h.MeanRR = sum / float64(h.Beats)
+	var dev float64
+	for _, b := range beats {
+		dev += (b - h.MeanRR) * (b - h.MeanRR)
+	}
+	h.SDNN = math.Sqrt(dev / float64(h.Beats-1))
+	if diffs > 0 {
+		h.RMSSD = math.Sqrt(sq / float64(diffs))
+	}
+	return h, true
+}
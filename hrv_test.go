@@ -0,0 +1,81 @@
+package tcx
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHRV(t *testing.T) {
+	const doc = `<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+    xmlns:ns3="http://www.garmin.com/xmlschemas/ActivityExtension/v2">
+  <Activities><Activity Sport="Running"><Id>2015-04-12T07:00:00Z</Id>
+    <Lap StartTime="2015-04-12T07:00:00Z"><Track>
+      <Trackpoint><Time>2015-04-12T07:00:05Z</Time><HeartRateBpm><Value>59</Value></HeartRateBpm>
+        <Extensions><ns3:TPX><ns3:RR>1000</ns3:RR><ns3:RR>1040</ns3:RR><ns3:RR>1000</ns3:RR></ns3:TPX></Extensions></Trackpoint>
+      <Trackpoint><Time>2015-04-12T07:00:10Z</Time><HeartRateBpm><Value>59</Value></HeartRateBpm>
+        <Extensions><ns3:TPX><ns3:RR>1040</ns3:RR><ns3:RR>3000</ns3:RR><ns3:RR>1000</ns3:RR><ns3:RR>600</ns3:RR><ns3:RR>1400</ns3:RR></ns3:TPX></Extensions></Trackpoint>
+      <Trackpoint><Time>2015-04-12T07:00:15Z</Time><HeartRateBpm><Value>59</Value></HeartRateBpm>
+        <Extensions><ns3:TPX><ns3:RR>1040</ns3:RR><ns3:RR>1000</ns3:RR><ns3:RR>1040</ns3:RR><ns3:RR>1000</ns3:RR><ns3:RR>1040</ns3:RR></ns3:TPX></Extensions></Trackpoint>
+    </Track></Lap>
+  </Activity></Activities>
+</TrainingCenterDatabase>`
+	db, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &db.Activities[0]
+	if rr := a.RRIntervals(time.Time{}, time.Time{}); len(rr) != 13 || rr[1] != 1040*time.Millisecond {
+		t.Fatalf("R-R intervals = %v", rr)
+	}
+
+	h, ok := a.HRV(time.Time{}, time.Time{})
+	if !ok || h.Beats != 10 || h.Rejected != 3 {
+		t.Fatalf("HRV = %+v, %v", h, ok)
+	}
+	if math.Abs(h.RMSSD-40) > 1e-9 || math.Abs(h.SDNN-math.Sqrt(4000.0/9)) > 1e-9 || h.MeanRR != 1020 {
+		t.Errorf("HRV = %+v", h)
+	}
+
+	until := time.Date(2015, 4, 12, 7, 0, 6, 0, time.UTC)
+	if h, ok := a.HRV(time.Time{}, until); !ok || h.Beats != 3 {
+		t.Errorf("HRV over the first trackpoint = %+v, %v", h, ok)
+	}
+}
+
+func TestHRVArtifacts(t *testing.T) {
+	a := testActivity("Running", repeat(0.0, 2), nil)
+	// A leading ectopic beat must not lock out the beats after it, and the
+	// beats either side of the dropped 200 ms artifact are not successive.
+	a.Laps[0].Track[1].RRIntervals = []int{600, 1000, 1100, 1000, 1100, 200, 1100, 1000, 1100}
+	h, ok := a.HRV(time.Time{}, time.Time{})
+	if !ok || h.Beats != 7 || h.Rejected != 2 {
+		t.Fatalf("HRV = %+v, %v", h, ok)
+	}
+	if math.Abs(h.RMSSD-100) > 1e-9 {
+		t.Errorf("RMSSD = %v, want 100", h.RMSSD)
+	}
+}
+
+func TestHRVRoundTrip(t *testing.T) {
+	a := testActivity("Running", repeat(0.0, 2), nil)
+	a.Laps[0].Track[1].RRIntervals = []int{1000, 1040}
+	var buf bytes.Buffer
+	db := &Tcx{Activities: []Activity{*a}}
+	if err := db.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); strings.Contains(s, "TPX") || !strings.Contains(s, HRVNS) {
+		t.Errorf("R-R intervals not written in their own namespace:\n%s", s)
+	}
+	db, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := db.Activities[0].Laps[0].Track[1]
+	if len(p.RRIntervals) != 2 || p.RRIntervals[1] != 1040 || len(p.OtherExtensions) != 0 {
+		t.Errorf("trackpoint = %+v", p)
+	}
+}
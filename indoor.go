@@ -0,0 +1,80 @@
+package tcx
+
+import "math"
+
+// indoorSpread is the distance, in meters, within which the fixes of an
+// indoor activity stay, such as those of a watch left to search for
+// satellites by a treadmill.
+const indoorSpread = 50.0
+
+// speedDistances returns the distance in meters covered at each point of
+// track, integrating the speed recorded by a foot pod or a wheel sensor.
+func speedDistances(track []*Trackpoint) []float64 {
+	d := make([]float64, len(track))
+	for i := 1; i < len(track); i++ {
+		d[i] = d[i-1] + track[i-1].SpeedInMetersPerSec*math.Max(0, track[i].Time.Sub(track[i-1].Time).Seconds())
+	}
+	return d
+}
+
+// IsIndoor reports whether the activity was recorded without GPS, as on a
+// treadmill or a trainer: none of its trackpoints has a fix other than
+// 0°N 0°E, or its fixes all lie within 50 m of each other while the laps
+// or the speed sensor record it covering more than 200 m.
+func (a *Activity) IsIndoor() bool {
+	pts := a.trackpoints()
+	if len(pts) == 0 {
+		return false
+	}
+	var first *Trackpoint
+	var spread float64
+	for _, p := range pts {
+		if !p.HasPosition() || p.LatitudeInDegrees == 0 && p.LongitudeInDegrees == 0 {
+			continue
+		}
+		if first == nil {
+			first = p
+			continue
+		}
+		spread = math.Max(spread, distance(first.LatitudeInDegrees, first.LongitudeInDegrees, p.LatitudeInDegrees, p.LongitudeInDegrees))
+	}
+	if first == nil {
+		return true
+	}
+	if spread >= indoorSpread {
+		return false
+	}
+	var recorded float64
+	for _, l := range a.Laps {
+		recorded += l.DistanceInMeters
+	}
+	if d := speedDistances(pts); len(d) > 0 {
+		recorded = math.Max(recorded, d[len(d)-1])
+	}
+	return recorded > 4*indoorSpread
+}
+
+// fixes returns the trackpoints of the activity with a GPS fix, none for an
+// indoor activity.
+func (a *Activity) fixes() []*Trackpoint {
+	if a.IsIndoor() {
+		return nil
+	}
+	var fixes []*Trackpoint
+	for _, p := range a.trackpoints() {
+		if p.HasPosition() {
+			fixes = append(fixes, p)
+		}
+	}
+	return fixes
+}
+
+// distances returns the distance in meters covered at each point of pts,
+// the trackpoints of the activity, from the GPS fixes or, indoors, from the
+// speed sensor.
+func (a *Activity) distances(pts []*Trackpoint) []float64 {
+	if a.IsIndoor() {
+		return speedDistances(pts)
+	}
+	return cumulativeDistances(pts)
+}
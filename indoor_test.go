@@ -0,0 +1,43 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIsIndoor(t *testing.T) {
+	outdoor := testActivity(SportRunning, repeat(3.0, 301), nil)
+	if outdoor.IsIndoor() {
+		t.Error("outdoor run is indoor")
+	}
+	if (&Activity{}).IsIndoor() {
+		t.Error("empty activity is indoor")
+	}
+
+	// A treadmill run without positions, one at 0°N 0°E, and one with a
+	// watch searching for satellites on the spot.
+	noFix := testActivity(SportRunning, repeat(3.0, 301), nil)
+	nullIsland := testActivity(SportRunning, repeat(3.0, 301), nil)
+	stuck := testActivity(SportRunning, repeat(3.0, 301), nil)
+	for i := range noFix.Laps[0].Track {
+		noFix.Laps[0].Track[i].LatitudeInDegrees, noFix.Laps[0].Track[i].LongitudeInDegrees = 0, 0
+		nullIsland.Laps[0].Track[i].SetPosition(0, 0)
+		stuck.Laps[0].Track[i].SetPosition(47+float64(i%3)*1e-5, -1.5)
+	}
+	for name, a := range map[string]*Activity{"no fix": noFix, "0°N 0°E": nullIsland, "stuck": stuck} {
+		if !a.IsIndoor() {
+			t.Errorf("%s: not indoor", name)
+			continue
+		}
+		if d := a.ComputedDistance(); math.Abs(d-900) > 1e-6 {
+			t.Errorf("%s: computed distance = %v, want 900", name, d)
+		}
+		if _, _, ok := a.Center(); ok || a.Polyline(5) != "" {
+			t.Errorf("%s: GPS track not suppressed", name)
+		}
+		a.Laps[0].DistanceInMeters = 0
+		if n := a.RepairDistances(); n != 1 || math.Abs(a.Laps[0].DistanceInMeters-900) > 1e-6 {
+			t.Errorf("%s: repaired lap distance = %v", name, a.Laps[0].DistanceInMeters)
+		}
+	}
+}
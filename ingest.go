@@ -0,0 +1,110 @@
+package tcx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ingest records the samples received on ch until ch is closed or ctx is
+// done. It stops at the first sample the recorder rejects.
+func (r *Recorder) Ingest(ctx context.Context, ch <-chan Sample) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case s, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := r.Record(s); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// samplePayload is the JSON form of a Sample in message payloads. Time is
+// either RFC 3339 or milliseconds since the Unix epoch.
+type samplePayload struct {
+	Time      json.RawMessage `json:"time"`
+	Latitude  float64         `json:"lat"`
+	Longitude float64         `json:"lon"`
+	Altitude  float64         `json:"alt"`
+	HeartRate int             `json:"hr"`
+	Cadence   int             `json:"cadence"`
+	Speed     float64         `json:"speed"`
+	Power     int             `json:"power"`
+}
+
+// ParseSamplePayload decodes a JSON sample such as
+//
+//	{"time": "2020-05-01T07:00:00Z", "lat": 47.2, "lon": -1.5, "hr": 142}
+//
+// A sample without time is stamped on reception by the recorder.
+func ParseSamplePayload(payload []byte) (Sample, error) {
+	var p samplePayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return Sample{}, fmt.Errorf("couldn't parse sample: %v", err)
+	}
+	s := Sample{
+		Latitude:  p.Latitude,
+		Longitude: p.Longitude,
+		Altitude:  p.Altitude,
+		HeartRate: p.HeartRate,
+		Cadence:   p.Cadence,
+		Speed:     p.Speed,
+		Power:     p.Power,
+	}
+	if len(p.Time) > 0 && string(p.Time) != "null" {
+		var ms int64
+		if err := json.Unmarshal(p.Time, &ms); err == nil {
+			s.Time = time.UnixMilli(ms)
+		} else if err := json.Unmarshal(p.Time, &s.Time); err != nil {
+			return Sample{}, fmt.Errorf("couldn't parse sample time %s", p.Time)
+		}
+	}
+	return s, nil
+}
+
+// HandleMessage records an MQTT message, with a signature that adapts to the
+// message callbacks of MQTT clients. A JSON object payload is a full sample;
+// a number payload is a single reading named by the last topic level, one
+// of hr, cadence, power, speed or alt, as published by simple sensors on
+// topics like bike/hr.
+func (r *Recorder) HandleMessage(topic string, payload []byte) error {
+	body := strings.TrimSpace(string(payload))
+	if strings.HasPrefix(body, "{") {
+		s, err := ParseSamplePayload(payload)
+		if err != nil {
+			return err
+		}
+		return r.Record(s)
+	}
+	v, err := strconv.ParseFloat(body, 64)
+	if err != nil {
+		return fmt.Errorf("couldn't parse %s reading %q", topic, body)
+	}
+	var s Sample
+	switch path.Base(topic) {
+	case "hr", "heartrate":
+		s.HeartRate = int(v + 0.5)
+	case "cadence":
+		s.Cadence = int(v + 0.5)
+	case "power":
+		s.Power = int(v + 0.5)
+	case "speed":
+		s.Speed = v
+	case "alt", "altitude":
+		s.Altitude = v
+	default:
+		return fmt.Errorf("unknown reading topic %s", topic)
+	}
+	// Readings stamped within the same second share a trackpoint.
+	s.Time = r.now().Truncate(time.Second)
+	return r.Record(s)
+}
@@ -0,0 +1,65 @@
+package tcx
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIngest(t *testing.T) {
+	start := time.Date(2020, 5, 1, 7, 0, 0, 0, time.UTC)
+	r := NewRecorder(SportBiking)
+	ch := make(chan Sample)
+	go func() {
+		for i := 0; i < 10; i++ {
+			ch <- Sample{Time: start.Add(time.Duration(i) * time.Second), Speed: 8, Power: 200}
+		}
+		close(ch)
+	}()
+	if err := r.Ingest(context.Background(), ch); err != nil {
+		t.Fatal(err)
+	}
+	a := r.Activity()
+	if n := len(a.trackpoints()); n != 10 || a.TotalDistance() != 72 {
+		t.Errorf("got %d trackpoints over %v m", n, a.TotalDistance())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.Ingest(ctx, make(chan Sample)); err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestHandleMessage(t *testing.T) {
+	now := time.Date(2020, 5, 1, 7, 0, 0, 400e6, time.UTC)
+	r := NewRecorder(SportBiking)
+	r.now = func() time.Time { return now }
+
+	if err := r.HandleMessage("rig/gps", []byte(`{"time": "2020-05-01T07:00:00Z", "lat": 47.2, "lon": -1.5}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.HandleMessage("rig/hr", []byte("141.6")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.HandleMessage("rig/power", []byte("250")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.HandleMessage("rig/gps", []byte(`{"time": 1588316401000, "lat": 47.2001, "lon": -1.5}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.HandleMessage("rig/temperature", []byte("21")); err == nil {
+		t.Error("expected an error for an unknown reading")
+	}
+
+	pts := r.Activity().trackpoints()
+	if len(pts) != 2 {
+		t.Fatalf("got %d trackpoints, want 2", len(pts))
+	}
+	if p := pts[0]; p.HeartRateInBpm != 142 || p.PowerInWatts != 250 || p.LatitudeInDegrees != 47.2 {
+		t.Errorf("first trackpoint = %+v", p)
+	}
+	if !pts[1].Time.Equal(now.Truncate(time.Second).Add(time.Second)) {
+		t.Errorf("second trackpoint at %v", pts[1].Time)
+	}
+}
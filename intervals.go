@@ -0,0 +1,210 @@
+package tcx
+
+import (
+	"math"
+	"time"
+)
+
+// IntervalKind tells work from recovery intervals.
+type IntervalKind int
+
+const (
+	Recovery IntervalKind = iota
+	Work
+)
+
+func (k IntervalKind) String() string {
+	if k == Work {
+		return "work"
+	}
+	return "recovery"
+}
+
+// IntervalOptions configures DetectIntervals.
+type IntervalOptions struct {
+	// Metric is the intensity the intervals are detected on, MetricSpeed
+	// or MetricPower.
+	Metric Metric
+	// Threshold is the intensity, in the metric's unit, separating work
+	// from recovery. Zero derives it from the data, splitting the samples
+	// into two clusters.
+	Threshold float64
+	// Smoothing is the width of the rolling average applied before the
+	// threshold, so that single samples don't split an interval.
+	Smoothing time.Duration
+	// MinDuration is the shortest interval kept. Shorter spans join their
+	// neighbors.
+	MinDuration time.Duration
+}
+
+// DefaultIntervalOptions detects intervals of 30 s and more on speed.
+var DefaultIntervalOptions = IntervalOptions{
+	Metric:      MetricSpeed,
+	Smoothing:   10 * time.Second,
+	MinDuration: 30 * time.Second,
+}
+
+// DetectedInterval is a work or recovery segment found by DetectIntervals.
+type DetectedInterval struct {
+	Kind     IntervalKind
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+	Distance float64 // meters
+	// Intensity is the average of the detection metric.
+	Intensity        float64
+	AverageSpeed     float64 // m/s
+	AverageHeartRate float64 // bpm, zero without heart rate data
+}
+
+// DetectIntervals segments the activity into alternating work and
+// recovery intervals from its speed or power, for sessions recorded
+// without pressing the lap button. Intervals are found on the smoothed
+// metric, then each boundary is moved, within the smoothing width, to the
+// change point of the raw data: where the means on either side differ the
+// most.
+func DetectIntervals(a *Activity, opts IntervalOptions) []DetectedInterval {
+	pts := a.trackpoints()
+	if len(pts) < 2 {
+		return nil
+	}
+	// Speeds follow GPS positions, or the speed sensor without them.
+	speedAt := make(map[*Trackpoint]float64, len(pts))
+	for _, iv := range a.intervals() {
+		if iv.dt > 0 {
+			speedAt[iv.p] = iv.dist / iv.dt
+		}
+	}
+	speed := resample1Hz(pts, func(p *Trackpoint) float64 { return speedAt[p] })
+	hr := resample1Hz(pts, func(p *Trackpoint) float64 { return float64(p.HeartRateInBpm) })
+	x := speed
+	if opts.Metric == MetricPower {
+		x = resample1Hz(pts, func(p *Trackpoint) float64 { return float64(p.PowerInWatts) })
+	}
+	if len(x) == 0 {
+		return nil
+	}
+
+	// Smooth, threshold and label every second.
+	prefix := prefixSums(x)
+	w := max(int(opts.Smoothing/time.Second), 1)
+	threshold := opts.Threshold
+	smooth := make([]float64, len(x))
+	for i := range x {
+		lo, hi := max(i-w/2, 0), min(i+w-w/2, len(x))
+		smooth[i] = (prefix[hi] - prefix[lo]) / float64(hi-lo)
+	}
+	if threshold == 0 {
+		threshold = splitClusters(smooth)
+	}
+	type run struct {
+		work   bool
+		lo, hi int
+	}
+	var runs []run
+	for i, v := range smooth {
+		work := v >= threshold
+		if len(runs) == 0 || runs[len(runs)-1].work != work {
+			runs = append(runs, run{work, i, i})
+		}
+		runs[len(runs)-1].hi = i + 1
+	}
+
+	// Join the shortest runs to their neighbors until all are long enough.
+	minLen := int(opts.MinDuration / time.Second)
+	for len(runs) > 1 {
+		shortest := 0
+		for i, r := range runs {
+			if r.hi-r.lo < runs[shortest].hi-runs[shortest].lo {
+				shortest = i
+			}
+		}
+		if runs[shortest].hi-runs[shortest].lo >= minLen {
+			break
+		}
+		runs[shortest].work = !runs[shortest].work
+		merged := runs[:0]
+		for _, r := range runs {
+			if n := len(merged); n > 0 && merged[n-1].work == r.work {
+				merged[n-1].hi = r.hi
+				continue
+			}
+			merged = append(merged, r)
+		}
+		runs = merged
+	}
+
+	// Move each boundary to the change point of the raw data nearby.
+	mean := func(lo, hi int) float64 { return (prefix[hi] - prefix[lo]) / float64(hi-lo) }
+	for i := 1; i < len(runs); i++ {
+		prev, cur := &runs[i-1], &runs[i]
+		best, bestDiff := cur.lo, -1.0
+		for b := max(cur.lo-w, prev.lo+1); b <= min(cur.lo+w, cur.hi-1); b++ {
+			if d := math.Abs(mean(prev.lo, b) - mean(b, cur.hi)); d > bestDiff {
+				best, bestDiff = b, d
+			}
+		}
+		prev.hi, cur.lo = best, best
+	}
+
+	start := pts[0].Time
+	var detected []DetectedInterval
+	for _, r := range runs {
+		n := float64(r.hi - r.lo)
+		di := DetectedInterval{
+			Kind:      Recovery,
+			Start:     start.Add(time.Duration(r.lo) * time.Second),
+			End:       start.Add(time.Duration(r.hi) * time.Second),
+			Duration:  time.Duration(r.hi-r.lo) * time.Second,
+			Intensity: mean(r.lo, r.hi),
+		}
+		if r.work {
+			di.Kind = Work
+		}
+		var beats, withHR float64
+		for i := r.lo; i < r.hi; i++ {
+			di.Distance += speed[i]
+			if hr[i] > 0 {
+				beats += hr[i]
+				withHR++
+			}
+		}
+		di.AverageSpeed = di.Distance / n
+		if withHR > 0 {
+			di.AverageHeartRate = beats / withHR
+		}
+		detected = append(detected, di)
+	}
+	return detected
+}
+
+// splitClusters returns the value splitting xs into two clusters, midway
+// between their means (one dimensional k-means).
+func splitClusters(xs []float64) float64 {
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	t := sum / float64(len(xs))
+	for iter := 0; iter < 50; iter++ {
+		var lo, hi, nlo, nhi float64
+		for _, x := range xs {
+			if x >= t {
+				hi += x
+				nhi++
+			} else {
+				lo += x
+				nlo++
+			}
+		}
+		if nlo == 0 || nhi == 0 {
+			return t
+		}
+		next := (lo/nlo + hi/nhi) / 2
+		if next == t {
+			break
+		}
+		t = next
+	}
+	return t
+}
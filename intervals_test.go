@@ -0,0 +1,48 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDetectIntervals(t *testing.T) {
+	// A 5 minute warmup at 3 m/s, then 4 x (2 min at 5 m/s, 1 min at 2.5
+	// m/s), with a couple of GPS glitches.
+	var speeds []float64
+	speeds = append(speeds, repeat(3.0, 300)...)
+	for i := 0; i < 4; i++ {
+		speeds = append(speeds, repeat(5.0, 120)...)
+		speeds = append(speeds, repeat(2.5, 60)...)
+	}
+	speeds = append(speeds, 2.5)
+	speeds[340], speeds[620] = 1, 8
+	a := testActivity(SportRunning, speeds, repeat(150, len(speeds)))
+
+	got := DetectIntervals(a, DefaultIntervalOptions)
+	if len(got) != 9 {
+		t.Fatalf("detected %d intervals: %+v", len(got), got)
+	}
+	start := a.Laps[0].StartTime
+	for i, di := range got[1:] {
+		want := Work
+		if i%2 == 1 {
+			want = Recovery
+		}
+		if di.Kind != want {
+			t.Errorf("interval %d is %v, want %v", i+1, di.Kind, want)
+		}
+	}
+	if w := got[1]; math.Abs(w.Start.Sub(start).Seconds()-300) > 2 || math.Abs(w.Duration.Seconds()-120) > 2 {
+		t.Errorf("first work interval = %v + %v", w.Start.Sub(start), w.Duration)
+	}
+	if w := got[1]; math.Abs(w.AverageSpeed-5) > 0.1 || math.Abs(w.Distance-600) > 10 || w.AverageHeartRate != 150 {
+		t.Errorf("first work interval = %+v", w)
+	}
+
+	opts := DefaultIntervalOptions
+	opts.Threshold = 10
+	if got := DetectIntervals(a, opts); len(got) != 1 || got[0].Kind != Recovery || got[0].Duration != time.Duration(len(speeds)-1)*time.Second {
+		t.Errorf("DetectIntervals() over a high threshold = %+v", got)
+	}
+}
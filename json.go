@@ -0,0 +1,93 @@
+package tcx
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// trackpointJSON is the JSON form of a Trackpoint. Missing values are left
+// out, and the position is an object present only with a GPS fix.
+type trackpointJSON struct {
+	Time           time.Time     `json:"time"`
+	Position       *positionJSON `json:"position,omitempty"`
+	AltitudeMeters *float64      `json:"altitudeMeters,omitempty"`
+	DistanceMeters *float64      `json:"distanceMeters,omitempty"`
+	HeartRateBpm   *int          `json:"heartRateBpm,omitempty"`
+	Cadence        *int          `json:"cadence,omitempty"`
+	Speed          *float64      `json:"speed,omitempty"`
+	RunCadence     int           `json:"runCadence,omitempty"`
+	Watts          *int          `json:"watts,omitempty"`
+	RRIntervals    []int         `json:"rrIntervals,omitempty"`
+}
+
+type positionJSON struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// MarshalJSON writes the trackpoint with its position as an object, left
+// out without a GPS fix, and without its missing values.
+func (p Trackpoint) MarshalJSON() ([]byte, error) {
+	v := trackpointJSON{
+		Time:        p.Time,
+		RunCadence:  p.RunCadence,
+		RRIntervals: p.RRIntervals,
+	}
+	if p.HasPosition() {
+		v.Position = &positionJSON{p.LatitudeInDegrees, p.LongitudeInDegrees}
+	}
+	if p.HasAltitude() {
+		v.AltitudeMeters = &p.AltitudeInMeters
+	}
+	if p.HasDistance() {
+		v.DistanceMeters = &p.DistanceInMeters
+	}
+	if p.HasHeartRate() {
+		v.HeartRateBpm = &p.HeartRateInBpm
+	}
+	if p.HasCadence() {
+		v.Cadence = &p.Cadence
+	}
+	if p.HasSpeed() {
+		v.Speed = &p.SpeedInMetersPerSec
+	}
+	if p.HasPower() {
+		v.Watts = &p.PowerInWatts
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON reads a trackpoint written by MarshalJSON.
+func (p *Trackpoint) UnmarshalJSON(data []byte) error {
+	var v trackpointJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = Trackpoint{
+		Time:        v.Time,
+		RunCadence:  v.RunCadence,
+		RRIntervals: v.RRIntervals,
+	}
+	if v.Position != nil {
+		p.SetPosition(v.Position.Latitude, v.Position.Longitude)
+	}
+	if v.AltitudeMeters != nil {
+		p.SetAltitude(*v.AltitudeMeters)
+	}
+	if v.DistanceMeters != nil {
+		p.SetDistance(*v.DistanceMeters)
+	}
+	if v.HeartRateBpm != nil {
+		p.SetHeartRate(*v.HeartRateBpm)
+	}
+	if v.Cadence != nil {
+		p.SetCadence(*v.Cadence)
+	}
+	if v.Watts != nil {
+		p.SetPower(*v.Watts)
+	}
+	if v.Speed != nil {
+		p.SetSpeed(*v.Speed)
+	}
+	return nil
+}
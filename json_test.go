@@ -0,0 +1,38 @@
+package tcx
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	db, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Activities[0].Laps[2].Track[1].LatitudeInDegrees = 0
+	db.Activities[0].Laps[2].Track[1].LongitudeInDegrees = 0
+	data, err := json.Marshal(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(data)
+	for _, bad := range []string{"XMLName", "Space", "LatitudeInDegrees", `"latitude":0`} {
+		if strings.Contains(s, bad) {
+			t.Errorf("JSON holds %q", bad)
+		}
+	}
+	if !strings.Contains(s, `"startTime":"2015-04-12T07:28:19Z"`) {
+		t.Errorf("times not written as RFC 3339: %.300s", s)
+	}
+
+	var back Tcx
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.Activities, db.Activities) {
+		t.Error("activities changed through JSON")
+	}
+}
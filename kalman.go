@@ -0,0 +1,168 @@
+package tcx
+
+import "math"
+
+// KalmanOptions configures the GPS position filter.
+type KalmanOptions struct {
+	// PositionNoise is the standard deviation of GPS fixes, in meters.
+	// Zero or less uses the default.
+	PositionNoise float64
+	// Acceleration is the standard deviation of the athlete's
+	// acceleration, in m/s², driving how quickly velocity may change.
+	// Zero or less uses the default.
+	Acceleration float64
+	// SpeedNoise is the standard deviation of the speed sensor, in m/s.
+	// Zero ignores the speed data.
+	SpeedNoise float64
+}
+
+// DefaultKalmanOptions suits wrist-worn GPS watches.
+var DefaultKalmanOptions = KalmanOptions{PositionNoise: 8, Acceleration: 1.5, SpeedNoise: 0.5}
+
+// mat2 is a 2x2 matrix.
+type mat2 [2][2]float64
+
+func (m mat2) mul(n mat2) mat2 {
+	return mat2{
+		{m[0][0]*n[0][0] + m[0][1]*n[1][0], m[0][0]*n[0][1] + m[0][1]*n[1][1]},
+		{m[1][0]*n[0][0] + m[1][1]*n[1][0], m[1][0]*n[0][1] + m[1][1]*n[1][1]},
+	}
+}
+
+func (m mat2) add(n mat2) mat2 {
+	return mat2{{m[0][0] + n[0][0], m[0][1] + n[0][1]}, {m[1][0] + n[1][0], m[1][1] + n[1][1]}}
+}
+
+func (m mat2) sub(n mat2) mat2 {
+	return mat2{{m[0][0] - n[0][0], m[0][1] - n[0][1]}, {m[1][0] - n[1][0], m[1][1] - n[1][1]}}
+}
+
+func (m mat2) t() mat2 {
+	return mat2{{m[0][0], m[1][0]}, {m[0][1], m[1][1]}}
+}
+
+func (m mat2) inv() mat2 {
+	det := m[0][0]*m[1][1] - m[0][1]*m[1][0]
+	return mat2{{m[1][1] / det, -m[0][1] / det}, {-m[1][0] / det, m[0][0] / det}}
+}
+
+func (m mat2) apply(v [2]float64) [2]float64 {
+	return [2]float64{m[0][0]*v[0] + m[0][1]*v[1], m[1][0]*v[0] + m[1][1]*v[1]}
+}
+
+// kalmanAxis is the constant-velocity state, position and velocity, of one
+// horizontal axis.
+type kalmanAxis struct {
+	x [2]float64
+	p mat2
+}
+
+// update folds in a measurement z of state component i with variance r.
+func (k *kalmanAxis) update(i int, z, r float64) {
+	s := k.p[i][i] + r
+	gain := [2]float64{k.p[0][i] / s, k.p[1][i] / s}
+	innov := z - k.x[i]
+	k.x[0] += gain[0] * innov
+	k.x[1] += gain[1] * innov
+	var p mat2
+	for a := 0; a < 2; a++ {
+		for b := 0; b < 2; b++ {
+			p[a][b] = k.p[a][b] - gain[a]*k.p[i][b]
+		}
+	}
+	k.p = p
+}
+
+// KalmanSmooth smooths the GPS track of the activity with a constant-velocity
+// Kalman filter followed by a Rauch-Tung-Striebel backward pass. Successive
+// fixes are fused with the speed sensor when available. Only trackpoints
+// holding a fix are rewritten.
+func (a *Activity) KalmanSmooth(opts KalmanOptions) {
+	a.Invalidate()
+	var pts []*Trackpoint
+	for _, p := range a.trackpoints() {
+		if p.HasPosition() {
+			pts = append(pts, p)
+		}
+	}
+	if len(pts) < 2 {
+		return
+	}
+	// Without noise the covariances are singular and the backward pass
+	// divides by zero.
+	if opts.PositionNoise <= 0 {
+		opts.PositionNoise = DefaultKalmanOptions.PositionNoise
+	}
+	if opts.Acceleration <= 0 {
+		opts.Acceleration = DefaultKalmanOptions.Acceleration
+	}
+
+	// Work in meters on a plane tangent at the first fix.
+	lat0, lon0 := pts[0].LatitudeInDegrees, pts[0].LongitudeInDegrees
+	kx := earthRadius * math.Cos(deg2rad(lat0)) * math.Pi / 180
+	ky := earthRadius * math.Pi / 180
+
+	n := len(pts)
+	type step struct{ pred, filt [2]kalmanAxis }
+	steps := make([]step, n)
+	r := opts.PositionNoise * opts.PositionNoise
+	initial := mat2{{r, 0}, {0, 100}}
+	state := [2]kalmanAxis{
+		{x: [2]float64{0, 0}, p: initial},
+		{x: [2]float64{0, 0}, p: initial},
+	}
+	fs := make([]mat2, n)
+	for i, pt := range pts {
+		z := [2]float64{(pt.LongitudeInDegrees - lon0) * kx, (pt.LatitudeInDegrees - lat0) * ky}
+		if i > 0 {
+			dt := pt.Time.Sub(pts[i-1].Time).Seconds()
+			f := mat2{{1, dt}, {0, 1}}
+			q2 := opts.Acceleration * opts.Acceleration
+			q := mat2{{q2 * dt * dt * dt / 3, q2 * dt * dt / 2}, {q2 * dt * dt / 2, q2 * dt}}
+			fs[i] = f
+			for ax := range state {
+				state[ax].x = f.apply(state[ax].x)
+				state[ax].p = f.mul(state[ax].p).mul(f.t()).add(q)
+			}
+		} else {
+			state[0].x[0], state[1].x[0] = z[0], z[1]
+		}
+		steps[i].pred = state
+
+		for ax := range state {
+			state[ax].update(0, z[ax], r)
+		}
+		// The speed sensor measures the norm of the velocity: project it
+		// on the current heading.
+		if opts.SpeedNoise > 0 && pt.SpeedInMetersPerSec > 0 {
+			vx, vy := state[0].x[1], state[1].x[1]
+			if norm := math.Hypot(vx, vy); norm > 0.1 {
+				s2 := opts.SpeedNoise * opts.SpeedNoise
+				state[0].update(1, pt.SpeedInMetersPerSec*vx/norm, s2)
+				state[1].update(1, pt.SpeedInMetersPerSec*vy/norm, s2)
+			}
+		}
+		steps[i].filt = state
+	}
+
+	// Rauch-Tung-Striebel smoothing, backwards from the last fix.
+	smoothed := steps[n-1].filt
+	for i := n - 2; i >= 0; i-- {
+		for ax := range smoothed {
+			filt, pred := steps[i].filt[ax], steps[i+1].pred[ax]
+			c := filt.p.mul(fs[i+1].t()).mul(pred.p.inv())
+			diff := [2]float64{smoothed[ax].x[0] - pred.x[0], smoothed[ax].x[1] - pred.x[1]}
+			corr := c.apply(diff)
+			smoothed[ax] = kalmanAxis{
+				x: [2]float64{filt.x[0] + corr[0], filt.x[1] + corr[1]},
+				p: filt.p.add(c.mul(smoothed[ax].p.sub(pred.p)).mul(c.t())),
+			}
+		}
+		steps[i].filt = smoothed
+	}
+
+	for i, pt := range pts {
+		pt.LongitudeInDegrees = lon0 + steps[i].filt[0].x[0]/kx
+		pt.LatitudeInDegrees = lat0 + steps[i].filt[1].x[0]/ky
+	}
+}
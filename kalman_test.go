@@ -0,0 +1,38 @@
+package tcx
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestKalmanSmooth(t *testing.T) {
+	a := testActivity("Running", repeat(3.0, 601), nil)
+	truth := cumulativeDistances(a.trackpoints())
+	want := truth[len(truth)-1]
+
+	rng := rand.New(rand.NewSource(1))
+	for i := range a.Laps[0].Track {
+		p := &a.Laps[0].Track[i]
+		p.LatitudeInDegrees += rng.NormFloat64() * 5 / 111195
+		p.LongitudeInDegrees += rng.NormFloat64() * 5 / 75800
+	}
+	noisy := cumulativeDistances(a.trackpoints())
+
+	a.KalmanSmooth(DefaultKalmanOptions)
+	smooth := cumulativeDistances(a.trackpoints())
+	got := smooth[len(smooth)-1]
+	if math.Abs(got-want) > 0.1*want || math.Abs(got-want) >= math.Abs(noisy[len(noisy)-1]-want) {
+		t.Errorf("smoothed distance %.f m, noisy %.f m, want %.f m", got, noisy[len(noisy)-1], want)
+	}
+}
+
+func TestKalmanSmoothZeroOptions(t *testing.T) {
+	a := testActivity("Running", repeat(3.0, 61), nil)
+	a.KalmanSmooth(KalmanOptions{})
+	for _, p := range a.trackpoints() {
+		if math.IsNaN(p.LatitudeInDegrees) || math.IsNaN(p.LongitudeInDegrees) {
+			t.Fatalf("KalmanSmooth with zero options gave %v, %v", p.LatitudeInDegrees, p.LongitudeInDegrees)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package tcx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// KML namespaces.
+const (
+	KMLNS   = "http://www.opengis.net/kml/2.2"
+	KMLGxNS = "http://www.google.com/kml/ext/2.2"
+)
+
+// TourOptions configures the animated tour of WriteKMZ.
+type TourOptions struct {
+	// Speedup is the ratio of activity time to tour time.
+	Speedup float64
+	// Step is the activity time between camera moves.
+	Step time.Duration
+	// Extrude draws the track as a wall down to the ground.
+	Extrude bool
+	// FollowCamera points the camera along the direction of travel;
+	// otherwise it looks north.
+	FollowCamera bool
+	// Range, Tilt are the camera distance in meters and its angle from
+	// vertical in degrees.
+	Range, Tilt float64
+}
+
+// DefaultTourOptions plays an activity sixty times faster than recorded,
+// with a camera following it from behind.
+var DefaultTourOptions = TourOptions{
+	Speedup:      60,
+	Step:         10 * time.Second,
+	Extrude:      true,
+	FollowCamera: true,
+	Range:        400,
+	Tilt:         60,
+}
+
+type kmlDocument struct {
+	XMLName   xml.Name     `xml:"kml"`
+	XMLNs     string       `xml:"xmlns,attr"`
+	XMLNsGx   string       `xml:"xmlns:gx,attr"`
+	Name      string       `xml:"Document>name"`
+	Style     kmlStyle     `xml:"Document>Style"`
+	Placemark kmlPlacemark `xml:"Document>Placemark"`
+	Tour      kmlTour      `xml:"Document>gx:Tour"`
+}
+
+type kmlStyle struct {
+	ID        string `xml:"id,attr"`
+	LineColor string `xml:"LineStyle>color"`
+	LineWidth int    `xml:"LineStyle>width"`
+	PolyColor string `xml:"PolyStyle>color"`
+}
+
+type kmlPlacemark struct {
+	Name     string   `xml:"name"`
+	StyleURL string   `xml:"styleUrl"`
+	Extrude  int      `xml:"gx:Track>extrude"`
+	AltMode  string   `xml:"gx:Track>altitudeMode"`
+	When     []string `xml:"gx:Track>when"`
+	Coord    []string `xml:"gx:Track>gx:coord"`
+}
+
+type kmlTour struct {
+	Name     string     `xml:"name"`
+	Playlist []kmlFlyTo `xml:"gx:Playlist>gx:FlyTo"`
+}
+
+type kmlFlyTo struct {
+	Duration float64   `xml:"gx:duration"`
+	FlyMode  string    `xml:"gx:flyToMode"`
+	LookAt   kmlLookAt `xml:"LookAt"`
+}
+
+type kmlLookAt struct {
+	When      string  `xml:"gx:TimeStamp>when"`
+	Longitude float64 `xml:"longitude"`
+	Latitude  float64 `xml:"latitude"`
+	Altitude  float64 `xml:"altitude"`
+	Heading   float64 `xml:"heading"`
+	Tilt      float64 `xml:"tilt"`
+	Range     float64 `xml:"range"`
+	AltMode   string  `xml:"altitudeMode"`
+}
+
+// WriteKMZ writes the activity as a KMZ archive for Google Earth. The track
+// is a time-stamped gx:Track, so the time slider replays it, and a gx:Tour
+// flies the camera along it.
+func (a *Activity) WriteKMZ(w io.Writer, opts TourOptions) error {
+	var pts []*Trackpoint
+	for _, p := range a.trackpoints() {
+		if p.HasPosition() {
+			pts = append(pts, p)
+		}
+	}
+	if len(pts) < 2 {
+		return fmt.Errorf("activity %v has no track to export", a.ID)
+	}
+	if opts.Speedup <= 0 || opts.Step <= 0 {
+		return fmt.Errorf("tour speedup and step must be positive")
+	}
+
+	doc := kmlDocument{
+		XMLNs:   KMLNS,
+		XMLNsGx: KMLGxNS,
+		Name:    fmt.Sprintf("%s %s", a.Sport, a.ID.Format("2006-01-02 15:04")),
+		Style:   kmlStyle{ID: "track", LineColor: "ff0000ff", LineWidth: 4, PolyColor: "7f0000ff"},
+	}
+	doc.Placemark = kmlPlacemark{Name: doc.Name, StyleURL: "#track", AltMode: "absolute"}
+	if opts.Extrude {
+		doc.Placemark.Extrude = 1
+	}
+	for _, p := range pts {
+		doc.Placemark.When = append(doc.Placemark.When, p.Time.UTC().Format(time.RFC3339))
+		doc.Placemark.Coord = append(doc.Placemark.Coord,
+			fmt.Sprintf("%.7f %.7f %.1f", p.LongitudeInDegrees, p.LatitudeInDegrees, p.AltitudeInMeters))
+	}
+
+	doc.Tour.Name = "Play " + doc.Name
+	heading := 0.0
+	next := pts[0].Time
+	for i, p := range pts {
+		if p.Time.Before(next) && i != len(pts)-1 {
+			continue
+		}
+		if opts.FollowCamera {
+			// Look along the next stretch of at least 20 m.
+			for j := i + 1; j < len(pts); j++ {
+				q := pts[j]
+				if distance(p.LatitudeInDegrees, p.LongitudeInDegrees, q.LatitudeInDegrees, q.LongitudeInDegrees) >= 20 {
+					heading = math.Mod(bearing(p.LatitudeInDegrees, p.LongitudeInDegrees, q.LatitudeInDegrees, q.LongitudeInDegrees)+360, 360)
+					break
+				}
+			}
+		}
+		fly := kmlFlyTo{
+			Duration: opts.Step.Seconds() / opts.Speedup,
+			FlyMode:  "smooth",
+			LookAt: kmlLookAt{
+				When:      p.Time.UTC().Format(time.RFC3339),
+				Longitude: p.LongitudeInDegrees,
+				Latitude:  p.LatitudeInDegrees,
+				Altitude:  p.AltitudeInMeters,
+				Heading:   math.Round(heading*10) / 10,
+				Tilt:      opts.Tilt,
+				Range:     opts.Range,
+				AltMode:   "absolute",
+			},
+		}
+		if len(doc.Tour.Playlist) == 0 {
+			fly.Duration, fly.FlyMode = 2, "bounce"
+		}
+		doc.Tour.Playlist = append(doc.Tour.Playlist, fly)
+		next = p.Time.Add(opts.Step)
+	}
+
+	z := zip.NewWriter(w)
+	f, err := z.Create("doc.kml")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(f)
+	enc.Indent("", " ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return z.Close()
+}
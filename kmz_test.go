@@ -0,0 +1,55 @@
+package tcx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteKMZ(t *testing.T) {
+	tcx, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	a := &tcx.Activities[0]
+	var buf bytes.Buffer
+	if err := a.WriteKMZ(&buf, DefaultTourOptions); err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(z.File) != 1 || z.File[0].Name != "doc.kml" {
+		t.Fatalf("unexpected archive content %v", z.File)
+	}
+	f, err := z.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kml := string(data)
+
+	for _, want := range []string{`xmlns:gx="http://www.google.com/kml/ext/2.2"`, "<gx:Tour>", "<extrude>1</extrude>"} {
+		if !strings.Contains(kml, want) {
+			t.Errorf("doc.kml lacks %s", want)
+		}
+	}
+	if n := strings.Count(kml, "<gx:coord>"); n != len(a.trackpoints()) {
+		t.Errorf("got %d coordinates, want %d", n, len(a.trackpoints()))
+	}
+	// One camera move per 10 s of a 1h52m activity, give or take gaps.
+	if n := strings.Count(kml, "<gx:FlyTo>"); n < 500 || n > 700 {
+		t.Errorf("got %d camera moves", n)
+	}
+
+	if err := (&Activity{}).WriteKMZ(&buf, DefaultTourOptions); err == nil {
+		t.Error("expected an error for an activity without track")
+	}
+}
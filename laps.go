@@ -0,0 +1,111 @@
+package tcx
+
+import (
+	"fmt"
+	"time"
+)
+
+// SplitLapAt splits the lap in progress at t in two, the trackpoints from t
+// onward starting the new lap. The recorded distance of the lap is shared
+// between the halves in proportion to the distance the track measures up to
+// t, or to their time when it measures none, and the calories in proportion
+// to their time. The other totals are recomputed from the trackpoints;
+// maximums the trackpoints do not record are kept from the lap. The lap
+// extensions, which summarize the whole lap, are dropped.
+func (a *Activity) SplitLapAt(t time.Time) error {
+	a.Invalidate()
+	for i := range a.Laps {
+		l := &a.Laps[i]
+		k := 0
+		for k < len(l.Track) && l.Track[k].Time.Before(t) {
+			k++
+		}
+		if k == 0 || k == len(l.Track) || !t.After(l.StartTime) {
+			continue
+		}
+		whole := *l
+		whole.update()
+		first, second := *l, *l
+		first.Track = l.Track[:k:k]
+		second.Track = l.Track[k:]
+		second.StartTime = t
+		first.update()
+		second.update()
+		first.TotalTimeInSeconds = t.Sub(l.StartTime).Seconds()
+		second.TotalTimeInSeconds = max(second.TotalTimeInSeconds, l.TotalTimeInSeconds-first.TotalTimeInSeconds)
+		// The distance covered up to t, interpolated over the trackpoints
+		// around it.
+		upTo := first.DistanceInMeters
+		p, q := &l.Track[k-1], &l.Track[k]
+		if span := q.Time.Sub(p.Time); span > 0 {
+			upTo += segmentDistance(p, q, true) * float64(t.Sub(p.Time)) / float64(span)
+		}
+		total := first.TotalTimeInSeconds + second.TotalTimeInSeconds
+		switch {
+		case whole.DistanceInMeters > 0:
+			d := l.DistanceInMeters * upTo / whole.DistanceInMeters
+			first.DistanceInMeters, second.DistanceInMeters = d, l.DistanceInMeters-d
+		case total > 0:
+			d := l.DistanceInMeters * first.TotalTimeInSeconds / total
+			first.DistanceInMeters, second.DistanceInMeters = d, l.DistanceInMeters-d
+		}
+		if total > 0 {
+			c := l.Calories * first.TotalTimeInSeconds / total
+			first.Calories, second.Calories = c, l.Calories-c
+		}
+		// Without readings in the track, the recorded maximums are the best
+		// bound for both halves.
+		for _, half := range []*Lap{&first, &second} {
+			if half.MaximumSpeedInMetersPerSec == 0 {
+				half.MaximumSpeedInMetersPerSec = l.MaximumSpeedInMetersPerSec
+			}
+			if half.MaximumHeartRateInBpm == 0 {
+				half.MaximumHeartRateInBpm = l.MaximumHeartRateInBpm
+			}
+		}
+		first.Extensions, second.Extensions = nil, nil
+		second.Notes = ""
+		a.Laps = append(a.Laps[:i], append([]Lap{first, second}, a.Laps[i+1:]...)...)
+		return nil
+	}
+	return fmt.Errorf("couldn't split lap: no lap has trackpoints on both sides of %v", t)
+}
+
+// MergeLaps merges laps i to j, inclusive, into lap i. Time, distance and
+// calories are summed, maximums kept and the average heart rate weighted by
+// time. The lap extensions, which summarize single laps, are dropped.
+func (a *Activity) MergeLaps(i, j int) error {
+	a.Invalidate()
+	if i < 0 || j >= len(a.Laps) || i >= j {
+		return fmt.Errorf("couldn't merge laps %d to %d of %d", i, j, len(a.Laps))
+	}
+	m := a.Laps[i]
+	m.Track = append([]Trackpoint(nil), m.Track...)
+	hr := float64(m.AverageHeartRateInBpm) * m.TotalTimeInSeconds
+	hrTime := 0.0
+	if m.AverageHeartRateInBpm > 0 {
+		hrTime = m.TotalTimeInSeconds
+	}
+	for _, l := range a.Laps[i+1 : j+1] {
+		m.Track = append(m.Track, l.Track...)
+		m.TotalTimeInSeconds += l.TotalTimeInSeconds
+		m.DistanceInMeters += l.DistanceInMeters
+		m.Calories += l.Calories
+		m.MaximumSpeedInMetersPerSec = max(m.MaximumSpeedInMetersPerSec, l.MaximumSpeedInMetersPerSec)
+		m.MaximumHeartRateInBpm = max(m.MaximumHeartRateInBpm, l.MaximumHeartRateInBpm)
+		if l.AverageHeartRateInBpm > 0 {
+			hr += float64(l.AverageHeartRateInBpm) * l.TotalTimeInSeconds
+			hrTime += l.TotalTimeInSeconds
+		}
+		if m.Notes == "" {
+			m.Notes = l.Notes
+		}
+	}
+	if hrTime > 0 {
+		m.AverageHeartRateInBpm = int(hr/hrTime + 0.5)
+	}
+	m.Extensions = nil
+	a.Laps[i] = m
+	a.Laps = append(a.Laps[:i+1], a.Laps[j+1:]...)
+	return nil
+}
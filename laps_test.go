@@ -0,0 +1,84 @@
+package tcx
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitAndMergeLaps(t *testing.T) {
+	a := testActivity(SportRunning, repeat(4.0, 601), append(repeat(140, 301), repeat(160, 300)...))
+	l := &a.Laps[0]
+	l.update()
+	l.DistanceInMeters, l.Calories = 2400, 60
+	orig := a.Clone()
+
+	at := a.ID.Add(200 * time.Second)
+	if err := a.SplitLapAt(at); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Laps) != 2 || len(a.Laps[0].Track) != 200 || !a.Laps[1].StartTime.Equal(at) {
+		t.Fatalf("split into %d laps", len(a.Laps))
+	}
+	first, second := a.Laps[0], a.Laps[1]
+	if first.TotalTimeInSeconds != 200 || second.TotalTimeInSeconds != 400 {
+		t.Errorf("split times = %v s, %v s", first.TotalTimeInSeconds, second.TotalTimeInSeconds)
+	}
+	if math.Abs(first.DistanceInMeters+second.DistanceInMeters-2400) > 1e-9 || math.Abs(first.DistanceInMeters-800) > 5 {
+		t.Errorf("split distances = %v m, %v m", first.DistanceInMeters, second.DistanceInMeters)
+	}
+	if math.Abs(first.Calories-20) > 1e-9 || math.Abs(second.Calories-40) > 1e-9 {
+		t.Errorf("split calories = %v, %v", first.Calories, second.Calories)
+	}
+	if first.AverageHeartRateInBpm != 140 || second.MaximumHeartRateInBpm != 160 {
+		t.Errorf("split heart rates = %+v, %+v", first.AverageHeartRateInBpm, second.MaximumHeartRateInBpm)
+	}
+	if err := a.SplitLapAt(a.ID.Add(time.Hour)); err == nil {
+		t.Error("split a lap after the end of the activity")
+	}
+
+	// Without speed readings, the halves keep the recorded maximum speed.
+	b := orig.Clone()
+	b.Laps[0].MaximumSpeedInMetersPerSec = 5.5
+	for i := range b.Laps[0].Track {
+		b.Laps[0].Track[i].SpeedInMetersPerSec = 0
+	}
+	if err := b.SplitLapAt(at); err != nil {
+		t.Fatal(err)
+	}
+	if b.Laps[0].MaximumSpeedInMetersPerSec != 5.5 || b.Laps[1].MaximumSpeedInMetersPerSec != 5.5 {
+		t.Errorf("split maximum speeds = %v, %v", b.Laps[0].MaximumSpeedInMetersPerSec, b.Laps[1].MaximumSpeedInMetersPerSec)
+	}
+
+	if err := a.MergeLaps(0, 1); err != nil {
+		t.Fatal(err)
+	}
+	m := a.Laps[0]
+	if len(a.Laps) != 1 || !reflect.DeepEqual(m.Track, orig.Laps[0].Track) {
+		t.Fatalf("merged back into %d laps", len(a.Laps))
+	}
+	if m.TotalTimeInSeconds != 600 || math.Abs(m.DistanceInMeters-2400) > 1e-9 || math.Abs(m.Calories-60) > 1e-9 {
+		t.Errorf("merged totals = %v s, %v m, %v kcal", m.TotalTimeInSeconds, m.DistanceInMeters, m.Calories)
+	}
+	if m.MaximumHeartRateInBpm != 160 || m.AverageHeartRateInBpm != orig.Laps[0].AverageHeartRateInBpm {
+		t.Errorf("merged heart rates = %v avg, %v max", m.AverageHeartRateInBpm, m.MaximumHeartRateInBpm)
+	}
+	if err := a.MergeLaps(0, 1); err == nil {
+		t.Error("merged laps out of range")
+	}
+}
+
+func TestSplitLapTreadmill(t *testing.T) {
+	db, err := ParseFile("testdata/treadmill.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &db.Activities[0]
+	if err := a.SplitLapAt(a.ID.Add(300 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if d0, d1 := a.Laps[0].DistanceInMeters, a.Laps[1].DistanceInMeters; d0 != 900 || d1 != 900 {
+		t.Errorf("split treadmill lap into %v m and %v m", d0, d1)
+	}
+}
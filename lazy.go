@@ -0,0 +1,177 @@
+package tcx
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ActivityIndex locates an activity in a LazyFile.
+type ActivityIndex struct {
+	Sport string
+	ID    time.Time
+	// Offset and Size delimit the Activity element in the file, in bytes.
+	Offset, Size int64
+}
+
+// LazyFile gives access to the activities of a large TCX file without
+// decoding all of them. Opening the file only indexes its activities; each
+// one is decoded when asked for. Where the platform supports it, the file is
+// memory-mapped so that the operating system pages it in and out as needed.
+type LazyFile struct {
+	f     *os.File
+	r     io.ReaderAt
+	unmap func() error
+	index []ActivityIndex
+	// root is the root element of the file, whose namespace declarations
+	// wrap each activity decoded.
+	root xml.StartElement
+	opts []Option
+}
+
+// OpenLazy opens and indexes the TCX file at path. Activities are decoded
+// as Parse would with opts. The LazyFile must be closed once done with.
+func OpenLazy(path string, opts ...Option) (*LazyFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	lf := &LazyFile{f: f, r: f, opts: opts}
+	if r, unmap, err := mmapFile(f, info.Size()); err == nil {
+		lf.r, lf.unmap = r, unmap
+	}
+	if lf.index, lf.root, err = indexActivities(io.NewSectionReader(lf.r, 0, info.Size())); err != nil {
+		lf.Close()
+		return nil, err
+	}
+	return lf, nil
+}
+
+// indexActivities scans r for the Activity elements of the Activities of
+// the document, reading their sport and identifier only, and returns them
+// with the root element. The activities of multisport sessions are left
+// out.
+func indexActivities(r io.Reader) ([]ActivityIndex, xml.StartElement, error) {
+	var index []ActivityIndex
+	var root xml.StartElement
+	var cur *ActivityIndex
+	var path []string
+	activityDepth, inID := 0, false
+	d := xml.NewDecoder(r)
+	for {
+		off := d.InputOffset()
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, root, fmt.Errorf("couldn't index tcx data: %v", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			depth := len(path)
+			switch {
+			case depth == 1:
+				root = t.Copy()
+			case cur == nil && depth == 3 && path[1] == "Activities" && t.Name.Local == "Activity":
+				cur = &ActivityIndex{Offset: off}
+				activityDepth = depth
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "Sport" {
+						cur.Sport = attr.Value
+					}
+				}
+			case cur != nil && depth == activityDepth+1 && t.Name.Local == "Id":
+				inID = true
+			}
+		case xml.CharData:
+			if inID {
+				cur.ID, _ = time.Parse(time.RFC3339, strings.TrimSpace(string(t)))
+			}
+		case xml.EndElement:
+			inID = false
+			if cur != nil && len(path) == activityDepth {
+				cur.Size = d.InputOffset() - cur.Offset
+				index = append(index, *cur)
+				cur = nil
+			}
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+		}
+	}
+	return index, root, nil
+}
+
+// Len returns the number of activities in the file.
+func (lf *LazyFile) Len() int {
+	return len(lf.index)
+}
+
+// Index returns the location, sport and identifier of every activity.
+func (lf *LazyFile) Index() []ActivityIndex {
+	return append([]ActivityIndex(nil), lf.index...)
+}
+
+// Activity decodes the i-th activity of the file. It is parsed on its own
+// in a document with the root element of the file, so that namespace
+// prefixes resolve, the parse options apply and decode errors locate
+// problems as Parse does.
+func (lf *LazyFile) Activity(i int) (*Activity, error) {
+	if i < 0 || i >= len(lf.index) {
+		return nil, fmt.Errorf("activity %d out of range [0, %d)", i, len(lf.index))
+	}
+	ix := lf.index[i]
+	var head strings.Builder
+	head.WriteString("<" + qualifiedName(lf.root.Name))
+	for _, attr := range lf.root.Attr {
+		head.WriteString(" " + qualifiedName(attr.Name) + `="`)
+		xml.EscapeText(&head, []byte(attr.Value))
+		head.WriteString(`"`)
+	}
+	head.WriteString("><Activities>")
+	doc := io.MultiReader(
+		strings.NewReader(head.String()),
+		io.NewSectionReader(lf.r, ix.Offset, ix.Size),
+		strings.NewReader("</Activities></"+qualifiedName(lf.root.Name)+">"),
+	)
+	t, err := ParseContext(context.Background(), doc, lf.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse tcx activity %d: %w", i, err)
+	}
+	if len(t.Activities) != 1 {
+		return nil, fmt.Errorf("couldn't parse tcx activity %d: found %d activities", i, len(t.Activities))
+	}
+	return &t.Activities[0], nil
+}
+
+// qualifiedName returns n as written in a document, with its prefix, as
+// RawToken reads it.
+func qualifiedName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+// Close releases the mapping and closes the file.
+func (lf *LazyFile) Close() error {
+	if lf.unmap != nil {
+		if err := lf.unmap(); err != nil {
+			lf.f.Close()
+			return err
+		}
+		lf.unmap = nil
+	}
+	return lf.f.Close()
+}
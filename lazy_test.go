@@ -0,0 +1,113 @@
+package tcx
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenLazy(t *testing.T) {
+	lf, err := OpenLazy("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lf.Close()
+
+	full, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	if lf.Len() != 1 {
+		t.Fatalf("indexed %d activities, want 1", lf.Len())
+	}
+	ix := lf.Index()[0]
+	if ix.Sport != "Running" || !ix.ID.Equal(time.Date(2015, 4, 12, 7, 28, 19, 0, time.UTC)) {
+		t.Errorf("index = %+v", ix)
+	}
+	a, err := lf.Activity(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a.Laps, full.Activities[0].Laps) {
+		t.Error("lazily decoded activity differs from the parsed one")
+	}
+	if _, err := lf.Activity(1); err == nil {
+		t.Error("expected an error for an out of range activity")
+	}
+}
+
+func TestOpenLazyMany(t *testing.T) {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0"?><TrainingCenterDatabase xmlns="` + TrainingCenterNS + `"><Activities>`)
+	sports := []string{"Running", "Biking", "Other"}
+	for i, s := range sports {
+		b.WriteString(`<Activity Sport="` + s + `"><Id>2020-01-0` + string(rune('1'+i)) + `T10:00:00Z</Id>`)
+		b.WriteString(`<Lap StartTime="2020-01-01T10:00:00Z"><TotalTimeSeconds>60</TotalTimeSeconds><Track><Trackpoint><Time>2020-01-01T10:00:00Z</Time></Trackpoint></Track></Lap></Activity>`)
+	}
+	b.WriteString(`</Activities></TrainingCenterDatabase>`)
+	path := filepath.Join(t.TempDir(), "many.tcx")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lf, err := OpenLazy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lf.Close()
+	if lf.Len() != len(sports) {
+		t.Fatalf("indexed %d activities, want %d", lf.Len(), len(sports))
+	}
+	for i, s := range sports {
+		a, err := lf.Activity(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Sport != s || a.ID.Day() != i+1 || a.TotalDuration() != time.Minute {
+			t.Errorf("activity %d = %s on day %d", i, a.Sport, a.ID.Day())
+		}
+	}
+}
+
+func TestOpenLazyMultiSport(t *testing.T) {
+	const lap = `<Lap StartTime="2020-01-01T10:00:00Z"><TotalTimeSeconds>60</TotalTimeSeconds><Track>` +
+		`<Trackpoint><Time>2020-01-01T10:00:00Z</Time><Extensions><ns3:TPX><ns3:Watts>210</ns3:Watts></ns3:TPX></Extensions></Trackpoint>` +
+		`<Trackpoint><Time>2020-01-01T10:01:00Z</Time></Trackpoint></Track></Lap>`
+	doc := `<?xml version="1.0"?><TrainingCenterDatabase xmlns="` + TrainingCenterNS + `" xmlns:ns3="` + ActivityExtensionNS + `">` +
+		`<Activities><Activity Sport="Biking"><Id>2020-01-01T10:00:00Z</Id>` + lap + `</Activity>` +
+		`<MultiSportSession><Id>2020-01-02T10:00:00Z</Id><FirstSport><Activity Sport="Running"><Id>2020-01-02T10:00:00Z</Id>` + lap +
+		`</Activity></FirstSport></MultiSportSession></Activities></TrainingCenterDatabase>`
+	path := filepath.Join(t.TempDir(), "multisport.tcx")
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lf, err := OpenLazy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lf.Close()
+	if lf.Len() != 1 || lf.Index()[0].Sport != SportBiking {
+		t.Fatalf("index = %+v, want the top-level activity only", lf.Index())
+	}
+	a, err := lf.Activity(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := a.Laps[0].Track[0]; p.PowerInWatts != 210 {
+		t.Errorf("trackpoint = %+v", p)
+	}
+
+	// Parse options apply to the activities decoded.
+	strict, err := OpenLazy(path, WithMaxTrackpoints(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer strict.Close()
+	if _, err := strict.Activity(0); err == nil {
+		t.Error("expected an error for an activity over the trackpoint limit")
+	}
+}
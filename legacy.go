@@ -0,0 +1,90 @@
+package tcx
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// TrainingCenterNSv1 is the namespace of TCX version 1 documents, which
+// file activities in a History of sport folders instead of Activities.
+const TrainingCenterNSv1 = "http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v1"
+
+// historyFolderV1 is a sport folder of a version 1 History, holding runs
+// and nested folders.
+type historyFolderV1 struct {
+	Folders []historyFolderV1 `xml:"Folder"`
+	Runs    []runV1           `xml:"Run"`
+}
+
+// runV1 is a version 1 activity, identified by the start of its first lap.
+type runV1 struct {
+	Laps  []Lap  `xml:"Lap"`
+	Notes string `xml:"Notes"`
+}
+
+// activities upgrades the runs of the folder and its subfolders to
+// activities of sport.
+func (f *historyFolderV1) activities(sport string) []Activity {
+	var activities []Activity
+	for _, r := range f.Runs {
+		a := Activity{Sport: sport, Laps: r.Laps}
+		if len(r.Laps) > 0 {
+			a.ID = r.Laps[0].StartTime
+		}
+		if r.Notes != "" && len(a.Laps) > 0 && a.Laps[0].Notes == "" {
+			a.Laps[0].Notes = r.Notes
+		}
+		activities = append(activities, a)
+	}
+	for i := range f.Folders {
+		activities = append(activities, f.Folders[i].activities(sport)...)
+	}
+	return activities
+}
+
+// tcxAlias has the fields of Tcx without its methods.
+type tcxAlias Tcx
+
+// tcxIn is a Tcx as read, along with the History of version 1 documents.
+// Its own XMLName shadows that of the alias, which encoding/xml can't set
+// through the unexported embedding.
+type tcxIn struct {
+	XMLName xml.Name
+	tcxAlias
+	History struct {
+		Running historyFolderV1 `xml:"Running"`
+		Biking  historyFolderV1 `xml:"Biking"`
+		Other   historyFolderV1 `xml:"Other"`
+	} `xml:"History"`
+}
+
+// UnmarshalXML reads a TCX document whatever its namespace, version 2,
+// version 1 or none, since elements are matched by local name. Version 1
+// documents are upgraded to version 2: their runs become activities and
+// their namespace and schema location those of version 2. Documents whose
+// root is not TrainingCenterDatabase fail with ErrNotTCX.
+func (t *Tcx) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if start.Name.Local != "TrainingCenterDatabase" {
+		return fmt.Errorf("%w: root element is <%s>, not <TrainingCenterDatabase>", ErrNotTCX, start.Name.Local)
+	}
+	var v tcxIn
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*t = Tcx(v.tcxAlias)
+	t.XMLName = v.XMLName
+	if t.XMLNs == "" {
+		t.XMLNs = start.Name.Space
+	}
+	h := &v.History
+	n := len(t.Activities)
+	t.Activities = append(t.Activities, h.Running.activities(SportRunning)...)
+	t.Activities = append(t.Activities, h.Biking.activities(SportBiking)...)
+	t.Activities = append(t.Activities, h.Other.activities(SportOther)...)
+	if t.XMLNs == TrainingCenterNSv1 || len(t.Activities) > n {
+		t.XMLName.Space = TrainingCenterNS
+		t.XMLNs = TrainingCenterNS
+		t.XMLSchemaLoc = ""
+	}
+	return nil
+}
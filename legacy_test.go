@@ -0,0 +1,68 @@
+package tcx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseV1(t *testing.T) {
+	db, err := Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v1"
+  xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+  xsi:schemaLocation="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v1 http://www.garmin.com/xmlschemas/TrainingCenterDatabasev1.xsd">
+<History>
+<Running><Folder><Run>
+<Lap StartTime="2007-06-02T08:00:00Z"><TotalTimeSeconds>2</TotalTimeSeconds><DistanceMeters>8</DistanceMeters>
+<Track>
+<Trackpoint><Time>2007-06-02T08:00:00Z</Time><Position><LatitudeDegrees>47</LatitudeDegrees><LongitudeDegrees>-1.5</LongitudeDegrees></Position><HeartRateBpm>130</HeartRateBpm></Trackpoint>
+<Trackpoint><Time>2007-06-02T08:00:02Z</Time><HeartRateBpm>134</HeartRateBpm></Trackpoint>
+</Track></Lap>
+<Notes>Tempo</Notes>
+</Run></Folder></Running>
+<Biking><Run><Lap StartTime="2007-06-03T08:00:00Z"><TotalTimeSeconds>60</TotalTimeSeconds><DistanceMeters>500</DistanceMeters></Lap></Run></Biking>
+</History>
+</TrainingCenterDatabase>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.XMLNs != TrainingCenterNS || db.XMLSchemaLoc != "" || len(db.Activities) != 2 {
+		t.Fatalf("namespace %q, schema location %q, %d activities", db.XMLNs, db.XMLSchemaLoc, len(db.Activities))
+	}
+	run, ride := db.Activities[0], db.Activities[1]
+	if run.Sport != SportRunning || !run.ID.Equal(time.Date(2007, 6, 2, 8, 0, 0, 0, time.UTC)) || run.Laps[0].Notes != "Tempo" {
+		t.Errorf("run = %v %v %q", run.Sport, run.ID, run.Laps[0].Notes)
+	}
+	if p := run.Laps[0].Track[1]; p.HeartRateInBpm != 134 || len(run.Laps[0].Track) != 2 {
+		t.Errorf("trackpoint = %+v", p)
+	}
+	if ride.Sport != SportBiking || ride.TotalDistance() != 500 {
+		t.Errorf("ride = %v, %v m", ride.Sport, ride.TotalDistance())
+	}
+}
+
+func TestParseNamespaces(t *testing.T) {
+	body := `<Activities><Activity Sport="Running"><Id>2015-04-12T07:00:00Z</Id>
+<Lap StartTime="2015-04-12T07:00:00Z"><TotalTimeSeconds>10</TotalTimeSeconds><DistanceMeters>40</DistanceMeters></Lap>
+</Activity></Activities>`
+	prefixed := strings.NewReplacer("</", "</tc:", "<", "<tc:").Replace(body)
+	for name, doc := range map[string]string{
+		"none":     `<TrainingCenterDatabase>` + body + `</TrainingCenterDatabase>`,
+		"other":    `<TrainingCenterDatabase xmlns="http://example.com/tcx">` + body + `</TrainingCenterDatabase>`,
+		"prefixed": `<tc:TrainingCenterDatabase xmlns:tc="` + TrainingCenterNS + `">` + prefixed + `</tc:TrainingCenterDatabase>`,
+	} {
+		db, err := Parse(strings.NewReader(doc))
+		if err != nil || len(db.Activities) != 1 || db.Activities[0].TotalDistance() != 40 {
+			t.Errorf("%s namespace: %v", name, err)
+		}
+	}
+
+	_, err := Parse(strings.NewReader(`<gpx version="1.1"><trk/></gpx>`))
+	if !errors.Is(err, ErrNotTCX) {
+		t.Errorf("Parse(gpx) = %v", err)
+	}
+	if _, err := Parse(strings.NewReader("  ")); !errors.Is(err, ErrNotTCX) {
+		t.Errorf("Parse(blank) = %v", err)
+	}
+}
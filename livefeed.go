@@ -0,0 +1,173 @@
+package tcx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LiveStatus is the progress of a recording.
+type LiveStatus struct {
+	Start     time.Time `json:"start"`
+	Time      time.Time `json:"time"`
+	Elapsed   float64   `json:"elapsed"`
+	Distance  float64   `json:"distance"`
+	Pace      float64   `json:"pace,omitempty"`
+	Latitude  float64   `json:"lat,omitempty"`
+	Longitude float64   `json:"lon,omitempty"`
+	HeartRate int       `json:"hr,omitempty"`
+	Points    int       `json:"points"`
+}
+
+// Status returns the progress of the recording: time, distance, average
+// pace in seconds per kilometer and the latest readings.
+func (r *Recorder) Status() LiveStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a := &r.activity
+	s := LiveStatus{Start: a.ID}
+	for i := range a.Laps {
+		l := &a.Laps[i]
+		s.Elapsed += l.TotalTimeInSeconds
+		s.Distance += l.DistanceInMeters
+		s.Points += len(l.Track)
+		if n := len(l.Track); n > 0 {
+			p := &l.Track[n-1]
+			s.Time, s.HeartRate = p.Time, p.HeartRateInBpm
+			if p.HasPosition() {
+				s.Latitude, s.Longitude = p.LatitudeInDegrees, p.LongitudeInDegrees
+			}
+		}
+	}
+	if s.Distance > 0 {
+		s.Pace = s.Elapsed / s.Distance * 1000
+	}
+	return s
+}
+
+// positionsSince returns the GPS fixes of the trackpoints from the n-th on,
+// as [lon, lat, alt], and the number of trackpoints recorded.
+func (r *Recorder) positionsSince(n int) ([][3]float64, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var coords [][3]float64
+	i := 0
+	for _, l := range r.activity.Laps {
+		for k := range l.Track {
+			if p := &l.Track[k]; i >= n && p.HasPosition() {
+				coords = append(coords, [3]float64{p.LongitudeInDegrees, p.LatitudeInDegrees, p.AltitudeInMeters})
+			}
+			i++
+		}
+	}
+	return coords, i
+}
+
+// LiveFeed serves a recording in progress over HTTP, for share-my-run
+// pages. Mounted at a prefix with http.StripPrefix, it answers:
+//
+//	/status     the LiveStatus as JSON
+//	/track      the track as a GeoJSON LineString feature; ?since=n returns
+//	            only the fixes from the n-th trackpoint on, and the "next"
+//	            property is the value of since for the following request
+//	/events     a server-sent event stream of status and track updates
+type LiveFeed struct {
+	rec *Recorder
+	// Interval is the period of event stream updates; zero means a second.
+	Interval time.Duration
+	// AllowOrigin, when set, is sent as the Access-Control-Allow-Origin
+	// header so that pages of that origin may read the feed. "*" opens the
+	// feed to any page.
+	AllowOrigin string
+}
+
+// NewLiveFeed returns a feed of r updated every second.
+func NewLiveFeed(r *Recorder) *LiveFeed {
+	return &LiveFeed{rec: r, Interval: time.Second}
+}
+
+type liveTrack struct {
+	Type     string `json:"type"`
+	Geometry struct {
+		Type        string       `json:"type"`
+		Coordinates [][3]float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties struct {
+		Next int `json:"next"`
+	} `json:"properties"`
+}
+
+func (f *LiveFeed) track(since int) liveTrack {
+	var t liveTrack
+	coords, next := f.rec.positionsSince(since)
+	t.Type, t.Geometry.Type = "Feature", "LineString"
+	t.Geometry.Coordinates = coords
+	if t.Geometry.Coordinates == nil {
+		t.Geometry.Coordinates = [][3]float64{}
+	}
+	t.Properties.Next = next
+	return t
+}
+
+func (f *LiveFeed) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if f.AllowOrigin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", f.AllowOrigin)
+	}
+	switch strings.TrimSuffix(req.URL.Path, "/") {
+	case "/status":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(f.rec.Status())
+	case "/track":
+		since, _ := strconv.Atoi(req.URL.Query().Get("since"))
+		w.Header().Set("Content-Type", "application/geo+json")
+		json.NewEncoder(w).Encode(f.track(since))
+	case "/events":
+		f.serveEvents(w, req)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// serveEvents streams status and new track fixes until the client leaves.
+func (f *LiveFeed) serveEvents(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	interval := f.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	since, sent := 0, -1
+	for {
+		status := f.rec.Status()
+		if status.Points != sent {
+			track := f.track(since)
+			for _, ev := range []struct {
+				name string
+				data any
+			}{{"status", status}, {"track", track}} {
+				data, _ := json.Marshal(ev.data)
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.name, data); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+			since, sent = track.Properties.Next, status.Points
+		}
+		select {
+		case <-req.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package tcx
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLiveFeed(t *testing.T) {
+	start := time.Date(2020, 5, 1, 7, 0, 0, 0, time.UTC)
+	r := NewRecorder(SportRunning)
+	record := func(from, to int) {
+		for i := from; i < to; i++ {
+			r.Record(Sample{Time: start.Add(time.Duration(i) * time.Second),
+				Latitude: 47 + float64(i)*3/111194.93, Longitude: -1.5, HeartRate: 130})
+		}
+	}
+	record(0, 101)
+
+	feed := NewLiveFeed(r)
+	feed.Interval = 10 * time.Millisecond
+	srv := httptest.NewServer(feed)
+	defer srv.Close()
+
+	var status LiveStatus
+	get(t, srv.URL+"/status", &status)
+	if status.Points != 101 || status.Elapsed != 100 || status.Pace < 330 || status.Pace > 337 {
+		t.Errorf("status = %+v", status)
+	}
+
+	var track liveTrack
+	get(t, srv.URL+"/track?since=90", &track)
+	if len(track.Geometry.Coordinates) != 11 || track.Properties.Next != 101 {
+		t.Errorf("got %d coordinates, next %d", len(track.Geometry.Coordinates), track.Properties.Next)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(nil, 1<<20)
+	var tracks []liveTrack
+	for sc.Scan() && len(tracks) < 2 {
+		line := sc.Text()
+		if !strings.HasPrefix(line, "data: ") || !strings.Contains(line, "geometry") {
+			continue
+		}
+		var tr liveTrack
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &tr); err != nil {
+			t.Fatal(err)
+		}
+		tracks = append(tracks, tr)
+		if len(tracks) == 1 {
+			record(101, 106)
+		}
+	}
+	if len(tracks) != 2 || len(tracks[0].Geometry.Coordinates) != 101 || len(tracks[1].Geometry.Coordinates) != 5 {
+		t.Errorf("event stream sent %d track updates", len(tracks))
+	}
+}
+
+func get(t *testing.T, url string, v any) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLiveFeedDefaults(t *testing.T) {
+	r := NewRecorder(SportRunning)
+	r.Record(Sample{Time: time.Date(2020, 5, 1, 7, 0, 0, 0, time.UTC), HeartRate: 130})
+	feed := &LiveFeed{rec: r}
+
+	w := httptest.NewRecorder()
+	feed.ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+	if h := w.Header().Get("Access-Control-Allow-Origin"); h != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q without AllowOrigin", h)
+	}
+	feed.AllowOrigin = "https://example.com"
+	w = httptest.NewRecorder()
+	feed.ServeHTTP(w, httptest.NewRequest("GET", "/status", nil))
+	if h := w.Header().Get("Access-Control-Allow-Origin"); h != feed.AllowOrigin {
+		t.Errorf("Access-Control-Allow-Origin = %q, want %q", h, feed.AllowOrigin)
+	}
+
+	// A zero Interval streams at the default period instead of panicking.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	w = httptest.NewRecorder()
+	feed.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil).WithContext(ctx))
+	if !strings.Contains(w.Body.String(), "event: status") {
+		t.Errorf("event stream = %q", w.Body.String())
+	}
+}
@@ -0,0 +1,126 @@
+package tcx
+
+import (
+	"math"
+	"time"
+)
+
+// TRIMP returns the training impulse of the activity (Banister): the
+// minutes spent at each fraction of heart rate reserve, weighted by an
+// exponential of it that follows blood lactate, with the coefficients of
+// the athlete's sex (those of men when unknown). ok is false when the
+// athlete's resting and maximum heart rates or the activity's heart rates
+// are missing.
+func (a *Activity) TRIMP(ath Athlete) (float64, bool) {
+	if _, ok := ath.heartRateReserve(ath.MaxHeartRate); !ok {
+		return 0, false
+	}
+	k, b := 0.64, 1.92
+	if ath.Sex == SexFemale {
+		k, b = 0.86, 1.67
+	}
+	var trimp, seconds float64
+	for _, iv := range a.intervals() {
+		if !iv.p.HasHeartRate() || iv.dt > maxHoldGap.Seconds() {
+			continue
+		}
+		hrr, _ := ath.heartRateReserve(iv.p.HeartRateInBpm)
+		hrr = math.Max(0, math.Min(1, hrr))
+		trimp += iv.dt / 60 * hrr * k * math.Exp(b*hrr)
+		seconds += iv.dt
+	}
+	return trimp, seconds > 0
+}
+
+// LoadOptions configures the training load model.
+type LoadOptions struct {
+	// AcuteDays and ChronicDays are the time constants of the acute (ATL,
+	// fatigue) and chronic (CTL, fitness) loads.
+	AcuteDays, ChronicDays float64
+}
+
+// DefaultLoadOptions uses the usual 7 and 42 day time constants.
+var DefaultLoadOptions = LoadOptions{AcuteDays: 7, ChronicDays: 42}
+
+// DailyLoad is the training load of a day.
+type DailyLoad struct {
+	Day time.Time
+	// TRIMP sums the training impulses of the activities started that day.
+	TRIMP float64
+	// ATL and CTL are the acute and chronic loads at the end of the day,
+	// exponentially weighted averages of the daily TRIMP, and TSB, their
+	// difference, the training stress balance or form.
+	ATL, CTL, TSB float64
+}
+
+// TrainingLoad returns the load of every day from the first activity of
+// the collection to the last, rest days included, in the time zone of the
+// collection. Activities without heart rate count as rest.
+func (c *Collection) TrainingLoad(ath Athlete, opts LoadOptions) []DailyLoad {
+	if len(c.Activities) == 0 {
+		return nil
+	}
+	loc := c.location()
+	trimps := make(map[time.Time]float64)
+	first, last := dayStart(c.Activities[0].start().In(loc)), time.Time{}
+	for _, a := range c.Activities {
+		day := dayStart(a.start().In(loc))
+		if t, ok := a.TRIMP(ath); ok {
+			trimps[day] += t
+		}
+		if day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+	}
+	acute := 1 - math.Exp(-1/math.Max(opts.AcuteDays, 1))
+	chronic := 1 - math.Exp(-1/math.Max(opts.ChronicDays, 1))
+	var loads []DailyLoad
+	var atl, ctl float64
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		t := trimps[day]
+		atl += (t - atl) * acute
+		ctl += (t - ctl) * chronic
+		loads = append(loads, DailyLoad{Day: day, TRIMP: t, ATL: atl, CTL: ctl, TSB: ctl - atl})
+	}
+	return loads
+}
+
+// PeriodLoad is the training load of a week or a month.
+type PeriodLoad struct {
+	Start time.Time
+	TRIMP float64
+	// ATL, CTL and TSB are those at the end of the period, or of the last
+	// activity day when sooner.
+	ATL, CTL, TSB float64
+}
+
+// LoadByWeek returns the training load of the ISO weeks, starting on
+// Monday, between the first and last activities of the collection.
+func (c *Collection) LoadByWeek(ath Athlete, opts LoadOptions) []PeriodLoad {
+	return loadByPeriod(c.TrainingLoad(ath, opts), weekStart)
+}
+
+// LoadByMonth returns the training load of the months between the first
+// and last activities of the collection.
+func (c *Collection) LoadByMonth(ath Athlete, opts LoadOptions) []PeriodLoad {
+	return loadByPeriod(c.TrainingLoad(ath, opts), monthStart)
+}
+
+// loadByPeriod sums daily loads, in order, by the start of their period,
+// given by period.
+func loadByPeriod(days []DailyLoad, period func(time.Time) time.Time) []PeriodLoad {
+	var periods []PeriodLoad
+	for _, d := range days {
+		start := period(d.Day)
+		if len(periods) == 0 || !periods[len(periods)-1].Start.Equal(start) {
+			periods = append(periods, PeriodLoad{Start: start})
+		}
+		p := &periods[len(periods)-1]
+		p.TRIMP += d.TRIMP
+		p.ATL, p.CTL, p.TSB = d.ATL, d.CTL, d.TSB
+	}
+	return periods
+}
@@ -0,0 +1,64 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTRIMP(t *testing.T) {
+	ath := Athlete{Sex: SexMale, MaxHeartRate: 190, RestingHeartRate: 50}
+	a := testActivity(SportRunning, repeat(3.0, 601), repeat(150, 601))
+	hrr := 100.0 / 140
+	want := 10 * hrr * 0.64 * math.Exp(1.92*hrr)
+	if got, ok := a.TRIMP(ath); !ok || math.Abs(got-want) > 1e-6 {
+		t.Errorf("TRIMP = %v, %v, want %v", got, ok, want)
+	}
+	ath.Sex = SexFemale
+	if got, _ := a.TRIMP(ath); got <= want {
+		t.Errorf("female TRIMP = %v, want more than %v", got, want)
+	}
+	if _, ok := a.TRIMP(Athlete{}); ok {
+		t.Error("TRIMP without heart rate bounds")
+	}
+	if _, ok := testActivity(SportRunning, repeat(3.0, 601), nil).TRIMP(ath); ok {
+		t.Error("TRIMP without heart rates")
+	}
+}
+
+func TestTrainingLoad(t *testing.T) {
+	ath := Athlete{MaxHeartRate: 190, RestingHeartRate: 50}
+	day := func(d int) *Activity {
+		a := testActivity(SportRunning, repeat(3.0, 601), repeat(150, 601))
+		a.ShiftTime(time.Date(2015, 4, d, 7, 0, 0, 0, time.UTC).Sub(a.ID))
+		return a
+	}
+	// Sunday 12, twice, and Tuesday 21 April.
+	c := NewCollection([]*Activity{day(12), day(21), day(12)})
+	trimp, _ := c.Activities[0].TRIMP(ath)
+
+	days := c.TrainingLoad(ath, DefaultLoadOptions)
+	if len(days) != 10 || days[0].TRIMP != 2*trimp || days[1].TRIMP != 0 {
+		t.Fatalf("days = %+v", days)
+	}
+	if want := 2 * trimp * (1 - math.Exp(-1.0/7)); math.Abs(days[0].ATL-want) > 1e-9 {
+		t.Errorf("ATL = %v, want %v", days[0].ATL, want)
+	}
+	if d := days[8]; d.ATL >= days[0].ATL || d.CTL >= days[0].CTL || d.TSB != d.CTL-d.ATL {
+		t.Errorf("loads don't decay on rest days: %+v", d)
+	}
+
+	weeks := c.LoadByWeek(ath, DefaultLoadOptions)
+	if len(weeks) != 3 || weeks[0].TRIMP != 2*trimp || weeks[1].TRIMP != 0 || weeks[2].ATL != days[9].ATL {
+		t.Errorf("weeks = %+v", weeks)
+	}
+	if want := time.Date(2015, 4, 20, 0, 0, 0, 0, time.UTC); !weeks[2].Start.Equal(want) {
+		t.Errorf("last week starts %v, want %v", weeks[2].Start, want)
+	}
+	if months := c.LoadByMonth(ath, DefaultLoadOptions); len(months) != 1 || math.Abs(months[0].TRIMP-3*trimp) > 1e-9 {
+		t.Errorf("months = %+v", months)
+	}
+	if (&Collection{}).TrainingLoad(ath, DefaultLoadOptions) != nil {
+		t.Error("load of an empty collection")
+	}
+}
@@ -0,0 +1,235 @@
+package tcx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// MatchedPoint is the position of a trackpoint snapped to a road network.
+type MatchedPoint struct {
+	Latitude, Longitude float64
+	// Matched is false when no road was found near the trackpoint.
+	Matched bool
+	// Way names the road or trail, when the matcher knows it.
+	Way string
+}
+
+// MapMatcher snaps GPS fixes to a road or trail network. Match returns one
+// point per fix, in order.
+type MapMatcher interface {
+	Match(ctx context.Context, track []*Trackpoint) ([]MatchedPoint, error)
+}
+
+// MatchResult summarizes a map matching.
+type MatchResult struct {
+	Matched, Unmatched int
+	// Distance is the length of the matched track, in meters.
+	Distance float64
+}
+
+// MapMatch snaps the trackpoints of the activity to the network of m,
+// replacing the positions of the matched ones, and measures the corrected
+// track. Lap distances are left as recorded.
+func (a *Activity) MapMatch(ctx context.Context, m MapMatcher) (MatchResult, error) {
+	a.Invalidate()
+	var track []*Trackpoint
+	for _, p := range a.trackpoints() {
+		if p.HasPosition() {
+			track = append(track, p)
+		}
+	}
+	var res MatchResult
+	if len(track) == 0 {
+		return res, nil
+	}
+	matched, err := m.Match(ctx, track)
+	if err != nil {
+		return res, err
+	}
+	if len(matched) != len(track) {
+		return res, fmt.Errorf("map matcher returned %d points for %d", len(matched), len(track))
+	}
+	for i, mp := range matched {
+		if !mp.Matched {
+			res.Unmatched++
+			continue
+		}
+		res.Matched++
+		track[i].LatitudeInDegrees, track[i].LongitudeInDegrees = mp.Latitude, mp.Longitude
+	}
+	d := cumulativeDistances(track)
+	res.Distance = d[len(d)-1]
+	return res, nil
+}
+
+// OSRMMatcher matches tracks with the match service of an OSRM server.
+type OSRMMatcher struct {
+	// BaseURL of the server, such as http://localhost:5000.
+	BaseURL string
+	// Profile is the routing profile: driving, cycling or foot.
+	Profile string
+	// Radius is the assumed GPS precision, in meters.
+	Radius float64
+	// BatchSize is the number of fixes per request, at most the server's
+	// max-matching-size.
+	BatchSize int
+	Client    *http.Client
+}
+
+// NewOSRMMatcher returns a matcher for the server at baseURL with the given
+// profile.
+func NewOSRMMatcher(baseURL, profile string) *OSRMMatcher {
+	return &OSRMMatcher{BaseURL: baseURL, Profile: profile, Radius: 10, BatchSize: 100, Client: http.DefaultClient}
+}
+
+type osrmResponse struct {
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	Tracepoints []*struct {
+		Location [2]float64 `json:"location"`
+		Name     string     `json:"name"`
+	} `json:"tracepoints"`
+}
+
+func (o *OSRMMatcher) Match(ctx context.Context, track []*Trackpoint) ([]MatchedPoint, error) {
+	size := o.BatchSize
+	if size < 2 {
+		size = 100
+	}
+	out := make([]MatchedPoint, 0, len(track))
+	for from := 0; from < len(track); from += size {
+		batch := track[from:min(from+size, len(track))]
+		res, err := o.match(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res...)
+	}
+	return out, nil
+}
+
+func (o *OSRMMatcher) match(ctx context.Context, batch []*Trackpoint) ([]MatchedPoint, error) {
+	coords := make([]string, len(batch))
+	stamps := make([]string, len(batch))
+	radii := make([]string, len(batch))
+	for i, p := range batch {
+		coords[i] = fmt.Sprintf("%.6f,%.6f", p.LongitudeInDegrees, p.LatitudeInDegrees)
+		stamps[i] = fmt.Sprint(p.Time.Unix())
+		radii[i] = fmt.Sprint(o.Radius)
+	}
+	q := url.Values{}
+	q.Set("timestamps", strings.Join(stamps, ";"))
+	q.Set("radiuses", strings.Join(radii, ";"))
+	q.Set("overview", "false")
+	q.Set("gaps", "ignore")
+	u := fmt.Sprintf("%s/match/v1/%s/%s?%s", strings.TrimSuffix(o.BaseURL, "/"), o.Profile, strings.Join(coords, ";"), q.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var r osrmResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("couldn't parse osrm response: %v", err)
+	}
+	out := make([]MatchedPoint, len(batch))
+	switch {
+	case r.Code == "NoMatch":
+		return out, nil
+	case r.Code != "Ok":
+		return nil, fmt.Errorf("osrm match failed: %s %s", r.Code, r.Message)
+	case len(r.Tracepoints) != len(batch):
+		return nil, fmt.Errorf("osrm returned %d tracepoints for %d", len(r.Tracepoints), len(batch))
+	}
+	for i, tp := range r.Tracepoints {
+		if tp != nil {
+			out[i] = MatchedPoint{Latitude: tp.Location[1], Longitude: tp.Location[0], Matched: true, Way: tp.Name}
+		}
+	}
+	return out, nil
+}
+
+// RoadNetwork is an offline road or trail graph for GraphMatcher.
+type RoadNetwork struct {
+	Ways []Way
+}
+
+// Way is a road or trail, as a polyline of [lat, lon] nodes.
+type Way struct {
+	Name  string
+	Nodes [][2]float64
+	// Tags holds OSM-style attributes such as highway and surface.
+	Tags map[string]string
+}
+
+// GraphMatcher matches tracks to a RoadNetwork without any service. Each fix
+// snaps to the nearest segment within Radius, staying on the previous way
+// unless another one is clearly closer, which keeps switchbacks and
+// junctions from flickering between parallel roads.
+type GraphMatcher struct {
+	Network *RoadNetwork
+	// Radius is the largest snapping distance, in meters.
+	Radius float64
+}
+
+func (g *GraphMatcher) Match(ctx context.Context, track []*Trackpoint) ([]MatchedPoint, error) {
+	out := make([]MatchedPoint, len(track))
+	prevWay := -1
+	for i, p := range track {
+		if i%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		best, bestWay := math.Inf(1), -1
+		var bestLat, bestLon float64
+		for w, way := range g.Network.Ways {
+			for k := 1; k < len(way.Nodes); k++ {
+				lat, lon := projectOnSegment(p.LatitudeInDegrees, p.LongitudeInDegrees, way.Nodes[k-1], way.Nodes[k])
+				d := distance(p.LatitudeInDegrees, p.LongitudeInDegrees, lat, lon)
+				if w != prevWay {
+					// Leaving the current way costs a few meters.
+					d += 5
+				}
+				if d < best {
+					best, bestWay, bestLat, bestLon = d, w, lat, lon
+				}
+			}
+		}
+		if bestWay < 0 || best > g.Radius+5 ||
+			distance(p.LatitudeInDegrees, p.LongitudeInDegrees, bestLat, bestLon) > g.Radius {
+			continue
+		}
+		out[i] = MatchedPoint{Latitude: bestLat, Longitude: bestLon, Matched: true, Way: g.Network.Ways[bestWay].Name}
+		prevWay = bestWay
+	}
+	return out, nil
+}
+
+// projectOnSegment returns the point of segment ab nearest to (lat, lon), on
+// a local flat projection.
+func projectOnSegment(lat, lon float64, a, b [2]float64) (float64, float64) {
+	k := math.Cos(deg2rad(lat))
+	ax, ay := (a[1]-lon)*k, a[0]-lat
+	bx, by := (b[1]-lon)*k, b[0]-lat
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/l))
+	}
+	return a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1])
+}
@@ -0,0 +1,86 @@
+package tcx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// zigzagActivity runs north along lon -1.5 with GPS scatter of 8 m either
+// side of the road.
+func zigzagActivity() *Activity {
+	a := testActivity(SportRunning, repeat(3.0, 301), repeat(140, 301))
+	for i := range a.Laps[0].Track {
+		off := 8.0
+		if i%2 == 1 {
+			off = -8
+		}
+		a.Laps[0].Track[i].LongitudeInDegrees += off / (111194.93 * math.Cos(deg2rad(47)))
+	}
+	return a
+}
+
+func TestGraphMatcher(t *testing.T) {
+	a := zigzagActivity()
+	net := &RoadNetwork{Ways: []Way{
+		{Name: "Main Street", Nodes: [][2]float64{{46.99, -1.5}, {47.02, -1.5}}},
+		{Name: "Far Road", Nodes: [][2]float64{{46.99, -1.49}, {47.02, -1.49}}},
+	}}
+	res, err := a.MapMatch(context.Background(), &GraphMatcher{Network: net, Radius: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Matched != 301 || res.Unmatched != 0 {
+		t.Errorf("matched %d, unmatched %d", res.Matched, res.Unmatched)
+	}
+	if math.Abs(res.Distance-900) > 1 {
+		t.Errorf("matched distance = %v, want 900", res.Distance)
+	}
+	for _, p := range a.trackpoints() {
+		if p.LongitudeInDegrees != -1.5 {
+			t.Fatalf("trackpoint left on %v", p.LongitudeInDegrees)
+		}
+	}
+}
+
+func TestOSRMMatcher(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !strings.HasPrefix(r.URL.Path, "/match/v1/foot/") {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		coords := strings.Split(strings.TrimPrefix(r.URL.Path, "/match/v1/foot/"), ";")
+		resp := map[string]any{"code": "Ok"}
+		var tps []any
+		for i, c := range coords {
+			var lon, lat float64
+			if _, err := fmt.Sscanf(c, "%f,%f", &lon, &lat); err != nil {
+				t.Fatal(err)
+			}
+			if i == 0 {
+				tps = append(tps, nil)
+				continue
+			}
+			tps = append(tps, map[string]any{"location": []float64{-1.5, lat}, "name": "Main Street"})
+		}
+		resp["tracepoints"] = tps
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	a := zigzagActivity()
+	m := NewOSRMMatcher(srv.URL, "foot")
+	res, err := a.MapMatch(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 4 || res.Matched != 297 || res.Unmatched != 4 {
+		t.Errorf("%d requests matched %d, unmatched %d", requests, res.Matched, res.Unmatched)
+	}
+}
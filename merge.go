@@ -0,0 +1,99 @@
+package tcx
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// start returns the time the activity started: its first lap or, without
+// laps, its id.
+func (a *Activity) start() time.Time {
+	if len(a.Laps) > 0 {
+		return a.Laps[0].StartTime
+	}
+	return a.ID
+}
+
+// MergeActivities joins recordings of the same session, such as the two
+// files left by a watch that crashed mid-run, into one activity. Laps are
+// concatenated in time order; trackpoints at or before the last trackpoint
+// already merged are dropped as overlap, and the totals of the laps that
+// lost trackpoints are recomputed as in Crop. The activities must share their sport and
+// are not modified.
+func MergeActivities(activities ...*Activity) (*Activity, error) {
+	if len(activities) == 0 {
+		return nil, fmt.Errorf("couldn't merge activities: no activity")
+	}
+	sorted := append([]*Activity(nil), activities...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].start().Before(sorted[j].start()) })
+	for _, a := range sorted[1:] {
+		if a.Sport != sorted[0].Sport {
+			return nil, fmt.Errorf("couldn't merge activities: %s and %s sports differ", sorted[0].Sport, a.Sport)
+		}
+	}
+
+	m := sorted[0].Clone()
+	m.Laps = nil
+	var last time.Time
+	for _, a := range sorted {
+		for _, l := range a.Clone().Laps {
+			if len(l.Track) == 0 {
+				if last.IsZero() || l.StartTime.After(last) {
+					m.Laps = append(m.Laps, l)
+				}
+				continue
+			}
+			track := l.Track[:0]
+			for _, p := range l.Track {
+				if last.IsZero() || p.Time.After(last) {
+					track = append(track, p)
+					last = p.Time
+				}
+			}
+			if len(track) == 0 {
+				continue
+			}
+			if len(track) < len(l.Track) {
+				l.refit(track)
+			}
+			m.Laps = append(m.Laps, l)
+		}
+	}
+	return m, nil
+}
+
+// MergeFiles joins files holding recordings of the same session into one
+// file with a single activity, merged with MergeActivities. Multisport
+// sessions, courses and workouts are copied as they are, and the author is
+// the first one set. The result shares no memory with files.
+func MergeFiles(files ...*Tcx) (*Tcx, error) {
+	m := NewTcx()
+	var activities []*Activity
+	for _, f := range files {
+		for i := range f.Activities {
+			activities = append(activities, &f.Activities[i])
+		}
+		// Clone all but the activities, which MergeActivities copies.
+		rest := *f
+		rest.Activities = nil
+		c := rest.Clone()
+		m.MultiSportSessions = append(m.MultiSportSessions, c.MultiSportSessions...)
+		m.Courses = append(m.Courses, c.Courses...)
+		m.Workouts = append(m.Workouts, c.Workouts...)
+		if m.Author == nil {
+			m.Author = c.Author
+		}
+	}
+	if len(files) > 0 {
+		m.XMLNs, m.XMLNsXsi, m.XMLNsXsd, m.XMLSchemaLoc = files[0].XMLNs, files[0].XMLNsXsi, files[0].XMLNsXsd, files[0].XMLSchemaLoc
+	}
+	if len(activities) > 0 {
+		a, err := MergeActivities(activities...)
+		if err != nil {
+			return nil, err
+		}
+		m.Activities = []Activity{*a}
+	}
+	return m, nil
+}
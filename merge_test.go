@@ -0,0 +1,94 @@
+package tcx
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeActivities(t *testing.T) {
+	full := testActivity(SportRunning, repeat(4.0, 601), repeat(150, 601))
+	full.Laps[0].update()
+	// The watch crashed after 5 minutes; the second file restarts 10 s
+	// before the end of the first one.
+	first := full.Crop(time.Time{}, full.ID.Add(300*time.Second))
+	second := full.Crop(full.ID.Add(290*time.Second), time.Time{})
+
+	m, err := MergeActivities(second, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Laps) != 2 || !m.ID.Equal(full.ID) {
+		t.Fatalf("merged %d laps, id %v", len(m.Laps), m.ID)
+	}
+	pts := m.trackpoints()
+	if len(pts) != 601 {
+		t.Errorf("merged %d trackpoints, want 601", len(pts))
+	}
+	for i := 1; i < len(pts); i++ {
+		if !pts[i].Time.After(pts[i-1].Time) {
+			t.Fatalf("trackpoint %d at %v out of order", i, pts[i].Time)
+		}
+	}
+	l := m.Laps[1]
+	if !l.StartTime.Equal(full.ID.Add(301*time.Second)) || l.TotalTimeInSeconds != 299 {
+		t.Errorf("second lap starts %v, lasts %v s", l.StartTime, l.TotalTimeInSeconds)
+	}
+	if d := m.TotalDistance() - full.TotalDistance(); math.Abs(d) > 5 {
+		t.Errorf("merged distance off by %v m", d)
+	}
+	if len(second.Laps[0].Track) != 311 {
+		t.Error("MergeActivities modified its input")
+	}
+
+	if _, err := MergeActivities(first, testActivity(SportBiking, repeat(8.0, 10), nil)); err == nil {
+		t.Error("merged activities of different sports")
+	}
+	if _, err := MergeActivities(); err == nil {
+		t.Error("merged no activity")
+	}
+}
+
+func TestMergeTreadmill(t *testing.T) {
+	db, err := ParseFile("testdata/treadmill.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &db.Activities[0]
+	m, err := MergeActivities(a.Crop(time.Time{}, a.ID.Add(400*time.Second)), a.Crop(a.ID.Add(200*time.Second), time.Time{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The 5 s between the last trackpoint of the first crop and the first
+	// one kept from the second belong to neither lap.
+	if d := m.TotalDistance(); d != 1800-15 {
+		t.Errorf("merged treadmill crops cover %v m, want 1785", d)
+	}
+}
+
+func TestMergeFiles(t *testing.T) {
+	a := testActivity(SportRunning, repeat(4.0, 101), nil)
+	f1 := &Tcx{XMLNs: TrainingCenterNS, Activities: []Activity{*a.Crop(time.Time{}, a.ID.Add(50*time.Second))}}
+	f2 := &Tcx{Activities: []Activity{*a.Crop(a.ID.Add(40*time.Second), time.Time{})}, Courses: []Course{{Name: "Loop", Track: []Trackpoint{{}}}},
+		Workouts: []Workout{{Name: "Intervals"}}, Author: &Author{Name: "Garmin Connect"}}
+	m, err := MergeFiles(f1, f2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Activities) != 1 || len(m.Activities[0].trackpoints()) != 101 || len(m.Courses) != 1 || m.XMLNs != TrainingCenterNS {
+		t.Errorf("merged file = %d activities, %d courses", len(m.Activities), len(m.Courses))
+	}
+	if len(m.Workouts) != 1 || m.Author == nil || m.Author.Name != "Garmin Connect" {
+		t.Errorf("merged file = %d workouts, author %+v", len(m.Workouts), m.Author)
+	}
+	m.Courses[0].Track[0].HeartRateInBpm = 150
+	m.Author.Name = "Merged"
+	if f2.Courses[0].Track[0].HeartRateInBpm != 0 || f2.Author.Name != "Garmin Connect" {
+		t.Error("the merged file shares memory with its sources")
+	}
+	var b strings.Builder
+	if err := m.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+}
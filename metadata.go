@@ -0,0 +1,129 @@
+package tcx
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MetadataNS is the namespace of the metadata element written in activity
+// extensions. The TCX schema accepts elements of other namespaces there.
+const MetadataNS = "https://github.com/rdifrango/go-tcx/metadata/v1"
+
+// ActivityExtensions holds the activity-level extensions.
+type ActivityExtensions struct {
+	Metadata *Metadata `xml:"https://github.com/rdifrango/go-tcx/metadata/v1 Metadata" json:"metadata,omitempty"`
+	// Other holds the extension elements of other namespaces.
+	Other []ExtensionElement `xml:",any" json:"-"`
+}
+
+// Metadata holds the user's own tags, notes and rating of an activity.
+type Metadata struct {
+	Tags  []string `xml:"Tag" json:"tags,omitempty"`
+	Notes string   `xml:"Notes,omitempty" json:"notes,omitempty"`
+	// Rating runs from 1 to 5; zero is unrated.
+	Rating int `xml:"Rating,omitempty" json:"rating,omitempty"`
+}
+
+// empty reports whether m holds nothing.
+func (m Metadata) empty() bool {
+	return len(m.Tags) == 0 && m.Notes == "" && m.Rating == 0
+}
+
+// Metadata returns the metadata of the activity, empty when it has none.
+func (a *Activity) Metadata() Metadata {
+	if a.Extensions == nil || a.Extensions.Metadata == nil {
+		return Metadata{}
+	}
+	return *a.Extensions.Metadata
+}
+
+// SetMetadata replaces the metadata of the activity, stored in its
+// extensions. Empty metadata removes the element.
+func (a *Activity) SetMetadata(m Metadata) {
+	if m.empty() {
+		if a.Extensions != nil {
+			a.Extensions.Metadata = nil
+		}
+		return
+	}
+	if a.Extensions == nil {
+		a.Extensions = &ActivityExtensions{}
+	}
+	a.Extensions.Metadata = &m
+}
+
+// HasTag reports whether the activity is tagged with tag, ignoring case.
+func (a *Activity) HasTag(tag string) bool {
+	for _, t := range a.Metadata().Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Tag adds tags to the activity, skipping those it already has.
+func (a *Activity) Tag(tags ...string) {
+	for _, t := range tags {
+		if a.HasTag(t) {
+			continue
+		}
+		m := a.Metadata()
+		m.Tags = append(append([]string(nil), m.Tags...), t)
+		a.SetMetadata(m)
+	}
+}
+
+// SidecarPath returns the path of the JSON sidecar of the activity file at
+// path, such as run.tcx.json for run.tcx.
+func SidecarPath(path string) string {
+	return path + ".json"
+}
+
+// ReadSidecar reads the metadata in the JSON sidecar of the activity file
+// at path. It returns nil when there is no sidecar.
+func ReadSidecar(path string) (*Metadata, error) {
+	data, err := os.ReadFile(SidecarPath(path))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Metadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("couldn't parse sidecar %s: %v", SidecarPath(path), err)
+	}
+	return &m, nil
+}
+
+// WriteSidecar writes m to the JSON sidecar of the activity file at path,
+// leaving the activity file untouched.
+func WriteSidecar(path string, m Metadata) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(SidecarPath(path), append(data, '\n'), 0o644)
+}
+
+// FilterTagged returns the activities carrying every one of tags and rated
+// at least minRating.
+func FilterTagged(activities []*Activity, minRating int, tags ...string) []*Activity {
+	var matched []*Activity
+	for _, a := range activities {
+		if a.Metadata().Rating < minRating {
+			continue
+		}
+		ok := true
+		for _, t := range tags {
+			ok = ok && a.HasTag(t)
+		}
+		if ok {
+			matched = append(matched, a)
+		}
+	}
+	return matched
+}
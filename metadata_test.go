@@ -0,0 +1,87 @@
+package tcx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMetadataExtensions(t *testing.T) {
+	a := testActivity(SportRunning, repeat(3.0, 10), nil)
+	a.Tag("race", "Tempo", "RACE")
+	m := a.Metadata()
+	m.Notes, m.Rating = "windy", 4
+	a.SetMetadata(m)
+
+	db := NewTcx()
+	db.XMLNs = TrainingCenterNS
+	db.Activities = []Activity{*a}
+	var b bytes.Buffer
+	if err := xml.NewEncoder(&b).Encode(db); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `<Metadata xmlns="`+MetadataNS+`">`) {
+		t.Errorf("metadata not namespaced: %s", b.String()[:200])
+	}
+	back, err := Parse(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := back.Activities[0].Metadata()
+	want := Metadata{Tags: []string{"race", "Tempo"}, Notes: "windy", Rating: 4}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("metadata = %+v, want %+v", got, want)
+	}
+
+	frozen := back.Activities[0].Freeze().Thaw()
+	back.Activities[0].Extensions.Metadata.Tags[0] = "changed"
+	if frozen.Metadata().Tags[0] != "race" {
+		t.Error("frozen activity shares its tags")
+	}
+
+	a.SetMetadata(Metadata{})
+	if a.Extensions.Metadata != nil || a.HasTag("race") {
+		t.Error("empty metadata was kept")
+	}
+}
+
+func TestSidecar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.tcx")
+	if m, err := ReadSidecar(path); m != nil || err != nil {
+		t.Fatalf("ReadSidecar() without sidecar = %v, %v", m, err)
+	}
+	want := Metadata{Tags: []string{"long run"}, Rating: 5}
+	if err := WriteSidecar(path, want); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadSidecar(path)
+	if err != nil || !reflect.DeepEqual(*m, want) {
+		t.Errorf("ReadSidecar() = %+v, %v", m, err)
+	}
+}
+
+func TestFilterTagged(t *testing.T) {
+	var activities []*Activity
+	for _, m := range []Metadata{
+		{Tags: []string{"race", "10k"}, Rating: 5},
+		{Tags: []string{"race"}, Rating: 2},
+		{Tags: []string{"easy"}},
+		{},
+	} {
+		a := testActivity(SportRunning, repeat(3.0, 10), nil)
+		a.SetMetadata(m)
+		activities = append(activities, a)
+	}
+	if got := FilterTagged(activities, 0, "race"); len(got) != 2 {
+		t.Errorf("%d activities tagged race, want 2", len(got))
+	}
+	if got := FilterTagged(activities, 3, "Race"); len(got) != 1 || got[0] != activities[0] {
+		t.Errorf("FilterTagged() rated 3 = %v", got)
+	}
+	if got := FilterTagged(activities, 0); len(got) != 4 {
+		t.Errorf("FilterTagged() without criteria = %d activities", len(got))
+	}
+}
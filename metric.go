@@ -0,0 +1,185 @@
+package tcx
+
+import "math"
+
+// Metric identifies a trackpoint quantity for analyses working on any metric.
+type Metric int
+
+const (
+	MetricHeartRate Metric = iota // bpm
+	MetricSpeed                   // m/s
+	MetricPace                    // seconds per kilometer
+	MetricCadence                 // as recorded by the device
+	MetricAltitude                // meters
+	MetricGrade                   // percent
+	MetricPower                   // watts
+	MetricDistance                // meters
+)
+
+var metricNames = map[Metric]string{
+	MetricHeartRate: "heart rate",
+	MetricSpeed:     "speed",
+	MetricPace:      "pace",
+	MetricCadence:   "cadence",
+	MetricAltitude:  "altitude",
+	MetricGrade:     "grade",
+	MetricPower:     "power",
+	MetricDistance:  "distance",
+}
+
+func (m Metric) String() string {
+	return metricNames[m]
+}
+
+// gradeDistance is the minimum distance, in meters, over which grades are
+// measured to keep altitude noise in check.
+const gradeDistance = 20.0
+
+// interval is the span between two consecutive trackpoints, carrying the
+// values recorded at its start.
+type interval struct {
+	p     *Trackpoint
+	dt    float64 // seconds
+	dist  float64 // meters
+	grade float64 // percent
+}
+
+// segmentDistance returns the distance from p to q. It follows the
+// distances recorded at both ends, as footpods and treadmills measure them
+// best, then GPS positions when gps is set, or the speed recorded at p when
+// either end has no fix.
+func segmentDistance(p, q *Trackpoint, gps bool) float64 {
+	switch {
+	case p.HasDistance() && q.HasDistance() && q.DistanceInMeters >= p.DistanceInMeters:
+		return q.DistanceInMeters - p.DistanceInMeters
+	case gps && p.HasPosition() && q.HasPosition():
+		return distance(p.LatitudeInDegrees, p.LongitudeInDegrees, q.LatitudeInDegrees, q.LongitudeInDegrees)
+	}
+	return p.SpeedInMetersPerSec * max(q.Time.Sub(p.Time).Seconds(), 0)
+}
+
+// intervals splits the activity into the spans between consecutive
+// trackpoints, measured by segmentDistance with GPS positions unless the
+// activity is indoor.
+func (a *Activity) intervals() []interval {
+	pts := a.trackpoints()
+	if len(pts) < 2 {
+		return nil
+	}
+	gps := !a.IsIndoor()
+	ivs := make([]interval, 0, len(pts)-1)
+	cum := make([]float64, len(pts))
+	for i := 1; i < len(pts); i++ {
+		p, q := pts[i-1], pts[i]
+		iv := interval{p: p, dt: q.Time.Sub(p.Time).Seconds()}
+		if iv.dt < 0 {
+			iv.dt = 0
+		}
+		iv.dist = segmentDistance(p, q, gps)
+		cum[i] = cum[i-1] + iv.dist
+		ivs = append(ivs, iv)
+	}
+
+	// Interpolate altitudes over dropouts by distance, carrying the last one
+	// past the end, so that a missing altitude doesn't read as a drop to sea
+	// level.
+	alts := make([]float64, len(pts))
+	known := make([]bool, len(pts))
+	last := -1
+	for i, p := range pts {
+		if !p.HasAltitude() {
+			continue
+		}
+		alts[i], known[i] = p.AltitudeInMeters, true
+		for k := last + 1; last >= 0 && k < i; k++ {
+			f := 0.0
+			if span := cum[i] - cum[last]; span > 0 {
+				f = (cum[k] - cum[last]) / span
+			}
+			alts[k], known[k] = alts[last]+f*(alts[i]-alts[last]), true
+		}
+		last = i
+	}
+	for k := last + 1; last >= 0 && k < len(pts); k++ {
+		alts[k], known[k] = alts[last], true
+	}
+
+	// Measure each grade back to the first point at least gradeDistance
+	// meters behind.
+	j := 0
+	for i := 1; i < len(pts); i++ {
+		for j+1 < i && cum[i]-cum[j+1] >= gradeDistance {
+			j++
+		}
+		if d := cum[i] - cum[j]; d >= gradeDistance && known[i] && known[j] {
+			ivs[i-1].grade = 100 * (alts[i] - alts[j]) / d
+		}
+	}
+	return ivs
+}
+
+// speed returns the speed recorded at the start of the interval or, without
+// a reading, the average speed over it.
+func (iv interval) speed() float64 {
+	if iv.p.HasSpeed() || iv.dt == 0 {
+		return iv.p.SpeedInMetersPerSec
+	}
+	return iv.dist / iv.dt
+}
+
+// value returns the metric over the interval, or false when it was not
+// recorded.
+func (m Metric) value(iv interval) (float64, bool) {
+	switch m {
+	case MetricGrade:
+		return iv.grade, true
+	case MetricSpeed:
+		return iv.speed(), true
+	case MetricPace:
+		if v := iv.speed(); v > 0 {
+			return 1000 / v, true
+		}
+		return 0, false
+	}
+	return m.at(iv.p)
+}
+
+// at returns the metric recorded at p, or false when it was not recorded.
+// Grades span several trackpoints and are never recorded at one.
+func (m Metric) at(p *Trackpoint) (float64, bool) {
+	switch m {
+	case MetricHeartRate:
+		return float64(p.HeartRateInBpm), p.HasHeartRate()
+	case MetricSpeed:
+		return p.SpeedInMetersPerSec, true
+	case MetricPace:
+		if p.SpeedInMetersPerSec <= 0 {
+			return 0, false
+		}
+		return 1000 / p.SpeedInMetersPerSec, true
+	case MetricCadence:
+		return float64(p.Cadence), p.HasCadence()
+	case MetricAltitude:
+		return p.AltitudeInMeters, p.HasAltitude()
+	case MetricPower:
+		return float64(p.PowerInWatts), p.HasPower()
+	}
+	return 0, false
+}
+
+// set records v as the metric at p. Pace and grade are derived metrics and
+// can't be set.
+func (m Metric) set(p *Trackpoint, v float64) {
+	switch m {
+	case MetricHeartRate:
+		p.SetHeartRate(int(math.Round(v)))
+	case MetricSpeed:
+		p.SpeedInMetersPerSec = v
+	case MetricCadence:
+		p.SetCadence(int(math.Round(v)))
+	case MetricAltitude:
+		p.SetAltitude(v)
+	case MetricPower:
+		p.SetPower(int(math.Round(v)))
+	}
+}
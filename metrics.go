@@ -0,0 +1,105 @@
+package tcx
+
+import (
+	"expvar"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ParseStats describes one call to Parse.
+type ParseStats struct {
+	Bytes       int64
+	Trackpoints int
+	Duration    time.Duration
+	// Recoverable counts the problems skipped over while parsing.
+	Recoverable int
+	// Err is the error returned by Parse, if any.
+	Err error
+}
+
+// ParseObserver receives the statistics of every parse, for services that
+// export them to their own metrics system.
+type ParseObserver interface {
+	ObserveParse(s ParseStats)
+}
+
+// ParserMetrics are the totals of every parse since the process started.
+type ParserMetrics struct {
+	FilesParsed       int64
+	Failures          int64
+	Bytes             int64
+	Trackpoints       int64
+	RecoverableErrors int64
+	ParseTime         time.Duration
+	LastParseTime     time.Duration
+}
+
+var (
+	metrics struct {
+		files, failures, bytes, trackpoints, recoverable, nanos, last atomic.Int64
+	}
+	observerMu sync.RWMutex
+	observer   ParseObserver
+)
+
+// SetParseObserver registers o to receive the statistics of every parse. A
+// nil observer removes the current one.
+func SetParseObserver(o ParseObserver) {
+	observerMu.Lock()
+	observer = o
+	observerMu.Unlock()
+}
+
+// Metrics returns the parser totals.
+func Metrics() ParserMetrics {
+	return ParserMetrics{
+		FilesParsed:       metrics.files.Load(),
+		Failures:          metrics.failures.Load(),
+		Bytes:             metrics.bytes.Load(),
+		Trackpoints:       metrics.trackpoints.Load(),
+		RecoverableErrors: metrics.recoverable.Load(),
+		ParseTime:         time.Duration(metrics.nanos.Load()),
+		LastParseTime:     time.Duration(metrics.last.Load()),
+	}
+}
+
+// PublishExpvar publishes the parser totals as the expvar variable name,
+// served on /debug/vars. Like expvar.Publish, it panics if name is taken.
+func PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return Metrics() }))
+}
+
+// observeParse adds s to the totals and hands it to the observer.
+func observeParse(s ParseStats) {
+	if s.Err != nil {
+		metrics.failures.Add(1)
+	} else {
+		metrics.files.Add(1)
+	}
+	metrics.bytes.Add(s.Bytes)
+	metrics.trackpoints.Add(int64(s.Trackpoints))
+	metrics.recoverable.Add(int64(s.Recoverable))
+	metrics.nanos.Add(int64(s.Duration))
+	metrics.last.Store(int64(s.Duration))
+
+	observerMu.RLock()
+	o := observer
+	observerMu.RUnlock()
+	if o != nil {
+		o.ObserveParse(s)
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
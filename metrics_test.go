@@ -0,0 +1,56 @@
+package tcx
+
+import (
+	"expvar"
+	"os"
+	"strings"
+	"testing"
+)
+
+type recordingObserver struct {
+	stats []ParseStats
+}
+
+func (o *recordingObserver) ObserveParse(s ParseStats) {
+	o.stats = append(o.stats, s)
+}
+
+func TestParseMetrics(t *testing.T) {
+	o := &recordingObserver{}
+	SetParseObserver(o)
+	defer SetParseObserver(nil)
+
+	before := Metrics()
+	if _, err := ParseFile("testdata/test1.tcx"); err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	if _, err := Parse(strings.NewReader("<TrainingCenterDatabase>")); err == nil {
+		t.Fatal("expected an error for truncated data")
+	}
+	after := Metrics()
+
+	info, err := os.Stat("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(o.stats) != 2 {
+		t.Fatalf("observer saw %d parses, want 2", len(o.stats))
+	}
+	if s := o.stats[0]; s.Bytes != info.Size() || s.Trackpoints != 1937 || s.Err != nil {
+		t.Errorf("first parse stats = %+v", s)
+	}
+	if o.stats[1].Err == nil {
+		t.Error("second parse should report its error")
+	}
+	if after.FilesParsed-before.FilesParsed != 1 || after.Failures-before.Failures != 1 {
+		t.Errorf("got %d files and %d failures", after.FilesParsed-before.FilesParsed, after.Failures-before.Failures)
+	}
+	if after.Trackpoints-before.Trackpoints != 1937 || after.ParseTime <= before.ParseTime {
+		t.Errorf("totals did not grow: %+v", after)
+	}
+
+	PublishExpvar("tcx_test_parser")
+	if v := expvar.Get("tcx_test_parser"); v == nil || !strings.Contains(v.String(), `"FilesParsed"`) {
+		t.Errorf("expvar not published: %v", v)
+	}
+}
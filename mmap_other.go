@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package tcx
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// mmapFile is not supported on this platform; LazyFile falls back to
+// reading the file through its ReaderAt.
+func mmapFile(f *os.File, size int64) (io.ReaderAt, func() error, error) {
+	return nil, nil, errors.New("memory mapping not supported")
+}
@@ -0,0 +1,36 @@
+package tcx
+
+import "time"
+
+// MovingOptions configures the pause detection of MovingTime.
+type MovingOptions struct {
+	// MinSpeed is the speed, in m/s, under which the athlete counts as
+	// stopped.
+	MinSpeed float64
+	// MaxGap is the longest gap between trackpoints still counted as
+	// moving; longer gaps are pauses of the recording.
+	MaxGap time.Duration
+}
+
+// DefaultMovingOptions counts walking pace and above as moving, and gaps
+// over 10 s, as left by device auto-pause, as stopped.
+var DefaultMovingOptions = MovingOptions{
+	MinSpeed: inferMovingSpeed,
+	MaxGap:   10 * time.Second,
+}
+
+// MovingTime returns the time the activity spent in motion: TotalDuration
+// less the stops and the recording pauses detected with opts. Speeds come
+// from the speed sensor, or from GPS positions when it reads zero.
+func (a *Activity) MovingTime(opts MovingOptions) time.Duration {
+	var moving float64
+	for _, iv := range a.intervals() {
+		if iv.dt == 0 || iv.dt > opts.MaxGap.Seconds() {
+			continue
+		}
+		if iv.speed() >= opts.MinSpeed {
+			moving += iv.dt
+		}
+	}
+	return time.Duration(moving * float64(time.Second))
+}
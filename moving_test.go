@@ -0,0 +1,32 @@
+package tcx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMovingTime(t *testing.T) {
+	// 300 s running, a 60 s stop with the watch running, then an
+	// auto-paused 2 minutes before 100 s more running.
+	speeds := append(repeat(3.0, 300), repeat(0.0, 60)...)
+	a := testActivity(SportRunning, append(speeds, repeat(3.0, 101)...), nil)
+	track := a.Laps[0].Track
+	for i := 360; i < len(track); i++ {
+		track[i].Time = track[i].Time.Add(2 * time.Minute)
+	}
+	if got := a.MovingTime(DefaultMovingOptions); got != 400*time.Second {
+		t.Errorf("MovingTime() = %v, want 6m40s", got)
+	}
+	if got := a.MovingTime(MovingOptions{MinSpeed: 0, MaxGap: time.Hour}); got != 580*time.Second {
+		t.Errorf("MovingTime() without pause detection = %v, want 9m40s", got)
+	}
+
+	// Without a speed sensor, speeds come from positions, which lag the
+	// sensor by a second in testActivity.
+	for i := range track {
+		track[i].SpeedInMetersPerSec = 0
+	}
+	if got := a.MovingTime(DefaultMovingOptions); got != 399*time.Second {
+		t.Errorf("MovingTime() from GPS = %v, want 6m39s", got)
+	}
+}
@@ -0,0 +1,137 @@
+package tcx
+
+import "time"
+
+// MultiSportSession is a multisport activity, such as a triathlon: a first
+// activity followed by further sports, each possibly preceded by a
+// transition.
+type MultiSportSession struct {
+	ID         time.Time   `xml:"Id" json:"id"`
+	FirstSport Activity    `xml:"FirstSport>Activity" json:"firstSport"`
+	NextSports []NextSport `xml:"NextSport" json:"nextSports,omitempty"`
+	Notes      string      `xml:"Notes,omitempty" json:"notes,omitempty"`
+}
+
+// NextSport is a leg of a multisport session after the first one.
+type NextSport struct {
+	// Transition is the time spent changing over before the leg, such as
+	// T1 or T2 in a triathlon, when recorded.
+	Transition *Lap     `xml:"Transition" json:"transition,omitempty"`
+	Activity   Activity `xml:"Activity" json:"activity"`
+}
+
+// Legs returns the activities of the session in order.
+func (s *MultiSportSession) Legs() []*Activity {
+	legs := []*Activity{&s.FirstSport}
+	for i := range s.NextSports {
+		legs = append(legs, &s.NextSports[i].Activity)
+	}
+	return legs
+}
+
+// AllActivities returns the activities of t followed by the legs of its
+// multisport sessions, so that code handling plain activities sees every
+// activity of the file.
+func (t *Tcx) AllActivities() []*Activity {
+	var all []*Activity
+	for i := range t.Activities {
+		all = append(all, &t.Activities[i])
+	}
+	for i := range t.MultiSportSessions {
+		all = append(all, t.MultiSportSessions[i].Legs()...)
+	}
+	return all
+}
+
+// LegReport holds the results of one leg of a multisport session. Only the
+// metrics of the leg's sport are set.
+type LegReport struct {
+	Sport    string
+	Start    time.Time
+	Duration time.Duration
+	Distance float64 // meters
+	// Split is the race time at the end of the leg, transitions included.
+	Split time.Duration
+
+	// SwimPace is the pace per 100 m of swim legs, rest excluded.
+	SwimPace SwimPace
+	// AveragePower, in watts, and AverageSpeed, in m/s, of bike legs.
+	AveragePower float64
+	AverageSpeed float64
+	// RunPace is the time per kilometer of run legs.
+	RunPace time.Duration
+}
+
+// RaceReport aggregates the legs and transitions of a multisport session.
+type RaceReport struct {
+	Legs []LegReport
+	// Transitions holds the duration of the transition before each leg
+	// after the first, zero when none was recorded.
+	Transitions []time.Duration
+	Total       time.Duration
+}
+
+// legSport returns the sport of a leg. Multisport files record swims as
+// Other, so legs that are neither run nor bike count as swims unless their
+// speeds say otherwise.
+func legSport(a *Activity) string {
+	if a.Sport == SportRunning || a.Sport == SportBiking {
+		return a.Sport
+	}
+	if s := a.InferSport(); s != "" && s != SportSwimming {
+		return s
+	}
+	return SportSwimming
+}
+
+// Report builds the race report of the session: the splits and sport
+// specific metrics of every leg, the transition times and the overall time.
+func (s *MultiSportSession) Report() RaceReport {
+	var r RaceReport
+	for i, a := range s.Legs() {
+		if i > 0 {
+			var t time.Duration
+			if tr := s.NextSports[i-1].Transition; tr != nil {
+				t = time.Duration(tr.TotalTimeInSeconds * float64(time.Second))
+			}
+			r.Transitions = append(r.Transitions, t)
+			r.Total += t
+		}
+		leg := LegReport{
+			Sport:    legSport(a),
+			Start:    a.ID,
+			Duration: a.TotalDuration(),
+			Distance: a.TotalDistance(),
+		}
+		if len(a.Laps) > 0 && leg.Start.IsZero() {
+			leg.Start = a.Laps[0].StartTime
+		}
+		r.Total += leg.Duration
+		leg.Split = r.Total
+
+		switch leg.Sport {
+		case SportSwimming:
+			leg.SwimPace = a.SwimPaces(SwimMeters).Pace
+		case SportBiking:
+			if leg.Duration > 0 {
+				leg.AverageSpeed = leg.Distance / leg.Duration.Seconds()
+			}
+			var watts, n float64
+			for _, p := range a.perSecond() {
+				if p.PowerInWatts > 0 {
+					watts += float64(p.PowerInWatts)
+					n++
+				}
+			}
+			if n > 0 {
+				leg.AveragePower = watts / n
+			}
+		default:
+			if leg.Distance > 0 {
+				leg.RunPace = time.Duration(float64(leg.Duration) * 1000 / leg.Distance)
+			}
+		}
+		r.Legs = append(r.Legs, leg)
+	}
+	return r
+}
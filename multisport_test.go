@@ -0,0 +1,122 @@
+package tcx
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const multisportTCX = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+  <Activities>
+    <MultiSportSession>
+      <Id>2015-04-12T07:00:00Z</Id>
+      <FirstSport>
+        <Activity Sport="Other">
+          <Id>2015-04-12T07:00:00Z</Id>
+          <Lap StartTime="2015-04-12T07:00:00Z">
+            <TotalTimeSeconds>1800</TotalTimeSeconds>
+            <DistanceMeters>1500</DistanceMeters>
+            <Intensity>Active</Intensity>
+            <TriggerMethod>Manual</TriggerMethod>
+          </Lap>
+        </Activity>
+      </FirstSport>
+      <NextSport>
+        <Transition StartTime="2015-04-12T07:30:00Z">
+          <TotalTimeSeconds>120</TotalTimeSeconds>
+          <DistanceMeters>0</DistanceMeters>
+          <Intensity>Active</Intensity>
+          <TriggerMethod>Manual</TriggerMethod>
+        </Transition>
+        <Activity Sport="Biking">
+          <Id>2015-04-12T07:32:00Z</Id>
+          <Lap StartTime="2015-04-12T07:32:00Z">
+            <TotalTimeSeconds>3600</TotalTimeSeconds>
+            <DistanceMeters>36000</DistanceMeters>
+            <Intensity>Active</Intensity>
+            <TriggerMethod>Manual</TriggerMethod>
+          </Lap>
+        </Activity>
+      </NextSport>
+      <Notes>Sprint distance</Notes>
+    </MultiSportSession>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestMultiSportReport(t *testing.T) {
+	db, err := Parse(strings.NewReader(multisportTCX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Activities) != 0 || len(db.MultiSportSessions) != 1 {
+		t.Fatalf("parsed %d activities, %d sessions", len(db.Activities), len(db.MultiSportSessions))
+	}
+	s := &db.MultiSportSessions[0]
+	if got := len(s.Legs()); got != 2 {
+		t.Fatalf("%d legs, want 2", got)
+	}
+
+	run := testActivity(SportRunning, repeat(4.0, 1201), nil)
+	run.ID = time.Date(2015, 4, 12, 8, 33, 0, 0, time.UTC)
+	for i := range run.Laps[0].Track {
+		run.Laps[0].Track[i].Time = run.ID.Add(time.Duration(i) * time.Second)
+	}
+	s.NextSports = append(s.NextSports, NextSport{Transition: &Lap{TotalTimeInSeconds: 60}, Activity: *run})
+
+	r := s.Report()
+	if len(r.Legs) != 3 || len(r.Transitions) != 2 {
+		t.Fatalf("report = %+v", r)
+	}
+	if r.Legs[0].Sport != SportSwimming || r.Legs[0].SwimPace.String() != "2:00" {
+		t.Errorf("swim leg = %+v", r.Legs[0])
+	}
+	if r.Legs[1].Sport != SportBiking || r.Legs[1].AverageSpeed != 10 {
+		t.Errorf("bike leg = %+v", r.Legs[1])
+	}
+	if r.Legs[2].RunPace != 250*time.Second {
+		t.Errorf("run pace = %v, want 4m10s", r.Legs[2].RunPace)
+	}
+	if r.Transitions[0] != 2*time.Minute || r.Transitions[1] != time.Minute {
+		t.Errorf("transitions = %v", r.Transitions)
+	}
+	if r.Legs[1].Split != 5520*time.Second || r.Total != 6780*time.Second {
+		t.Errorf("bike split %v, total %v", r.Legs[1].Split, r.Total)
+	}
+	if math.Abs(r.Legs[2].Distance-4800) > 1e-9 {
+		t.Errorf("run distance = %v", r.Legs[2].Distance)
+	}
+}
+
+func TestMultiSportSessions(t *testing.T) {
+	db, err := Parse(strings.NewReader(multisportTCX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Activities = append(db.Activities, *testActivity(SportRunning, repeat(3.0, 10), nil))
+	all := db.AllActivities()
+	if len(all) != 3 || all[0].Sport != SportRunning || all[1].Sport != SportOther || all[2].Sport != SportBiking {
+		t.Fatalf("AllActivities() = %d activities", len(all))
+	}
+	if all[2] != &db.MultiSportSessions[0].NextSports[0].Activity {
+		t.Error("AllActivities() copied the legs")
+	}
+	if n := db.MultiSportSessions[0].Notes; n != "Sprint distance" {
+		t.Errorf("notes = %q", n)
+	}
+
+	var b bytes.Buffer
+	if err := db.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	back, err := Parse(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.MultiSportSessions, db.MultiSportSessions) {
+		t.Errorf("sessions changed through Write:\n%s", b.String())
+	}
+}
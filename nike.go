@@ -0,0 +1,184 @@
+package tcx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// nikeActivity is an activity of the Nike Run Club API and data export.
+type nikeActivity struct {
+	Type             string `json:"type"`
+	StartEpochMs     int64  `json:"start_epoch_ms"`
+	EndEpochMs       int64  `json:"end_epoch_ms"`
+	ActiveDurationMs int64  `json:"active_duration_ms"`
+	Summaries        []struct {
+		Metric  string  `json:"metric"`
+		Summary string  `json:"summary"`
+		Value   float64 `json:"value"`
+	} `json:"summaries"`
+	Metrics []struct {
+		Type   string        `json:"type"`
+		Unit   string        `json:"unit"`
+		Values []nikeSamples `json:"values"`
+	} `json:"metrics"`
+	Moments []struct {
+		Key       string `json:"key"`
+		Value     string `json:"value"`
+		Timestamp int64  `json:"timestamp"`
+	} `json:"moments"`
+}
+
+type nikeSamples struct {
+	StartEpochMs int64   `json:"start_epoch_ms"`
+	EndEpochMs   int64   `json:"end_epoch_ms"`
+	Value        float64 `json:"value"`
+}
+
+func epochMs(ms int64) time.Time {
+	return time.UnixMilli(ms).UTC()
+}
+
+// ParseNikeRunClub reads a Nike Run Club activity into a Tcx. Trackpoints
+// are placed at each GPS sample, carrying the latest elevation and heart
+// rate, and pauses recorded as moments split the activity into laps.
+func ParseNikeRunClub(r io.Reader) (*Tcx, error) {
+	var na nikeActivity
+	if err := json.NewDecoder(r).Decode(&na); err != nil {
+		return nil, fmt.Errorf("couldn't parse nike data: %v", err)
+	}
+	if na.StartEpochMs == 0 {
+		return nil, fmt.Errorf("couldn't parse nike data: missing start time")
+	}
+
+	a := Activity{Sport: SportOther, ID: epochMs(na.StartEpochMs)}
+	switch strings.ToLower(na.Type) {
+	case "run", "jogging":
+		a.Sport = SportRunning
+	case "cycling", "cycle":
+		a.Sport = SportBiking
+	case "walk", "walking":
+		a.Sport = SportWalking
+	}
+
+	metrics := make(map[string][]nikeSamples)
+	for _, m := range na.Metrics {
+		v := m.Values
+		sort.Slice(v, func(i, j int) bool { return v[i].StartEpochMs < v[j].StartEpochMs })
+		metrics[strings.ToLower(m.Type)] = v
+	}
+	// latest returns the last sample of a metric started at or before ms.
+	latest := func(name string, ms int64) float64 {
+		v := metrics[name]
+		i := sort.Search(len(v), func(i int) bool { return v[i].StartEpochMs > ms })
+		if i == 0 {
+			return 0
+		}
+		return v[i-1].Value
+	}
+
+	// Distance samples are increments in kilometers.
+	var dts, dvs []float64
+	var total float64
+	for _, s := range metrics["distance"] {
+		dts, dvs = append(dts, float64(s.StartEpochMs)), append(dvs, total)
+		total += s.Value * 1000
+		dts, dvs = append(dts, float64(s.EndEpochMs)), append(dvs, total)
+	}
+	distanceAt := func(ms int64) float64 {
+		if len(dts) == 0 {
+			return 0
+		}
+		return interpolate(dts, dvs, float64(ms))
+	}
+
+	var track []Trackpoint
+	lons := metrics["longitude"]
+	for _, lat := range metrics["latitude"] {
+		ms := lat.StartEpochMs
+		i := sort.Search(len(lons), func(i int) bool { return lons[i].StartEpochMs >= ms })
+		if i == len(lons) || lons[i].StartEpochMs != ms {
+			continue
+		}
+		track = append(track, Trackpoint{
+			Time:               epochMs(ms),
+			LatitudeInDegrees:  lat.Value,
+			LongitudeInDegrees: lons[i].Value,
+			AltitudeInMeters:   latest("elevation", ms),
+			HeartRateInBpm:     int(latest("heart_rate", ms) + 0.5),
+		})
+	}
+
+	// Lap boundaries are the start, each pause/resume pair and the end.
+	type span struct{ from, to int64 }
+	spans := []span{{from: na.StartEpochMs}}
+	for _, m := range na.Moments {
+		if m.Key != "halt" {
+			continue
+		}
+		switch m.Value {
+		case "pause":
+			spans[len(spans)-1].to = m.Timestamp
+		case "resume":
+			spans = append(spans, span{from: m.Timestamp})
+		}
+	}
+	end := na.EndEpochMs
+	if end == 0 && len(track) > 0 {
+		end = track[len(track)-1].Time.UnixMilli()
+	}
+	if spans[len(spans)-1].to == 0 {
+		spans[len(spans)-1].to = end
+	}
+
+	var calories float64
+	for _, s := range metrics["calories"] {
+		calories += s.Value
+	}
+	var elapsed int64
+	for _, s := range spans {
+		elapsed += s.to - s.from
+	}
+
+	j := 0
+	for _, s := range spans {
+		lap := Lap{
+			StartTime:          epochMs(s.from),
+			TotalTimeInSeconds: float64(s.to-s.from) / 1000,
+			DistanceInMeters:   distanceAt(s.to) - distanceAt(s.from),
+			Intensity:          "Active",
+			TriggerMethod:      "Manual",
+		}
+		if elapsed > 0 {
+			lap.Calories = calories * float64(s.to-s.from) / float64(elapsed)
+		}
+		for j < len(track) && track[j].Time.UnixMilli() < s.from {
+			j++
+		}
+		from := j
+		for j < len(track) && track[j].Time.UnixMilli() <= s.to {
+			j++
+		}
+		lap.Track = track[from:j]
+		a.Laps = append(a.Laps, lap)
+	}
+
+	// Fall back on the summary distance when no distance metric was sent.
+	if len(dts) == 0 {
+		for _, s := range na.Summaries {
+			if s.Metric == "distance" && s.Summary == "total" && elapsed > 0 {
+				for i := range a.Laps {
+					a.Laps[i].DistanceInMeters = s.Value * 1000 * a.Laps[i].TotalTimeInSeconds * 1000 / float64(elapsed)
+				}
+			}
+		}
+	}
+
+	t := NewTcx()
+	t.XMLNs = TrainingCenterNS
+	t.Activities = []Activity{a}
+	return t, nil
+}
@@ -0,0 +1,61 @@
+package tcx
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testNike = `{
+  "type": "run",
+  "start_epoch_ms": 1500000000000,
+  "end_epoch_ms": 1500000090000,
+  "metrics": [
+    {"type": "latitude", "unit": "DEG", "values": [
+      {"start_epoch_ms": 1500000000000, "end_epoch_ms": 1500000000000, "value": 45.0},
+      {"start_epoch_ms": 1500000030000, "end_epoch_ms": 1500000030000, "value": 45.001},
+      {"start_epoch_ms": 1500000060000, "end_epoch_ms": 1500000060000, "value": 45.001},
+      {"start_epoch_ms": 1500000090000, "end_epoch_ms": 1500000090000, "value": 45.002}]},
+    {"type": "longitude", "unit": "DEG", "values": [
+      {"start_epoch_ms": 1500000000000, "end_epoch_ms": 1500000000000, "value": 7.0},
+      {"start_epoch_ms": 1500000030000, "end_epoch_ms": 1500000030000, "value": 7.0},
+      {"start_epoch_ms": 1500000060000, "end_epoch_ms": 1500000060000, "value": 7.0},
+      {"start_epoch_ms": 1500000090000, "end_epoch_ms": 1500000090000, "value": 7.0}]},
+    {"type": "heart_rate", "unit": "BPM", "values": [
+      {"start_epoch_ms": 1500000000000, "end_epoch_ms": 1500000000000, "value": 120},
+      {"start_epoch_ms": 1500000060000, "end_epoch_ms": 1500000060000, "value": 150}]},
+    {"type": "distance", "unit": "KM", "values": [
+      {"start_epoch_ms": 1500000000000, "end_epoch_ms": 1500000030000, "value": 0.11},
+      {"start_epoch_ms": 1500000060000, "end_epoch_ms": 1500000090000, "value": 0.11}]},
+    {"type": "calories", "unit": "KCAL", "values": [
+      {"start_epoch_ms": 1500000000000, "end_epoch_ms": 1500000090000, "value": 9}]}
+  ],
+  "moments": [
+    {"key": "halt", "value": "pause", "timestamp": 1500000030000},
+    {"key": "halt", "value": "resume", "timestamp": 1500000060000}
+  ]
+}`
+
+func TestParseNikeRunClub(t *testing.T) {
+	tcx, err := ParseNikeRunClub(strings.NewReader(testNike))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := tcx.Activities[0]
+	if a.Sport != SportRunning || len(a.Laps) != 2 {
+		t.Fatalf("got %s activity with %d laps", a.Sport, len(a.Laps))
+	}
+	if a.TotalDuration() != time.Minute {
+		t.Errorf("got duration %v, want 1m", a.TotalDuration())
+	}
+	if math.Abs(a.TotalDistance()-220) > 1e-6 || math.Abs(a.Laps[1].Calories-4.5) > 1e-9 {
+		t.Errorf("got %v m and %v kcal", a.TotalDistance(), a.Laps[1].Calories)
+	}
+	if len(a.Laps[0].Track) != 2 || len(a.Laps[1].Track) != 2 {
+		t.Fatalf("laps hold %d and %d trackpoints", len(a.Laps[0].Track), len(a.Laps[1].Track))
+	}
+	if hr := a.Laps[1].Track[0].HeartRateInBpm; hr != 150 {
+		t.Errorf("heart rate after resume = %d, want 150", hr)
+	}
+}
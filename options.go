@@ -0,0 +1,412 @@
+package tcx
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Errors returned when a parse exceeds its limits.
+var (
+	ErrInputTooLarge      = errors.New("input exceeds the size limit")
+	ErrTooManyTrackpoints = errors.New("input exceeds the trackpoint limit")
+)
+
+// Option configures a parse.
+type Option func(*parseConfig)
+
+type parseConfig struct {
+	maxBytes       int64
+	maxTrackpoints int
+	mode           parseMode
+	bestEffort     bool
+}
+
+// parseMode selects how a parse handles documents straying from the schema.
+type parseMode int
+
+const (
+	modeDefault parseMode = iota
+	modeStrict
+	modeLenient
+)
+
+// WithStrict fails the parse of documents outside the TCX namespace or with
+// any problem reported by Validate.
+func WithStrict() Option {
+	return func(c *parseConfig) { c.mode = modeStrict }
+}
+
+// WithLenient recovers what it can from quirky documents: it accepts a
+// missing namespace and malformed XML such as unknown entities, reads
+// timestamps without a time zone as UTC, and drops out-of-range positions
+// and negative readings. Every correction is recorded in the Warnings of
+// the result.
+func WithLenient() Option {
+	return func(c *parseConfig) { c.mode = modeLenient }
+}
+
+// WithMaxBytes limits the input to n bytes, both before and after
+// decompression, so that compressed bombs can't exhaust memory; larger
+// inputs fail with ErrInputTooLarge.
+func WithMaxBytes(n int64) Option {
+	return func(c *parseConfig) { c.maxBytes = n }
+}
+
+// WithMaxTrackpoints limits the input to n trackpoints; inputs holding more
+// fail with ErrTooManyTrackpoints.
+func WithMaxTrackpoints(n int) Option {
+	return func(c *parseConfig) { c.maxTrackpoints = n }
+}
+
+// limitedReader fails with ErrInputTooLarge once more than n bytes are read.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, ErrInputTooLarge
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	if l.n -= int64(n); l.n < 0 {
+		return n, ErrInputTooLarge
+	}
+	return n, err
+}
+
+// guardedTokens passes on the tokens of d while checking the context,
+// counting trackpoints and checking timestamps and positions. When
+// lenient, it also adds a zone to timestamps lacking one. When best
+// effort, it drops broken values and closes the open elements of
+// documents ending early, collecting the errors met.
+type guardedTokens struct {
+	ctx            context.Context
+	d              *xml.Decoder
+	maxTrackpoints int
+	trackpoints    int
+
+	lenient    bool
+	bestEffort bool
+	elements   []xml.Name
+	warnings   []Warning
+	errs       []error
+	// activity, lap and trackpoint count the elements met, in document
+	// order, for DecodeError.
+	activity, lap, trackpoint int
+	// pending holds the tokens of a checked element still to pass on.
+	pending []xml.Token
+	// stopped is set once the document ended early, when best effort.
+	stopped bool
+}
+
+func newGuardedTokens(ctx context.Context, d *xml.Decoder, cfg parseConfig) *guardedTokens {
+	return &guardedTokens{
+		ctx:            ctx,
+		d:              d,
+		maxTrackpoints: cfg.maxTrackpoints,
+		lenient:        cfg.mode == modeLenient,
+		bestEffort:     cfg.bestEffort,
+		activity:       -1,
+		lap:            -1,
+		trackpoint:     -1,
+	}
+}
+
+func (g *guardedTokens) Token() (xml.Token, error) {
+	if len(g.pending) > 0 {
+		tok := g.pending[0]
+		g.pending = g.pending[1:]
+		return tok, nil
+	}
+	tok, err := g.next()
+	start, ok := tok.(xml.StartElement)
+	if !ok || err != nil || len(g.elements) < 2 || !checkedElement(start, g.elements[len(g.elements)-2].Local) {
+		return tok, err
+	}
+	tokens := []xml.Token{start}
+	for depth := len(g.elements); len(g.elements) >= depth; {
+		if tok, err = g.next(); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, xml.CopyToken(tok))
+	}
+	if err := checkElement(tokens, g.lenient); err != nil {
+		if err = g.fail(err); err != nil {
+			return nil, err
+		}
+		return g.Token()
+	}
+	g.pending = tokens[1:]
+	return start, nil
+}
+
+// next returns the next token of d, closing the open elements once d fails
+// when best effort.
+func (g *guardedTokens) next() (xml.Token, error) {
+	if g.stopped {
+		return g.close()
+	}
+	if err := g.ctx.Err(); err != nil {
+		return g.stop(err)
+	}
+	tok, err := g.d.Token()
+	if err != nil {
+		if err == io.EOF && len(g.elements) == 0 {
+			return nil, err
+		}
+		return g.stop(err)
+	}
+	switch t := tok.(type) {
+	case xml.StartElement:
+		switch t.Name.Local {
+		case "Activity", "Run":
+			g.activity, g.lap, g.trackpoint = g.activity+1, -1, -1
+		case "Lap":
+			g.lap, g.trackpoint = g.lap+1, -1
+		case "Trackpoint":
+			if g.trackpoints++; g.maxTrackpoints > 0 && g.trackpoints > g.maxTrackpoints {
+				return g.stop(ErrTooManyTrackpoints)
+			}
+			g.trackpoint++
+		}
+		g.elements = append(g.elements, t.Name)
+		for i := 0; i < len(t.Attr); i++ {
+			if t.Attr[i].Name.Local != "StartTime" {
+				continue
+			}
+			if g.lenient {
+				t.Attr[i].Value = g.zoned(t.Attr[i].Value)
+			}
+			if _, err := time.Parse(time.RFC3339, t.Attr[i].Value); err != nil {
+				if err := g.fail(fmt.Errorf("%w %q", ErrMalformedTimestamp, t.Attr[i].Value)); err != nil {
+					return nil, err
+				}
+				t.Attr = slices.Delete(t.Attr, i, i+1)
+				i--
+			}
+		}
+		tok = t
+	case xml.EndElement:
+		if n := len(g.elements); n > 0 {
+			g.elements = g.elements[:n-1]
+		}
+	case xml.CharData:
+		if n := len(g.elements); g.lenient && n > 0 && (g.elements[n-1].Local == "Time" || g.elements[n-1].Local == "Id") {
+			if s, z := string(t), g.zoned(string(t)); z != s {
+				tok = xml.CharData(z)
+			}
+		}
+	}
+	return tok, nil
+}
+
+// stop ends the document on err: when best effort, it records err and
+// closes the open elements; otherwise it returns err, located when the
+// document is truncated.
+func (g *guardedTokens) stop(err error) (xml.Token, error) {
+	var syntax *xml.SyntaxError
+	if err == io.EOF || err == io.ErrUnexpectedEOF || errors.As(err, &syntax) && strings.Contains(syntax.Msg, "unexpected EOF") {
+		err = g.decodeError(fmt.Errorf("%w: %v", ErrTruncated, err))
+	}
+	if !g.bestEffort || len(g.elements) == 0 {
+		return nil, err
+	}
+	g.errs = append(g.errs, err)
+	g.stopped = true
+	return g.close()
+}
+
+// close returns the end of the innermost open element, then io.EOF.
+func (g *guardedTokens) close() (xml.Token, error) {
+	n := len(g.elements)
+	if n == 0 {
+		return nil, io.EOF
+	}
+	name := g.elements[n-1]
+	g.elements = g.elements[:n-1]
+	return xml.EndElement{Name: name}, nil
+}
+
+// fail records err, located, when best effort, or returns it.
+func (g *guardedTokens) fail(err error) error {
+	err = g.decodeError(err)
+	if !g.bestEffort {
+		return err
+	}
+	g.errs = append(g.errs, err)
+	return nil
+}
+
+// decodeError locates err at the current element.
+func (g *guardedTokens) decodeError(err error) *DecodeError {
+	line, _ := g.d.InputPos()
+	e := &DecodeError{Activity: -1, Lap: -1, Trackpoint: -1, Line: line, Err: err}
+	for _, name := range g.elements {
+		switch name.Local {
+		case "Activity", "Run":
+			e.Activity = g.activity
+		case "Lap":
+			e.Lap = g.lap
+		case "Trackpoint":
+			e.Trackpoint = g.trackpoint
+		}
+	}
+	return e
+}
+
+// zoned returns the timestamp s with a UTC zone when it has none.
+func (g *guardedTokens) zoned(s string) string {
+	v := strings.TrimSpace(s)
+	if _, err := time.Parse(time.RFC3339, v); err == nil {
+		return s
+	}
+	if _, err := time.Parse("2006-01-02T15:04:05.999999999", v); err != nil {
+		return s
+	}
+	line, _ := g.d.InputPos()
+	g.warnings = append(g.warnings, Warning{Path: fmt.Sprintf("line %d", line), Message: fmt.Sprintf("time %s without zone read as UTC", v)})
+	return v + "Z"
+}
+
+// ParseContext parses a TCX reader like Parse, stopping with the error of
+// ctx once it is done, within the limits set by opts.
+func ParseContext(ctx context.Context, r io.Reader, opts ...Option) (*Tcx, error) {
+	var cfg parseConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+	start := time.Now()
+	cr := &countingReader{r: r}
+	var in io.Reader = cr
+	if cfg.maxBytes > 0 {
+		in = &limitedReader{r: cr, n: cfg.maxBytes}
+	}
+	g := NewTcx()
+	dr, err := decompress(in)
+	if err == nil && cfg.maxBytes > 0 {
+		dr = &limitedReader{r: dr, n: cfg.maxBytes}
+	}
+	if err == nil {
+		d := xml.NewDecoder(dr)
+		tokens := newGuardedTokens(ctx, d, cfg)
+		if tokens.lenient {
+			d.Strict = false
+		}
+		err = xml.NewTokenDecoder(tokens).Decode(g)
+		var parse *time.ParseError
+		switch {
+		case err == io.EOF:
+			err = fmt.Errorf("%w: no root element", ErrNotTCX)
+		case errors.As(err, &parse):
+			err = tokens.decodeError(fmt.Errorf("%w: %v", ErrMalformedTimestamp, err))
+		}
+		if len(tokens.errs) > 0 {
+			err = errors.Join(append(tokens.errs, err)...)
+		}
+		g.Warnings = tokens.warnings
+	}
+	if err == nil {
+		err = g.checkMode(cfg.mode)
+	} else if cfg.bestEffort {
+		err = errors.Join(err, g.checkMode(cfg.mode))
+	}
+	stats := ParseStats{Bytes: cr.n, Err: err, Recoverable: len(g.Warnings)}
+	if err == nil {
+		for _, a := range g.AllActivities() {
+			for _, l := range a.Laps {
+				stats.Trackpoints += len(l.Track)
+			}
+		}
+		for _, c := range g.Courses {
+			stats.Trackpoints += len(c.Track)
+		}
+	}
+	stats.Duration = time.Since(start)
+	observeParse(stats)
+	if err != nil {
+		if cfg.bestEffort {
+			return g, fmt.Errorf("couldn't parse tcx data: %w", err)
+		}
+		return nil, fmt.Errorf("couldn't parse tcx data: %w", err)
+	}
+	return g, nil
+}
+
+// Warning is a problem recovered from by a lenient parse, located by a
+// line of the document or a path such as Activities[0].Laps[2].Track[15].
+type Warning struct {
+	Path    string
+	Message string
+}
+
+func (w Warning) String() string {
+	return w.Path + ": " + w.Message
+}
+
+// checkMode applies the parse mode to the decoded document: strict parses
+// fail on the first problem, lenient ones fix what they can.
+func (t *Tcx) checkMode(mode parseMode) error {
+	switch mode {
+	case modeStrict:
+		if t.XMLNs != TrainingCenterNS {
+			return fmt.Errorf("namespace %q is not %s", t.XMLNs, TrainingCenterNS)
+		}
+		if errs := t.Validate(); len(errs) > 0 {
+			return errs[0]
+		}
+	case modeLenient:
+		if t.XMLNs != TrainingCenterNS {
+			t.warn("TrainingCenterDatabase", "namespace %q is not %s", t.XMLNs, TrainingCenterNS)
+		}
+		for i := range t.Activities {
+			t.fixReadings(fmt.Sprintf("Activities[%d]", i), &t.Activities[i])
+		}
+		for i := range t.MultiSportSessions {
+			for j, a := range t.MultiSportSessions[i].Legs() {
+				t.fixReadings(fmt.Sprintf("MultiSportSessions[%d].Legs[%d]", i, j), a)
+			}
+		}
+	}
+	return nil
+}
+
+func (t *Tcx) warn(path, format string, args ...any) {
+	t.Warnings = append(t.Warnings, Warning{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// fixReadings drops the out-of-range positions and negative readings of
+// the trackpoints of a.
+func (t *Tcx) fixReadings(path string, a *Activity) {
+	for i := range a.Laps {
+		for j := range a.Laps[i].Track {
+			p := &a.Laps[i].Track[j]
+			pp := fmt.Sprintf("%s.Laps[%d].Track[%d]", path, i, j)
+			if p.LatitudeInDegrees < -90 || p.LatitudeInDegrees > 90 || p.LongitudeInDegrees < -180 || p.LongitudeInDegrees > 180 {
+				t.warn(pp, "position %v, %v out of range dropped", p.LatitudeInDegrees, p.LongitudeInDegrees)
+				p.ClearPosition()
+			}
+			if p.HeartRateInBpm < 0 {
+				t.warn(pp, "negative heart rate dropped")
+				p.ClearHeartRate()
+			}
+			if p.Cadence < 0 {
+				t.warn(pp, "negative cadence dropped")
+				p.ClearCadence()
+			}
+			if p.SpeedInMetersPerSec < 0 || p.PowerInWatts < 0 {
+				t.warn(pp, "negative speed or power dropped")
+				p.SpeedInMetersPerSec, p.PowerInWatts = max(p.SpeedInMetersPerSec, 0), max(p.PowerInWatts, 0)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package tcx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cancelingReader cancels its context once n bytes have been read.
+type cancelingReader struct {
+	r      io.Reader
+	n      int
+	cancel context.CancelFunc
+}
+
+func (c *cancelingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if c.n -= n; c.n <= 0 {
+		c.cancel()
+	}
+	return n, err
+}
+
+func TestParseContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := ParseContext(ctx, &cancelingReader{r: &endlessTrack{n: 1000000}, n: 1 << 20, cancel: cancel})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled parse = %v", err)
+	}
+
+	data, err := os.ReadFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	open := func() io.Reader { f, _ := os.Open("testdata/test1.tcx"); t.Cleanup(func() { f.Close() }); return f }
+	if _, err := ParseContext(context.Background(), open(), WithMaxBytes(int64(len(data))), WithMaxTrackpoints(1937)); err != nil {
+		t.Errorf("parse within limits = %v", err)
+	}
+	if _, err := Parse(open(), WithMaxBytes(int64(len(data)/2))); !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("parse over the size limit = %v", err)
+	}
+	if _, err := Parse(open(), WithMaxTrackpoints(1936)); !errors.Is(err, ErrTooManyTrackpoints) {
+		t.Errorf("parse over the trackpoint limit = %v", err)
+	}
+	// The size limit applies to the decompressed input too.
+	gz := gzipped(t, data)
+	if _, err := Parse(bytes.NewReader(gz), WithMaxBytes(int64(len(data)))); err != nil {
+		t.Errorf("gzip parse within limits = %v", err)
+	}
+	if _, err := Parse(bytes.NewReader(gz), WithMaxBytes(int64(len(gz)))); !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("gzip parse inflating over the size limit = %v", err)
+	}
+	bomb := gzipped(t, []byte(`<TrainingCenterDatabase xmlns="`+TrainingCenterNS+`"><Activities><Activity Sport="Running"><Id>2015-04-12T07:00:00Z</Id><Notes>`+strings.Repeat("x", 64<<20)))
+	if _, err := Parse(bytes.NewReader(bomb), WithMaxBytes(1<<20)); !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("gzip bomb parse = %v", err)
+	}
+}
+
+const quirkyTCX = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase>
+  <Activities>
+    <Activity Sport="Running">
+      <Id>2015-04-12T07:00:00</Id>
+      <Lap StartTime="2015-04-12T07:00:00">
+        <TotalTimeSeconds>2</TotalTimeSeconds>
+        <DistanceMeters>8</DistanceMeters>
+        <Intensity>Active</Intensity>
+        <TriggerMethod>Manual</TriggerMethod>
+        <Track>
+          <Trackpoint><Time>2015-04-12T07:00:00.5</Time><Position><LatitudeDegrees>47</LatitudeDegrees><LongitudeDegrees>-1.5</LongitudeDegrees></Position></Trackpoint>
+          <Trackpoint><Time>2015-04-12T07:00:01Z</Time><Position><LatitudeDegrees>147</LatitudeDegrees><LongitudeDegrees>-1.5</LongitudeDegrees></Position><HeartRateBpm><Value>-1</Value></HeartRateBpm></Trackpoint>
+        </Track>
+      </Lap>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestParseModes(t *testing.T) {
+	if _, err := Parse(strings.NewReader(quirkyTCX)); err == nil {
+		t.Error("default parse accepted timestamps without zone")
+	}
+	if _, err := Parse(strings.NewReader(quirkyTCX), WithStrict()); err == nil {
+		t.Error("strict parse accepted a quirky file")
+	}
+
+	db, err := Parse(strings.NewReader(quirkyTCX), WithLenient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := db.Activities[0]
+	if want := time.Date(2015, 4, 12, 7, 0, 0, 0, time.UTC); !a.ID.Equal(want) || !a.Laps[0].StartTime.Equal(want) {
+		t.Errorf("id %v, lap start %v, want %v", a.ID, a.Laps[0].StartTime, want)
+	}
+	p, q := a.Laps[0].Track[0], a.Laps[0].Track[1]
+	if p.Time.Nanosecond() != 5e8 || !p.HasPosition() {
+		t.Errorf("first trackpoint = %+v", p)
+	}
+	if q.HasPosition() || q.HasHeartRate() {
+		t.Errorf("out-of-range values kept: %+v", q)
+	}
+	// Three zoneless times, the namespace, the position and the heart rate.
+	if len(db.Warnings) != 6 {
+		t.Errorf("%d warnings:\n%v", len(db.Warnings), db.Warnings)
+	}
+	if w := db.Warnings[len(db.Warnings)-1]; w.Path != "Activities[0].Laps[0].Track[1]" {
+		t.Errorf("last warning = %v", w)
+	}
+
+	var b bytes.Buffer
+	clean := &Tcx{Activities: []Activity{*testActivity(SportRunning, repeat(4.0, 11), repeat(150, 11))}}
+	clean.Activities[0].Laps[0].update()
+	if err := clean.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(bytes.NewReader(b.Bytes()), WithStrict()); err != nil {
+		t.Errorf("strict parse of a valid file = %v", err)
+	}
+	if lenient, err := Parse(bytes.NewReader(b.Bytes()), WithLenient()); err != nil || len(lenient.Warnings) != 0 {
+		t.Errorf("lenient parse of a clean file: %v, %v", lenient.Warnings, err)
+	}
+}
@@ -0,0 +1,49 @@
+package tcx
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rdifrango/go-tcx/units"
+)
+
+// Pace is the time taken to cover a kilometer.
+type Pace time.Duration
+
+// PaceFromSpeed returns the pace at speed, in m/s. It is zero when the speed
+// is not positive.
+func PaceFromSpeed(speed float64) Pace {
+	if speed <= 0 {
+		return 0
+	}
+	return Pace(float64(time.Second) * 1000 / speed)
+}
+
+// PerKilometer returns the time taken to cover a kilometer.
+func (p Pace) PerKilometer() time.Duration {
+	return time.Duration(p)
+}
+
+// PerMile returns the time taken to cover a mile.
+func (p Pace) PerMile() time.Duration {
+	return time.Duration(float64(p) * units.MetersPerMile / units.MetersPerKilometer)
+}
+
+// Speed returns the speed of the pace, in m/s, or zero for a zero pace.
+func (p Pace) Speed() float64 {
+	if p <= 0 {
+		return 0
+	}
+	return 1000 / time.Duration(p).Seconds()
+}
+
+// String formats the pace per kilometer as minutes and seconds, such as 4:05.
+func (p Pace) String() string {
+	return formatPace(p.PerKilometer())
+}
+
+// formatPace formats d as minutes and seconds, rounded to the second.
+func formatPace(d time.Duration) string {
+	s := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
@@ -0,0 +1,38 @@
+package tcx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPace(t *testing.T) {
+	p := PaceFromSpeed(4)
+	if p.PerKilometer() != 250*time.Second || p.String() != "4:10" {
+		t.Errorf("pace at 4 m/s = %v (%v)", p, p.PerKilometer())
+	}
+	if got := p.PerMile(); got != 402336*time.Millisecond {
+		t.Errorf("PerMile() = %v", got)
+	}
+	if time.Duration(p) != p.PerKilometer() || p.Speed() != 4 {
+		t.Errorf("conversions of %v: %v, %v m/s", p, time.Duration(p), p.Speed())
+	}
+
+	tests := []struct {
+		pace Pace
+		want string
+	}{
+		{Pace(305 * time.Second), "5:05"},
+		{Pace(299600 * time.Millisecond), "5:00"},
+		{Pace(59 * time.Second), "0:59"},
+		{Pace(75 * time.Minute), "75:00"},
+		{0, "0:00"},
+	}
+	for _, tt := range tests {
+		if got := tt.pace.String(); got != tt.want {
+			t.Errorf("%v: String() = %q, want %q", time.Duration(tt.pace), got, tt.want)
+		}
+	}
+	if PaceFromSpeed(0) != 0 || Pace(0).Speed() != 0 {
+		t.Error("zero speed should give a zero pace")
+	}
+}
@@ -0,0 +1,213 @@
+package tcx
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// PacingStrategy selects how a target time is spread along a course.
+type PacingStrategy int
+
+const (
+	// EvenPacing holds the same pace over the whole course.
+	EvenPacing PacingStrategy = iota
+	// NegativeSplit runs the second half 2% faster than the first.
+	NegativeSplit
+	// GradeAdjusted holds an even effort, slowing uphill and speeding up
+	// downhill according to the metabolic cost of running on grades.
+	GradeAdjusted
+)
+
+// negativeSplitRatio is the pace difference between the two halves of a
+// negative split plan.
+const negativeSplitRatio = 0.02
+
+// splitDistance is the distance between the laps and course points of a
+// pacing plan.
+const splitDistance = 1000.0
+
+// minettiCost returns the energy cost of running, in J/kg/m, on the given
+// grade (Minetti et al., 2002).
+func minettiCost(grade float64) float64 {
+	i := math.Max(-0.45, math.Min(0.45, grade))
+	return 155.4*math.Pow(i, 5) - 30.4*math.Pow(i, 4) - 43.3*math.Pow(i, 3) + 46.3*i*i + 19.5*i + 3.6
+}
+
+// PacingPlan returns a copy of the course whose trackpoint times follow the
+// given strategy to finish in target when starting at start, so a device's
+// virtual partner can run the plan. The copy has one lap and one course point
+// per kilometer carrying the planned split times.
+func PacingPlan(c *Course, start time.Time, target time.Duration, strategy PacingStrategy) (*Course, error) {
+	if len(c.Track) < 2 {
+		return nil, fmt.Errorf("course %q has no track to pace", c.Name)
+	}
+	dist := cumulativeDistances(pointers(c.Track))
+	total := dist[len(dist)-1]
+	if total <= 0 || target <= 0 {
+		return nil, fmt.Errorf("course %q cannot be paced: %.f m in %v", c.Name, total, target)
+	}
+
+	// Weight every segment by its relative pace, then scale the weights so
+	// that they sum up to the target time.
+	weights := make([]float64, len(dist))
+	var sum float64
+	for i := 1; i < len(dist); i++ {
+		d := dist[i] - dist[i-1]
+		w := 1.0
+		switch strategy {
+		case NegativeSplit:
+			if dist[i-1]+d/2 < total/2 {
+				w += negativeSplitRatio / 2
+			} else {
+				w -= negativeSplitRatio / 2
+			}
+		case GradeAdjusted:
+			if d > 0 {
+				grade := (c.Track[i].AltitudeInMeters - c.Track[i-1].AltitudeInMeters) / d
+				w = minettiCost(grade) / minettiCost(0)
+			}
+		}
+		weights[i] = w * d
+		sum += weights[i]
+	}
+
+	plan := *c
+	plan.Track = make([]Trackpoint, len(c.Track))
+	copy(plan.Track, c.Track)
+	times := make([]float64, len(dist))
+	for i := range plan.Track {
+		if i > 0 {
+			times[i] = times[i-1] + weights[i]/sum*target.Seconds()
+		}
+		plan.Track[i].Time = start.Add(time.Duration(times[i] * float64(time.Second)))
+	}
+
+	plan.Laps = nil
+	plan.CoursePoints = nil
+	lats := make([]float64, len(dist))
+	lons := make([]float64, len(dist))
+	for i, p := range plan.Track {
+		lats[i], lons[i] = p.LatitudeInDegrees, p.LongitudeInDegrees
+	}
+	for from, to := 0.0, 0.0; from < total; from = to {
+		to = from + splitDistance
+		if total-to < 1 {
+			// Fold sub-meter leftovers into the last split.
+			to = total
+		}
+		t0, t1 := interpolate(dist, times, from), interpolate(dist, times, to)
+		lap := CourseLap{
+			TotalTimeInSeconds:      t1 - t0,
+			DistanceInMeters:        to - from,
+			BeginLatitudeInDegrees:  interpolate(dist, lats, from),
+			BeginLongitudeInDegrees: interpolate(dist, lons, from),
+			EndLatitudeInDegrees:    interpolate(dist, lats, to),
+			EndLongitudeInDegrees:   interpolate(dist, lons, to),
+			Intensity:               "Active",
+		}
+		plan.Laps = append(plan.Laps, lap)
+		plan.CoursePoints = append(plan.CoursePoints, CoursePoint{
+			Name:               fmt.Sprintf("KM %d", len(plan.Laps)),
+			Time:               start.Add(time.Duration(t1 * float64(time.Second))),
+			LatitudeInDegrees:  lap.EndLatitudeInDegrees,
+			LongitudeInDegrees: lap.EndLongitudeInDegrees,
+			PointType:          "Generic",
+			Notes:              fmt.Sprintf("split %v", time.Duration(lap.TotalTimeInSeconds*float64(time.Second)).Round(time.Second)),
+		})
+	}
+	return &plan, nil
+}
+
+// PacingProfile labels how the pace of an effort evolved.
+type PacingProfile int
+
+const (
+	// EvenProfile ran both halves within 1% of each other.
+	EvenProfile PacingProfile = iota
+	// NegativeSplitProfile ran the second half faster.
+	NegativeSplitProfile
+	// PositiveSplitProfile slowed down in the second half.
+	PositiveSplitProfile
+)
+
+func (p PacingProfile) String() string {
+	switch p {
+	case NegativeSplitProfile:
+		return "negative split"
+	case PositiveSplitProfile:
+		return "positive split"
+	}
+	return "even"
+}
+
+// evenSplitTolerance is the difference between halves, in percent, within
+// which an effort is even.
+const evenSplitTolerance = 1.0
+
+// pacingSegment is the distance over which the pace series of
+// PacingAnalysis is measured, in meters.
+const pacingSegment = 200.0
+
+// PacingAnalysis compares the halves of an effort.
+type PacingAnalysis struct {
+	// FirstHalf and SecondHalf are the paces over each half of the
+	// distance, stops included.
+	FirstHalf, SecondHalf Pace
+	// SplitPercent is how much longer the second half took than the first,
+	// in percent: negative for a negative split.
+	SplitPercent float64
+	// Variability is the coefficient of variation of the pace over 200 m
+	// segments, in percent: near zero for an evenly paced effort.
+	Variability float64
+	Profile     PacingProfile
+}
+
+// PacingAnalysis compares the first and second halves of the activity by
+// distance and measures the variability of its pace, for race
+// post-mortems. ok is false when the activity covers less than 400 m.
+func (a *Activity) PacingAnalysis() (PacingAnalysis, bool) {
+	t, d := a.cumulativeTimeDistance()
+	if len(d) == 0 || d[len(d)-1] < 2*pacingSegment {
+		return PacingAnalysis{}, false
+	}
+	total, half := d[len(d)-1], d[len(d)-1]/2
+	var i int
+	mid := timeAtDistance(t, d, half, &i)
+	first, second := mid, t[len(t)-1]-mid
+	if first <= 0 || second <= 0 {
+		return PacingAnalysis{}, false
+	}
+	r := PacingAnalysis{
+		FirstHalf:    PaceFromSpeed(half / first),
+		SecondHalf:   PaceFromSpeed((total - half) / second),
+		SplitPercent: 100 * (second - first) / first,
+	}
+	switch {
+	case r.SplitPercent < -evenSplitTolerance:
+		r.Profile = NegativeSplitProfile
+	case r.SplitPercent > evenSplitTolerance:
+		r.Profile = PositiveSplitProfile
+	}
+
+	var paces []float64
+	i = 0
+	prev := 0.0
+	for x := pacingSegment; x <= total; x += pacingSegment {
+		at := timeAtDistance(t, d, x, &i)
+		paces = append(paces, (at-prev)/pacingSegment)
+		prev = at
+	}
+	var mean, sq float64
+	for _, p := range paces {
+		mean += p
+	}
+	mean /= float64(len(paces))
+	for _, p := range paces {
+		sq += (p - mean) * (p - mean)
+	}
+	if mean > 0 {
+		r.Variability = 100 * math.Sqrt(sq/float64(len(paces))) / mean
+	}
+	return r, true
+}
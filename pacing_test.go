@@ -0,0 +1,88 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPacingPlan(t *testing.T) {
+	a := testActivity("Running", repeat(5.0, 1001), nil)
+	c := &Course{Name: "Loop", Track: a.Laps[0].Track}
+	// The second half climbs steadily.
+	for i := 500; i < len(c.Track); i++ {
+		c.Track[i].AltitudeInMeters = 10 + float64(i-500)/2
+	}
+	start := time.Date(2015, 4, 12, 9, 0, 0, 0, time.UTC)
+	target := 20 * time.Minute
+
+	for _, s := range []PacingStrategy{EvenPacing, NegativeSplit, GradeAdjusted} {
+		plan, err := PacingPlan(c, start, target, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		end := plan.Track[len(plan.Track)-1].Time.Sub(start)
+		if d := end - target; d < -time.Second || d > time.Second {
+			t.Errorf("strategy %d finishes in %v", s, end)
+		}
+		if len(plan.Laps) != 5 || len(plan.CoursePoints) != 5 {
+			t.Fatalf("strategy %d: %d laps, %d course points", s, len(plan.Laps), len(plan.CoursePoints))
+		}
+		first, last := plan.Laps[0].TotalTimeInSeconds, plan.Laps[4].TotalTimeInSeconds
+		switch s {
+		case EvenPacing:
+			if math.Abs(first-240) > 0.5 {
+				t.Errorf("even first split = %v, want 240", first)
+			}
+		case NegativeSplit:
+			if last >= first {
+				t.Errorf("negative split: first %v, last %v", first, last)
+			}
+		case GradeAdjusted:
+			if last <= first {
+				t.Errorf("grade adjusted: first %v, last %v", first, last)
+			}
+		}
+	}
+	if c.Track[0].Time.Equal(start) {
+		t.Error("PacingPlan modified the original course")
+	}
+	if _, err := PacingPlan(&Course{}, start, target, EvenPacing); err == nil {
+		t.Error("expected an error for an empty course")
+	}
+}
+
+func TestPacingAnalysis(t *testing.T) {
+	even := testActivity(SportRunning, repeat(4.0, 601), nil)
+	negative := testActivity(SportRunning, append(repeat(3.8, 300), repeat(4.2, 301)...), nil)
+	positive := testActivity(SportRunning, append(repeat(4.4, 300), repeat(3.6, 301)...), nil)
+	for _, tt := range []struct {
+		name string
+		a    *Activity
+		want PacingProfile
+	}{
+		{"even", even, EvenProfile},
+		{"negative", negative, NegativeSplitProfile},
+		{"positive", positive, PositiveSplitProfile},
+	} {
+		r, ok := tt.a.PacingAnalysis()
+		if !ok || r.Profile != tt.want {
+			t.Errorf("%s: profile = %v, %v, want %v", tt.name, r.Profile, ok, tt.want)
+		}
+	}
+
+	r, _ := even.PacingAnalysis()
+	if math.Abs(r.FirstHalf.Speed()-4) > 0.01 || math.Abs(r.SplitPercent) > 0.1 || r.Variability > 0.5 {
+		t.Errorf("even run = %+v", r)
+	}
+	// 3.8 m/s over 1140 m and 4.2 m/s over 1260 m, halves of 1200 m.
+	r, _ = negative.PacingAnalysis()
+	first, second := 1140/3.8+60/4.2, 1200/4.2
+	if math.Abs(r.SplitPercent-100*(second-first)/first) > 0.5 || r.Variability < 3 || r.Profile.String() != "negative split" {
+		t.Errorf("negative split = %+v, want %.1f%%", r, 100*(second-first)/first)
+	}
+
+	if _, ok := testActivity(SportRunning, repeat(4.0, 60), nil).PacingAnalysis(); ok {
+		t.Error("analyzed a 240 m effort")
+	}
+}
@@ -0,0 +1,358 @@
+package tcx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Parquet physical types, converted types and encodings used by the writer.
+const (
+	parquetInt32     int32 = 1
+	parquetInt64     int32 = 2
+	parquetDouble    int32 = 5
+	parquetByteArray int32 = 6
+
+	parquetUTF8            int32 = 0
+	parquetTimestampMillis int32 = 9
+	parquetNoConversion    int32 = -1
+
+	parquetPlain int32 = 0
+	parquetRLE   int32 = 3
+)
+
+// parquetMagic starts and ends every Parquet file.
+const parquetMagic = "PAR1"
+
+// parquetColumn accumulates the values of one column, PLAIN encoded.
+type parquetColumn struct {
+	name     string
+	typ      int32
+	conv     int32
+	optional bool
+	defined  []bool
+	data     bytes.Buffer
+}
+
+func (c *parquetColumn) add(ok bool, v any) {
+	c.defined = append(c.defined, ok)
+	if !ok {
+		return
+	}
+	switch v := v.(type) {
+	case string:
+		binary.Write(&c.data, binary.LittleEndian, uint32(len(v)))
+		c.data.WriteString(v)
+	default:
+		binary.Write(&c.data, binary.LittleEndian, v)
+	}
+}
+
+func (c *parquetColumn) addInt32(v int32, ok bool)    { c.add(ok || !c.optional, v) }
+func (c *parquetColumn) addDouble(v float64, ok bool) { c.add(ok || !c.optional, v) }
+func (c *parquetColumn) addString(v string)           { c.add(true, v) }
+func (c *parquetColumn) addTime(t time.Time)          { c.add(true, t.UnixMilli()) }
+
+// parquetTable is a table written as a single row group.
+type parquetTable struct {
+	columns []*parquetColumn
+}
+
+func (t *parquetTable) column(name string, typ, conv int32, optional bool) *parquetColumn {
+	c := &parquetColumn{name: name, typ: typ, conv: conv, optional: optional}
+	t.columns = append(t.columns, c)
+	return c
+}
+
+func (t *parquetTable) rows() int {
+	if len(t.columns) == 0 {
+		return 0
+	}
+	return len(t.columns[0].defined)
+}
+
+// thriftWriter writes the Thrift compact protocol used by Parquet metadata.
+type thriftWriter struct {
+	buf  bytes.Buffer
+	last []int16
+}
+
+// Thrift compact protocol types.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+func (w *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (w *thriftWriter) zigzag(v int64) {
+	w.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (w *thriftWriter) field(id int16, typ byte) {
+	last := w.last[len(w.last)-1]
+	if d := id - last; d > 0 && d <= 15 {
+		w.buf.WriteByte(byte(d)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.zigzag(int64(id))
+	}
+	w.last[len(w.last)-1] = id
+}
+
+func (w *thriftWriter) begin()                 { w.last = append(w.last, 0) }
+func (w *thriftWriter) end()                   { w.buf.WriteByte(0); w.last = w.last[:len(w.last)-1] }
+func (w *thriftWriter) i32(id int16, v int32)  { w.field(id, thriftI32); w.zigzag(int64(v)) }
+func (w *thriftWriter) i64(id int16, v int64)  { w.field(id, thriftI64); w.zigzag(v) }
+func (w *thriftWriter) str(id int16, s string) { w.field(id, thriftBinary); w.rawString(s) }
+func (w *thriftWriter) rawString(s string)     { w.varint(uint64(len(s))); w.buf.WriteString(s) }
+func (w *thriftWriter) structField(id int16)   { w.field(id, thriftStruct); w.begin() }
+func (w *thriftWriter) list(id int16, elem byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.buf.WriteByte(byte(n)<<4 | elem)
+	} else {
+		w.buf.WriteByte(0xF0 | elem)
+		w.varint(uint64(n))
+	}
+}
+
+// rleLevels encodes definition levels of bit width 1 as RLE runs.
+func rleLevels(defined []bool) []byte {
+	var w thriftWriter
+	for i := 0; i < len(defined); {
+		j := i
+		for j < len(defined) && defined[j] == defined[i] {
+			j++
+		}
+		w.varint(uint64(j-i) << 1)
+		if defined[i] {
+			w.buf.WriteByte(1)
+		} else {
+			w.buf.WriteByte(0)
+		}
+		i = j
+	}
+	return w.buf.Bytes()
+}
+
+// write writes the table as a Parquet file with one data page per column.
+func (t *parquetTable) write(w io.Writer) error {
+	var out bytes.Buffer
+	out.WriteString(parquetMagic)
+	type chunk struct {
+		offset, size int64
+	}
+	chunks := make([]chunk, len(t.columns))
+	for i, c := range t.columns {
+		var page bytes.Buffer
+		if c.optional {
+			levels := rleLevels(c.defined)
+			binary.Write(&page, binary.LittleEndian, uint32(len(levels)))
+			page.Write(levels)
+		}
+		page.Write(c.data.Bytes())
+
+		var h thriftWriter
+		h.begin()
+		h.i32(1, 0) // DATA_PAGE
+		h.i32(2, int32(page.Len()))
+		h.i32(3, int32(page.Len()))
+		h.structField(5)
+		h.i32(1, int32(len(c.defined)))
+		h.i32(2, parquetPlain)
+		h.i32(3, parquetRLE)
+		h.i32(4, parquetRLE)
+		h.end()
+		h.end()
+
+		chunks[i] = chunk{int64(out.Len()), int64(h.buf.Len() + page.Len())}
+		out.Write(h.buf.Bytes())
+		out.Write(page.Bytes())
+	}
+
+	var m thriftWriter
+	m.begin()
+	m.i32(1, 1)
+	m.list(2, thriftStruct, len(t.columns)+1)
+	m.begin()
+	m.str(4, "schema")
+	m.i32(5, int32(len(t.columns)))
+	m.end()
+	for _, c := range t.columns {
+		m.begin()
+		m.i32(1, c.typ)
+		if c.optional {
+			m.i32(3, 1)
+		} else {
+			m.i32(3, 0)
+		}
+		m.str(4, c.name)
+		if c.conv != parquetNoConversion {
+			m.i32(6, c.conv)
+		}
+		m.end()
+	}
+	m.i64(3, int64(t.rows()))
+	m.list(4, thriftStruct, 1)
+	m.begin()
+	m.list(1, thriftStruct, len(t.columns))
+	var total int64
+	for i, c := range t.columns {
+		total += chunks[i].size
+		m.begin()
+		m.i64(2, chunks[i].offset)
+		m.structField(3)
+		m.i32(1, c.typ)
+		m.list(2, thriftI32, 2)
+		m.zigzag(int64(parquetPlain))
+		m.zigzag(int64(parquetRLE))
+		m.list(3, thriftBinary, 1)
+		m.rawString(c.name)
+		m.i32(4, 0) // UNCOMPRESSED
+		m.i64(5, int64(len(c.defined)))
+		m.i64(6, chunks[i].size)
+		m.i64(7, chunks[i].size)
+		m.i64(9, chunks[i].offset)
+		m.end()
+		m.end()
+	}
+	m.i64(2, total)
+	m.i64(3, int64(t.rows()))
+	m.end()
+	m.str(6, "go-tcx")
+	m.end()
+
+	out.Write(m.buf.Bytes())
+	binary.Write(&out, binary.LittleEndian, uint32(m.buf.Len()))
+	out.WriteString(parquetMagic)
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// trackpointTable builds the trackpoint table of the activities.
+func trackpointTable(activities []*Activity) *parquetTable {
+	t := &parquetTable{}
+	id := t.column("activity_id", parquetInt64, parquetTimestampMillis, false)
+	lap := t.column("lap", parquetInt32, parquetNoConversion, false)
+	tm := t.column("time", parquetInt64, parquetTimestampMillis, false)
+	lat := t.column("latitude", parquetDouble, parquetNoConversion, true)
+	lon := t.column("longitude", parquetDouble, parquetNoConversion, true)
+	alt := t.column("altitude", parquetDouble, parquetNoConversion, true)
+	dist := t.column("distance", parquetDouble, parquetNoConversion, true)
+	hr := t.column("heart_rate", parquetInt32, parquetNoConversion, true)
+	cad := t.column("cadence", parquetInt32, parquetNoConversion, true)
+	speed := t.column("speed", parquetDouble, parquetNoConversion, true)
+	power := t.column("power", parquetInt32, parquetNoConversion, true)
+	for _, a := range activities {
+		for li, l := range a.Laps {
+			for _, p := range l.Track {
+				id.addTime(a.ID)
+				lap.addInt32(int32(li), true)
+				tm.addTime(p.Time)
+				lat.addDouble(p.LatitudeInDegrees, p.HasPosition())
+				lon.addDouble(p.LongitudeInDegrees, p.HasPosition())
+				alt.addDouble(p.AltitudeInMeters, p.HasAltitude())
+				dist.addDouble(p.DistanceInMeters, p.HasDistance())
+				hr.addInt32(int32(p.HeartRateInBpm), p.HasHeartRate())
+				cad.addInt32(int32(p.Cadence), p.HasCadence())
+				speed.addDouble(p.SpeedInMetersPerSec, p.SpeedInMetersPerSec > 0)
+				power.addInt32(int32(p.PowerInWatts), p.HasPower())
+			}
+		}
+	}
+	return t
+}
+
+// activityTable builds the summary table of the activities.
+func activityTable(activities []*Activity) *parquetTable {
+	t := &parquetTable{}
+	id := t.column("activity_id", parquetInt64, parquetTimestampMillis, false)
+	sport := t.column("sport", parquetByteArray, parquetUTF8, false)
+	date := t.column("date", parquetByteArray, parquetUTF8, false)
+	laps := t.column("laps", parquetInt32, parquetNoConversion, false)
+	duration := t.column("duration_s", parquetDouble, parquetNoConversion, false)
+	dist := t.column("distance_m", parquetDouble, parquetNoConversion, false)
+	hr := t.column("avg_heart_rate", parquetDouble, parquetNoConversion, true)
+	cal := t.column("calories", parquetDouble, parquetNoConversion, false)
+	for _, a := range activities {
+		id.addTime(a.ID)
+		sport.addString(a.Sport)
+		date.addString(a.ID.UTC().Format("2006-01-02"))
+		laps.addInt32(int32(len(a.Laps)), true)
+		duration.addDouble(a.TotalDuration().Seconds(), true)
+		dist.addDouble(a.TotalDistance(), true)
+		avg, n := a.AverageHeartbeat()
+		hr.addDouble(avg, n > 0)
+		var kcal float64
+		for _, l := range a.Laps {
+			kcal += l.Calories
+		}
+		cal.addDouble(kcal, true)
+	}
+	return t
+}
+
+// WriteParquet writes the trackpoints of the activities under dir as
+// Parquet files partitioned Hive-style by sport and date,
+// dir/sport=Running/date=2015-04-12/trackpoints.parquet, and a summary of
+// every activity to dir/activities.parquet.
+func WriteParquet(dir string, activities []*Activity) error {
+	type partition struct{ sport, date string }
+	parts := make(map[partition][]*Activity)
+	for _, a := range activities {
+		p := partition{a.Sport, a.ID.UTC().Format("2006-01-02")}
+		parts[p] = append(parts[p], a)
+	}
+	keys := make([]partition, 0, len(parts))
+	for p := range parts {
+		keys = append(keys, p)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].sport < keys[j].sport || keys[i].sport == keys[j].sport && keys[i].date < keys[j].date
+	})
+
+	for _, p := range keys {
+		sport := p.sport
+		if sport == "" {
+			sport = SportOther
+		}
+		// Sports come from the files: escape them so that none reaches
+		// outside dir.
+		pdir := filepath.Join(dir, "sport="+url.PathEscape(sport), "date="+p.date)
+		if err := os.MkdirAll(pdir, 0o755); err != nil {
+			return err
+		}
+		if err := writeParquetFile(filepath.Join(pdir, "trackpoints.parquet"), trackpointTable(parts[p])); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return writeParquetFile(filepath.Join(dir, "activities.parquet"), activityTable(activities))
+}
+
+func writeParquetFile(path string, t *parquetTable) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := t.write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("couldn't write %s: %v", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,210 @@
+package tcx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// thriftReader decodes Thrift compact structs into maps keyed by field id.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case 1, 2:
+		return typ == 1
+	case 3:
+		r.pos++
+		return int64(r.data[r.pos-1])
+	case 4, 5, 6:
+		return r.zigzag()
+	case 7:
+		r.pos += 8
+		return math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.pos-8:]))
+	case 8:
+		n := int(r.varint())
+		r.pos += n
+		return string(r.data[r.pos-n : r.pos])
+	case 9:
+		h := r.data[r.pos]
+		r.pos++
+		n := int(h >> 4)
+		if n == 15 {
+			n = int(r.varint())
+		}
+		l := make([]any, n)
+		for i := range l {
+			l[i] = r.value(h & 0x0F)
+		}
+		return l
+	case 12:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("unexpected thrift type %d", typ))
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	m := make(map[int16]any)
+	var id int16
+	for {
+		h := r.data[r.pos]
+		r.pos++
+		if h == 0 {
+			return m
+		}
+		if d := int16(h >> 4); d != 0 {
+			id += d
+		} else {
+			id = int16(r.zigzag())
+		}
+		m[id] = r.value(h & 0x0F)
+	}
+}
+
+// readParquetColumn decodes a column of a file written by parquetTable,
+// returning nil for null values.
+func readParquetColumn(t *testing.T, data []byte, name string) (rows int64, values []any) {
+	if string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatal("missing parquet magic")
+	}
+	n := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := (&thriftReader{data: data, pos: len(data) - 8 - n}).readStruct()
+	schema := meta[2].([]any)
+	for i, c := range meta[4].([]any)[0].(map[int16]any)[1].([]any) {
+		col := c.(map[int16]any)[3].(map[int16]any)
+		if col[3].([]any)[0] != name {
+			continue
+		}
+		elem := schema[i+1].(map[int16]any)
+		optional := elem[3].(int64) == 1
+		r := &thriftReader{data: data, pos: int(col[9].(int64))}
+		page := r.readStruct()
+		count := int(page[5].(map[int16]any)[1].(int64))
+		defined := make([]bool, 0, count)
+		if optional {
+			end := r.pos + 4 + int(binary.LittleEndian.Uint32(data[r.pos:]))
+			r.pos += 4
+			for r.pos < end {
+				run := int(r.varint() >> 1)
+				for k := 0; k < run; k++ {
+					defined = append(defined, data[r.pos] == 1)
+				}
+				r.pos++
+			}
+		} else {
+			for k := 0; k < count; k++ {
+				defined = append(defined, true)
+			}
+		}
+		for _, ok := range defined {
+			if !ok {
+				values = append(values, nil)
+				continue
+			}
+			switch col[1].(int64) {
+			case int64(parquetInt32):
+				values = append(values, int64(int32(binary.LittleEndian.Uint32(data[r.pos:]))))
+				r.pos += 4
+			case int64(parquetInt64):
+				values = append(values, int64(binary.LittleEndian.Uint64(data[r.pos:])))
+				r.pos += 8
+			case int64(parquetDouble):
+				values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(data[r.pos:])))
+				r.pos += 8
+			case int64(parquetByteArray):
+				l := int(binary.LittleEndian.Uint32(data[r.pos:]))
+				values = append(values, string(data[r.pos+4:r.pos+4+l]))
+				r.pos += 4 + l
+			}
+		}
+		return meta[3].(int64), values
+	}
+	t.Fatalf("no column %s", name)
+	return 0, nil
+}
+
+func TestWriteParquet(t *testing.T) {
+	tcx, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	a := &tcx.Activities[0]
+	walk := testActivity(SportWalking, repeat(1.4, 120), repeat(0, 120))
+	dir := t.TempDir()
+	if err := WriteParquet(dir, []*Activity{a, walk}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "sport=Running", "date=2015-04-12", "trackpoints.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pts := a.trackpoints()
+	rows, hrs := readParquetColumn(t, data, "heart_rate")
+	if rows != int64(len(pts)) || len(hrs) != len(pts) {
+		t.Fatalf("got %d rows and %d values, want %d", rows, len(hrs), len(pts))
+	}
+	for i, p := range pts {
+		if hrs[i] != int64(p.HeartRateInBpm) {
+			t.Fatalf("heart rate %d = %v, want %d", i, hrs[i], p.HeartRateInBpm)
+		}
+	}
+	_, lats := readParquetColumn(t, data, "latitude")
+	if lats[len(lats)-1] != pts[len(pts)-1].LatitudeInDegrees {
+		t.Errorf("last latitude = %v", lats[len(lats)-1])
+	}
+
+	// The walk has no heart rate, which must be written as nulls.
+	data, err = os.ReadFile(filepath.Join(dir, "sport=Walking", "date=2015-04-12", "trackpoints.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, hrs := readParquetColumn(t, data, "heart_rate"); len(hrs) != 120 || hrs[0] != nil {
+		t.Errorf("walk heart rates = %v", hrs[:1])
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "activities.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, sports := readParquetColumn(t, data, "sport")
+	if rows != 2 || sports[0] != SportRunning || sports[1] != SportWalking {
+		t.Errorf("activities sports = %v", sports)
+	}
+
+	// Sports must not lead outside the directory.
+	evil := testActivity("../../x", repeat(1.4, 10), nil)
+	evil.Laps[0].Track[0].ClearAltitude()
+	evil.Laps[0].Track[1].SetDistance(1.4)
+	dir = filepath.Join(t.TempDir(), "out")
+	if err := WriteParquet(dir, []*Activity{evil}); err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "sport=..%2F..%2Fx", "date=2015-04-12", "trackpoints.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, alts := readParquetColumn(t, data, "altitude"); alts[0] != nil || alts[1] == nil {
+		t.Errorf("altitudes = %v, want a null first", alts[:2])
+	}
+	if _, dists := readParquetColumn(t, data, "distance"); dists[0] != nil || dists[1] != 1.4 {
+		t.Errorf("distances = %v, want a null then 1.4", dists[:2])
+	}
+}
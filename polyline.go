@@ -0,0 +1,61 @@
+package tcx
+
+import (
+	"math"
+	"strings"
+)
+
+// Polyline returns the track of the activity as a Google encoded polyline,
+// with coordinates rounded to precision decimal places: 5 for Google Maps,
+// 6 for OSRM and Valhalla. Trackpoints without a GPS fix are skipped, and
+// indoor activities have none.
+func (a *Activity) Polyline(precision int) string {
+	return a.SimplifiedPolyline(precision, 0)
+}
+
+// SimplifiedPolyline is like Polyline, but first thins the track with the
+// Douglas-Peucker algorithm so that no dropped trackpoint lies farther than
+// tolerance meters from the line, which keeps long activities short enough
+// for URLs and activity cards. A zero tolerance keeps every fix.
+func (a *Activity) SimplifiedPolyline(precision int, tolerance float64) string {
+	var fixes []Trackpoint
+	for _, p := range a.fixes() {
+		fixes = append(fixes, *p)
+	}
+	if tolerance > 0 {
+		keep := douglasPeucker(fixes, tolerance)
+		kept := fixes[:0]
+		for i, k := range keep {
+			if k {
+				kept = append(kept, fixes[i])
+			}
+		}
+		fixes = kept
+	}
+
+	factor := math.Pow10(precision)
+	var b strings.Builder
+	var lastLat, lastLon int64
+	for _, p := range fixes {
+		lat := int64(math.Round(p.LatitudeInDegrees * factor))
+		lon := int64(math.Round(p.LongitudeInDegrees * factor))
+		encodePolylineValue(&b, lat-lastLat)
+		encodePolylineValue(&b, lon-lastLon)
+		lastLat, lastLon = lat, lon
+	}
+	return b.String()
+}
+
+// encodePolylineValue appends v to b in the polyline encoding: zigzag
+// encoded, then split in 5 bit chunks offset by 63.
+func encodePolylineValue(b *strings.Builder, v int64) {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		b.WriteByte(byte(0x20|u&0x1F) + 63)
+		u >>= 5
+	}
+	b.WriteByte(byte(u) + 63)
+}
@@ -0,0 +1,27 @@
+package tcx
+
+import "testing"
+
+func TestPolyline(t *testing.T) {
+	// The example of Google's polyline algorithm documentation.
+	a := &Activity{Laps: []Lap{{Track: []Trackpoint{
+		{LatitudeInDegrees: 38.5, LongitudeInDegrees: -120.2},
+		{HeartRateInBpm: 120},
+		{LatitudeInDegrees: 40.7, LongitudeInDegrees: -120.95},
+		{LatitudeInDegrees: 43.252, LongitudeInDegrees: -126.453},
+	}}}}
+	if got, want := a.Polyline(5), "_p~iF~ps|U_ulLnnqC_mqNvxq`@"; got != want {
+		t.Errorf("Polyline(5) = %q, want %q", got, want)
+	}
+
+	// A straight run simplifies to its ends.
+	run := testActivity(SportRunning, repeat(4.0, 101), nil)
+	full, simple := run.Polyline(6), run.SimplifiedPolyline(6, 1)
+	if len(simple) >= len(full) {
+		t.Errorf("simplified polyline %q isn't shorter than %q", simple, full)
+	}
+	two := &Activity{Laps: []Lap{{Track: []Trackpoint{run.Laps[0].Track[0], run.Laps[0].Track[100]}}}}
+	if want := two.Polyline(6); simple != want {
+		t.Errorf("SimplifiedPolyline(6, 1) = %q, want %q", simple, want)
+	}
+}
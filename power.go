@@ -0,0 +1,125 @@
+package tcx
+
+import "math"
+
+// powerSeries returns the power of the activity resampled to 1 Hz, or nil
+// when no trackpoint carries power.
+func (a *Activity) powerSeries() []float64 {
+	return powerSeries(a.trackpoints())
+}
+
+// powerSeries returns the power of pts resampled to 1 Hz, or nil when no
+// trackpoint carries power.
+func powerSeries(pts []*Trackpoint) []float64 {
+	for _, p := range pts {
+		if p.HasPower() {
+			return resample1Hz(pts, func(p *Trackpoint) float64 { return float64(p.PowerInWatts) })
+		}
+	}
+	return nil
+}
+
+// AveragePower returns the average power of the activity, in watts. Zeros,
+// such as coasting, count towards the average once the activity has power
+// data. It is zero without power data.
+func (a *Activity) AveragePower() float64 {
+	return averagePower(a.powerSeries())
+}
+
+func averagePower(series []float64) float64 {
+	if len(series) == 0 {
+		return 0
+	}
+	var total float64
+	for _, w := range series {
+		total += w
+	}
+	return total / float64(len(series))
+}
+
+// NormalizedPower returns the normalized power of the activity, in watts:
+// the fourth root of the mean of the fourth powers of the 30 s rolling
+// average power. It is zero without power data or under 30 s of it.
+func (a *Activity) NormalizedPower() float64 {
+	return normalizedPower(a.powerSeries())
+}
+
+func normalizedPower(series []float64) float64 {
+	const window = 30
+	if len(series) < window {
+		return 0
+	}
+	var sum, total float64
+	for i, w := range series {
+		sum += w
+		if i >= window {
+			sum -= series[i-window]
+		}
+		if i >= window-1 {
+			total += math.Pow(sum/window, 4)
+		}
+	}
+	return math.Pow(total/float64(len(series)-window+1), 0.25)
+}
+
+// PowerMetrics summarizes the power of an activity or a lap. The training
+// load metrics are relative to the functional threshold power (FTP) they
+// were computed for, and zero without one.
+type PowerMetrics struct {
+	Average    float64 // watts
+	Max        float64 // watts
+	Normalized float64 // watts
+	// IntensityFactor is the normalized power over the FTP.
+	IntensityFactor float64
+	// TrainingStressScore is the training load of the effort, 100 for an
+	// hour at FTP.
+	TrainingStressScore float64
+}
+
+// powerMetrics computes the power metrics of a 1 Hz series.
+func powerMetrics(series []float64, ftp float64) PowerMetrics {
+	m := PowerMetrics{Average: averagePower(series), Normalized: normalizedPower(series)}
+	for _, w := range series {
+		m.Max = math.Max(m.Max, w)
+	}
+	if ftp > 0 {
+		m.IntensityFactor = m.Normalized / ftp
+		m.TrainingStressScore = float64(len(series)) * m.Normalized * m.IntensityFactor / (ftp * 3600) * 100
+	}
+	return m
+}
+
+// PowerMetrics returns the power metrics of the activity for an athlete of
+// the given FTP, in watts. It is zero without power data.
+func (a *Activity) PowerMetrics(ftp float64) PowerMetrics {
+	return powerMetrics(a.powerSeries(), ftp)
+}
+
+// IntensityFactor returns the normalized power of the activity over ftp.
+func (a *Activity) IntensityFactor(ftp float64) float64 {
+	return a.PowerMetrics(ftp).IntensityFactor
+}
+
+// TrainingStressScore returns the training stress score of the activity
+// for an athlete of the given FTP, in watts.
+func (a *Activity) TrainingStressScore(ftp float64) float64 {
+	return a.PowerMetrics(ftp).TrainingStressScore
+}
+
+// PowerMetrics returns the power metrics of the lap track for an athlete of
+// the given FTP, in watts. Laps without power in their track fall back to
+// the average and maximum of their extensions.
+func (l *Lap) PowerMetrics(ftp float64) PowerMetrics {
+	series := powerSeries(pointers(l.Track))
+	if len(series) == 0 {
+		var m PowerMetrics
+		if w, ok := l.AveragePower(); ok {
+			m.Average = float64(w)
+		}
+		if w, ok := l.MaxPower(); ok {
+			m.Max = float64(w)
+		}
+		return m
+	}
+	return powerMetrics(series, ftp)
+}
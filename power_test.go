@@ -0,0 +1,103 @@
+package tcx
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPower(t *testing.T) {
+	a := testActivity(SportBiking, repeat(10.0, 601), nil)
+	if p := a.AveragePower(); p != 0 || a.NormalizedPower() != 0 {
+		t.Errorf("power without data = %v", p)
+	}
+	track := a.Laps[0].Track
+	for i := range track {
+		track[i].PowerInWatts = 100
+		if i >= 300 {
+			track[i].PowerInWatts = 300
+		}
+	}
+	if p := a.AveragePower(); p != 200 {
+		t.Errorf("AveragePower() = %v, want 200", p)
+	}
+	// Steady blocks normalize close to the fourth-power mean of the blocks.
+	want := math.Pow((math.Pow(100, 4)+math.Pow(300, 4))/2, 0.25)
+	if np := a.NormalizedPower(); math.Abs(np-want) > 5 || np <= a.AveragePower() {
+		t.Errorf("NormalizedPower() = %v, want about %v", np, want)
+	}
+	for i := range track {
+		track[i].PowerInWatts = 250
+	}
+	if np := a.NormalizedPower(); math.Abs(np-250) > 1e-9 {
+		t.Errorf("steady NormalizedPower() = %v, want 250", np)
+	}
+}
+
+func TestRunCadence(t *testing.T) {
+	db, err := Parse(strings.NewReader(`<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+  <Activities><Activity Sport="Running"><Id>2015-04-12T07:00:00Z</Id>
+    <Lap StartTime="2015-04-12T07:00:00Z"><Track>
+      <Trackpoint>
+        <Time>2015-04-12T07:00:00Z</Time>
+        <Extensions>
+          <TPX xmlns="http://www.garmin.com/xmlschemas/ActivityExtension/v2">
+            <Speed>3.1</Speed>
+            <RunCadence>88</RunCadence>
+            <Watts>245</Watts>
+          </TPX>
+        </Extensions>
+      </Trackpoint>
+    </Track></Lap>
+  </Activity></Activities>
+</TrainingCenterDatabase>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := db.Activities[0].Laps[0].Track[0]
+	if p.RunCadence != 88 || p.PowerInWatts != 245 || p.SpeedInMetersPerSec != 3.1 {
+		t.Fatalf("trackpoint = %+v", p)
+	}
+	var b bytes.Buffer
+	if err := db.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	speed, cadence, watts := strings.Index(out, "<Speed>3.1<"), strings.Index(out, "<RunCadence>88<"), strings.Index(out, "<Watts>245<")
+	if speed < 0 || cadence < speed || watts < cadence {
+		t.Errorf("TPX written out of order:\n%s", out)
+	}
+}
+
+func TestPowerMetrics(t *testing.T) {
+	// An hour at FTP scores 100.
+	a := testActivity(SportBiking, repeat(10.0, 3601), nil)
+	for i := range a.Laps[0].Track {
+		a.Laps[0].Track[i].PowerInWatts = 250
+	}
+	m := a.PowerMetrics(250)
+	if m.Average != 250 || m.Max != 250 || math.Abs(m.IntensityFactor-1) > 1e-9 || math.Abs(m.TrainingStressScore-100) > 0.1 {
+		t.Errorf("PowerMetrics(250) = %+v", m)
+	}
+	if f := a.IntensityFactor(312.5); math.Abs(f-0.8) > 1e-9 {
+		t.Errorf("IntensityFactor(312.5) = %v, want 0.8", f)
+	}
+	if m := a.PowerMetrics(0); math.Abs(m.Normalized-250) > 1e-9 || m.TrainingStressScore != 0 {
+		t.Errorf("PowerMetrics(0) = %+v", m)
+	}
+
+	if err := a.SplitLapAt(a.Laps[0].StartTime.Add(30 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	a.Laps[1].Track[100].PowerInWatts = 900
+	if m := a.Laps[1].PowerMetrics(250); m.Max != 900 || m.TrainingStressScore < 50 {
+		t.Errorf("lap PowerMetrics(250) = %+v", m)
+	}
+	avg, peak := 210, 480
+	l := Lap{Extensions: &LapExtensions{LX: &LapExtension{AvgWatts: &avg, MaxWatts: &peak}}}
+	if m := l.PowerMetrics(250); m.Average != 210 || m.Max != 480 {
+		t.Errorf("PowerMetrics() from extensions = %+v", m)
+	}
+}
@@ -0,0 +1,60 @@
+package tcx
+
+import "time"
+
+// PowerSpikeOptions configures power spike filtering.
+type PowerSpikeOptions struct {
+	// MaxPower is the ceiling, in watts, above which any sample is an
+	// artifact.
+	MaxPower int
+	// Ratio and MinJump define a single-sample spike: a sample at least
+	// Ratio times and MinJump watts above both of its neighbours.
+	Ratio   float64
+	MinJump int
+}
+
+// DefaultPowerSpikeOptions keeps genuine sprints, which ramp up over a few
+// seconds, and removes isolated readings such as 3000 W artifacts.
+var DefaultPowerSpikeOptions = PowerSpikeOptions{MaxPower: 2500, Ratio: 2, MinJump: 500}
+
+// PowerSpike is a power sample removed by FilterPowerSpikes.
+type PowerSpike struct {
+	Time        time.Time
+	Watts       int
+	Replacement int
+}
+
+// FilterPowerSpikes replaces power spikes with the mean of the neighbouring
+// samples and returns what was removed. Run it before computing normalized
+// or maximal power.
+func (a *Activity) FilterPowerSpikes(opts PowerSpikeOptions) []PowerSpike {
+	a.Invalidate()
+	var spikes []PowerSpike
+	pts := a.trackpoints()
+	raw := make([]int, len(pts))
+	for i, p := range pts {
+		raw[i] = p.PowerInWatts
+	}
+	// neighbour returns the nearest sample under the ceiling from i in
+	// direction dir.
+	neighbour := func(i, dir int) int {
+		for j := i + dir; j >= 0 && j < len(raw); j += dir {
+			if raw[j] <= opts.MaxPower {
+				return raw[j]
+			}
+		}
+		return 0
+	}
+	for i, p := range pts {
+		prev, next := neighbour(i, -1), neighbour(i, 1)
+		v, around := raw[i], max(prev, next)
+		spike := v > opts.MaxPower ||
+			(v-around >= opts.MinJump && float64(v) >= opts.Ratio*float64(around))
+		if !spike {
+			continue
+		}
+		p.PowerInWatts = (prev + next) / 2
+		spikes = append(spikes, PowerSpike{Time: p.Time, Watts: v, Replacement: p.PowerInWatts})
+	}
+	return spikes
+}
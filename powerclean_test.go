@@ -0,0 +1,40 @@
+package tcx
+
+import "testing"
+
+func TestFilterPowerSpikes(t *testing.T) {
+	a := testActivity(SportBiking, repeat(9.0, 60), nil)
+	track := a.Laps[0].Track
+	for i := range track {
+		track[i].PowerInWatts = 200
+	}
+	track[10].PowerInWatts = 3000
+	track[20].PowerInWatts = 1800
+	track[30].PowerInWatts, track[31].PowerInWatts = 2800, 2900
+	// A sprint from coasting, ramping up over a few seconds.
+	for i, w := range []int{0, 0, 500, 900, 1100, 1000, 700} {
+		track[40+i].PowerInWatts = w
+	}
+
+	spikes := a.FilterPowerSpikes(DefaultPowerSpikeOptions)
+	if len(spikes) != 4 {
+		t.Fatalf("removed %d spikes, want 4: %+v", len(spikes), spikes)
+	}
+	if spikes[0].Watts != 3000 || spikes[0].Replacement != 200 || !spikes[0].Time.Equal(track[10].Time) {
+		t.Errorf("first spike = %+v", spikes[0])
+	}
+	for _, i := range []int{10, 20} {
+		if track[i].PowerInWatts != 200 {
+			t.Errorf("power %d = %d, want 200", i, track[i].PowerInWatts)
+		}
+	}
+	if track[30].PowerInWatts != 200 || track[31].PowerInWatts != 200 {
+		t.Errorf("double spike replaced by %d, %d", track[30].PowerInWatts, track[31].PowerInWatts)
+	}
+	if track[44].PowerInWatts != 1100 {
+		t.Errorf("sprint peak changed to %d", track[44].PowerInWatts)
+	}
+	if got := a.MeanMaxPower().At(1); got != 1100 {
+		t.Errorf("max power after filtering = %v, want 1100", got)
+	}
+}
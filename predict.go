@@ -0,0 +1,113 @@
+package tcx
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Standard race distances, in meters.
+const (
+	Distance5K           = 5000.0
+	Distance10K          = 10000.0
+	DistanceHalfMarathon = 21097.5
+	DistanceMarathon     = 42195.0
+)
+
+// StandardRaceDistances lists the distances predicted by PredictRaceTimes.
+var StandardRaceDistances = []float64{Distance5K, Distance10K, DistanceHalfMarathon, DistanceMarathon}
+
+// RiegelExponent is the fatigue factor used by PredictRiegel.
+const RiegelExponent = 1.06
+
+// Effort is a performance over a known distance, such as a race result or a
+// best effort extracted from an activity.
+type Effort struct {
+	Distance float64 // meters
+	Duration time.Duration
+	Date     time.Time
+}
+
+// Prediction holds the predicted finish times for one race distance.
+type Prediction struct {
+	Distance float64
+	Riegel   time.Duration
+	VO2max   time.Duration
+}
+
+// PredictRiegel predicts the time over distance (meters) from a single effort
+// using Riegel's formula t2 = t1 * (d2/d1)^1.06.
+func PredictRiegel(e Effort, distance float64) time.Duration {
+	if e.Distance <= 0 || e.Duration <= 0 {
+		return 0
+	}
+	s := e.Duration.Seconds() * math.Pow(distance/e.Distance, RiegelExponent)
+	return time.Duration(s * float64(time.Second))
+}
+
+// vdot returns the Daniels-Gilbert VO2max (ml/kg/min) equivalent of covering
+// distance meters in the given number of minutes.
+func vdot(distance, minutes float64) float64 {
+	v := distance / minutes
+	vo2 := -4.60 + 0.182258*v + 0.000104*v*v
+	pct := 0.8 + 0.1894393*math.Exp(-0.012778*minutes) + 0.2989558*math.Exp(-0.1932605*minutes)
+	return vo2 / pct
+}
+
+// VDOT returns the Daniels-Gilbert VO2max estimate (ml/kg/min) of an effort.
+func VDOT(e Effort) float64 {
+	if e.Distance <= 0 || e.Duration <= 0 {
+		return 0
+	}
+	return vdot(e.Distance, e.Duration.Minutes())
+}
+
+// PredictFromVO2max predicts the time over distance (meters) of a runner with
+// the given VO2max using the Daniels-Gilbert equations.
+func PredictFromVO2max(vo2max, distance float64) time.Duration {
+	if vo2max <= 0 || distance <= 0 {
+		return 0
+	}
+	// vdot decreases monotonically with time, so bisect on the duration.
+	lo, hi := 1.0, 48*60.0
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if vdot(distance, mid) > vo2max {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return time.Duration(lo * float64(time.Minute)).Round(time.Second)
+}
+
+// PredictRaceTimes predicts finish times over StandardRaceDistances from a set
+// of recent efforts. The Riegel model extrapolates from the effort closest in
+// distance to each race; the VO2max model uses the best VDOT among efforts.
+func PredictRaceTimes(efforts []Effort) []Prediction {
+	var valid []Effort
+	best := 0.0
+	for _, e := range efforts {
+		if e.Distance <= 0 || e.Duration <= 0 {
+			continue
+		}
+		valid = append(valid, e)
+		best = math.Max(best, VDOT(e))
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+
+	predictions := make([]Prediction, 0, len(StandardRaceDistances))
+	for _, d := range StandardRaceDistances {
+		sort.SliceStable(valid, func(i, j int) bool {
+			return math.Abs(math.Log(valid[i].Distance/d)) < math.Abs(math.Log(valid[j].Distance/d))
+		})
+		predictions = append(predictions, Prediction{
+			Distance: d,
+			Riegel:   PredictRiegel(valid[0], d).Round(time.Second),
+			VO2max:   PredictFromVO2max(best, d),
+		})
+	}
+	return predictions
+}
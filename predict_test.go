@@ -0,0 +1,33 @@
+package tcx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPredictRaceTimes(t *testing.T) {
+	e := Effort{Distance: Distance5K, Duration: 20 * time.Minute}
+
+	if v := VDOT(e); v < 49 || v > 50.5 {
+		t.Errorf("VDOT() = %v, want about 49.8", v)
+	}
+	if got, want := PredictRiegel(e, Distance10K).Round(time.Second), 41*time.Minute+42*time.Second; got != want {
+		t.Errorf("PredictRiegel() = %v, want %v", got, want)
+	}
+
+	p := PredictRaceTimes([]Effort{e, {Distance: Distance10K, Duration: 44 * time.Minute}})
+	if len(p) != len(StandardRaceDistances) {
+		t.Fatalf("got %d predictions", len(p))
+	}
+	// The 10K prediction extrapolates from the 10K effort itself.
+	if p[1].Riegel != 44*time.Minute {
+		t.Errorf("10K Riegel = %v, want 44m0s", p[1].Riegel)
+	}
+	// Daniels' tables give 41:2x for 10K at VDOT 50.
+	if p[1].VO2max < 41*time.Minute || p[1].VO2max > 42*time.Minute {
+		t.Errorf("10K VO2max = %v", p[1].VO2max)
+	}
+	if PredictRaceTimes(nil) != nil {
+		t.Error("expected no predictions without efforts")
+	}
+}
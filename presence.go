@@ -0,0 +1,220 @@
+package tcx
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// presence is a set of trackpoint values recorded as zero.
+type presence uint8
+
+const (
+	zeroPosition presence = 1 << iota
+	zeroAltitude
+	zeroHeartRate
+	zeroCadence
+	zeroDistance
+	zeroPower
+	zeroSpeed
+)
+
+// Trackpoint values are plain numbers, with zero standing for a value the
+// device did not record. A value recorded as zero, such as an altitude at
+// sea level or a cadence while coasting, is remembered apart so that the
+// Has methods below tell it from a missing one, and it is written back.
+
+// HasPosition reports whether the trackpoint carries a GPS fix.
+func (p *Trackpoint) HasPosition() bool {
+	return p.LatitudeInDegrees != 0 || p.LongitudeInDegrees != 0 || p.zeros&zeroPosition != 0
+}
+
+// HasAltitude reports whether the trackpoint carries an altitude.
+func (p *Trackpoint) HasAltitude() bool {
+	return p.AltitudeInMeters != 0 || p.zeros&zeroAltitude != 0
+}
+
+// HasHeartRate reports whether the trackpoint carries a heart rate.
+func (p *Trackpoint) HasHeartRate() bool {
+	return p.HeartRateInBpm != 0 || p.zeros&zeroHeartRate != 0
+}
+
+// HasCadence reports whether the trackpoint carries a cadence.
+func (p *Trackpoint) HasCadence() bool {
+	return p.Cadence != 0 || p.zeros&zeroCadence != 0
+}
+
+// HasDistance reports whether the trackpoint carries the distance covered
+// since the start of the activity.
+func (p *Trackpoint) HasDistance() bool {
+	return p.DistanceInMeters != 0 || p.zeros&zeroDistance != 0
+}
+
+// HasPower reports whether the trackpoint carries a power reading.
+func (p *Trackpoint) HasPower() bool {
+	return p.PowerInWatts != 0 || p.zeros&zeroPower != 0
+}
+
+// HasSpeed reports whether the trackpoint carries a speed reading.
+func (p *Trackpoint) HasSpeed() bool {
+	return p.SpeedInMetersPerSec != 0 || p.zeros&zeroSpeed != 0
+}
+
+// set records whether a value of the trackpoint is a recorded zero.
+func (p *Trackpoint) set(flag presence, zero bool) {
+	if zero {
+		p.zeros |= flag
+	} else {
+		p.zeros &^= flag
+	}
+}
+
+// SetPosition sets the GPS fix of the trackpoint, 0, 0 included.
+func (p *Trackpoint) SetPosition(lat, lon float64) {
+	p.LatitudeInDegrees, p.LongitudeInDegrees = lat, lon
+	p.set(zeroPosition, lat == 0 && lon == 0)
+}
+
+// SetAltitude sets the altitude of the trackpoint, zero included.
+func (p *Trackpoint) SetAltitude(meters float64) {
+	p.AltitudeInMeters = meters
+	p.set(zeroAltitude, meters == 0)
+}
+
+// SetHeartRate sets the heart rate of the trackpoint, zero included.
+func (p *Trackpoint) SetHeartRate(bpm int) {
+	p.HeartRateInBpm = bpm
+	p.set(zeroHeartRate, bpm == 0)
+}
+
+// SetCadence sets the cadence of the trackpoint, zero included.
+func (p *Trackpoint) SetCadence(c int) {
+	p.Cadence = c
+	p.set(zeroCadence, c == 0)
+}
+
+// SetDistance sets the distance covered since the start of the activity at
+// the trackpoint, zero included.
+func (p *Trackpoint) SetDistance(meters float64) {
+	p.DistanceInMeters = meters
+	p.set(zeroDistance, meters == 0)
+}
+
+// SetPower sets the power of the trackpoint, zero included.
+func (p *Trackpoint) SetPower(watts int) {
+	p.PowerInWatts = watts
+	p.set(zeroPower, watts == 0)
+}
+
+// SetSpeed sets the speed of the trackpoint, zero included.
+func (p *Trackpoint) SetSpeed(metersPerSec float64) {
+	p.SpeedInMetersPerSec = metersPerSec
+	p.set(zeroSpeed, metersPerSec == 0)
+}
+
+// ClearPosition marks the GPS fix of the trackpoint as missing.
+func (p *Trackpoint) ClearPosition() {
+	p.LatitudeInDegrees, p.LongitudeInDegrees = 0, 0
+	p.zeros &^= zeroPosition
+}
+
+// ClearAltitude marks the altitude of the trackpoint as missing.
+func (p *Trackpoint) ClearAltitude() {
+	p.AltitudeInMeters = 0
+	p.zeros &^= zeroAltitude
+}
+
+// ClearHeartRate marks the heart rate of the trackpoint as missing.
+func (p *Trackpoint) ClearHeartRate() {
+	p.HeartRateInBpm = 0
+	p.zeros &^= zeroHeartRate
+}
+
+// ClearCadence marks the cadence of the trackpoint as missing.
+func (p *Trackpoint) ClearCadence() {
+	p.Cadence = 0
+	p.zeros &^= zeroCadence
+}
+
+// ClearDistance marks the distance of the trackpoint as missing.
+func (p *Trackpoint) ClearDistance() {
+	p.DistanceInMeters = 0
+	p.zeros &^= zeroDistance
+}
+
+// ClearPower marks the power of the trackpoint as missing.
+func (p *Trackpoint) ClearPower() {
+	p.PowerInWatts = 0
+	p.zeros &^= zeroPower
+}
+
+// ClearSpeed marks the speed of the trackpoint as missing.
+func (p *Trackpoint) ClearSpeed() {
+	p.SpeedInMetersPerSec = 0
+	p.zeros &^= zeroSpeed
+}
+
+// trackpointIn is a Trackpoint as read, with pointers telling missing
+// elements from zeros. Names carry no namespace so that TPX extensions
+// decode whatever their prefix.
+type trackpointIn struct {
+	Time       time.Time   `xml:"Time"`
+	Latitude   *float64    `xml:"Position>LatitudeDegrees"`
+	Longitude  *float64    `xml:"Position>LongitudeDegrees"`
+	Altitude   *float64    `xml:"AltitudeMeters"`
+	Distance   *float64    `xml:"DistanceMeters"`
+	HeartRate  heartRateIn `xml:"HeartRateBpm"`
+	Cadence    *int        `xml:"Cadence"`
+	Extensions struct {
+		TPX struct {
+			Speed       *float64 `xml:"Speed"`
+			RunCadence  int      `xml:"RunCadence"`
+			Watts       *int     `xml:"Watts"`
+			RRIntervals []int    `xml:"RR"`
+		} `xml:"TPX"`
+		HRV struct {
+			RRIntervals []int `xml:"RR"`
+		} `xml:"https://github.com/rdifrango/go-tcx/hrv/v1 HRV"`
+		Other []ExtensionElement `xml:",any"`
+	} `xml:"Extensions"`
+}
+
+// UnmarshalXML reads a trackpoint, remembering the values recorded as zero.
+func (p *Trackpoint) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v trackpointIn
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	tpx := v.Extensions.TPX
+	*p = Trackpoint{
+		Time:            v.Time,
+		RunCadence:      tpx.RunCadence,
+		RRIntervals:     v.Extensions.HRV.RRIntervals,
+		OtherExtensions: v.Extensions.Other,
+	}
+	if p.RRIntervals == nil {
+		// Files written by earlier versions kept them in TPX.
+		p.RRIntervals = tpx.RRIntervals
+	}
+	if v.Latitude != nil && v.Longitude != nil {
+		p.SetPosition(*v.Latitude, *v.Longitude)
+	}
+	if v.Altitude != nil {
+		p.SetAltitude(*v.Altitude)
+	}
+	if v.Distance != nil {
+		p.SetDistance(*v.Distance)
+	}
+	if v.HeartRate.ok {
+		p.SetHeartRate(v.HeartRate.bpm)
+	}
+	if v.Cadence != nil {
+		p.SetCadence(*v.Cadence)
+	}
+	if tpx.Watts != nil {
+		p.SetPower(*tpx.Watts)
+	}
+	if tpx.Speed != nil {
+		p.SetSpeed(*tpx.Speed)
+	}
+	return nil
+}
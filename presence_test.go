@@ -0,0 +1,88 @@
+package tcx
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const zerosTCX = `<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+  <Activities><Activity Sport="Biking"><Id>2015-04-12T07:00:00Z</Id>
+    <Lap StartTime="2015-04-12T07:00:00Z"><TotalTimeSeconds>2</TotalTimeSeconds><Track>
+      <Trackpoint>
+        <Time>2015-04-12T07:00:00Z</Time>
+        <Position><LatitudeDegrees>0</LatitudeDegrees><LongitudeDegrees>0</LongitudeDegrees></Position>
+        <AltitudeMeters>0</AltitudeMeters>
+        <HeartRateBpm><Value>120</Value></HeartRateBpm>
+        <Cadence>0</Cadence>
+        <Extensions><TPX xmlns="http://www.garmin.com/xmlschemas/ActivityExtension/v2"><Speed>0</Speed><Watts>0</Watts></TPX></Extensions>
+      </Trackpoint>
+      <Trackpoint>
+        <Time>2015-04-12T07:00:01Z</Time>
+        <Cadence>90</Cadence>
+      </Trackpoint>
+      <Trackpoint>
+        <Time>2015-04-12T07:00:02Z</Time>
+      </Trackpoint>
+    </Track></Lap>
+  </Activity></Activities>
+</TrainingCenterDatabase>`
+
+func TestPresence(t *testing.T) {
+	db, err := Parse(strings.NewReader(zerosTCX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	track := db.Activities[0].Laps[0].Track
+	p, q := &track[0], &track[1]
+	if !p.HasPosition() || !p.HasAltitude() || !p.HasCadence() || !p.HasHeartRate() || !p.HasPower() || !p.HasSpeed() {
+		t.Errorf("recorded zeros read as missing: %+v", *p)
+	}
+	if q.HasPosition() || q.HasAltitude() || q.HasHeartRate() || !q.HasCadence() || q.HasPower() || q.HasSpeed() {
+		t.Errorf("missing values read as recorded: %+v", *q)
+	}
+	if c := db.Activities[0].AverageCadence(); c != 45 {
+		t.Errorf("AverageCadence() = %v, want 45", c)
+	}
+
+	var b bytes.Buffer
+	if err := db.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	back, err := Parse(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.Activities[0].Laps[0].Track, track) {
+		t.Errorf("recorded zeros lost through Write:\n%s", b.String())
+	}
+	data, err := json.Marshal(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON Tcx
+	if err := json.Unmarshal(data, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromJSON.Activities[0].Laps[0].Track, track) {
+		t.Errorf("recorded zeros lost through JSON: %s", data)
+	}
+
+	p.ClearCadence()
+	p.ClearPosition()
+	if p.HasCadence() || p.HasPosition() {
+		t.Error("Clear left values present")
+	}
+	var r Trackpoint
+	r.SetAltitude(0)
+	if !r.HasAltitude() || r.HasHeartRate() {
+		t.Errorf("SetAltitude(0): %+v", r)
+	}
+	r.SetAltitude(12)
+	r.ClearAltitude()
+	if r.HasAltitude() || r.AltitudeInMeters != 0 {
+		t.Errorf("ClearAltitude(): %+v", r)
+	}
+}
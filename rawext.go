@@ -0,0 +1,110 @@
+package tcx
+
+import (
+	"bytes"
+	"encoding/xml"
+)
+
+// ExtensionElement is an element of an Extensions block that the package
+// does not interpret, such as vendor data, kept as read so that writing the
+// file back preserves it. Text is the character data before the first
+// child, and the Tail of each child the character data following it, so
+// that mixed content is kept; between children, whitespace only is left
+// to the indentation of the writer.
+type ExtensionElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr         `xml:",any,attr"`
+	Text     string             `xml:",chardata"`
+	Children []ExtensionElement `xml:",any"`
+	Tail     string             `xml:"-"`
+}
+
+// UnmarshalXML reads the element and its descendants, leaving out namespace
+// declarations, which the encoder writes again from the element names.
+func (x *ExtensionElement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*x = ExtensionElement{XMLName: start.Name}
+	for _, a := range start.Attr {
+		if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+			continue
+		}
+		x.Attrs = append(x.Attrs, a)
+	}
+	var text []byte
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			x.setText(text)
+			text = nil
+			var c ExtensionElement
+			if err := c.UnmarshalXML(d, t); err != nil {
+				return err
+			}
+			x.Children = append(x.Children, c)
+		case xml.CharData:
+			text = append(text, t...)
+		case xml.EndElement:
+			if len(x.Children) == 0 {
+				x.Text = string(text)
+			} else {
+				x.setText(text)
+			}
+			return nil
+		}
+	}
+}
+
+// setText stores the character data read before the next child, or the end
+// of an element with children, dropping whitespace only.
+func (x *ExtensionElement) setText(text []byte) {
+	if len(bytes.TrimSpace(text)) == 0 {
+		return
+	}
+	if n := len(x.Children); n > 0 {
+		x.Children[n-1].Tail = string(text)
+	} else {
+		x.Text = string(text)
+	}
+}
+
+// MarshalXML writes the element with its character data and children in
+// document order.
+func (x ExtensionElement) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{Name: x.XMLName, Attr: x.Attrs}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if x.Text != "" {
+		if err := e.EncodeToken(xml.CharData(x.Text)); err != nil {
+			return err
+		}
+	}
+	for _, c := range x.Children {
+		if err := e.EncodeElement(c, xml.StartElement{Name: c.XMLName}); err != nil {
+			return err
+		}
+		if c.Tail != "" {
+			if err := e.EncodeToken(xml.CharData(c.Tail)); err != nil {
+				return err
+			}
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// cloneElements returns a deep copy of elements.
+func cloneElements(elements []ExtensionElement) []ExtensionElement {
+	if elements == nil {
+		return nil
+	}
+	c := make([]ExtensionElement, len(elements))
+	for i, x := range elements {
+		x.Attrs = append([]xml.Attr(nil), x.Attrs...)
+		x.Children = cloneElements(x.Children)
+		c[i] = x
+	}
+	return c
+}
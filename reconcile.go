@@ -0,0 +1,148 @@
+package tcx
+
+import (
+	"math"
+	"time"
+)
+
+// Distance sources chosen by ReconcileDistance.
+const (
+	DistanceFromGPS     = "gps"
+	DistanceFromSensor  = "sensor"
+	DistanceFromBlended = "blended"
+)
+
+// ReconcileOptions configures footpod and GPS distance reconciliation.
+type ReconcileOptions struct {
+	// Segment is the length of the spans judged separately.
+	Segment time.Duration
+	// MinCoverage is the share of a segment that must be covered by GPS
+	// fixes for GPS to be used there; below it, as in tunnels or under
+	// tree cover, the sensor wins.
+	MinCoverage float64
+	// Tolerance is the relative difference under which both sources agree
+	// and are averaged. When they disagree outdoors, GPS wins.
+	Tolerance float64
+}
+
+// DefaultReconcileOptions suits footpods and wheel sensors calibrated within
+// a few percent.
+var DefaultReconcileOptions = ReconcileOptions{Segment: 30 * time.Second, MinCoverage: 0.8, Tolerance: 0.05}
+
+// DistanceSegment is the reconciled distance of one segment.
+type DistanceSegment struct {
+	Start, End time.Time
+	Source     string
+	// GPS and Sensor are the distances, in meters, measured by each source.
+	GPS, Sensor float64
+	// Distance is the distance kept.
+	Distance float64
+}
+
+// Reconciliation is the result of ReconcileDistance.
+type Reconciliation struct {
+	Segments []DistanceSegment
+	// Cumulative is the reconciled distance, in meters, at each trackpoint
+	// of the activity in lap order.
+	Cumulative []float64
+}
+
+// ReconcileDistance chooses or blends the speed sensor and GPS distances of
+// the activity segment by segment, and rewrites the lap distances from the
+// result so that they add up to the reconciled cumulative distance. Laps
+// neither source covers keep their recorded distance.
+func (a *Activity) ReconcileDistance(opts ReconcileOptions) Reconciliation {
+	a.Invalidate()
+	pts := a.trackpoints()
+	var r Reconciliation
+	if len(pts) == 0 {
+		return r
+	}
+	gps := make([]float64, len(pts))
+	sensor := make([]float64, len(pts))
+	hasGPS := make([]bool, len(pts))
+	hasSensor := make([]bool, len(pts))
+	dts := make([]float64, len(pts))
+	for i := 1; i < len(pts); i++ {
+		p, q := pts[i-1], pts[i]
+		dt := q.Time.Sub(p.Time)
+		if dt <= 0 || dt > maxHoldGap {
+			continue
+		}
+		dts[i] = dt.Seconds()
+		if p.HasPosition() && q.HasPosition() {
+			gps[i] = distance(p.LatitudeInDegrees, p.LongitudeInDegrees, q.LatitudeInDegrees, q.LongitudeInDegrees)
+			hasGPS[i] = true
+		}
+		if p.SpeedInMetersPerSec > 0 || q.SpeedInMetersPerSec > 0 {
+			sensor[i] = p.SpeedInMetersPerSec * dts[i]
+			hasSensor[i] = true
+		}
+	}
+
+	steps := make([]float64, len(pts))
+	for from := 1; from < len(pts); {
+		to := from
+		for to < len(pts) && pts[to].Time.Sub(pts[from-1].Time) <= opts.Segment {
+			to++
+		}
+		if to == from {
+			to++
+		}
+		s := DistanceSegment{Start: pts[from-1].Time, End: pts[to-1].Time}
+		var covered, total float64
+		sensed := false
+		for i := from; i < to; i++ {
+			s.GPS += gps[i]
+			s.Sensor += sensor[i]
+			total += dts[i]
+			if hasGPS[i] {
+				covered += dts[i]
+			}
+			sensed = sensed || hasSensor[i]
+		}
+		switch {
+		case !sensed:
+			s.Source = DistanceFromGPS
+		case total == 0 || covered/total < opts.MinCoverage:
+			s.Source = DistanceFromSensor
+		case math.Abs(s.GPS-s.Sensor) <= opts.Tolerance*math.Max(s.GPS, s.Sensor):
+			s.Source = DistanceFromBlended
+		default:
+			s.Source = DistanceFromGPS
+		}
+		for i := from; i < to; i++ {
+			switch s.Source {
+			case DistanceFromGPS:
+				steps[i] = gps[i]
+			case DistanceFromSensor:
+				steps[i] = sensor[i]
+			default:
+				steps[i] = (gps[i] + sensor[i]) / 2
+			}
+			s.Distance += steps[i]
+		}
+		r.Segments = append(r.Segments, s)
+		from = to
+	}
+
+	r.Cumulative = make([]float64, len(pts))
+	k := 0
+	for l := range a.Laps {
+		lap := 0.0
+		measured := false
+		for range a.Laps[l].Track {
+			lap += steps[k]
+			measured = measured || hasGPS[k] || hasSensor[k]
+			if k > 0 {
+				r.Cumulative[k] = r.Cumulative[k-1] + steps[k]
+			}
+			k++
+		}
+		// Keep the recorded distance of laps no source measured.
+		if measured {
+			a.Laps[l].DistanceInMeters = lap
+		}
+	}
+	return r
+}
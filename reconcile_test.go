@@ -0,0 +1,62 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestReconcileDistance(t *testing.T) {
+	// 300 s at 3 m/s through a tunnel from 120 to 180 s, with the footpod
+	// reading 20% fast for the last minute.
+	a := testActivity(SportRunning, repeat(3.0, 301), nil)
+	track := a.Laps[0].Track
+	for i := 120; i <= 180; i++ {
+		track[i].LatitudeInDegrees, track[i].LongitudeInDegrees = 0, 0
+	}
+	for i := 240; i <= 300; i++ {
+		track[i].SpeedInMetersPerSec = 3.6
+	}
+	a.Laps = []Lap{
+		{StartTime: track[0].Time, Track: track[:150]},
+		{StartTime: track[150].Time, Track: track[150:]},
+	}
+
+	r := a.ReconcileDistance(DefaultReconcileOptions)
+	if len(r.Segments) != 10 {
+		t.Fatalf("%d segments, want 10", len(r.Segments))
+	}
+	want := []string{
+		DistanceFromBlended, DistanceFromBlended, DistanceFromBlended, DistanceFromBlended,
+		DistanceFromSensor, DistanceFromSensor,
+		DistanceFromBlended, DistanceFromBlended,
+		DistanceFromGPS, DistanceFromGPS,
+	}
+	for i, s := range r.Segments {
+		if s.Source != want[i] {
+			t.Errorf("segment %d from %s, want %s (gps %.1f, sensor %.1f)", i, s.Source, want[i], s.GPS, s.Sensor)
+		}
+	}
+	total := r.Cumulative[len(r.Cumulative)-1]
+	if math.Abs(total-900) > 5 {
+		t.Errorf("reconciled distance = %v, want about 900", total)
+	}
+	if d := a.TotalDistance(); math.Abs(d-total) > 1e-6 {
+		t.Errorf("lap distances add up to %v, want %v", d, total)
+	}
+	if math.Abs(a.Laps[0].DistanceInMeters-447) > 5 {
+		t.Errorf("first lap distance = %v", a.Laps[0].DistanceInMeters)
+	}
+}
+
+func TestReconcileDistanceUnmeasured(t *testing.T) {
+	// A lap with neither fixes nor speeds keeps its recorded distance.
+	a := testActivity(SportRunning, repeat(0.0, 60), nil)
+	for i := range a.Laps[0].Track {
+		a.Laps[0].Track[i].ClearPosition()
+	}
+	a.Laps[0].DistanceInMeters = 250
+	a.ReconcileDistance(DefaultReconcileOptions)
+	if d := a.Laps[0].DistanceInMeters; d != 250 {
+		t.Errorf("lap distance = %v, want 250", d)
+	}
+}
@@ -0,0 +1,205 @@
+package tcx
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Sample is a reading of one or more sensors at one instant, as fed to a
+// Recorder. Zero fields carry no reading.
+type Sample struct {
+	Time      time.Time
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+	HeartRate int
+	Cadence   int
+	Speed     float64
+	Power     int
+	// RRIntervals are R-R intervals in milliseconds, appended to those of
+	// the trackpoint.
+	RRIntervals []int
+}
+
+// Recorder builds an activity from live samples. It is safe for concurrent
+// use, so sensors may record from their own goroutines while the activity
+// in progress is read from another.
+type Recorder struct {
+	mu       sync.Mutex
+	activity Activity
+	totals   lapTotals
+	// now stamps samples received without a time.
+	now func() time.Time
+}
+
+// NewRecorder returns a recorder of a new activity of the given sport.
+func NewRecorder(sport string) *Recorder {
+	return &Recorder{activity: Activity{Sport: sport}, now: time.Now}
+}
+
+// Record adds a sample to the current lap. Samples must come in time order;
+// samples of the same instant, from different sensors, are merged into one
+// trackpoint.
+func (r *Recorder) Record(s Sample) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s.Time.IsZero() {
+		s.Time = r.now()
+	}
+	s.Time = s.Time.UTC()
+
+	a := &r.activity
+	if len(a.Laps) == 0 {
+		a.ID = s.Time
+		a.Laps = []Lap{{StartTime: s.Time, Intensity: "Active", TriggerMethod: "Manual"}}
+	}
+	l := &a.Laps[len(a.Laps)-1]
+	if n := len(l.Track); n > 0 {
+		last := &l.Track[n-1]
+		switch {
+		case s.Time.Before(last.Time):
+			return fmt.Errorf("sample at %v is older than the last one at %v", s.Time, last.Time)
+		case s.Time.Equal(last.Time):
+			mergeSample(last, s)
+			r.totals.apply(l)
+			return nil
+		}
+		r.totals.add(l.Track)
+	}
+	var p Trackpoint
+	p.Time = s.Time
+	mergeSample(&p, s)
+	l.Track = append(l.Track, p)
+	r.totals.apply(l)
+	return nil
+}
+
+// lapTotals accumulates the totals of the lap being recorded over all its
+// trackpoints but the last, which samples may still be merged into. This
+// keeps every sample O(1) however long the lap.
+type lapTotals struct {
+	dist     float64
+	maxSpeed float64
+	hr, nhr  int
+	maxHR    int
+}
+
+// add accumulates the last trackpoint of track, before another is
+// appended.
+func (t *lapTotals) add(track []Trackpoint) {
+	n := len(track)
+	p := &track[n-1]
+	t.maxSpeed = math.Max(t.maxSpeed, p.SpeedInMetersPerSec)
+	if p.HeartRateInBpm > 0 {
+		t.hr += p.HeartRateInBpm
+		t.nhr++
+		t.maxHR = max(t.maxHR, p.HeartRateInBpm)
+	}
+	if n > 1 {
+		t.dist += segmentDistance(&track[n-2], p, true)
+	}
+}
+
+// apply sets the totals of l from the accumulated ones and its last
+// trackpoint, as Lap.update would.
+func (t lapTotals) apply(l *Lap) {
+	n := len(l.Track)
+	t.add(l.Track)
+	l.TotalTimeInSeconds = l.Track[n-1].Time.Sub(l.StartTime).Seconds()
+	l.DistanceInMeters, l.MaximumSpeedInMetersPerSec = t.dist, t.maxSpeed
+	l.MaximumHeartRateInBpm = t.maxHR
+	if t.nhr > 0 {
+		l.AverageHeartRateInBpm = (t.hr + t.nhr/2) / t.nhr
+	}
+}
+
+// mergeSample copies the readings of s into p.
+func mergeSample(p *Trackpoint, s Sample) {
+	if s.Latitude != 0 || s.Longitude != 0 {
+		p.LatitudeInDegrees, p.LongitudeInDegrees = s.Latitude, s.Longitude
+	}
+	if s.Altitude != 0 {
+		p.AltitudeInMeters = s.Altitude
+	}
+	if s.HeartRate != 0 {
+		p.HeartRateInBpm = s.HeartRate
+	}
+	if s.Cadence != 0 {
+		p.Cadence = s.Cadence
+	}
+	if s.Speed != 0 {
+		p.SpeedInMetersPerSec = s.Speed
+	}
+	if s.Power != 0 {
+		p.PowerInWatts = s.Power
+	}
+	p.RRIntervals = append(p.RRIntervals, s.RRIntervals...)
+}
+
+// update recomputes the totals of a lap from its track, measuring distance
+// with segmentDistance.
+func (l *Lap) update() {
+	n := len(l.Track)
+	if n == 0 {
+		return
+	}
+	l.TotalTimeInSeconds = l.Track[n-1].Time.Sub(l.StartTime).Seconds()
+	var dist, maxSpeed float64
+	var hr, nhr int
+	l.MaximumHeartRateInBpm = 0
+	for i := range l.Track {
+		p := &l.Track[i]
+		maxSpeed = math.Max(maxSpeed, p.SpeedInMetersPerSec)
+		if p.HeartRateInBpm > 0 {
+			hr += p.HeartRateInBpm
+			nhr++
+			l.MaximumHeartRateInBpm = max(l.MaximumHeartRateInBpm, p.HeartRateInBpm)
+		}
+		if i == 0 {
+			continue
+		}
+		dist += segmentDistance(&l.Track[i-1], p, true)
+	}
+	l.DistanceInMeters, l.MaximumSpeedInMetersPerSec = dist, maxSpeed
+	if nhr > 0 {
+		l.AverageHeartRateInBpm = (hr + nhr/2) / nhr
+	}
+}
+
+// Lap ends the current lap at t; the next sample starts a new one.
+func (r *Recorder) Lap(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a := &r.activity
+	if len(a.Laps) == 0 {
+		return
+	}
+	l := &a.Laps[len(a.Laps)-1]
+	if d := t.Sub(l.StartTime).Seconds(); d > l.TotalTimeInSeconds {
+		l.TotalTimeInSeconds = d
+	}
+	a.Laps = append(a.Laps, Lap{StartTime: t.UTC(), Intensity: "Active", TriggerMethod: "Manual"})
+	r.totals = lapTotals{}
+}
+
+// Activity returns a copy of the activity recorded so far.
+func (r *Recorder) Activity() *Activity {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a := r.activity.Clone()
+	// Leave out a lap just started and still empty.
+	if n := len(a.Laps); n > 1 && len(a.Laps[n-1].Track) == 0 {
+		a.Laps = a.Laps[:n-1]
+	}
+	return a
+}
+
+// Tcx returns the activity recorded so far in a Tcx, ready to be written.
+func (r *Recorder) Tcx() *Tcx {
+	t := NewTcx()
+	t.XMLNs = TrainingCenterNS
+	t.Activities = []Activity{*r.Activity()}
+	return t
+}
@@ -0,0 +1,59 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	start := time.Date(2020, 5, 1, 7, 0, 0, 0, time.UTC)
+	r := NewRecorder(SportRunning)
+	for i := 0; i <= 60; i++ {
+		at := start.Add(time.Duration(i) * time.Second)
+		// Position and heart rate come from separate sensors.
+		if err := r.Record(Sample{Time: at, Latitude: 47 + float64(i)*3/111194.93, Longitude: -1.5}); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Record(Sample{Time: at, HeartRate: 120 + i}); err != nil {
+			t.Fatal(err)
+		}
+		if i == 30 {
+			r.Lap(at)
+		}
+	}
+	if err := r.Record(Sample{Time: start, HeartRate: 100}); err == nil {
+		t.Error("expected an error for an out of order sample")
+	}
+
+	a := r.Activity()
+	if a.Sport != SportRunning || !a.ID.Equal(start) || len(a.Laps) != 2 {
+		t.Fatalf("got %s activity at %v with %d laps", a.Sport, a.ID, len(a.Laps))
+	}
+	if n := len(a.trackpoints()); n != 61 {
+		t.Errorf("got %d trackpoints, want 61", n)
+	}
+	if a.TotalDuration() != time.Minute || math.Abs(a.TotalDistance()-177) > 0.5 {
+		t.Errorf("got %v and %v m", a.TotalDuration(), a.TotalDistance())
+	}
+	// The running totals match those recomputed from the track.
+	for i, l := range a.Laps {
+		want := l
+		want.update()
+		if l.DistanceInMeters != want.DistanceInMeters || l.TotalTimeInSeconds != want.TotalTimeInSeconds ||
+			l.AverageHeartRateInBpm != want.AverageHeartRateInBpm || l.MaximumHeartRateInBpm != want.MaximumHeartRateInBpm {
+			t.Errorf("lap %d totals = %v m, %v s, %d/%d bpm, want %v m, %v s, %d/%d bpm", i,
+				l.DistanceInMeters, l.TotalTimeInSeconds, l.AverageHeartRateInBpm, l.MaximumHeartRateInBpm,
+				want.DistanceInMeters, want.TotalTimeInSeconds, want.AverageHeartRateInBpm, want.MaximumHeartRateInBpm)
+		}
+	}
+	if p := a.Laps[1].Track[0]; p.HeartRateInBpm != 151 || !p.HasPosition() {
+		t.Errorf("merged trackpoint = %+v", p)
+	}
+
+	// The snapshot is not affected by further recording.
+	r.Record(Sample{Time: start.Add(2 * time.Minute), HeartRate: 150})
+	if n := len(a.trackpoints()); n != 61 {
+		t.Errorf("snapshot grew to %d trackpoints", n)
+	}
+}
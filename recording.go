@@ -0,0 +1,79 @@
+package tcx
+
+import (
+	"sort"
+	"time"
+)
+
+// Recording describes how the trackpoints of an activity were sampled.
+type Recording struct {
+	// Smart is true for irregular intervals, as written by Garmin smart
+	// recording, and false for every-second recording.
+	Smart bool
+	// Interval is the median interval between trackpoints.
+	Interval time.Duration
+	// Rate is the effective sample rate, in Hz, pauses excluded.
+	Rate float64
+}
+
+// smartShare is the share of intervals longer than a second above which a
+// file is smart recorded. Every-second files only skip the odd sample.
+const smartShare = 0.25
+
+// Recording detects whether the activity uses smart recording and reports
+// its effective sample rate. Gaps longer than 10 s are pauses and ignored.
+func (a *Activity) Recording() Recording {
+	var intervals []time.Duration
+	var total time.Duration
+	long := 0
+	pts := a.trackpoints()
+	for i := 1; i < len(pts); i++ {
+		dt := pts[i].Time.Sub(pts[i-1].Time)
+		if dt <= 0 || dt > maxHoldGap {
+			continue
+		}
+		intervals = append(intervals, dt)
+		total += dt
+		if dt > time.Second {
+			long++
+		}
+	}
+	if len(intervals) == 0 {
+		return Recording{}
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	r := Recording{
+		Interval: intervals[len(intervals)/2],
+		Rate:     float64(len(intervals)) / total.Seconds(),
+	}
+	r.Smart = r.Interval > time.Second || float64(long)/float64(len(intervals)) > smartShare
+	return r
+}
+
+// perSecond returns the trackpoints of a smart recorded activity expanded
+// to one per second, each trackpoint held until the next one as in
+// resample1Hz, so that averages over samples are averages over time. It
+// returns the trackpoints themselves for every-second recordings.
+func (a *Activity) perSecond() []*Trackpoint {
+	pts := a.trackpoints()
+	if !a.Recording().Smart {
+		return pts
+	}
+	var out []*Trackpoint
+	for i, p := range pts {
+		out = append(out, p)
+		if i+1 == len(pts) {
+			break
+		}
+		gap := pts[i+1].Time.Sub(p.Time)
+		if gap > maxHoldGap {
+			continue
+		}
+		for t := time.Second; t < gap; t += time.Second {
+			q := *p
+			q.Time = p.Time.Add(t)
+			out = append(out, &q)
+		}
+	}
+	return out
+}
@@ -0,0 +1,34 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRecording(t *testing.T) {
+	a := testActivity(SportRunning, repeat(3.0, 100), repeat(150, 100))
+	r := a.Recording()
+	if r.Smart || r.Interval != time.Second || r.Rate != 1 {
+		t.Errorf("every-second recording = %+v", r)
+	}
+
+	// Smart recording: 90 s at 120 bpm sampled every 6 s, then 30 s at
+	// 160 bpm sampled every second.
+	var track []Trackpoint
+	start := time.Date(2015, 4, 12, 7, 0, 0, 0, time.UTC)
+	for s := 0; s < 90; s += 6 {
+		track = append(track, Trackpoint{Time: start.Add(time.Duration(s) * time.Second), HeartRateInBpm: 120, SpeedInMetersPerSec: 2})
+	}
+	for s := 90; s <= 120; s++ {
+		track = append(track, Trackpoint{Time: start.Add(time.Duration(s) * time.Second), HeartRateInBpm: 160, SpeedInMetersPerSec: 4})
+	}
+	smart := &Activity{Sport: SportRunning, Laps: []Lap{{StartTime: start, Track: track}}}
+	r = smart.Recording()
+	if !r.Smart || math.Abs(r.Rate-45.0/120) > 1e-9 {
+		t.Errorf("smart recording = %+v", r)
+	}
+	if hr, n := smart.AverageHeartbeat(); math.Abs(hr-(90*120+31*160)/121.0) > 1e-9 || n != 121 {
+		t.Errorf("AverageHeartbeat() = %v over %d samples, want the time-weighted mean", hr, n)
+	}
+}
@@ -0,0 +1,93 @@
+package tcx
+
+import "reflect"
+
+// RepairOptions configures Repair.
+type RepairOptions struct {
+	// DropOutliers enables the removal of GPS outliers.
+	DropOutliers bool
+	// MaxSpeed is the speed, in m/s, above which a jump to a GPS fix and
+	// back is implausible and the fix an outlier.
+	MaxSpeed float64
+}
+
+// DefaultRepairOptions drops fixes reached faster than 50 m/s.
+var DefaultRepairOptions = RepairOptions{
+	DropOutliers: true,
+	MaxSpeed:     50,
+}
+
+// RepairReport counts the changes made by Repair.
+type RepairReport struct {
+	// Duplicates is the number of trackpoints dropped as exact copies of
+	// the trackpoint before them.
+	Duplicates int
+	// OutOfOrder is the number of trackpoints dropped for not coming after
+	// the trackpoint before them.
+	OutOfOrder int
+	// Outliers is the number of GPS fixes removed as outliers; their
+	// trackpoints and other values are kept.
+	Outliers int
+}
+
+// Changed reports whether Repair modified the activity.
+func (r RepairReport) Changed() bool {
+	return r.Duplicates+r.OutOfOrder+r.Outliers > 0
+}
+
+// Repair cleans up the trackpoints of the activity: it drops exact
+// duplicates and trackpoints whose time does not move forward, then, when
+// opts.DropOutliers is set, removes the GPS fixes that could only be reached
+// and left faster than opts.MaxSpeed. Lap totals are left as recorded.
+func (a *Activity) Repair(opts RepairOptions) RepairReport {
+	a.Invalidate()
+	var r RepairReport
+	var last *Trackpoint
+	for i := range a.Laps {
+		l := &a.Laps[i]
+		track := l.Track[:0]
+		for _, p := range l.Track {
+			switch {
+			case last != nil && reflect.DeepEqual(p, *last):
+				r.Duplicates++
+			case last != nil && !p.Time.After(last.Time):
+				r.OutOfOrder++
+			default:
+				track = append(track, p)
+				last = &track[len(track)-1]
+			}
+		}
+		l.Track = track
+	}
+	if opts.DropOutliers && opts.MaxSpeed > 0 {
+		r.Outliers = a.dropOutliers(opts.MaxSpeed)
+	}
+	return r
+}
+
+// dropOutliers clears the positions that are too fast to reach from the
+// previous fix and to leave for the next one, and returns their number.
+func (a *Activity) dropOutliers(maxSpeed float64) int {
+	var fixes []*Trackpoint
+	for _, p := range a.trackpoints() {
+		if p.HasPosition() {
+			fixes = append(fixes, p)
+		}
+	}
+	tooFast := func(p, q *Trackpoint) bool {
+		dt := q.Time.Sub(p.Time).Seconds()
+		d := distance(p.LatitudeInDegrees, p.LongitudeInDegrees, q.LatitudeInDegrees, q.LongitudeInDegrees)
+		return dt > 0 && d/dt > maxSpeed
+	}
+	n := 0
+	var prev *Trackpoint
+	for i, p := range fixes {
+		if prev != nil && tooFast(prev, p) && (i+1 == len(fixes) || tooFast(p, fixes[i+1])) {
+			p.ClearPosition()
+			n++
+			continue
+		}
+		prev = p
+	}
+	return n
+}
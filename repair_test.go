@@ -0,0 +1,45 @@
+package tcx
+
+import "testing"
+
+func TestRepair(t *testing.T) {
+	a := testActivity(SportRunning, repeat(4.0, 101), repeat(150, 101))
+	track := a.Laps[0].Track
+	// A duplicated sample, a sample from the past and a GPS spike 5 km away.
+	broken := append([]Trackpoint(nil), track[:50]...)
+	broken = append(broken, track[49], track[10])
+	spike := track[50]
+	spike.LatitudeInDegrees += 0.045
+	broken = append(broken, spike)
+	broken = append(broken, track[51:]...)
+	a.Laps[0].Track = broken
+
+	r := a.Repair(DefaultRepairOptions)
+	if r.Duplicates != 1 || r.OutOfOrder != 1 || r.Outliers != 1 || !r.Changed() {
+		t.Errorf("Repair() = %+v", r)
+	}
+	got := a.Laps[0].Track
+	if len(got) != 101 {
+		t.Fatalf("repaired track has %d trackpoints, want 101", len(got))
+	}
+	if got[50].HasPosition() || got[50].HeartRateInBpm != 150 {
+		t.Errorf("outlier = %+v, want its position cleared only", got[50])
+	}
+	for i := 1; i < len(got); i++ {
+		if !got[i].Time.After(got[i-1].Time) {
+			t.Fatalf("trackpoint %d at %v not after %v", i, got[i].Time, got[i-1].Time)
+		}
+	}
+	if d := a.ComputedDistance(); d > 500 {
+		t.Errorf("distance after repair = %v m", d)
+	}
+
+	if r := a.Repair(DefaultRepairOptions); r.Changed() {
+		t.Errorf("second Repair() = %+v", r)
+	}
+	b := testActivity(SportRunning, repeat(4.0, 10), nil)
+	b.Laps[0].Track[5].LatitudeInDegrees += 0.045
+	if r := b.Repair(RepairOptions{}); r.Outliers != 0 || !b.Laps[0].Track[5].HasPosition() {
+		t.Errorf("Repair() without DropOutliers = %+v", r)
+	}
+}
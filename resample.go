@@ -0,0 +1,101 @@
+package tcx
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Resample replaces the track of every lap with trackpoints on a uniform
+// time grid, every interval from the first trackpoint of the lap, as
+// expected by analyses and models working on regular series. Position,
+// altitude, distance, heart rate, cadence, speed and power are interpolated
+// linearly between the surrounding trackpoints; a value is only set when
+// both of them record it. R-R intervals go to the new trackpoint nearest in
+// time. No trackpoint is made up within recording pauses longer than 10 s.
+// Extensions other than TPX are dropped.
+func (a *Activity) Resample(interval time.Duration) {
+	a.Invalidate()
+	if interval <= 0 {
+		return
+	}
+	for i := range a.Laps {
+		l := &a.Laps[i]
+		if len(l.Track) == 0 {
+			continue
+		}
+		start, end := l.Track[0].Time, l.Track[len(l.Track)-1].Time
+		track := make([]Trackpoint, 0, int(end.Sub(start)/interval)+1)
+		j := 0
+		for t := start; !t.After(end); t = t.Add(interval) {
+			for j+1 < len(l.Track) && !l.Track[j+1].Time.After(t) {
+				j++
+			}
+			p := &l.Track[j]
+			if p.Time.Equal(t) || j+1 == len(l.Track) {
+				q := interpolateTrackpoint(p, p, 0)
+				q.Time = t
+				track = append(track, q)
+				continue
+			}
+			next := &l.Track[j+1]
+			gap := next.Time.Sub(p.Time)
+			if gap > maxHoldGap {
+				continue
+			}
+			q := interpolateTrackpoint(p, next, float64(t.Sub(p.Time))/float64(gap))
+			q.Time = t
+			track = append(track, q)
+		}
+		assignRRIntervals(track, l.Track)
+		l.Track = track
+	}
+}
+
+// assignRRIntervals appends the R-R intervals of the trackpoints of from to
+// the trackpoint of track nearest in time, keeping every beat.
+func assignRRIntervals(track, from []Trackpoint) {
+	if len(track) == 0 {
+		return
+	}
+	for i := range from {
+		p := &from[i]
+		if len(p.RRIntervals) == 0 {
+			continue
+		}
+		k := sort.Search(len(track), func(k int) bool { return !track[k].Time.Before(p.Time) })
+		if k == len(track) || (k > 0 && p.Time.Sub(track[k-1].Time) <= track[k].Time.Sub(p.Time)) {
+			k--
+		}
+		track[k].RRIntervals = append(track[k].RRIntervals, p.RRIntervals...)
+	}
+}
+
+// interpolateTrackpoint returns the trackpoint at fraction f of the way
+// from p to q, with the values both record. Counts are taken from p.
+func interpolateTrackpoint(p, q *Trackpoint, f float64) Trackpoint {
+	lerp := func(a, b float64) float64 { return a + f*(b-a) }
+	r := Trackpoint{
+		SpeedInMetersPerSec: lerp(p.SpeedInMetersPerSec, q.SpeedInMetersPerSec),
+		RunCadence:          p.RunCadence,
+	}
+	if p.HasPosition() && q.HasPosition() {
+		r.SetPosition(lerp(p.LatitudeInDegrees, q.LatitudeInDegrees), lerp(p.LongitudeInDegrees, q.LongitudeInDegrees))
+	}
+	if p.HasAltitude() && q.HasAltitude() {
+		r.SetAltitude(lerp(p.AltitudeInMeters, q.AltitudeInMeters))
+	}
+	if p.HasDistance() && q.HasDistance() {
+		r.SetDistance(lerp(p.DistanceInMeters, q.DistanceInMeters))
+	}
+	if p.HasHeartRate() && q.HasHeartRate() {
+		r.SetHeartRate(int(math.Round(lerp(float64(p.HeartRateInBpm), float64(q.HeartRateInBpm)))))
+	}
+	if p.HasCadence() && q.HasCadence() {
+		r.SetCadence(int(math.Round(lerp(float64(p.Cadence), float64(q.Cadence)))))
+	}
+	if p.HasPower() && q.HasPower() {
+		r.SetPower(int(math.Round(lerp(float64(p.PowerInWatts), float64(q.PowerInWatts)))))
+	}
+	return r
+}
@@ -0,0 +1,69 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestResample(t *testing.T) {
+	// Trackpoints every 3 s, with a 60 s pause after the tenth.
+	a := testActivity(SportRunning, repeat(3.0, 20), repeat(140, 20))
+	track := a.Laps[0].Track
+	for i := range track {
+		track[i].Time = track[0].Time.Add(time.Duration(3*i) * time.Second)
+		if i >= 10 {
+			track[i].Time = track[i].Time.Add(60 * time.Second)
+		}
+		track[i].SetHeartRate(140 + i)
+	}
+	track[4].ClearAltitude()
+	first, last := track[0].Time, track[19].Time
+
+	a.Resample(time.Second)
+	track = a.Laps[0].Track
+	// 28 trackpoints before the pause and 28 after it.
+	if len(track) != 56 || !track[0].Time.Equal(first) || !track[len(track)-1].Time.Equal(last) {
+		t.Fatalf("resampled to %d trackpoints from %v to %v", len(track), track[0].Time, track[len(track)-1].Time)
+	}
+	for i := 1; i < len(track); i++ {
+		if dt := track[i].Time.Sub(track[i-1].Time); dt != time.Second && dt != 63*time.Second {
+			t.Fatalf("trackpoint %d is %v after the previous one", i, dt)
+		}
+	}
+	if p := track[1]; p.HeartRateInBpm != 140 || !p.HasHeartRate() || math.Abs(p.LatitudeInDegrees-47) < 1e-9 {
+		t.Errorf("interpolated trackpoint = %+v", p)
+	}
+	if p := track[2]; p.HeartRateInBpm != 141 {
+		t.Errorf("heart rate at 2 s = %v, want 141", p.HeartRateInBpm)
+	}
+	// Altitude is missing around the trackpoint that lacks it.
+	if track[10].HasAltitude() || track[12].HasAltitude() || track[14].HasAltitude() || !track[15].HasAltitude() {
+		t.Errorf("altitudes around the gap: %v %v %v %v", track[10].HasAltitude(), track[12].HasAltitude(), track[14].HasAltitude(), track[15].HasAltitude())
+	}
+}
+
+func TestResampleDistanceAndRR(t *testing.T) {
+	a := testActivity(SportRunning, repeat(3.0, 11), nil)
+	for i := range a.Laps[0].Track {
+		p := &a.Laps[0].Track[i]
+		p.SetDistance(3 * float64(i))
+		p.RRIntervals = []int{1000}
+	}
+
+	a.Resample(1500 * time.Millisecond)
+	track := a.Laps[0].Track
+	if len(track) != 7 {
+		t.Fatalf("resampled to %d trackpoints", len(track))
+	}
+	if p := track[1]; !p.HasDistance() || math.Abs(p.DistanceInMeters-4.5) > 1e-9 {
+		t.Errorf("distance at 1.5 s = %v, want 4.5", p.DistanceInMeters)
+	}
+	beats := 0
+	for _, p := range track {
+		beats += len(p.RRIntervals)
+	}
+	if beats != 11 || len(track[6].RRIntervals) != 2 {
+		t.Errorf("kept %d beats, %d at 9 s", beats, len(track[6].RRIntervals))
+	}
+}
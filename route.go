@@ -0,0 +1,130 @@
+package tcx
+
+import (
+	"math"
+	"time"
+)
+
+// RouteEffort is a pass of an activity over a reference route.
+type RouteEffort struct {
+	Activity   *Activity
+	Start, End time.Time
+	Elapsed    time.Duration
+	// Distance is the distance the activity covered over the route, in
+	// meters.
+	Distance float64
+}
+
+// routeCheckpoints places points along the positions of route, at most
+// spacing meters apart, from its first position to its last.
+func routeCheckpoints(route []Trackpoint, spacing float64) [][2]float64 {
+	var track []*Trackpoint
+	for i := range route {
+		if route[i].HasPosition() {
+			track = append(track, &route[i])
+		}
+	}
+	if len(track) == 0 {
+		return nil
+	}
+	cps := [][2]float64{{track[0].LatitudeInDegrees, track[0].LongitudeInDegrees}}
+	for i := 1; i < len(track); i++ {
+		p, q := track[i-1], track[i]
+		d := distance(p.LatitudeInDegrees, p.LongitudeInDegrees, q.LatitudeInDegrees, q.LongitudeInDegrees)
+		n := math.Ceil(d / spacing)
+		for k := 1.0; k <= n; k++ {
+			f := k / n
+			cps = append(cps, [2]float64{
+				p.LatitudeInDegrees + f*(q.LatitudeInDegrees-p.LatitudeInDegrees),
+				p.LongitudeInDegrees + f*(q.LongitudeInDegrees-p.LongitudeInDegrees),
+			})
+		}
+	}
+	return cps
+}
+
+// MatchRoute finds the passes of the activity over route, a reference
+// track such as a segment or a course, in order. A pass goes within
+// tolerance meters of every point of the route, in order, without
+// detours; its times are those of the trackpoints closest to the ends of
+// the route.
+func (a *Activity) MatchRoute(route []Trackpoint, tolerance float64) []RouteEffort {
+	if tolerance <= 0 {
+		return nil
+	}
+	cps := routeCheckpoints(route, tolerance)
+	if len(cps) == 0 {
+		return nil
+	}
+	track := a.fixes()
+	dist := cumulativeDistances(track)
+	from := func(i int, cp [2]float64) float64 {
+		return distance(track[i].LatitudeInDegrees, track[i].LongitudeInDegrees, cp[0], cp[1])
+	}
+	// closest returns the trackpoint closest to cp among those from i that
+	// stay within tolerance of it.
+	closest := func(i int, cp [2]float64) int {
+		best := i
+		for j := i + 1; j < len(track) && from(j, cp) <= tolerance; j++ {
+			if from(j, cp) < from(best, cp) {
+				best = j
+			}
+		}
+		return best
+	}
+
+	var efforts []RouteEffort
+	for i := 0; i < len(track); i++ {
+		if from(i, cps[0]) > tolerance {
+			continue
+		}
+		start := closest(i, cps[0])
+		end, ok := start, true
+		for k := 1; k < len(cps) && ok; k++ {
+			// Past the next checkpoint, the track may have gone no further
+			// than the route allows plus the tolerance on both ends.
+			limit := dist[end] + distance(cps[k-1][0], cps[k-1][1], cps[k][0], cps[k][1]) + 2*tolerance
+			for end < len(track) && from(end, cps[k]) > tolerance {
+				end++
+				if end < len(track) && dist[end] > limit {
+					ok = false
+					break
+				}
+			}
+			ok = ok && end < len(track)
+		}
+		if !ok {
+			// Skip the rest of this approach to the start.
+			for i+1 < len(track) && from(i+1, cps[0]) <= tolerance {
+				i++
+			}
+			continue
+		}
+		end = closest(end, cps[len(cps)-1])
+		efforts = append(efforts, RouteEffort{
+			Activity: a,
+			Start:    track[start].Time,
+			End:      track[end].Time,
+			Elapsed:  track[end].Time.Sub(track[start].Time),
+			Distance: dist[end] - dist[start],
+		})
+		i = end
+	}
+	return efforts
+}
+
+// MatchRoute finds the passes of the activities over route, in
+// chronological order. See Activity.MatchRoute.
+func (c *Collection) MatchRoute(route []Trackpoint, tolerance float64) []RouteEffort {
+	var efforts []RouteEffort
+	for _, a := range c.Activities {
+		efforts = append(efforts, a.MatchRoute(route, tolerance)...)
+	}
+	return efforts
+}
+
+// MatchCourse finds the passes of the activities over the track of the
+// course.
+func (c *Collection) MatchCourse(course *Course, tolerance float64) []RouteEffort {
+	return c.MatchRoute(course.Track, tolerance)
+}
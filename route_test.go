@@ -0,0 +1,47 @@
+package tcx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchRoute(t *testing.T) {
+	// The segment runs 400 m north, from 400 m after the start.
+	ref := testActivity(SportRunning, repeat(4.0, 301), nil)
+	segment := ref.Laps[0].Track[100:201]
+
+	fast := testActivity(SportRunning, repeat(5.0, 301), nil)
+	short := testActivity(SportRunning, repeat(4.0, 150), nil)
+	away := testActivity(SportRunning, repeat(4.0, 301), nil)
+	for i := range away.Laps[0].Track {
+		away.Laps[0].Track[i].LongitudeInDegrees += 0.01
+	}
+	c := NewCollection([]*Activity{fast, short, away, ref})
+
+	efforts := c.MatchRoute(segment, 10)
+	if len(efforts) != 2 {
+		t.Fatalf("%d efforts, want 2: %+v", len(efforts), efforts)
+	}
+	for i, want := range []struct {
+		a       *Activity
+		elapsed time.Duration
+	}{{fast, 80 * time.Second}, {ref, 100 * time.Second}} {
+		e := efforts[i]
+		if e.Activity != want.a || e.Elapsed != want.elapsed || e.Distance < 390 || e.Distance > 410 {
+			t.Errorf("effort %d = %v over %.0f m", i, e.Elapsed, e.Distance)
+		}
+	}
+
+	// Out and back: a detour between the ends of the segment doesn't match.
+	back := testActivity(SportRunning, repeat(4.0, 301), nil)
+	track := back.Laps[0].Track
+	for i := 150; i < len(track); i++ {
+		track[i].LatitudeInDegrees = track[300-i].LatitudeInDegrees
+	}
+	if efforts := back.MatchRoute(segment, 10); len(efforts) != 0 {
+		t.Errorf("out and back matched: %+v", efforts)
+	}
+	if efforts := c.MatchCourse(&Course{}, 10); efforts != nil {
+		t.Errorf("empty course matched: %+v", efforts)
+	}
+}
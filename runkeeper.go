@@ -0,0 +1,153 @@
+package tcx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// runkeeperActivity is a fitness activity of the Runkeeper export.
+type runkeeperActivity struct {
+	Type          string          `json:"type"`
+	StartTime     string          `json:"start_time"`
+	UTCOffset     float64         `json:"utc_offset"`
+	TotalDistance float64         `json:"total_distance"`
+	Duration      float64         `json:"duration"`
+	TotalCalories float64         `json:"total_calories"`
+	Path          []runkeeperPath `json:"path"`
+	HeartRate     []struct {
+		Timestamp float64 `json:"timestamp"`
+		HeartRate int     `json:"heart_rate"`
+	} `json:"heart_rate"`
+	Distance []struct {
+		Timestamp float64 `json:"timestamp"`
+		Distance  float64 `json:"distance"`
+	} `json:"distance"`
+}
+
+type runkeeperPath struct {
+	Timestamp float64 `json:"timestamp"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Altitude  float64 `json:"altitude"`
+	Type      string  `json:"type"`
+}
+
+// runkeeperTimeLayout is the local time format of Runkeeper start times.
+const runkeeperTimeLayout = "Mon, 2 Jan 2006 15:04:05"
+
+// Runkeeper activity types, by TCX sport.
+var runkeeperSports = map[string]string{
+	"Running":  SportRunning,
+	"Cycling":  SportBiking,
+	"Walking":  SportWalking,
+	"Swimming": SportSwimming,
+}
+
+// ParseRunkeeper reads a Runkeeper fitness activity, as returned by its API
+// and data export, into a Tcx. Pauses in the path split the activity into
+// laps.
+func ParseRunkeeper(r io.Reader) (*Tcx, error) {
+	var rk runkeeperActivity
+	if err := json.NewDecoder(r).Decode(&rk); err != nil {
+		return nil, fmt.Errorf("couldn't parse runkeeper data: %v", err)
+	}
+	local, err := time.Parse(runkeeperTimeLayout, rk.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse runkeeper start time: %v", err)
+	}
+	start := local.Add(-time.Duration(rk.UTCOffset * float64(time.Hour)))
+	at := func(ts float64) time.Time {
+		return start.Add(time.Duration(ts * float64(time.Second)))
+	}
+
+	a := Activity{Sport: SportOther, ID: start}
+	if s, ok := runkeeperSports[rk.Type]; ok {
+		a.Sport = s
+	}
+
+	sort.SliceStable(rk.HeartRate, func(i, j int) bool { return rk.HeartRate[i].Timestamp < rk.HeartRate[j].Timestamp })
+	heartRate := func(ts float64) int {
+		i := sort.Search(len(rk.HeartRate), func(i int) bool { return rk.HeartRate[i].Timestamp > ts })
+		if i == 0 {
+			return 0
+		}
+		return rk.HeartRate[i-1].HeartRate
+	}
+	var dts, dvs []float64
+	for _, d := range rk.Distance {
+		dts, dvs = append(dts, d.Timestamp), append(dvs, d.Distance)
+	}
+
+	// Split the path into segments at pauses.
+	var segments [][]runkeeperPath
+	var seg []runkeeperPath
+	for _, p := range rk.Path {
+		if p.Type == "manual" {
+			continue
+		}
+		seg = append(seg, p)
+		if p.Type == "pause" || p.Type == "end" {
+			segments, seg = append(segments, seg), nil
+		}
+	}
+	if len(seg) > 0 {
+		segments = append(segments, seg)
+	}
+	if len(segments) == 0 {
+		segments = [][]runkeeperPath{{{Timestamp: 0}, {Timestamp: rk.Duration}}}
+	}
+
+	for _, seg := range segments {
+		from, to := seg[0].Timestamp, seg[len(seg)-1].Timestamp
+		lap := Lap{
+			StartTime:          at(from),
+			TotalTimeInSeconds: to - from,
+			Intensity:          "Active",
+			TriggerMethod:      "Manual",
+		}
+		for _, p := range seg {
+			if p.Latitude == 0 && p.Longitude == 0 {
+				continue
+			}
+			lap.Track = append(lap.Track, Trackpoint{
+				Time:               at(p.Timestamp),
+				LatitudeInDegrees:  p.Latitude,
+				LongitudeInDegrees: p.Longitude,
+				AltitudeInMeters:   p.Altitude,
+				HeartRateInBpm:     heartRate(p.Timestamp),
+			})
+		}
+		if len(dts) > 0 {
+			lap.DistanceInMeters = interpolate(dts, dvs, to) - interpolate(dts, dvs, from)
+		} else {
+			d := cumulativeDistances(pointers(lap.Track))
+			if len(d) > 0 {
+				lap.DistanceInMeters = d[len(d)-1]
+			}
+		}
+		a.Laps = append(a.Laps, lap)
+	}
+
+	// Share the activity totals between laps by duration.
+	var moving, dist float64
+	for _, l := range a.Laps {
+		moving += l.TotalTimeInSeconds
+		dist += l.DistanceInMeters
+	}
+	for i := range a.Laps {
+		if moving > 0 {
+			a.Laps[i].Calories = rk.TotalCalories * a.Laps[i].TotalTimeInSeconds / moving
+		}
+		if dist == 0 && moving > 0 {
+			a.Laps[i].DistanceInMeters = rk.TotalDistance * a.Laps[i].TotalTimeInSeconds / moving
+		}
+	}
+
+	t := NewTcx()
+	t.XMLNs = TrainingCenterNS
+	t.Activities = []Activity{a}
+	return t, nil
+}
@@ -0,0 +1,53 @@
+package tcx
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRunkeeper = `{
+  "type": "Running",
+  "start_time": "Tue, 1 Mar 2011 07:00:00",
+  "utc_offset": -5,
+  "total_distance": 150,
+  "duration": 70,
+  "total_calories": 12,
+  "path": [
+    {"timestamp": 0, "latitude": 40.0, "longitude": -75.0, "altitude": 5, "type": "start"},
+    {"timestamp": 10, "latitude": 40.0003, "longitude": -75.0, "altitude": 6, "type": "gps"},
+    {"timestamp": 20, "latitude": 40.0006, "longitude": -75.0, "altitude": 7, "type": "pause"},
+    {"timestamp": 50, "latitude": 40.0006, "longitude": -75.0, "altitude": 7, "type": "resume"},
+    {"timestamp": 70, "latitude": 40.0012, "longitude": -75.0, "altitude": 8, "type": "end"}
+  ],
+  "heart_rate": [{"timestamp": 0, "heart_rate": 110}, {"timestamp": 15, "heart_rate": 140}],
+  "distance": [{"timestamp": 0, "distance": 0}, {"timestamp": 20, "distance": 66}, {"timestamp": 50, "distance": 66}, {"timestamp": 70, "distance": 150}]
+}`
+
+func TestParseRunkeeper(t *testing.T) {
+	tcx, err := ParseRunkeeper(strings.NewReader(testRunkeeper))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := tcx.Activities[0]
+	if a.Sport != SportRunning || !a.ID.Equal(time.Date(2011, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %s activity at %v", a.Sport, a.ID)
+	}
+	if len(a.Laps) != 2 {
+		t.Fatalf("got %d laps, want 2", len(a.Laps))
+	}
+	if a.TotalDuration() != 40*time.Second || a.TotalDistance() != 150 {
+		t.Errorf("got %v and %v m", a.TotalDuration(), a.TotalDistance())
+	}
+	if math.Abs(a.Laps[0].Calories-6) > 1e-9 {
+		t.Errorf("first lap calories = %v, want 6", a.Laps[0].Calories)
+	}
+	if hr := a.Laps[0].Track[2].HeartRateInBpm; hr != 140 {
+		t.Errorf("heart rate at 20 s = %d, want 140", hr)
+	}
+
+	if _, err := ParseRunkeeper(strings.NewReader(`{"start_time": "yesterday"}`)); err == nil {
+		t.Error("expected an error for a bad start time")
+	}
+}
@@ -0,0 +1,95 @@
+package tcx
+
+import "time"
+
+// RunWalkOptions configures walk break detection.
+type RunWalkOptions struct {
+	// WalkSpeed is the speed, in m/s, under which a sample may be walking.
+	WalkSpeed float64
+	// WalkCadence is the cadence, in the device's own convention, under
+	// which a sample may be walking. Zero disables the cadence test, as do
+	// samples without cadence.
+	WalkCadence int
+	// StopSpeed is the speed, in m/s, under which the athlete is considered
+	// stopped rather than walking.
+	StopSpeed float64
+	// MinWalk is the shortest span counted as a walk break.
+	MinWalk time.Duration
+}
+
+// DefaultRunWalkOptions suits devices recording cadence in strides (single
+// leg steps) per minute, like most Garmin and Suunto watches.
+var DefaultRunWalkOptions = RunWalkOptions{
+	WalkSpeed:   2.0,
+	WalkCadence: 70,
+	StopSpeed:   0.5,
+	MinWalk:     10 * time.Second,
+}
+
+// WalkBreak is a contiguous walking span within a run.
+type WalkBreak struct {
+	Start    time.Time
+	End      time.Time
+	Distance float64 // meters
+}
+
+// RunWalk summarizes the run and walk parts of an activity. Stopped time
+// belongs to neither.
+type RunWalk struct {
+	RunTime      time.Duration
+	WalkTime     time.Duration
+	RunDistance  float64 // meters
+	WalkDistance float64 // meters
+	Breaks       []WalkBreak
+}
+
+// RunWalk segments the activity into run and walk spans from speed and
+// cadence, as used by run/walk (Galloway) runners. Distances follow the
+// speed sensor; without a speed reading, speed and distance are measured as
+// in intervals, from the recorded distances or GPS.
+func (a *Activity) RunWalk(opts RunWalkOptions) RunWalk {
+	var rw RunWalk
+	var cur *WalkBreak
+	closeBreak := func() {
+		if cur == nil {
+			return
+		}
+		d := cur.End.Sub(cur.Start)
+		if d >= opts.MinWalk {
+			rw.WalkTime += d
+			rw.WalkDistance += cur.Distance
+			rw.Breaks = append(rw.Breaks, *cur)
+		} else {
+			rw.RunTime += d
+			rw.RunDistance += cur.Distance
+		}
+		cur = nil
+	}
+
+	for _, iv := range a.intervals() {
+		if iv.dt <= 0 {
+			continue
+		}
+		p, dt, speed, dist := iv.p, time.Duration(iv.dt*float64(time.Second)), iv.speed(), iv.dist
+		if p.SpeedInMetersPerSec > 0 {
+			dist = p.SpeedInMetersPerSec * iv.dt
+		}
+		switch {
+		case speed < opts.StopSpeed:
+			closeBreak()
+		case speed < opts.WalkSpeed &&
+			(opts.WalkCadence == 0 || p.Cadence == 0 || p.Cadence < opts.WalkCadence):
+			if cur == nil {
+				cur = &WalkBreak{Start: p.Time}
+			}
+			cur.End = p.Time.Add(dt)
+			cur.Distance += dist
+		default:
+			closeBreak()
+			rw.RunTime += dt
+			rw.RunDistance += dist
+		}
+	}
+	closeBreak()
+	return rw
+}
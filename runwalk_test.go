@@ -0,0 +1,54 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRunWalk(t *testing.T) {
+	var speeds []float64
+	for _, run := range []int{240, 240, 120} {
+		speeds = append(speeds, repeat(3.0, run)...)
+		speeds = append(speeds, repeat(1.5, 60)...)
+	}
+	// A short slowdown is not a walk break.
+	speeds = append(speeds[:len(speeds)-60], repeat(1.5, 5)...)
+	speeds = append(speeds, repeat(3.0, 120)...)
+	speeds = append(speeds, repeat(1.5, 60)...)
+	speeds = append(speeds, repeat(3.0, 61)...)
+	a := testActivity("Running", speeds, nil)
+
+	rw := a.RunWalk(DefaultRunWalkOptions)
+	if len(rw.Breaks) != 3 {
+		t.Fatalf("got %d walk breaks, want 3", len(rw.Breaks))
+	}
+	if rw.WalkTime != 3*time.Minute || rw.RunTime != 13*time.Minute+5*time.Second {
+		t.Errorf("walk %v, run %v", rw.WalkTime, rw.RunTime)
+	}
+	if math.Abs(rw.WalkDistance-270) > 1e-9 {
+		t.Errorf("walk distance = %v, want 270", rw.WalkDistance)
+	}
+}
+
+func TestRunWalkWithoutSpeed(t *testing.T) {
+	speeds := append(repeat(3.0, 300), repeat(1.5, 60)...)
+	speeds = append(speeds, repeat(3.0, 61)...)
+	a := testActivity("Running", speeds, nil)
+	for i := range a.Laps[0].Track {
+		a.Laps[0].Track[i].SpeedInMetersPerSec = 0
+	}
+	gps := a.RunWalk(DefaultRunWalkOptions)
+	if len(gps.Breaks) != 1 || gps.RunDistance < 1000 {
+		t.Errorf("GPS only: %d walk breaks, run distance %v", len(gps.Breaks), gps.RunDistance)
+	}
+
+	db, err := ParseFile("testdata/treadmill.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw := db.Activities[0].RunWalk(DefaultRunWalkOptions)
+	if rw.RunTime != 10*time.Minute || rw.RunDistance != 1800 {
+		t.Errorf("distance only: run %v over %v m", rw.RunTime, rw.RunDistance)
+	}
+}
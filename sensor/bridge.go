@@ -0,0 +1,152 @@
+package sensor
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+// Characteristic is a GATT characteristic of a connected sensor, as exposed
+// by the BLE stack in use.
+type Characteristic interface {
+	UUID() uint16
+	// EnableNotifications subscribes to the characteristic, calling
+	// handler with the value of every notification.
+	EnableNotifications(handler func(value []byte)) error
+}
+
+// Bridge turns sensor notifications into recorder samples.
+type Bridge struct {
+	rec *tcx.Recorder
+	// WheelCircumference, in meters, converts wheel revolutions to speed.
+	WheelCircumference float64
+	// Errors receives the notifications that could not be decoded or
+	// recorded; it may be nil.
+	Errors func(error)
+
+	mu  sync.Mutex
+	now func() time.Time
+	// The speed and cadence sensor and the power meter count crank
+	// revolutions independently, so each keeps its own counter.
+	wheel      revCounter
+	crank      revCounter
+	powerCrank revCounter
+}
+
+// NewBridge returns a bridge recording into rec, assuming 700x25c wheels.
+func NewBridge(rec *tcx.Recorder) *Bridge {
+	return &Bridge{rec: rec, WheelCircumference: 2.105, now: time.Now}
+}
+
+// Subscribe enables the notifications of c and records them.
+func (b *Bridge) Subscribe(c Characteristic) error {
+	var handle func([]byte) error
+	switch c.UUID() {
+	case HeartRateMeasurement:
+		handle = b.heartRate
+	case CSCMeasurement:
+		handle = b.csc
+	case CyclingPowerMeasurement:
+		handle = b.power
+	case RSCMeasurement:
+		handle = b.rsc
+	default:
+		return fmt.Errorf("unsupported characteristic %#04x", c.UUID())
+	}
+	return c.EnableNotifications(func(v []byte) {
+		if err := handle(v); err != nil && b.Errors != nil {
+			b.Errors(err)
+		}
+	})
+}
+
+// record stamps s with the current second, so readings of different
+// sensors within a second share a trackpoint.
+func (b *Bridge) record(s tcx.Sample) error {
+	s.Time = b.now().Truncate(time.Second)
+	return b.rec.Record(s)
+}
+
+func (b *Bridge) heartRate(v []byte) error {
+	hr, err := ParseHeartRate(v)
+	if err != nil {
+		return err
+	}
+	s := tcx.Sample{HeartRate: hr.BPM}
+	for _, rr := range hr.RR {
+		s.RRIntervals = append(s.RRIntervals, int(math.Round(rr*1000)))
+	}
+	return b.record(s)
+}
+
+func (b *Bridge) csc(v []byte) error {
+	c, err := ParseCSC(v)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	var s tcx.Sample
+	if c.HasWheel {
+		if rate, ok := b.wheel.update(c.WheelRevs, c.WheelEventTime, 1<<32); ok {
+			s.Speed = rate * b.WheelCircumference
+		}
+	}
+	if c.HasCrank {
+		if rate, ok := b.crank.update(uint32(c.CrankRevs), c.CrankEventTime, 1<<16); ok {
+			s.Cadence = int(rate*60 + 0.5)
+		}
+	}
+	b.mu.Unlock()
+	if s.Speed == 0 && s.Cadence == 0 {
+		return nil
+	}
+	return b.record(s)
+}
+
+func (b *Bridge) power(v []byte) error {
+	p, err := ParsePower(v)
+	if err != nil {
+		return err
+	}
+	s := tcx.Sample{Power: p.Watts}
+	if p.HasCrank {
+		b.mu.Lock()
+		if rate, ok := b.powerCrank.update(uint32(p.CrankRevs), p.CrankEventTime, 1<<16); ok {
+			s.Cadence = int(rate*60 + 0.5)
+		}
+		b.mu.Unlock()
+	}
+	return b.record(s)
+}
+
+func (b *Bridge) rsc(v []byte) error {
+	r, err := ParseRSC(v)
+	if err != nil {
+		return err
+	}
+	// TCX running cadence counts strides, one for every two steps.
+	return b.record(tcx.Sample{Speed: r.Speed, Cadence: r.Cadence / 2})
+}
+
+// revCounter turns cumulative revolutions and event times into a rate.
+type revCounter struct {
+	seen bool
+	revs uint32
+	time uint16
+}
+
+// update returns the revolutions per second since the previous reading,
+// which it replaces. Readings without a new event report nothing.
+func (c *revCounter) update(revs uint32, t uint16, wrap uint64) (float64, bool) {
+	prev := *c
+	*c = revCounter{true, revs, t}
+	if !prev.seen || t == prev.time {
+		return 0, false
+	}
+	dr := (uint64(revs) + wrap - uint64(prev.revs)) % wrap
+	dt := float64(t-prev.time) / 1024
+	return float64(dr) / dt, true
+}
@@ -0,0 +1,100 @@
+package sensor
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+// fakeCharacteristic delivers notifications by hand.
+type fakeCharacteristic struct {
+	uuid    uint16
+	handler func([]byte)
+}
+
+func (c *fakeCharacteristic) UUID() uint16 { return c.uuid }
+
+func (c *fakeCharacteristic) EnableNotifications(h func([]byte)) error {
+	c.handler = h
+	return nil
+}
+
+func TestBridge(t *testing.T) {
+	rec := tcx.NewRecorder(tcx.SportBiking)
+	b := NewBridge(rec)
+	now := time.Date(2020, 5, 1, 7, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	var errs []error
+	b.Errors = func(err error) { errs = append(errs, err) }
+
+	hr := &fakeCharacteristic{uuid: HeartRateMeasurement}
+	csc := &fakeCharacteristic{uuid: CSCMeasurement}
+	power := &fakeCharacteristic{uuid: CyclingPowerMeasurement}
+	for _, c := range []*fakeCharacteristic{hr, csc, power} {
+		if err := b.Subscribe(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Subscribe(&fakeCharacteristic{uuid: 0x2A19}); err == nil {
+		t.Error("expected an error for the battery level characteristic")
+	}
+
+	// Two seconds of riding at 4 wheel revolutions and 2 crank
+	// revolutions per second.
+	for i := 0; i <= 2; i++ {
+		now = time.Date(2020, 5, 1, 7, 0, i, 0, time.UTC)
+		hr.handler([]byte{0x00, byte(140 + i)})
+		revs, crank, et := uint32(4*i), uint16(2*i), uint16(1024*i)
+		csc.handler([]byte{0x03, byte(revs), 0, 0, 0, byte(et), byte(et >> 8), byte(crank), 0, byte(et), byte(et >> 8)})
+		power.handler([]byte{0x00, 0x00, 200, 0x00})
+	}
+	hr.handler([]byte{0x10, 142, 0x00, 0x04, 0x33, 0x03})
+	hr.handler([]byte{0x01})
+	if len(errs) != 1 {
+		t.Errorf("got errors %v", errs)
+	}
+
+	pts := rec.Tcx().Activities[0].Laps[0].Track
+	if len(pts) != 3 {
+		t.Fatalf("got %d trackpoints, want 3", len(pts))
+	}
+	p := pts[2]
+	if p.HeartRateInBpm != 142 || p.PowerInWatts != 200 || p.Cadence != 120 || math.Abs(p.SpeedInMetersPerSec-4*2.105) > 1e-9 {
+		t.Errorf("last trackpoint = %+v", p)
+	}
+	if len(p.RRIntervals) != 2 || p.RRIntervals[0] != 1000 || p.RRIntervals[1] != 800 {
+		t.Errorf("RRIntervals = %v, want [1000 800]", p.RRIntervals)
+	}
+}
+
+func TestBridgeCrankSources(t *testing.T) {
+	rec := tcx.NewRecorder(tcx.SportBiking)
+	b := NewBridge(rec)
+	now := time.Date(2020, 5, 1, 7, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	b.Errors = func(err error) { t.Error(err) }
+	csc := &fakeCharacteristic{uuid: CSCMeasurement}
+	power := &fakeCharacteristic{uuid: CyclingPowerMeasurement}
+	for _, c := range []*fakeCharacteristic{csc, power} {
+		if err := b.Subscribe(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Both sensors see 90 rpm, but their crank counters started apart.
+	for i := 0; i <= 2; i++ {
+		now = time.Date(2020, 5, 1, 7, 0, i, 0, time.UTC)
+		crank, et := uint16(3*i), uint16(2048*i)
+		csc.handler([]byte{0x02, byte(crank), 0, byte(et), byte(et >> 8)})
+		crank += 500
+		power.handler([]byte{0x20, 0x00, 200, 0x00, byte(crank), byte(crank >> 8), byte(et), byte(et >> 8)})
+	}
+
+	for _, p := range rec.Tcx().Activities[0].Laps[0].Track[1:] {
+		if p.Cadence != 90 {
+			t.Errorf("trackpoint at %v has cadence %d, want 90", p.Time, p.Cadence)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Package sensor bridges Bluetooth Low Energy fitness sensors to a
+// tcx.Recorder. It decodes the standard GATT characteristics of heart rate,
+// cycling speed and cadence, cycling power and running speed and cadence
+// sensors; the BLE stack itself is left to the caller, behind the
+// Characteristic interface.
+package sensor
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Standard GATT characteristic UUIDs.
+const (
+	HeartRateMeasurement    uint16 = 0x2A37
+	CSCMeasurement          uint16 = 0x2A5B
+	CyclingPowerMeasurement uint16 = 0x2A63
+	RSCMeasurement          uint16 = 0x2A53
+)
+
+// HeartRate is a Heart Rate Measurement notification.
+type HeartRate struct {
+	BPM int
+	// RR are the beat-to-beat intervals, in seconds.
+	RR []float64
+}
+
+// ParseHeartRate decodes a Heart Rate Measurement notification.
+func ParseHeartRate(b []byte) (HeartRate, error) {
+	var hr HeartRate
+	if len(b) < 2 {
+		return hr, fmt.Errorf("heart rate measurement too short")
+	}
+	flags, i := b[0], 1
+	if flags&0x01 != 0 {
+		if len(b) < 3 {
+			return hr, fmt.Errorf("heart rate measurement too short")
+		}
+		hr.BPM, i = int(binary.LittleEndian.Uint16(b[1:])), 3
+	} else {
+		hr.BPM, i = int(b[1]), 2
+	}
+	if flags&0x08 != 0 {
+		i += 2 // energy expended
+	}
+	if flags&0x10 != 0 {
+		for ; i+1 < len(b); i += 2 {
+			hr.RR = append(hr.RR, float64(binary.LittleEndian.Uint16(b[i:]))/1024)
+		}
+	}
+	return hr, nil
+}
+
+// CSC is a Cycling Speed and Cadence Measurement notification. Counters are
+// cumulative and event times in 1/1024 s, wrapping around.
+type CSC struct {
+	HasWheel       bool
+	WheelRevs      uint32
+	WheelEventTime uint16
+	HasCrank       bool
+	CrankRevs      uint16
+	CrankEventTime uint16
+}
+
+// ParseCSC decodes a CSC Measurement notification.
+func ParseCSC(b []byte) (CSC, error) {
+	var c CSC
+	if len(b) < 1 {
+		return c, fmt.Errorf("csc measurement too short")
+	}
+	flags, i := b[0], 1
+	if flags&0x01 != 0 {
+		if len(b) < i+6 {
+			return c, fmt.Errorf("csc measurement too short")
+		}
+		c.HasWheel = true
+		c.WheelRevs = binary.LittleEndian.Uint32(b[i:])
+		c.WheelEventTime = binary.LittleEndian.Uint16(b[i+4:])
+		i += 6
+	}
+	if flags&0x02 != 0 {
+		if len(b) < i+4 {
+			return c, fmt.Errorf("csc measurement too short")
+		}
+		c.HasCrank = true
+		c.CrankRevs = binary.LittleEndian.Uint16(b[i:])
+		c.CrankEventTime = binary.LittleEndian.Uint16(b[i+2:])
+	}
+	return c, nil
+}
+
+// Power is a Cycling Power Measurement notification.
+type Power struct {
+	Watts int
+	// Crank data, when the meter sends it.
+	HasCrank       bool
+	CrankRevs      uint16
+	CrankEventTime uint16
+}
+
+// Sizes of the optional fields of a Cycling Power Measurement that precede
+// the crank revolution data, by flag bit.
+var powerFieldSizes = []struct {
+	flag uint16
+	size int
+}{
+	{0x0001, 1}, // pedal power balance
+	{0x0004, 2}, // accumulated torque
+	{0x0010, 6}, // wheel revolution data
+}
+
+// ParsePower decodes a Cycling Power Measurement notification.
+func ParsePower(b []byte) (Power, error) {
+	var p Power
+	if len(b) < 4 {
+		return p, fmt.Errorf("cycling power measurement too short")
+	}
+	flags := binary.LittleEndian.Uint16(b)
+	p.Watts = int(int16(binary.LittleEndian.Uint16(b[2:])))
+	i := 4
+	for _, f := range powerFieldSizes {
+		if flags&f.flag != 0 {
+			i += f.size
+		}
+	}
+	if flags&0x0020 != 0 {
+		if len(b) < i+4 {
+			return p, fmt.Errorf("cycling power measurement too short")
+		}
+		p.HasCrank = true
+		p.CrankRevs = binary.LittleEndian.Uint16(b[i:])
+		p.CrankEventTime = binary.LittleEndian.Uint16(b[i+2:])
+	}
+	return p, nil
+}
+
+// RSC is a Running Speed and Cadence Measurement notification.
+type RSC struct {
+	// Speed in m/s.
+	Speed float64
+	// Cadence in steps per minute.
+	Cadence int
+}
+
+// ParseRSC decodes an RSC Measurement notification.
+func ParseRSC(b []byte) (RSC, error) {
+	if len(b) < 4 {
+		return RSC{}, fmt.Errorf("rsc measurement too short")
+	}
+	return RSC{
+		Speed:   float64(binary.LittleEndian.Uint16(b[1:])) / 256,
+		Cadence: int(b[3]),
+	}, nil
+}
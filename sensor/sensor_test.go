@@ -0,0 +1,56 @@
+package sensor
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseHeartRate(t *testing.T) {
+	// 8-bit value with two RR intervals.
+	hr, err := ParseHeartRate([]byte{0x10, 72, 0x00, 0x04, 0x33, 0x03})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hr.BPM != 72 || len(hr.RR) != 2 || hr.RR[0] != 1 || math.Abs(hr.RR[1]-0.8) > 1e-3 {
+		t.Errorf("got %+v", hr)
+	}
+	// 16-bit value with energy expended.
+	hr, err = ParseHeartRate([]byte{0x09, 0x2C, 0x01, 0x10, 0x00})
+	if err != nil || hr.BPM != 300 {
+		t.Errorf("got %+v, %v", hr, err)
+	}
+	if _, err := ParseHeartRate([]byte{0x01, 0x2C}); err == nil {
+		t.Error("expected an error for a truncated measurement")
+	}
+}
+
+func TestParseCSC(t *testing.T) {
+	c, err := ParseCSC([]byte{0x03, 0x10, 0, 0, 0, 0x00, 0x04, 0x05, 0, 0x00, 0x08})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.HasWheel || c.WheelRevs != 16 || c.WheelEventTime != 1024 || !c.HasCrank || c.CrankRevs != 5 || c.CrankEventTime != 2048 {
+		t.Errorf("got %+v", c)
+	}
+}
+
+func TestParsePower(t *testing.T) {
+	// Pedal balance, then crank data.
+	p, err := ParsePower([]byte{0x21, 0x00, 0xFA, 0x00, 100, 0x0A, 0x00, 0x00, 0x04})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Watts != 250 || !p.HasCrank || p.CrankRevs != 10 || p.CrankEventTime != 1024 {
+		t.Errorf("got %+v", p)
+	}
+}
+
+func TestParseRSC(t *testing.T) {
+	r, err := ParseRSC([]byte{0x00, 0x00, 0x03, 170})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Speed != 3 || r.Cadence != 170 {
+		t.Errorf("got %+v", r)
+	}
+}
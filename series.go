@@ -0,0 +1,76 @@
+package tcx
+
+import "time"
+
+// Series returns the metric at each trackpoint of the activity recording
+// it, with the times of those trackpoints, ready to chart. Distance is the
+// distance covered since the previous trackpoint, measured as in intervals,
+// speed and pace are measured the same way without a speed reading, and
+// grade is measured back over 20 m.
+func (a *Activity) Series(m Metric) ([]time.Time, []float64) {
+	pts := a.trackpoints()
+	var times []time.Time
+	var values []float64
+	switch m {
+	case MetricDistance, MetricGrade:
+		ivs := a.intervals()
+		if len(pts) == 0 {
+			return nil, nil
+		}
+		times = append(times, pts[0].Time)
+		values = append(values, 0)
+		for i, iv := range ivs {
+			times = append(times, pts[i+1].Time)
+			if m == MetricDistance {
+				values = append(values, iv.dist)
+			} else {
+				values = append(values, iv.grade)
+			}
+		}
+	case MetricSpeed, MetricPace:
+		// Without a speed reading, the speed is measured over the interval
+		// to the next trackpoint, or held from the previous one at the end.
+		ivs := a.intervals()
+		for i, p := range pts {
+			var v float64
+			var ok bool
+			switch {
+			case i < len(ivs):
+				v, ok = m.value(ivs[i])
+			case i > 0 && p.SpeedInMetersPerSec == 0:
+				v, ok = m.value(ivs[i-1])
+			default:
+				v, ok = m.at(p)
+			}
+			if ok {
+				times = append(times, p.Time)
+				values = append(values, v)
+			}
+		}
+	default:
+		for _, p := range pts {
+			if v, ok := m.at(p); ok {
+				times = append(times, p.Time)
+				values = append(values, v)
+			}
+		}
+	}
+	return times, values
+}
+
+// CumulativeSeries is like Series, but returns running totals: the distance
+// from the start for MetricDistance, and for other metrics their integral
+// over time since the start, such as the work done, in joules, for
+// MetricPower. Each reading holds until the next one.
+func (a *Activity) CumulativeSeries(m Metric) ([]time.Time, []float64) {
+	times, values := a.Series(m)
+	total := make([]float64, len(values))
+	for i := 1; i < len(values); i++ {
+		if m == MetricDistance {
+			total[i] = total[i-1] + values[i]
+			continue
+		}
+		total[i] = total[i-1] + values[i-1]*times[i].Sub(times[i-1]).Seconds()
+	}
+	return times, total
+}
@@ -0,0 +1,37 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSeries(t *testing.T) {
+	a := testActivity(SportBiking, repeat(10.0, 61), repeat(120, 61))
+	track := a.Laps[0].Track
+	for i := range track {
+		track[i].PowerInWatts = 200
+	}
+	track[30].ClearHeartRate()
+
+	times, hr := a.Series(MetricHeartRate)
+	if len(times) != 60 || len(hr) != 60 || hr[0] != 120 || !times[30].Equal(track[31].Time) {
+		t.Errorf("heart rate series = %d values", len(hr))
+	}
+	times, dist := a.Series(MetricDistance)
+	if len(dist) != 61 || dist[0] != 0 || math.Abs(dist[10]-10) > 0.01 {
+		t.Errorf("distance series = %v", dist[:11])
+	}
+	_, total := a.CumulativeSeries(MetricDistance)
+	if math.Abs(total[60]-600) > 0.01 || !times[60].Equal(track[60].Time) {
+		t.Errorf("cumulative distance = %v", total[60])
+	}
+	if _, work := a.CumulativeSeries(MetricPower); work[60] != 12000 {
+		t.Errorf("work = %v J, want 12000", work[60])
+	}
+	if _, pace := a.Series(MetricPace); pace[0] != 100 {
+		t.Errorf("pace = %v s/km, want 100", pace[0])
+	}
+	if times, values := (&Activity{}).Series(MetricDistance); times != nil || values != nil {
+		t.Error("series of an empty activity")
+	}
+}
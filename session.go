@@ -0,0 +1,143 @@
+package tcx
+
+// SessionType is the kind of training session an activity represents.
+type SessionType int
+
+const (
+	SessionUnknown SessionType = iota
+	SessionRecovery
+	SessionEndurance
+	SessionTempo
+	SessionThreshold
+	SessionIntervals
+	SessionRace
+)
+
+var sessionNames = map[SessionType]string{
+	SessionUnknown:   "unknown",
+	SessionRecovery:  "recovery",
+	SessionEndurance: "endurance",
+	SessionTempo:     "tempo",
+	SessionThreshold: "threshold",
+	SessionIntervals: "intervals",
+	SessionRace:      "race",
+}
+
+func (s SessionType) String() string {
+	return sessionNames[s]
+}
+
+// Intensity ratios to threshold heart rate (or speed) bounding the session
+// classes.
+const (
+	recoveryRatio  = 0.80
+	tempoRatio     = 0.90
+	thresholdRatio = 0.95
+	raceRatio      = 0.98
+
+	// Efforts above hardRatio and recoveries under easyRatio, each lasting
+	// at least minRepeat seconds, make up interval repeats.
+	hardRatio  = 0.95
+	easyRatio  = 0.88
+	minRepeat  = 30.0
+	minRepeats = 3
+)
+
+// intensities returns, for every interval, the effort relative to the
+// athlete's threshold: heart rate over LTHR when available, speed over
+// threshold speed otherwise. Intervals without data are left out.
+func (a *Activity) intensities(th Thresholds) (ratios, durations []float64) {
+	for _, iv := range a.intervals() {
+		if iv.dt == 0 {
+			continue
+		}
+		switch {
+		case th.HeartRate > 0 && iv.p.HeartRateInBpm > 0:
+			ratios = append(ratios, float64(iv.p.HeartRateInBpm)/float64(th.HeartRate))
+		case th.Speed > 0 && iv.p.SpeedInMetersPerSec > 0:
+			ratios = append(ratios, iv.p.SpeedInMetersPerSec/th.Speed)
+		default:
+			continue
+		}
+		durations = append(durations, iv.dt)
+	}
+	return ratios, durations
+}
+
+// countRepeats counts the hard efforts separated by recoveries in an
+// intensity series.
+func countRepeats(ratios, durations []float64) int {
+	repeats := 0
+	state, run := 0, 0.0 // 1 hard, -1 easy
+	counted := false
+	for i, r := range ratios {
+		s := 0
+		if r >= hardRatio {
+			s = 1
+		} else if r <= easyRatio {
+			s = -1
+		}
+		if s == 0 {
+			continue
+		}
+		if s != state {
+			state, run, counted = s, 0, false
+		}
+		run += durations[i]
+		if state == 1 && run >= minRepeat && !counted {
+			repeats++
+			counted = true
+		}
+	}
+	return repeats
+}
+
+// ClassifySession classifies the activity from the distribution of its
+// intensity relative to the athlete's thresholds and from the presence of
+// repeated hard efforts.
+func (a *Activity) ClassifySession(th Thresholds) SessionType {
+	ratios, durations := a.intensities(th)
+	var total, weighted, above, tempo float64
+	for i, r := range ratios {
+		total += durations[i]
+		weighted += r * durations[i]
+		if r >= thresholdRatio {
+			above += durations[i]
+		} else if r >= tempoRatio {
+			tempo += durations[i]
+		}
+	}
+	if total == 0 {
+		return SessionUnknown
+	}
+	mean := weighted / total
+
+	switch {
+	case countRepeats(ratios, durations) >= minRepeats && above < 0.8*total:
+		return SessionIntervals
+	case mean >= raceRatio && above >= 0.8*total:
+		return SessionRace
+	case above >= 0.4*total:
+		return SessionThreshold
+	case tempo+above >= 0.4*total:
+		return SessionTempo
+	case mean < recoveryRatio && total < 3600:
+		return SessionRecovery
+	}
+	return SessionEndurance
+}
+
+// FilterSessions returns the activities classified as one of types.
+func FilterSessions(activities []*Activity, th Thresholds, types ...SessionType) []*Activity {
+	var matched []*Activity
+	for _, a := range activities {
+		st := a.ClassifySession(th)
+		for _, t := range types {
+			if st == t {
+				matched = append(matched, a)
+				break
+			}
+		}
+	}
+	return matched
+}
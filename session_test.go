@@ -0,0 +1,44 @@
+package tcx
+
+import "testing"
+
+func TestClassifySession(t *testing.T) {
+	th := Thresholds{HeartRate: 170, Speed: 4}
+
+	var hrs []int
+	hrs = append(hrs, repeat(130, 600)...)
+	for i := 0; i < 5; i++ {
+		hrs = append(hrs, repeat(172, 180)...)
+		hrs = append(hrs, repeat(135, 120)...)
+	}
+	intervals := testActivity("Running", repeat(3.5, len(hrs)), hrs)
+
+	tests := []struct {
+		name string
+		a    *Activity
+		want SessionType
+	}{
+		{"recovery", testActivity("Running", repeat(2.5, 1800), repeat(125, 1800)), SessionRecovery},
+		{"endurance", testActivity("Running", repeat(3.0, 4000), repeat(145, 4000)), SessionEndurance},
+		{"tempo", testActivity("Running", repeat(3.6, 2400), repeat(158, 2400)), SessionTempo},
+		{"threshold", testActivity("Running", repeat(3.9, 2400), append(repeat(145, 1200), repeat(165, 1200)...)), SessionThreshold},
+		{"intervals", intervals, SessionIntervals},
+		{"race", testActivity("Running", repeat(4.1, 2400), repeat(172, 2400)), SessionRace},
+		{"speed only", testActivity("Running", repeat(4.1, 2400), nil), SessionRace},
+		{"no data", testActivity("Running", repeat(4.1, 2400), nil), SessionUnknown},
+	}
+	for _, tt := range tests {
+		thr := th
+		if tt.name == "no data" {
+			thr = Thresholds{HeartRate: 170}
+		}
+		if got := tt.a.ClassifySession(thr); got != tt.want {
+			t.Errorf("%s: ClassifySession() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	got := FilterSessions([]*Activity{tests[0].a, intervals}, th, SessionIntervals)
+	if len(got) != 1 || got[0] != intervals {
+		t.Errorf("FilterSessions() = %v", got)
+	}
+}
@@ -0,0 +1,30 @@
+package tcx
+
+import "time"
+
+// ShiftTime offsets every time of the activity by d: its id, lap start
+// times and trackpoint times. It corrects recordings made with a wrong
+// clock.
+func (a *Activity) ShiftTime(d time.Duration) {
+	a.Invalidate()
+	if !a.ID.IsZero() {
+		a.ID = a.ID.Add(d)
+	}
+	for i := range a.Laps {
+		l := &a.Laps[i]
+		if !l.StartTime.IsZero() {
+			l.StartTime = l.StartTime.Add(d)
+		}
+		for j := range l.Track {
+			if p := &l.Track[j]; !p.Time.IsZero() {
+				p.Time = p.Time.Add(d)
+			}
+		}
+	}
+}
+
+// StartTimeIn returns the start of the activity, its first lap or else its
+// id, as seen in loc, such as the time zone the activity took place in.
+func (a *Activity) StartTimeIn(loc *time.Location) time.Time {
+	return a.start().In(loc)
+}
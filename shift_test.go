@@ -0,0 +1,28 @@
+package tcx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShiftTime(t *testing.T) {
+	a := testActivity(SportRunning, repeat(4.0, 11), nil)
+	orig := a.Clone()
+	a.ShiftTime(-2 * time.Hour)
+	if !a.ID.Equal(orig.ID.Add(-2*time.Hour)) || !a.Laps[0].StartTime.Equal(orig.Laps[0].StartTime.Add(-2*time.Hour)) {
+		t.Errorf("shifted id %v, lap start %v", a.ID, a.Laps[0].StartTime)
+	}
+	for i, p := range a.Laps[0].Track {
+		if want := orig.Laps[0].Track[i].Time.Add(-2 * time.Hour); !p.Time.Equal(want) {
+			t.Fatalf("trackpoint %d at %v, want %v", i, p.Time, want)
+		}
+	}
+	if a.TotalDuration() != orig.TotalDuration() {
+		t.Error("ShiftTime changed the duration")
+	}
+
+	paris := time.FixedZone("CEST", 2*3600)
+	if s := orig.StartTimeIn(paris); s.Hour() != 9 || s.Location() != paris || !s.Equal(orig.ID) {
+		t.Errorf("StartTimeIn(CEST) = %v", s)
+	}
+}
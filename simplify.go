@@ -0,0 +1,71 @@
+package tcx
+
+// Simplify thins the track of every lap with the Douglas-Peucker algorithm,
+// keeping the trackpoints that deviate by more than tolerance meters from
+// the simplified line. The first and last fixes of each lap are kept so lap
+// boundaries don't move. Trackpoints without a GPS fix, such as those of
+// indoor activities or signal dropouts, carry no shape and are all kept. It
+// returns the number of trackpoints removed.
+func (a *Activity) Simplify(tolerance float64) int {
+	a.Invalidate()
+	var removed int
+	for i := range a.Laps {
+		l := &a.Laps[i]
+		var fixes []Trackpoint
+		var at []int
+		for j, p := range l.Track {
+			if p.HasPosition() {
+				fixes = append(fixes, p)
+				at = append(at, j)
+			}
+		}
+		keep := make([]bool, len(l.Track))
+		for j := range keep {
+			keep[j] = true
+		}
+		for j, k := range douglasPeucker(fixes, tolerance) {
+			keep[at[j]] = k
+		}
+		track := make([]Trackpoint, 0, len(keep))
+		for j, k := range keep {
+			if k {
+				track = append(track, l.Track[j])
+			}
+		}
+		removed += len(l.Track) - len(track)
+		l.Track = track
+	}
+	return removed
+}
+
+// douglasPeucker reports which points of track to keep so that none of
+// the dropped ones lies farther than tolerance meters from the kept line.
+func douglasPeucker(track []Trackpoint, tolerance float64) []bool {
+	keep := make([]bool, len(track))
+	if len(track) == 0 {
+		return keep
+	}
+	keep[0], keep[len(track)-1] = true, true
+	// Split ranges on an explicit stack: long tracks would recurse deeply.
+	stack := [][2]int{{0, len(track) - 1}}
+	for len(stack) > 0 {
+		r := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		first, last := track[r[0]], track[r[1]]
+		a := [2]float64{first.LatitudeInDegrees, first.LongitudeInDegrees}
+		b := [2]float64{last.LatitudeInDegrees, last.LongitudeInDegrees}
+		far, farthest := 0.0, -1
+		for i := r[0] + 1; i < r[1]; i++ {
+			p := &track[i]
+			lat, lon := projectOnSegment(p.LatitudeInDegrees, p.LongitudeInDegrees, a, b)
+			if d := distance(p.LatitudeInDegrees, p.LongitudeInDegrees, lat, lon); d > far {
+				far, farthest = d, i
+			}
+		}
+		if farthest >= 0 && far > tolerance {
+			keep[farthest] = true
+			stack = append(stack, [2]int{r[0], farthest}, [2]int{farthest, r[1]})
+		}
+	}
+	return keep
+}
@@ -0,0 +1,57 @@
+package tcx
+
+import "testing"
+
+func TestSimplify(t *testing.T) {
+	orig, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &orig.Activities[0]
+	before := len(a.trackpoints())
+	removed := a.Simplify(5)
+	after := len(a.trackpoints())
+	if removed == 0 || after+removed != before || after > before/3 {
+		t.Errorf("Simplify(5) removed %d of %d trackpoints", removed, before)
+	}
+	for i, l := range a.Laps {
+		if len(l.Track) == 1 {
+			t.Errorf("lap %d kept a single trackpoint", i)
+		}
+	}
+
+	// A straight line collapses to its ends; a detour is kept.
+	s := testActivity(SportRunning, repeat(3.0, 100), nil)
+	s.Laps[0].Track[50].LongitudeInDegrees += 0.001
+	first, last := s.Laps[0].Track[0], s.Laps[0].Track[99]
+	s.Simplify(1)
+	track := s.Laps[0].Track
+	var detour bool
+	for _, p := range track {
+		detour = detour || p.Time.Equal(first.Time.Add(50e9))
+	}
+	if len(track) > 6 || !detour || !track[0].Time.Equal(first.Time) || !track[len(track)-1].Time.Equal(last.Time) {
+		t.Errorf("simplified track = %+v", track)
+	}
+
+	straight := testActivity(SportRunning, repeat(3.0, 100), nil)
+	if n := straight.Simplify(1); n != 98 {
+		t.Errorf("Simplify(1) on a straight line removed %d trackpoints, want 98", n)
+	}
+
+	// Trackpoints without a fix are kept, and indoor activities untouched.
+	dropout := testActivity(SportRunning, repeat(3.0, 100), repeat(150, 100))
+	for i := 40; i < 45; i++ {
+		dropout.Laps[0].Track[i].ClearPosition()
+	}
+	if n := dropout.Simplify(1); n != 93 || len(dropout.Laps[0].Track) != 7 {
+		t.Errorf("Simplify(1) with a dropout removed %d trackpoints", n)
+	}
+	indoor := testActivity(SportRunning, repeat(3.0, 100), repeat(150, 100))
+	for i := range indoor.Laps[0].Track {
+		indoor.Laps[0].Track[i].ClearPosition()
+	}
+	if n := indoor.Simplify(1); n != 0 || len(indoor.Laps[0].Track) != 100 {
+		t.Errorf("Simplify(1) of an indoor activity removed %d trackpoints", n)
+	}
+}
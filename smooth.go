@@ -0,0 +1,161 @@
+package tcx
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// SmoothingMethod selects the filter of Smooth and SmoothedSeries.
+type SmoothingMethod int
+
+const (
+	// MovingAverage averages the values within the window.
+	MovingAverage SmoothingMethod = iota
+	// MovingMedian takes the median of the values within the window,
+	// removing spikes while keeping steps sharp.
+	MovingMedian
+	// KalmanFilter tracks the value as a random walk, forwards then
+	// backwards, weighing each reading by the measurement noise.
+	KalmanFilter
+)
+
+// SmoothOptions configures Smooth and SmoothedSeries.
+type SmoothOptions struct {
+	Method SmoothingMethod
+	// Window is the width of the moving average and median windows,
+	// centered on each trackpoint.
+	Window time.Duration
+	// Noise is the standard deviation of the readings, in the metric's
+	// unit, for KalmanFilter. Zero estimates it from the differences
+	// between successive readings.
+	Noise float64
+	// Drift is the standard deviation of the change of the true value over
+	// a second, for KalmanFilter. Zero derives it from Noise and Window.
+	Drift float64
+}
+
+// DefaultSmoothOptions is a 5 s moving average.
+var DefaultSmoothOptions = SmoothOptions{Method: MovingAverage, Window: 5 * time.Second}
+
+// SmoothedSeries returns the metric smoothed with opts at each trackpoint
+// of the activity recording it, with the times of those trackpoints. The
+// activity is left unchanged.
+func (a *Activity) SmoothedSeries(m Metric, opts SmoothOptions) ([]time.Time, []float64) {
+	pts, values := recorded(a.trackpoints(), m)
+	times := make([]time.Time, len(pts))
+	for i, p := range pts {
+		times[i] = p.Time
+	}
+	return times, smoothValues(times, values, opts)
+}
+
+// Smooth replaces the readings of a recorded metric, such as speed,
+// altitude or heart rate, with their smoothed values. Pace and grade are
+// derived from speed and altitude, smooth those instead.
+func (a *Activity) Smooth(m Metric, opts SmoothOptions) {
+	a.Invalidate()
+	pts, _ := recorded(a.trackpoints(), m)
+	_, smoothed := a.SmoothedSeries(m, opts)
+	for i, p := range pts {
+		m.set(p, smoothed[i])
+	}
+}
+
+// SmoothedElevation returns the elevation gain and loss of the activity, in
+// meters, computed on its altitude smoothed with opts, which brings noisy
+// barometric and GPS altitudes close to the surveyed figures.
+func (a *Activity) SmoothedElevation(opts SmoothOptions) (ascent, descent float64) {
+	c := a.Clone()
+	c.Smooth(MetricAltitude, opts)
+	return c.Elevation(DefaultElevationHysteresis)
+}
+
+// recorded returns the trackpoints recording m, with the recorded values.
+func recorded(all []*Trackpoint, m Metric) ([]*Trackpoint, []float64) {
+	var pts []*Trackpoint
+	var values []float64
+	for _, p := range all {
+		if v, ok := m.at(p); ok {
+			pts = append(pts, p)
+			values = append(values, v)
+		}
+	}
+	return pts, values
+}
+
+// smoothValues smooths values read at times.
+func smoothValues(times []time.Time, values []float64, opts SmoothOptions) []float64 {
+	if opts.Method == KalmanFilter {
+		return kalmanSmoothValues(times, values, opts)
+	}
+	half := opts.Window / 2
+	out := make([]float64, len(values))
+	window := make([]float64, 0, 16)
+	lo, hi := 0, 0
+	for i, t := range times {
+		for lo < i && t.Sub(times[lo]) > half {
+			lo++
+		}
+		for hi < len(times) && times[hi].Sub(t) <= half {
+			hi++
+		}
+		window = append(window[:0], values[lo:hi]...)
+		if opts.Method == MovingMedian {
+			sort.Float64s(window)
+			n := len(window)
+			out[i] = (window[(n-1)/2] + window[n/2]) / 2
+			continue
+		}
+		var sum float64
+		for _, v := range window {
+			sum += v
+		}
+		out[i] = sum / float64(len(window))
+	}
+	return out
+}
+
+// kalmanSmoothValues filters values as a random walk observed with noise,
+// then runs a Rauch-Tung-Striebel backward pass.
+func kalmanSmoothValues(times []time.Time, values []float64, opts SmoothOptions) []float64 {
+	n := len(values)
+	out := make([]float64, n)
+	if n == 0 {
+		return out
+	}
+	noise := opts.Noise
+	if noise == 0 && n > 1 {
+		// Successive differences of white noise have twice its variance.
+		var sum float64
+		for i := 1; i < n; i++ {
+			d := values[i] - values[i-1]
+			sum += d * d
+		}
+		noise = math.Sqrt(sum / float64(n-1) / 2)
+	}
+	r := math.Max(noise*noise, 1e-9)
+	drift := opts.Drift
+	if drift == 0 {
+		drift = noise / math.Max(opts.Window.Seconds(), 1)
+	}
+	q := drift * drift
+
+	x := make([]float64, n)    // filtered values
+	p := make([]float64, n)    // filtered variances
+	pred := make([]float64, n) // predicted variances
+	x[0], p[0], pred[0] = values[0], r, r
+	for i := 1; i < n; i++ {
+		dt := math.Max(times[i].Sub(times[i-1]).Seconds(), 0)
+		pred[i] = p[i-1] + q*dt
+		k := pred[i] / (pred[i] + r)
+		x[i] = x[i-1] + k*(values[i]-x[i-1])
+		p[i] = (1 - k) * pred[i]
+	}
+	out[n-1] = x[n-1]
+	for i := n - 2; i >= 0; i-- {
+		c := p[i] / pred[i+1]
+		out[i] = x[i] + c*(out[i+1]-x[i])
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package tcx
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSmooth(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	speeds := make([]float64, 601)
+	for i := range speeds {
+		speeds[i] = 3 + rng.NormFloat64()*0.5
+	}
+	speeds[300] = 30
+	a := testActivity(SportRunning, speeds, nil)
+
+	spread := func(xs []float64) float64 {
+		var sum float64
+		for _, x := range xs {
+			sum += (x - 3) * (x - 3)
+		}
+		return math.Sqrt(sum / float64(len(xs)))
+	}
+	for _, method := range []SmoothingMethod{MovingAverage, MovingMedian, KalmanFilter} {
+		opts := SmoothOptions{Method: method, Window: 9 * time.Second}
+		times, smoothed := a.SmoothedSeries(MetricSpeed, opts)
+		if len(times) != 601 || len(smoothed) != 601 {
+			t.Fatalf("method %d: %d values", method, len(smoothed))
+		}
+		if s := spread(smoothed); s > 0.5*spread(speeds) {
+			t.Errorf("method %d: spread %.3f, raw %.3f", method, s, spread(speeds))
+		}
+	}
+	_, median := a.SmoothedSeries(MetricSpeed, SmoothOptions{Method: MovingMedian, Window: 9 * time.Second})
+	if median[300] > 4 {
+		t.Errorf("median kept the spike: %v", median[300])
+	}
+
+	// Only trackpoints recording the metric are smoothed.
+	track := a.Laps[0].Track
+	for i := range track {
+		track[i].SetAltitude(100 + float64(i%2))
+	}
+	track[10].ClearAltitude()
+	ascent, _ := a.Elevation(0)
+	smoothAscent, _ := a.SmoothedElevation(DefaultSmoothOptions)
+	if ascent < 250 || smoothAscent > 5 || track[11].AltitudeInMeters != 101 {
+		t.Errorf("ascent %v raw, %v smoothed", ascent, smoothAscent)
+	}
+	a.Smooth(MetricAltitude, DefaultSmoothOptions)
+	if track[10].HasAltitude() || math.Abs(track[100].AltitudeInMeters-100.5) > 0.2 {
+		t.Errorf("smoothed altitudes %+v, %+v", track[10], track[100])
+	}
+}
@@ -0,0 +1,111 @@
+package tcx
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+// ErrNotTCX is returned when parsing a document that isn't TCX, such as a
+// GPX file.
+var ErrNotTCX = errors.New("not a TCX document")
+
+// Format is the format of an activity file.
+type Format int
+
+const (
+	FormatUnknown Format = iota
+	FormatTCX
+	FormatGPX
+	FormatKML
+	FormatFitlog
+	FormatFIT
+	FormatJSON
+	FormatZip
+)
+
+var formatNames = [...]string{"unknown", "TCX", "GPX", "KML", "fitlog", "FIT", "JSON", "zip"}
+
+func (f Format) String() string {
+	if f < 0 || int(f) >= len(formatNames) {
+		return formatNames[FormatUnknown]
+	}
+	return formatNames[f]
+}
+
+// xmlFormats maps root elements to formats.
+var xmlFormats = map[string]Format{
+	"TrainingCenterDatabase": FormatTCX,
+	"gpx":                    FormatGPX,
+	"kml":                    FormatKML,
+	"FitnessWorkbook":        FormatFitlog,
+}
+
+// sniffLength is the number of bytes Sniff looks at.
+const sniffLength = 4096
+
+// Sniff reads the start of r, gzip compressed or not, and reports the
+// format of the activity file it holds, so that uploads can be routed to
+// the right parser or rejected with a useful message. JSON covers the
+// GoldenCheetah, Runkeeper and Nike Run Club formats and zip the bulk
+// exports and KMZ files. Sniff buffers up to a few kilobytes of r; the
+// returned reader yields the whole of r, those bytes included, and is to
+// be read in place of r.
+func Sniff(r io.Reader) (Format, io.Reader, error) {
+	br := bufio.NewReaderSize(r, sniffLength)
+	head, err := br.Peek(sniffLength)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return FormatUnknown, br, err
+	}
+	f, err := sniffHead(head)
+	return f, br, err
+}
+
+// sniffHead reports the format of the file starting with head. Gzip
+// compressed data is decompressed one level only: anything compressed
+// further, such as a gzip quine, is unknown.
+func sniffHead(head []byte) (Format, error) {
+	if len(head) >= 2 && head[0] == 0x1f && head[1] == 0x8b {
+		// Only the start of the stream is at hand: decompress what it
+		// holds.
+		zr, err := gzip.NewReader(bytes.NewReader(head))
+		if err != nil {
+			return FormatUnknown, err
+		}
+		inner := make([]byte, sniffLength)
+		n, err := io.ReadFull(zr, inner)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return FormatUnknown, err
+		}
+		return sniffPlain(inner[:n]), nil
+	}
+	return sniffPlain(head), nil
+}
+
+// sniffPlain reports the format of the uncompressed file starting with head.
+func sniffPlain(head []byte) Format {
+	switch {
+	case len(head) >= 12 && (head[0] == 12 || head[0] == 14) && string(head[8:12]) == ".FIT":
+		return FormatFIT
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return FormatZip
+	}
+	text := bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(text) > 0 && (text[0] == '{' || text[0] == '[') {
+		return FormatJSON
+	}
+	d := xml.NewDecoder(bytes.NewReader(text))
+	d.Strict = false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return FormatUnknown
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return xmlFormats[start.Name.Local]
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package tcx
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSniff(t *testing.T) {
+	data, err := os.ReadFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fit := []byte{14, 0x10, 0, 0, 0, 0, 0, 0, '.', 'F', 'I', 'T', 0, 0}
+	for _, tt := range []struct {
+		name string
+		data []byte
+		want Format
+	}{
+		{"tcx", data, FormatTCX},
+		{"gzip", gzipped(t, data), FormatTCX},
+		{"double gzip", gzipped(t, gzipped(t, data)), FormatUnknown},
+		{"gpx", []byte("\xef\xbb\xbf<?xml version=\"1.0\"?>\n<!-- exported -->\n<gpx version=\"1.1\"><trk/></gpx>"), FormatGPX},
+		{"kml", []byte(`<kml xmlns="http://www.opengis.net/kml/2.2"/>`), FormatKML},
+		{"fitlog", []byte(`<FitnessWorkbook/>`), FormatFitlog},
+		{"fit", fit, FormatFIT},
+		{"json", []byte("\n  {\"RIDE\": {}}"), FormatJSON},
+		{"zip", []byte("PK\x03\x04rest"), FormatZip},
+		{"html", []byte("<html><body/></html>"), FormatUnknown},
+		{"text", []byte("hello"), FormatUnknown},
+		{"empty", nil, FormatUnknown},
+	} {
+		got, r, err := Sniff(bytes.NewReader(tt.data))
+		if err != nil || got != tt.want {
+			t.Errorf("%s: Sniff() = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+		if all, err := io.ReadAll(r); err != nil || !bytes.Equal(all, tt.data) {
+			t.Errorf("%s: Sniff() reader yields %d bytes of %d", tt.name, len(all), len(tt.data))
+		}
+	}
+	if _, _, err := Sniff(strings.NewReader("\x1f\x8bnot gzip")); err == nil {
+		t.Error("Sniff() of broken gzip data succeeded")
+	}
+}
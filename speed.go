@@ -0,0 +1,91 @@
+package tcx
+
+import "math"
+
+// SpeedOptions configures DeriveSpeeds.
+type SpeedOptions struct {
+	// Overwrite replaces the recorded speeds too; otherwise only
+	// trackpoints without a speed are filled.
+	Overwrite bool
+	// MaxSpeed is the highest plausible speed, in m/s. Faster readings,
+	// GPS jumps, are replaced with the speed before them. Zero picks the
+	// limit of the sport.
+	MaxSpeed float64
+}
+
+// DefaultSpeedOptions fills missing speeds with the limits of the sport.
+var DefaultSpeedOptions = SpeedOptions{}
+
+// maxSportSpeeds are the plausible speed limits, in m/s, by sport.
+var maxSportSpeeds = map[string]float64{
+	SportRunning:  12.5,
+	SportWalking:  4,
+	SportSwimming: 3,
+	SportBiking:   35,
+}
+
+// DeriveSpeeds fills the TPX speeds of the trackpoints from GPS fixes and
+// times, as many files lack them, then recomputes the maximum speed of
+// every lap from its track. The speed at a trackpoint is measured between
+// its neighbors, which halves GPS noise. It returns the number of speeds
+// set.
+func (a *Activity) DeriveSpeeds(opts SpeedOptions) int {
+	a.Invalidate()
+	limit := opts.MaxSpeed
+	if limit <= 0 {
+		limit = maxSportSpeeds[a.Sport]
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	pts := a.trackpoints()
+	speeds := make([]float64, len(pts))
+	for i, p := range pts {
+		if !p.HasPosition() {
+			speeds[i] = -1
+			continue
+		}
+		from, to := i, i
+		if i > 0 && pts[i-1].HasPosition() {
+			from = i - 1
+		}
+		if i+1 < len(pts) && pts[i+1].HasPosition() {
+			to = i + 1
+		}
+		q, r := pts[from], pts[to]
+		dt := r.Time.Sub(q.Time).Seconds()
+		if dt <= 0 {
+			speeds[i] = -1
+			continue
+		}
+		speeds[i] = distance(q.LatitudeInDegrees, q.LongitudeInDegrees, r.LatitudeInDegrees, r.LongitudeInDegrees) / dt
+	}
+
+	n, last := 0, 0.0
+	for i, p := range pts {
+		v := speeds[i]
+		if v < 0 {
+			continue
+		}
+		if v > limit {
+			v = last
+		}
+		last = v
+		if opts.Overwrite || p.SpeedInMetersPerSec == 0 {
+			p.SpeedInMetersPerSec = v
+			n++
+		}
+	}
+	for i := range a.Laps {
+		l := &a.Laps[i]
+		if len(l.Track) == 0 {
+			continue
+		}
+		var top float64
+		for _, p := range l.Track {
+			top = math.Max(top, p.SpeedInMetersPerSec)
+		}
+		l.MaximumSpeedInMetersPerSec = top
+	}
+	return n
+}
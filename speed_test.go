@@ -0,0 +1,40 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDeriveSpeeds(t *testing.T) {
+	a := testActivity(SportRunning, repeat(4.0, 61), nil)
+	track := a.Laps[0].Track
+	for i := range track {
+		track[i].SpeedInMetersPerSec = 0
+	}
+	// A GPS jump of 500 m and back at 30 s.
+	track[30].LatitudeInDegrees += 500 / 111195.0
+	track[40].SpeedInMetersPerSec = 3.5
+
+	if n := a.DeriveSpeeds(DefaultSpeedOptions); n != 60 {
+		t.Errorf("derived %d speeds, want 60", n)
+	}
+	for _, i := range []int{0, 10, 29, 30, 31, 60} {
+		if v := track[i].SpeedInMetersPerSec; math.Abs(v-4) > 0.05 {
+			t.Errorf("speed %d = %v, want 4", i, v)
+		}
+	}
+	if track[40].SpeedInMetersPerSec != 3.5 {
+		t.Errorf("recorded speed overwritten: %v", track[40].SpeedInMetersPerSec)
+	}
+	if v := a.Laps[0].MaximumSpeedInMetersPerSec; math.Abs(v-4) > 0.05 {
+		t.Errorf("lap maximum speed = %v", v)
+	}
+	if p, _ := a.AveragePace(); math.Abs(p.Speed()-4) > 0.05 {
+		t.Errorf("average pace = %v", p)
+	}
+
+	// Without a limit, the jump shows on the neighbors of the bad fix.
+	if n := a.DeriveSpeeds(SpeedOptions{Overwrite: true, MaxSpeed: 1000}); n != 61 || track[29].SpeedInMetersPerSec < 200 {
+		t.Errorf("derived %d speeds, jump at %v m/s", n, track[29].SpeedInMetersPerSec)
+	}
+}
@@ -0,0 +1,161 @@
+package tcx
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Critical speed is fitted on the part of the speed-duration curve where the
+// two-parameter model holds, between 3 and 20 minutes.
+const (
+	criticalSpeedMin = 3 * time.Minute
+	criticalSpeedMax = 20 * time.Minute
+)
+
+// BestEffortDistances are the distances, in meters, of the usual personal
+// records.
+var BestEffortDistances = []float64{400, 800, 1000, 1609.344, 3000, 5000, 10000, 15000, DistanceHalfMarathon, DistanceMarathon}
+
+// MeanMaxSpeed returns the speed-duration curve of the activity: the best
+// average speed, in m/s, at the durations of MeanMaxPower.
+func (a *Activity) MeanMaxSpeed() Curve {
+	series := resample1Hz(a.trackpoints(), func(p *Trackpoint) float64 { return p.SpeedInMetersPerSec })
+	return meanMaxCurve(series)
+}
+
+// MeanMaxSpeedRange merges the speed-duration curves of the activities
+// started within [from, to).
+func MeanMaxSpeedRange(activities []*Activity, from, to time.Time) Curve {
+	var curves []Curve
+	for _, a := range activities {
+		if a.ID.Before(from) || !a.ID.Before(to) {
+			continue
+		}
+		curves = append(curves, a.MeanMaxSpeed())
+	}
+	return MergeCurves(curves...)
+}
+
+// cumulativeTimeDistance returns the elapsed time, in seconds, and the
+// distance, in meters, at each trackpoint of the activity.
+func (a *Activity) cumulativeTimeDistance() (t, d []float64) {
+	ivs := a.intervals()
+	if len(ivs) == 0 {
+		return nil, nil
+	}
+	t = make([]float64, len(ivs)+1)
+	d = make([]float64, len(ivs)+1)
+	for i, iv := range ivs {
+		t[i+1] = t[i] + iv.dt
+		d[i+1] = d[i] + iv.dist
+	}
+	return t, d
+}
+
+// fastestWindow returns the shortest time, in seconds, to cover target
+// meters along the cumulative times t and distances d, and the indices of
+// the trackpoints at or just before its start and at its end. ok is false
+// when the distances don't reach target.
+func fastestWindow(t, d []float64, target float64) (best float64, from, to int, ok bool) {
+	if target <= 0 || len(d) == 0 || d[len(d)-1] < target {
+		return 0, 0, 0, false
+	}
+	best = math.Inf(1)
+	i := 0
+	for j := 1; j < len(d); j++ {
+		if d[j] < target {
+			continue
+		}
+		for i+1 < j && d[j]-d[i+1] >= target {
+			i++
+		}
+		// Start where exactly target meters remain to d[j].
+		start := interpolate(d[i:i+2], t[i:i+2], d[j]-target)
+		if t[j]-start < best {
+			best, from, to = t[j]-start, i, j
+		}
+	}
+	return best, from, to, true
+}
+
+// BestEffort returns the fastest time over distance meters within the
+// activity, with the indices, in trackpoint order across laps, of the
+// trackpoints at or just before its start and at its end. The indices are
+// -1 when the activity is shorter than distance.
+func (a *Activity) BestEffort(distance float64) (d time.Duration, startIdx, endIdx int) {
+	t, dist := a.cumulativeTimeDistance()
+	best, from, to, ok := fastestWindow(t, dist, distance)
+	if !ok {
+		return 0, -1, -1
+	}
+	return time.Duration(best * float64(time.Second)), from, to
+}
+
+// BestEfforts returns the fastest time over each of the given distances
+// (meters) within the activity, skipping distances longer than the activity.
+func (a *Activity) BestEfforts(distances []float64) []Effort {
+	t, d := a.cumulativeTimeDistance()
+	var efforts []Effort
+	for _, target := range distances {
+		best, _, _, ok := fastestWindow(t, d, target)
+		if !ok {
+			continue
+		}
+		efforts = append(efforts, Effort{
+			Distance: target,
+			Duration: time.Duration(best * float64(time.Second)),
+			Date:     a.ID,
+		})
+	}
+	return efforts
+}
+
+// BestEffortsRange returns the fastest time over each distance among the
+// activities started within [from, to).
+func BestEffortsRange(activities []*Activity, distances []float64, from, to time.Time) []Effort {
+	best := make(map[float64]Effort)
+	for _, a := range activities {
+		if a.ID.Before(from) || !a.ID.Before(to) {
+			continue
+		}
+		for _, e := range a.BestEfforts(distances) {
+			if b, ok := best[e.Distance]; !ok || e.Duration < b.Duration {
+				best[e.Distance] = e
+			}
+		}
+	}
+	efforts := make([]Effort, 0, len(best))
+	for _, e := range best {
+		efforts = append(efforts, e)
+	}
+	sort.Slice(efforts, func(i, j int) bool { return efforts[i].Distance < efforts[j].Distance })
+	return efforts
+}
+
+// CriticalSpeed fits the two-parameter critical speed model, distance =
+// CS * time + D', on the 3 to 20 minute part of a speed-duration curve. It
+// returns the critical speed in m/s and the anaerobic distance capacity D' in
+// meters. ok is false when the curve does not cover enough of that range.
+func CriticalSpeed(c Curve) (cs, dPrime float64, ok bool) {
+	var n, sx, sy, sxx, sxy float64
+	for _, p := range c {
+		if p.Duration < criticalSpeedMin || p.Duration > criticalSpeedMax || p.Value <= 0 {
+			continue
+		}
+		x := p.Duration.Seconds()
+		y := p.Value * x
+		n++
+		sx += x
+		sy += y
+		sxx += x * x
+		sxy += x * y
+	}
+	den := n*sxx - sx*sx
+	if n < 3 || den == 0 {
+		return 0, 0, false
+	}
+	cs = (n*sxy - sx*sy) / den
+	dPrime = (sy - cs*sx) / n
+	return cs, dPrime, true
+}
@@ -0,0 +1,71 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBestEfforts(t *testing.T) {
+	speeds := append(repeat(3.0, 900), repeat(5.0, 201)...)
+	speeds = append(speeds, repeat(3.0, 900)...)
+	a := testActivity("Running", speeds, nil)
+
+	efforts := a.BestEfforts([]float64{1000, 5000, 100000})
+	if len(efforts) != 2 {
+		t.Fatalf("got %d efforts, want 2", len(efforts))
+	}
+	if d := efforts[0].Duration - 200*time.Second; d < -time.Second || d > time.Second {
+		t.Errorf("best 1000 m = %v, want 3m20s", efforts[0].Duration)
+	}
+	if !efforts[0].Date.Equal(a.ID) {
+		t.Errorf("effort date = %v", efforts[0].Date)
+	}
+
+	b := testActivity("Running", repeat(5.5, 400), nil)
+	b.ID = b.ID.Add(time.Hour)
+	all := BestEffortsRange([]*Activity{a, b}, []float64{1000, 5000}, a.ID, b.ID.Add(time.Hour))
+	if len(all) != 2 || all[0].Date != b.ID || all[1].Date != a.ID {
+		t.Errorf("BestEffortsRange() = %+v", all)
+	}
+}
+
+func TestBestEffort(t *testing.T) {
+	speeds := append(repeat(3.0, 900), repeat(5.0, 201)...)
+	speeds = append(speeds, repeat(3.0, 900)...)
+	a := testActivity("Running", speeds, nil)
+	// Split the track over two laps; indices run across them.
+	track := a.Laps[0].Track
+	a.Laps = []Lap{{Track: track[:1000]}, {Track: track[1000:]}}
+
+	d, from, to := a.BestEffort(1000)
+	if diff := d - 200*time.Second; diff < -time.Second || diff > time.Second {
+		t.Errorf("best 1000 m = %v, want 3m20s", d)
+	}
+	if from < 899 || from > 901 || to < 1099 || to > 1101 {
+		t.Errorf("best 1000 m spans trackpoints %d to %d, want 900 to 1100", from, to)
+	}
+	if d, from, to := a.BestEffort(100000); d != 0 || from != -1 || to != -1 {
+		t.Errorf("BestEffort(100 km) = %v, %d, %d", d, from, to)
+	}
+}
+
+func TestCriticalSpeed(t *testing.T) {
+	// Curve following the model exactly: CS 4 m/s, D' 200 m.
+	var c Curve
+	for d := time.Minute; d <= 30*time.Minute; d += time.Minute {
+		c = append(c, CurvePoint{Duration: d, Value: 4 + 200/d.Seconds()})
+	}
+	cs, dp, ok := CriticalSpeed(c)
+	if !ok || math.Abs(cs-4) > 1e-9 || math.Abs(dp-200) > 1e-6 {
+		t.Errorf("CriticalSpeed() = %v, %v, %v", cs, dp, ok)
+	}
+	if _, _, ok := CriticalSpeed(c[:2]); ok {
+		t.Error("expected no fit on a 2 minute curve")
+	}
+
+	a := testActivity("Running", repeat(3.5, 1801), nil)
+	if got := a.MeanMaxSpeed().At(10 * time.Minute); got != 3.5 {
+		t.Errorf("MeanMaxSpeed() at 10 min = %v", got)
+	}
+}
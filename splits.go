@@ -0,0 +1,80 @@
+package tcx
+
+import (
+	"math"
+	"time"
+)
+
+// SplitUnit is the length of splits, in meters.
+type SplitUnit float64
+
+// Split units.
+const (
+	SplitKilometer SplitUnit = 1000
+	SplitMile      SplitUnit = 1609.344
+)
+
+// Split is one unit of distance of an activity.
+type Split struct {
+	Start    time.Time
+	Distance float64 // meters, short of the unit for the last split
+	Duration time.Duration
+	// Pace is the time per unit at the speed of the split.
+	Pace time.Duration
+	// AverageHeartRate is the time-weighted heart rate, in bpm, zero
+	// without heart rate data.
+	AverageHeartRate float64
+	// ElevationChange is the altitude difference, in meters, between the
+	// end and the start of the split.
+	ElevationChange float64
+}
+
+// Splits cuts the activity into consecutive splits of one unit of distance,
+// whatever its laps, the last one holding the remaining distance.
+func (a *Activity) Splits(unit SplitUnit) []Split {
+	t, d := a.cumulativeTimeDistance()
+	if len(d) == 0 || unit <= 0 || d[len(d)-1] == 0 {
+		return nil
+	}
+	pts := a.trackpoints()
+	hr := integrate(pts, func(p *Trackpoint) float64 { return float64(p.HeartRateInBpm) })
+	hasHR := integrate(pts, func(p *Trackpoint) float64 {
+		if p.HeartRateInBpm > 0 {
+			return 1
+		}
+		return 0
+	})
+	var altD, alt []float64
+	for i, p := range pts {
+		if p.HasAltitude() {
+			altD, alt = append(altD, d[i]), append(alt, p.AltitudeInMeters)
+		}
+	}
+
+	// Ignore a last split of rounding error when the distance is a whole
+	// number of units.
+	const epsilon = 1e-6
+	total := d[len(d)-1]
+	var splits []Split
+	for from := 0.0; total-from > epsilon; from += float64(unit) {
+		to := math.Min(from+float64(unit), total)
+		t0, t1 := interpolate(d, t, from), interpolate(d, t, to)
+		if to == total {
+			t1 = t[len(t)-1]
+		}
+		s := Split{
+			Start:    pts[0].Time.Add(time.Duration(t0 * float64(time.Second))),
+			Distance: to - from,
+			Duration: time.Duration((t1 - t0) * float64(time.Second)),
+		}
+		s.Pace = time.Duration(float64(s.Duration) * float64(unit) / s.Distance)
+		if secs := hasHR.at(t1) - hasHR.at(t0); secs > 0 {
+			s.AverageHeartRate = (hr.at(t1) - hr.at(t0)) / secs
+		}
+		if len(alt) > 0 {
+			s.ElevationChange = interpolate(altD, alt, to) - interpolate(altD, alt, from)
+		}
+		splits = append(splits, s)
+	}
+	return splits
+}
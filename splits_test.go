@@ -0,0 +1,50 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSplits(t *testing.T) {
+	// 2.5 km: a kilometer at 4 m/s, then 1.5 km at 2.5 m/s.
+	speeds := append(repeat(4.0, 251), repeat(2.5, 600)...)
+	hrs := append(repeat(150, 251), repeat(140, 600)...)
+	a := testActivity(SportRunning, speeds, hrs)
+	track := a.Laps[0].Track
+	for i := range track {
+		track[i].AltitudeInMeters = 100 + float64(i)/10
+	}
+	splits := a.Splits(SplitKilometer)
+	if len(splits) != 3 {
+		t.Fatalf("%d splits, want 3", len(splits))
+	}
+	want := []struct {
+		distance float64
+		duration time.Duration
+		hr       float64
+	}{{1000, 250 * time.Second, 150}, {1000, 400 * time.Second, 140}, {500, 200 * time.Second, 140}}
+	for i, w := range want {
+		s := splits[i]
+		if math.Abs(s.Distance-w.distance) > 0.5 || (s.Duration-w.duration).Abs() > time.Second || math.Abs(s.AverageHeartRate-w.hr) > 0.5 {
+			t.Errorf("split %d = %+v, want %v m in %v at %v bpm", i, s, w.distance, w.duration, w.hr)
+		}
+	}
+	if p := splits[2].Pace; (p - 400*time.Second).Abs() > time.Second {
+		t.Errorf("last split pace = %v, want 6m40s", p)
+	}
+	if d := splits[1].Start.Sub(track[250].Time).Abs(); d > time.Millisecond {
+		t.Errorf("second split starts at %v", splits[1].Start)
+	}
+	if e := splits[1].ElevationChange; math.Abs(e-40) > 0.5 {
+		t.Errorf("second split elevation change = %v, want 40", e)
+	}
+
+	miles := a.Splits(SplitMile)
+	if len(miles) != 2 || math.Abs(miles[1].Distance-(2500-1609.344)) > 0.5 {
+		t.Errorf("mile splits = %+v", miles)
+	}
+	if s := (&Activity{}).Splits(SplitKilometer); s != nil {
+		t.Errorf("splits of an empty activity = %v", s)
+	}
+}
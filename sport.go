@@ -0,0 +1,97 @@
+package tcx
+
+import "sort"
+
+// Sport names. The TCX schema only allows Running, Biking and Other in the
+// Sport attribute; Walking and Swimming are returned by InferSport only.
+const (
+	SportRunning  = "Running"
+	SportBiking   = "Biking"
+	SportOther    = "Other"
+	SportWalking  = "Walking"
+	SportSwimming = "Swimming"
+)
+
+// Speed boundaries, in m/s, used to tell sports apart.
+const (
+	inferMovingSpeed = 0.5
+	inferWalkSpeed   = 0.9
+	inferRunSpeed    = 2.2
+	inferBikeSpeed   = 5.5
+	inferBikeP90     = 8.0
+)
+
+// median returns the median of values, sorting them in place.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	return values[len(values)/2]
+}
+
+// InferSport guesses the sport of the activity from its speed distribution,
+// cadence range and heart rate. It returns an empty string when the activity
+// has too little moving data to decide.
+func (a *Activity) InferSport() string {
+	var speeds, cadences []float64
+	var hrSum, hrCount int
+	for _, p := range a.trackpoints() {
+		if p.SpeedInMetersPerSec < inferMovingSpeed {
+			continue
+		}
+		speeds = append(speeds, p.SpeedInMetersPerSec)
+		if p.Cadence > 0 {
+			cadences = append(cadences, float64(p.Cadence))
+		}
+		if p.HeartRateInBpm > 0 {
+			hrSum += p.HeartRateInBpm
+			hrCount++
+		}
+	}
+	if len(speeds) < 10 {
+		return ""
+	}
+	med := median(speeds)
+	p90 := speeds[len(speeds)*9/10]
+	cad := median(cadences)
+
+	switch {
+	case med >= inferBikeSpeed || p90 >= inferBikeP90:
+		return SportBiking
+	case med >= inferRunSpeed:
+		return SportRunning
+	case cad > 0 && cad < 45 && med < inferRunSpeed:
+		// Stroke rates sit well below any walking or running cadence.
+		return SportSwimming
+	case med >= inferWalkSpeed:
+		// Slow runs overlap brisk walks: rely on cadence (strides or
+		// steps per minute), then on heart rate.
+		if (cad >= 75 && cad < 110) || cad >= 150 {
+			return SportRunning
+		}
+		if cad == 0 && hrCount > 0 && hrSum/hrCount > 130 {
+			return SportRunning
+		}
+		return SportWalking
+	}
+	return SportSwimming
+}
+
+// CorrectSport rewrites the Sport attribute when it is "Other" or contradicts
+// the inferred sport. Only the schema values Running and Biking are ever
+// written. It reports whether the activity was changed.
+func (a *Activity) CorrectSport() bool {
+	inferred := a.InferSport()
+	if inferred != SportRunning && inferred != SportBiking {
+		return false
+	}
+	if a.Sport == inferred {
+		return false
+	}
+	if a.Sport != SportOther && a.Sport != SportRunning && a.Sport != SportBiking && a.Sport != "" {
+		return false
+	}
+	a.Sport = inferred
+	return true
+}
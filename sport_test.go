@@ -0,0 +1,39 @@
+package tcx
+
+import "testing"
+
+func TestInferSport(t *testing.T) {
+	tests := []struct {
+		speed float64
+		hr    int
+		want  string
+	}{
+		{8.5, 140, SportBiking},
+		{3.2, 150, SportRunning},
+		{1.4, 95, SportWalking},
+		{1.9, 150, SportRunning},
+	}
+	for _, tt := range tests {
+		a := testActivity(SportOther, repeat(tt.speed, 100), repeat(tt.hr, 100))
+		if got := a.InferSport(); got != tt.want {
+			t.Errorf("InferSport() at %v m/s = %q, want %q", tt.speed, got, tt.want)
+		}
+	}
+
+	a := testActivity(SportRunning, repeat(9.0, 100), nil)
+	if !a.CorrectSport() || a.Sport != SportBiking {
+		t.Errorf("CorrectSport() left Sport = %q", a.Sport)
+	}
+	w := testActivity(SportOther, repeat(1.4, 100), nil)
+	if w.CorrectSport() || w.Sport != SportOther {
+		t.Errorf("CorrectSport() rewrote a walk to %q", w.Sport)
+	}
+
+	tcx, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	if got := tcx.Activities[0].InferSport(); got != SportRunning {
+		t.Errorf("InferSport() = %q for test1.tcx", got)
+	}
+}
@@ -0,0 +1,169 @@
+package tcx
+
+// SportType is a sport finer than the TCX Sport attribute allows, as
+// training platforms and FIT files tell them apart.
+type SportType int
+
+const (
+	SportTypeUnknown SportType = iota
+	SportTypeRun
+	SportTypeTrailRun
+	SportTypeVirtualRun
+	SportTypeWalk
+	SportTypeHike
+	SportTypeRide
+	SportTypeMountainBikeRide
+	SportTypeVirtualRide
+	SportTypeSwim
+	SportTypeRowing
+	SportTypeNordicSki
+	SportTypeOther
+)
+
+// sportTypes describes each SportType: its name, its Strava sport type,
+// its FIT sport and sub sport, and the TCX Sport it is written as.
+var sportTypes = [...]struct {
+	name, strava     string
+	fitSport, fitSub byte
+	tcx              string
+}{
+	SportTypeUnknown:          {"Unknown", "", 0, 0, SportOther},
+	SportTypeRun:              {"Run", "Run", 1, 0, SportRunning},
+	SportTypeTrailRun:         {"TrailRun", "TrailRun", 1, 3, SportRunning},
+	SportTypeVirtualRun:       {"VirtualRun", "VirtualRun", 1, 1, SportRunning},
+	SportTypeWalk:             {"Walk", "Walk", 11, 0, SportOther},
+	SportTypeHike:             {"Hike", "Hike", 17, 0, SportOther},
+	SportTypeRide:             {"Ride", "Ride", 2, 0, SportBiking},
+	SportTypeMountainBikeRide: {"MountainBikeRide", "MountainBikeRide", 2, 8, SportBiking},
+	SportTypeVirtualRide:      {"VirtualRide", "VirtualRide", 2, 6, SportBiking},
+	SportTypeSwim:             {"Swim", "Swim", 5, 0, SportOther},
+	SportTypeRowing:           {"Rowing", "Rowing", 15, 0, SportOther},
+	SportTypeNordicSki:        {"NordicSki", "NordicSki", 12, 0, SportOther},
+	SportTypeOther:            {"Other", "Workout", 0, 0, SportOther},
+}
+
+func (s SportType) String() string {
+	if s < 0 || int(s) >= len(sportTypes) {
+		return sportTypes[SportTypeUnknown].name
+	}
+	return sportTypes[s].name
+}
+
+// valid returns s, or SportTypeUnknown when s is out of range.
+func (s SportType) valid() SportType {
+	if s < 0 || int(s) >= len(sportTypes) {
+		return SportTypeUnknown
+	}
+	return s
+}
+
+// TCXSport returns the Sport attribute the TCX schema allows for s.
+func (s SportType) TCXSport() string {
+	return sportTypes[s.valid()].tcx
+}
+
+// StravaSportType returns the Strava sport_type of s, empty when unknown.
+func (s SportType) StravaSportType() string {
+	return sportTypes[s.valid()].strava
+}
+
+// FITSport returns the FIT sport and sub sport codes of s.
+func (s SportType) FITSport() (sport, subSport byte) {
+	t := sportTypes[s.valid()]
+	return t.fitSport, t.fitSub
+}
+
+// SportTypeFromStrava returns the sport type of a Strava sport_type, or
+// SportTypeOther for those this package doesn't distinguish.
+func SportTypeFromStrava(name string) SportType {
+	if name == "" {
+		return SportTypeUnknown
+	}
+	for s, t := range sportTypes {
+		if t.strava == name {
+			return SportType(s)
+		}
+	}
+	return SportTypeOther
+}
+
+// SportTypeFromFIT returns the sport type of FIT sport and sub sport codes,
+// falling back to the sport alone when the sub sport is not distinguished.
+func SportTypeFromFIT(sport, subSport byte) SportType {
+	fallback := SportTypeOther
+	for s, t := range sportTypes {
+		if SportType(s) == SportTypeUnknown || t.fitSport != sport {
+			continue
+		}
+		if t.fitSub == subSport {
+			return SportType(s)
+		}
+		if t.fitSub == 0 {
+			fallback = SportType(s)
+		}
+	}
+	return fallback
+}
+
+// SportTypeFromTCX returns the sport type of a Sport attribute, including
+// the Walking and Swimming values of InferSport.
+func SportTypeFromTCX(sport string) SportType {
+	switch sport {
+	case SportRunning:
+		return SportTypeRun
+	case SportBiking:
+		return SportTypeRide
+	case SportWalking:
+		return SportTypeWalk
+	case SportSwimming:
+		return SportTypeSwim
+	case SportOther:
+		return SportTypeOther
+	}
+	return SportTypeUnknown
+}
+
+// hillyAscent is the climbing, in meters per kilometer, that makes a run a
+// trail run and a walk a hike.
+const hillyAscent = 40.0
+
+// DetectSport tells the sport type of the activity from its speeds and
+// cadences, as InferSport does, or from its Sport attribute when they are
+// too few, then refines it: indoor runs and rides are virtual, hilly runs
+// are trail runs, hilly walks are hikes, and fast efforts at stroke rates
+// are rowing.
+func (a *Activity) DetectSport() SportType {
+	s := SportTypeFromTCX(a.InferSport())
+	if s == SportTypeUnknown {
+		s = SportTypeFromTCX(a.Sport)
+	}
+	hilly := false
+	if km := a.ComputedDistance() / 1000; km > 0 {
+		hilly = a.TotalAscent()/km >= hillyAscent
+	}
+	switch {
+	case s == SportTypeRun && a.medianCadence() > 0 && a.medianCadence() < 45:
+		return SportTypeRowing
+	case s == SportTypeRun && a.IsIndoor():
+		return SportTypeVirtualRun
+	case s == SportTypeRun && hilly:
+		return SportTypeTrailRun
+	case s == SportTypeRide && a.IsIndoor():
+		return SportTypeVirtualRide
+	case s == SportTypeWalk && hilly:
+		return SportTypeHike
+	}
+	return s
+}
+
+// medianCadence returns the median cadence of the moving trackpoints, zero
+// when none was recorded.
+func (a *Activity) medianCadence() float64 {
+	var cadences []float64
+	for _, p := range a.trackpoints() {
+		if p.SpeedInMetersPerSec >= inferMovingSpeed && p.Cadence > 0 {
+			cadences = append(cadences, float64(p.Cadence))
+		}
+	}
+	return median(cadences)
+}
@@ -0,0 +1,56 @@
+package tcx
+
+import "testing"
+
+func TestDetectSport(t *testing.T) {
+	run := testActivity(SportOther, repeat(3.0, 601), nil)
+	ride := testActivity(SportBiking, repeat(8.0, 601), nil)
+	trail := testActivity(SportRunning, repeat(3.0, 601), nil)
+	hike := testActivity(SportOther, repeat(1.2, 601), nil)
+	treadmill := testActivity(SportRunning, repeat(3.0, 601), nil)
+	row := testActivity(SportOther, repeat(4.0, 601), nil)
+	for i := range trail.Laps[0].Track {
+		// Climbing 120 m over 1.8 km, and 60 m over 720 m.
+		trail.Laps[0].Track[i].AltitudeInMeters = 10 + float64(i/5)
+		hike.Laps[0].Track[i].AltitudeInMeters = 10 + float64(i/10)
+		treadmill.Laps[0].Track[i].LatitudeInDegrees, treadmill.Laps[0].Track[i].LongitudeInDegrees = 0, 0
+		row.Laps[0].Track[i].Cadence = 28
+	}
+	for _, tt := range []struct {
+		name string
+		a    *Activity
+		want SportType
+	}{
+		{"run", run, SportTypeRun},
+		{"ride", ride, SportTypeRide},
+		{"trail", trail, SportTypeTrailRun},
+		{"hike", hike, SportTypeHike},
+		{"treadmill", treadmill, SportTypeVirtualRun},
+		{"row", row, SportTypeRowing},
+		{"declared", &Activity{Sport: SportBiking}, SportTypeRide},
+	} {
+		if got := tt.a.DetectSport(); got != tt.want {
+			t.Errorf("%s: DetectSport() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSportTypeMappings(t *testing.T) {
+	for s := SportTypeRun; s <= SportTypeOther; s++ {
+		if got := SportTypeFromStrava(s.StravaSportType()); got != s {
+			t.Errorf("Strava round trip of %v = %v", s, got)
+		}
+		if got := SportTypeFromFIT(s.FITSport()); got != s && s != SportTypeUnknown {
+			t.Errorf("FIT round trip of %v = %v", s, got)
+		}
+	}
+	if got := SportTypeFromFIT(5, 17); got != SportTypeSwim {
+		t.Errorf("lap swimming = %v", got)
+	}
+	if got := SportTypeFromStrava("Kitesurf"); got != SportTypeOther {
+		t.Errorf("Kitesurf = %v", got)
+	}
+	if SportTypeHike.TCXSport() != SportOther || SportTypeVirtualRide.TCXSport() != SportBiking || SportType(99).String() != "Unknown" {
+		t.Error("unexpected TCX sport or name")
+	}
+}
@@ -0,0 +1,44 @@
+package tcx
+
+import "sync"
+
+// statsMu guards the summaries cached by Stats, so that activities can be
+// read from several goroutines.
+var statsMu sync.RWMutex
+
+// statsGen counts the changes of the default earth model; summaries cached
+// under an earlier count are stale.
+var statsGen uint64
+
+// Stats returns the summary of the activity, computing it on the first call
+// only, for dashboards querying many figures. The methods of the package
+// that change the track or the laps discard the cached summary; call
+// Invalidate after changing them directly.
+//
+// Stats and the other methods computing figures from an activity only read
+// it and may be called from any number of goroutines, as long as none
+// changes the activity meanwhile: change a Clone instead.
+func (a *Activity) Stats() Summary {
+	statsMu.RLock()
+	s, gen := a.stats, statsGen
+	if a.statsGen != gen {
+		s = nil
+	}
+	statsMu.RUnlock()
+	if s != nil {
+		return *s
+	}
+	summary := a.Summary()
+	statsMu.Lock()
+	a.stats, a.statsGen = &summary, gen
+	statsMu.Unlock()
+	return summary
+}
+
+// Invalidate discards the summary cached by Stats, after the fields of the
+// activity, its laps or its trackpoints have been changed directly.
+func (a *Activity) Invalidate() {
+	statsMu.Lock()
+	a.stats = nil
+	statsMu.Unlock()
+}
@@ -0,0 +1,41 @@
+package tcx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	a := testActivity(SportRunning, repeat(4.0, 601), repeat(150, 601))
+	s := a.Stats()
+	if s != a.Summary() || a.stats == nil {
+		t.Fatalf("Stats() = %+v", s)
+	}
+
+	// Direct changes are not seen until Invalidate.
+	a.Laps[0].Track[10].SetHeartRate(200)
+	if a.Stats().MaxHeartRate != 150 {
+		t.Error("Stats() recomputed without Invalidate")
+	}
+	a.Invalidate()
+	if a.Stats().MaxHeartRate != 200 {
+		t.Error("Stats() kept the summary after Invalidate")
+	}
+
+	// Mutators invalidate.
+	a.ShiftTime(time.Hour)
+	if a.stats != nil {
+		t.Error("ShiftTime() kept the cached summary")
+	}
+	a.Stats()
+	if err := a.SplitLapAt(a.Laps[0].StartTime.Add(5 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if a.stats != nil {
+		t.Error("SplitLapAt() kept the cached summary")
+	}
+	a.Stats()
+	if c := a.Crop(time.Time{}, a.Laps[0].StartTime.Add(time.Minute)); c.stats != nil || c.Stats().Duration >= s.Duration {
+		t.Errorf("cropped stats = %+v", c.Stats())
+	}
+}
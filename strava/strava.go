@@ -0,0 +1,106 @@
+// Package strava syncs the tcx model with Strava: it uploads activities
+// through the Uploads API and reads activity streams back into activities.
+// OAuth is left to the caller, who supplies access tokens through a
+// TokenSource; the package only depends on the standard library.
+package strava
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultBaseURL is the root of the Strava API.
+const DefaultBaseURL = "https://www.strava.com/api/v3"
+
+// TokenSource supplies the OAuth access token of a request, refreshing it
+// as needed. Tokens need the activity:write scope to upload and
+// activity:read or activity:read_all to download.
+type TokenSource interface {
+	AccessToken(ctx context.Context) (string, error)
+}
+
+// StaticToken is an access token used as is, such as one the caller
+// refreshes on its own.
+type StaticToken string
+
+func (t StaticToken) AccessToken(context.Context) (string, error) { return string(t), nil }
+
+// Client calls the Strava API on behalf of an athlete.
+type Client struct {
+	Tokens TokenSource
+	// BaseURL of the API, DefaultBaseURL when empty.
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// NewClient returns a client authorizing its requests with tokens.
+func NewClient(tokens TokenSource) *Client {
+	return &Client{Tokens: tokens, BaseURL: DefaultBaseURL, HTTP: http.DefaultClient}
+}
+
+// Error is a failed API call.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("strava: %d %s", e.StatusCode, e.Message)
+}
+
+// do sends a request to path, relative to the base URL, and decodes the
+// JSON response into v.
+func (c *Client) do(ctx context.Context, method, path, contentType string, body io.Reader, v any) error {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(base, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	token, err := c.Tokens.AccessToken(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't get strava access token: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Message string `json:"message"`
+			Errors  []struct {
+				Resource string `json:"resource"`
+				Field    string `json:"field"`
+				Code     string `json:"code"`
+			} `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		msg := e.Message
+		for _, d := range e.Errors {
+			msg += fmt.Sprintf(" (%s %s %s)", d.Resource, d.Field, d.Code)
+		}
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		return &Error{StatusCode: resp.StatusCode, Message: msg}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("couldn't parse strava response: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package strava
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// failingTokens fails to supply a token.
+type failingTokens struct{}
+
+func (failingTokens) AccessToken(context.Context) (string, error) {
+	return "", errors.New("refresh token revoked")
+}
+
+func TestClientErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message": "Authorization Error", "errors": [{"resource": "Athlete", "field": "access_token", "code": "invalid"}]}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(StaticToken("secret"))
+	c.BaseURL = srv.URL
+	_, err := c.UploadStatus(context.Background(), 1)
+	var e *Error
+	if !errors.As(err, &e) || e.StatusCode != http.StatusUnauthorized || e.Message != "Authorization Error (Athlete access_token invalid)" {
+		t.Errorf("UploadStatus() = %v", err)
+	}
+
+	c.Tokens = failingTokens{}
+	if _, err := c.UploadStatus(context.Background(), 1); err == nil {
+		t.Error("request sent without a token")
+	}
+}
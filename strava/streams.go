@@ -0,0 +1,166 @@
+package strava
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+// streamKeys are the stream types requested from the API.
+const streamKeys = "time,latlng,distance,altitude,velocity_smooth,heartrate,cadence,watts"
+
+// Streams holds the samples of an activity, one per recorded point, as
+// returned by the Streams API. Streams the activity lacks are nil.
+type Streams struct {
+	// Time is the offset of each point from the start, in seconds.
+	Time      []int
+	LatLng    [][2]float64
+	Distance  []float64 // meters
+	Altitude  []float64 // meters
+	Velocity  []float64 // m/s, smoothed
+	HeartRate []int
+	// Cadence is in revolutions per minute for rides and strides per
+	// minute for runs.
+	Cadence []int
+	Watts   []int
+}
+
+// stream is one stream of the API.
+type stream struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// ParseStreams reads activity streams JSON, either keyed by type or as a
+// list of streams.
+func ParseStreams(r io.Reader) (*Streams, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var list []stream
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(data, &list)
+	} else {
+		var byType map[string]stream
+		err = json.Unmarshal(data, &byType)
+		for typ, st := range byType {
+			st.Type = typ
+			list = append(list, st)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse strava streams: %v", err)
+	}
+	var s Streams
+	targets := map[string]any{
+		"time":            &s.Time,
+		"latlng":          &s.LatLng,
+		"distance":        &s.Distance,
+		"altitude":        &s.Altitude,
+		"velocity_smooth": &s.Velocity,
+		"heartrate":       &s.HeartRate,
+		"cadence":         &s.Cadence,
+		"watts":           &s.Watts,
+	}
+	for _, st := range list {
+		target, ok := targets[st.Type]
+		if !ok || len(st.Data) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(st.Data, target); err != nil {
+			return nil, fmt.Errorf("couldn't parse strava %s stream: %v", st.Type, err)
+		}
+	}
+	if s.Time == nil {
+		return nil, fmt.Errorf("strava streams have no time stream")
+	}
+	return &s, nil
+}
+
+// Activity converts the streams of an activity that started at start into
+// a one-lap activity. sportType is the Strava sport_type, written as the
+// closest TCX sport.
+func (s *Streams) Activity(start time.Time, sportType string) (*tcx.Activity, error) {
+	sport := tcx.SportTypeFromStrava(sportType)
+	b := tcx.NewActivityBuilder(sport.TCXSport()).StartLap(start)
+	for i, offset := range s.Time {
+		var p tcx.Trackpoint
+		p.Time = start.Add(time.Duration(offset) * time.Second)
+		if i < len(s.LatLng) {
+			p.SetPosition(s.LatLng[i][0], s.LatLng[i][1])
+		}
+		if i < len(s.Altitude) {
+			p.SetAltitude(s.Altitude[i])
+		}
+		if i < len(s.Velocity) {
+			p.SpeedInMetersPerSec = s.Velocity[i]
+		}
+		if i < len(s.HeartRate) {
+			p.SetHeartRate(s.HeartRate[i])
+		}
+		if i < len(s.Cadence) {
+			if sport.TCXSport() == tcx.SportRunning {
+				p.RunCadence = s.Cadence[i]
+			} else {
+				p.SetCadence(s.Cadence[i])
+			}
+		}
+		if i < len(s.Watts) {
+			p.PowerInWatts = s.Watts[i]
+		}
+		b.AddTrackpoint(p)
+	}
+	t, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	a := &t.Activities[0]
+	if n := len(s.Distance); n > 0 {
+		// Strava's distances are those shown to the athlete.
+		a.Laps[0].DistanceInMeters = s.Distance[n-1]
+	}
+	return a, nil
+}
+
+// ActivityStreams downloads the streams of the activity id.
+func (c *Client) ActivityStreams(ctx context.Context, id int64) (*Streams, error) {
+	var raw json.RawMessage
+	path := fmt.Sprintf("/activities/%d/streams?keys=%s&key_by_type=true", id, streamKeys)
+	if err := c.do(ctx, "GET", path, "", nil, &raw); err != nil {
+		return nil, err
+	}
+	return ParseStreams(bytes.NewReader(raw))
+}
+
+// Download fetches the activity id and its streams as a Tcx.
+func (c *Client) Download(ctx context.Context, id int64) (*tcx.Tcx, error) {
+	var meta struct {
+		Name      string    `json:"name"`
+		SportType string    `json:"sport_type"`
+		StartDate time.Time `json:"start_date"`
+	}
+	if err := c.do(ctx, "GET", fmt.Sprintf("/activities/%d", id), "", nil, &meta); err != nil {
+		return nil, err
+	}
+	s, err := c.ActivityStreams(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.Activity(meta.StartDate, meta.SportType)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Name != "" {
+		a.Laps[0].Notes = meta.Name
+	}
+	t := tcx.NewTcx()
+	t.XMLNs = tcx.TrainingCenterNS
+	t.Activities = []tcx.Activity{*a}
+	return t, nil
+}
@@ -0,0 +1,87 @@
+package strava
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+const streamsJSON = `{
+	"time": {"data": [0, 1, 3], "series_type": "distance", "original_size": 3, "resolution": "high"},
+	"latlng": {"data": [[47.0, -1.5], [47.00003, -1.5], [47.00009, -1.5]]},
+	"distance": {"data": [0, 3.4, 10.1]},
+	"altitude": {"data": [12.0, 12.2, 12.6]},
+	"velocity_smooth": {"data": [0, 3.4, 3.3]},
+	"heartrate": {"data": [120, 125, 131]},
+	"cadence": {"data": [84, 86, 85]}
+}`
+
+func TestParseStreams(t *testing.T) {
+	start := time.Date(2015, 4, 12, 7, 0, 0, 0, time.UTC)
+	for name, doc := range map[string]string{
+		"keyed": streamsJSON,
+		"list":  `[{"type": "time", "data": [0, 1, 3]}, {"type": "heartrate", "data": [120, 125, 131]}, {"type": "moving", "data": [false, true, true]}]`,
+	} {
+		s, err := ParseStreams(strings.NewReader(doc))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(s.Time) != 3 || s.HeartRate[2] != 131 {
+			t.Errorf("%s: streams = %+v", name, s)
+		}
+	}
+
+	s, _ := ParseStreams(strings.NewReader(streamsJSON))
+	a, err := s.Activity(start, "TrailRun")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := a.Laps[0]
+	if a.Sport != tcx.SportRunning || len(l.Track) != 3 || l.DistanceInMeters != 10.1 || l.TotalTimeInSeconds != 3 {
+		t.Errorf("activity = %s, %d trackpoints, %v m in %v s", a.Sport, len(l.Track), l.DistanceInMeters, l.TotalTimeInSeconds)
+	}
+	p := l.Track[2]
+	if !p.Time.Equal(start.Add(3*time.Second)) || p.LatitudeInDegrees != 47.00009 || p.HeartRateInBpm != 131 || p.RunCadence != 85 || p.Cadence != 0 {
+		t.Errorf("last trackpoint = %+v", p)
+	}
+
+	if _, err := ParseStreams(strings.NewReader(`{"heartrate": {"data": [1]}}`)); err == nil {
+		t.Error("parsed streams without time")
+	}
+}
+
+func TestDownload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/activities/7":
+			w.Write([]byte(`{"id": 7, "name": "Lunch ride", "sport_type": "Ride", "start_date": "2015-04-12T07:00:00Z"}`))
+		case "/activities/7/streams":
+			if r.URL.Query().Get("key_by_type") != "true" {
+				t.Errorf("query = %v", r.URL.RawQuery)
+			}
+			w.Write([]byte(streamsJSON))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(StaticToken("secret"))
+	c.BaseURL = srv.URL
+	db, err := c.Download(context.Background(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := db.Activities[0]
+	if a.Sport != tcx.SportBiking || a.Laps[0].Notes != "Lunch ride" || a.Laps[0].Track[1].Cadence != 86 {
+		t.Errorf("downloaded %s %q", a.Sport, a.Laps[0].Notes)
+	}
+	if _, err := c.Download(context.Background(), 8); err == nil {
+		t.Error("downloaded a missing activity")
+	}
+}
@@ -0,0 +1,124 @@
+package strava
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"time"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+// UploadOptions describes the activity created by an upload. Empty fields
+// are left for Strava to fill in.
+type UploadOptions struct {
+	Name        string
+	Description string
+	// ExternalID identifies the file on the caller's side, so that
+	// duplicates can be told apart.
+	ExternalID string
+	// Trainer marks indoor activities, Commute commutes.
+	Trainer, Commute bool
+}
+
+// Upload is the state of an upload, which Strava processes
+// asynchronously.
+type Upload struct {
+	ID         int64  `json:"id"`
+	ExternalID string `json:"external_id"`
+	Status     string `json:"status"`
+	// Error explains why processing failed, such as for a duplicate.
+	Error string `json:"error"`
+	// ActivityID is set once processing has created the activity.
+	ActivityID int64 `json:"activity_id"`
+}
+
+// Done reports whether processing is over, with an activity or an error.
+func (u *Upload) Done() bool {
+	return u.ActivityID != 0 || u.Error != ""
+}
+
+// Upload sends t, gzip compressed, to the Uploads API and returns the
+// upload as queued. Use WaitUpload to get the activity it creates.
+func (c *Client) Upload(ctx context.Context, t *tcx.Tcx, opts UploadOptions) (*Upload, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "activity.tcx.gz")
+	if err != nil {
+		return nil, err
+	}
+	zw := gzip.NewWriter(fw)
+	if err := t.Write(zw); err != nil {
+		return nil, fmt.Errorf("couldn't write activity: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	fields := [][2]string{
+		{"data_type", "tcx.gz"},
+		{"name", opts.Name},
+		{"description", opts.Description},
+		{"external_id", opts.ExternalID},
+	}
+	if opts.Trainer {
+		fields = append(fields, [2]string{"trainer", "1"})
+	}
+	if opts.Commute {
+		fields = append(fields, [2]string{"commute", "1"})
+	}
+	for _, f := range fields {
+		if f[1] == "" {
+			continue
+		}
+		if err := mw.WriteField(f[0], f[1]); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	var u Upload
+	if err := c.do(ctx, "POST", "/uploads", mw.FormDataContentType(), &body, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// UploadStatus returns the state of the upload id.
+func (c *Client) UploadStatus(ctx context.Context, id int64) (*Upload, error) {
+	var u Upload
+	if err := c.do(ctx, "GET", fmt.Sprintf("/uploads/%d", id), "", nil, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// minPollInterval is the shortest period at which WaitUpload polls, to
+// spare the API rate limit.
+const minPollInterval = time.Second
+
+// WaitUpload polls the upload id every interval, and at most once a second,
+// until it is processed or ctx is done. A processing error, such as a
+// duplicate activity, is returned as an error along with the upload.
+func (c *Client) WaitUpload(ctx context.Context, id int64, interval time.Duration) (*Upload, error) {
+	interval = max(interval, minPollInterval)
+	for {
+		u, err := c.UploadStatus(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if u.Error != "" {
+			return u, fmt.Errorf("strava upload %d failed: %s", id, u.Error)
+		}
+		if u.Done() {
+			return u, nil
+		}
+		select {
+		case <-ctx.Done():
+			return u, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package strava
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+func TestUpload(t *testing.T) {
+	db, err := tcx.ParseFile("../testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/uploads":
+			if r.FormValue("data_type") != "tcx.gz" || r.FormValue("name") != "Morning run" || r.FormValue("trainer") != "" {
+				t.Errorf("form = %v", r.MultipartForm.Value)
+			}
+			f, _, err := r.FormFile("file")
+			if err != nil {
+				t.Fatal(err)
+			}
+			zr, err := gzip.NewReader(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			up, err := tcx.Parse(zr)
+			if err != nil || len(up.Activities) != 1 {
+				t.Errorf("uploaded file: %v", err)
+			}
+			json.NewEncoder(w).Encode(Upload{ID: 42, ExternalID: "run-1", Status: "Your activity is still being processed."})
+		case r.URL.Path == "/uploads/42":
+			polls++
+			u := Upload{ID: 42, Status: "Your activity is still being processed."}
+			if polls == 2 {
+				u.Status, u.ActivityID = "Your activity is ready.", 7
+			}
+			json.NewEncoder(w).Encode(u)
+		case r.URL.Path == "/uploads/43":
+			json.NewEncoder(w).Encode(Upload{ID: 43, Error: "duplicate of activity 7"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(StaticToken("secret"))
+	c.BaseURL = srv.URL
+	ctx := context.Background()
+	u, err := c.Upload(ctx, db, UploadOptions{Name: "Morning run", ExternalID: "run-1"})
+	if err != nil || u.ID != 42 || u.Done() {
+		t.Fatalf("Upload() = %+v, %v", u, err)
+	}
+	begin := time.Now()
+	if u, err := c.WaitUpload(ctx, u.ID, 0); err != nil || u.ActivityID != 7 || polls != 2 {
+		t.Errorf("WaitUpload() = %+v, %v after %d polls", u, err, polls)
+	}
+	if d := time.Since(begin); d < minPollInterval {
+		t.Errorf("WaitUpload() polled again after %v", d)
+	}
+	if _, err := c.WaitUpload(ctx, 43, time.Millisecond); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("WaitUpload() of a duplicate = %v", err)
+	}
+}
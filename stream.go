@@ -0,0 +1,87 @@
+package tcx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// StreamPoint is a trackpoint passed by ParseStream, with the indexes of its
+// activity, as in Tcx.AllActivities, and of its lap in the activity.
+type StreamPoint struct {
+	Activity int
+	Lap      int
+	Trackpoint
+}
+
+// ParseStream decodes the trackpoints of the activities of a TCX reader one
+// at a time and passes them to fn in document order, without building the
+// document in memory, so that memory use stays flat whatever the size of the
+// file. Course trackpoints are skipped. An error returned by fn stops the
+// parse and is returned as is. Gzip compressed data is detected and
+// decompressed.
+func ParseStream(r io.Reader, fn func(StreamPoint) error) error {
+	start := time.Now()
+	cr := &countingReader{r: r}
+	stats := ParseStats{}
+	err := parseStream(cr, func(p StreamPoint) error {
+		stats.Trackpoints++
+		return fn(p)
+	})
+	stats.Bytes, stats.Err, stats.Duration = cr.n, err, time.Since(start)
+	observeParse(stats)
+	return err
+}
+
+func parseStream(r io.Reader, fn func(StreamPoint) error) error {
+	r, err := decompress(r)
+	if err != nil {
+		return err
+	}
+	d := xml.NewDecoder(r)
+	var path []string
+	activity, lap := -1, -1
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't parse tcx data: %v", err)
+		}
+		if _, ok := tok.(xml.EndElement); ok && len(path) > 0 {
+			path = path[:len(path)-1]
+			continue
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch name := se.Name.Local; {
+		case name == "Activity":
+			activity, lap = activity+1, -1
+		case name == "Lap" && activity >= 0:
+			lap++
+		case name == "Trackpoint":
+			// Only the trackpoints of activity laps: courses hold their
+			// track directly.
+			n := len(path)
+			if activity < 0 || n < 2 || path[n-1] != "Track" || path[n-2] != "Lap" {
+				if err := d.Skip(); err != nil {
+					return fmt.Errorf("couldn't parse tcx data: %v", err)
+				}
+				continue
+			}
+			p := StreamPoint{Activity: activity, Lap: lap}
+			if err := d.DecodeElement(&p.Trackpoint, &se); err != nil {
+				return fmt.Errorf("couldn't parse tcx trackpoint: %v", err)
+			}
+			if err := fn(p); err != nil {
+				return err
+			}
+			continue
+		}
+		path = append(path, se.Name.Local)
+	}
+}
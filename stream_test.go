@@ -0,0 +1,119 @@
+package tcx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseStream(t *testing.T) {
+	full, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := full.Activities[0].trackpoints()
+	laps := make(map[*Trackpoint]int)
+	for i := range full.Activities[0].Laps {
+		for j := range full.Activities[0].Laps[i].Track {
+			laps[&full.Activities[0].Laps[i].Track[j]] = i
+		}
+	}
+
+	f, err := os.Open("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var n int
+	err = ParseStream(f, func(p StreamPoint) error {
+		if n < len(want) && (!reflect.DeepEqual(p.Trackpoint, *want[n]) || p.Activity != 0 || p.Lap != laps[want[n]]) {
+			t.Errorf("trackpoint %d = %+v, want %+v in lap %d", n, p, *want[n], laps[want[n]])
+		}
+		n++
+		return nil
+	})
+	if err != nil || n != 1937 {
+		t.Errorf("streamed %d trackpoints, %v", n, err)
+	}
+
+	stop := errors.New("stop")
+	f.Seek(0, io.SeekStart)
+	n = 0
+	err = ParseStream(f, func(StreamPoint) error {
+		if n++; n == 10 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || n != 10 {
+		t.Errorf("stopped after %d trackpoints with %v", n, err)
+	}
+
+	// Course trackpoints are skipped, and activities counted.
+	const doc = `<TrainingCenterDatabase>
+<Activities>
+<Activity><Lap><Track><Trackpoint/></Track></Lap></Activity>
+<Activity><Lap/><Lap><Track><Trackpoint/><Trackpoint/></Track></Lap></Activity>
+</Activities>
+<Courses><Course><Lap/><Track><Trackpoint/></Track></Course></Courses>
+</TrainingCenterDatabase>`
+	var got []string
+	err = ParseStream(strings.NewReader(doc), func(p StreamPoint) error {
+		got = append(got, fmt.Sprintf("%d.%d", p.Activity, p.Lap))
+		return nil
+	})
+	if s := strings.Join(got, " "); err != nil || s != "0.0 1.1 1.1" {
+		t.Errorf("streamed %q, %v", s, err)
+	}
+}
+
+// endlessTrack generates a TCX document of n trackpoints on the fly.
+type endlessTrack struct {
+	n, i int
+	buf  strings.Reader
+}
+
+func (e *endlessTrack) Read(p []byte) (int, error) {
+	for e.buf.Len() == 0 {
+		switch {
+		case e.i == 0:
+			e.buf.Reset(`<TrainingCenterDatabase><Activities><Activity Sport="Running"><Lap><Track>`)
+		case e.i <= e.n:
+			e.buf.Reset(fmt.Sprintf(`<Trackpoint><Time>2015-04-12T07:00:00Z</Time><HeartRateBpm><Value>%d</Value></HeartRateBpm></Trackpoint>`, 100+e.i%50))
+		case e.i == e.n+1:
+			e.buf.Reset(`</Track></Lap></Activity></Activities></TrainingCenterDatabase>`)
+		default:
+			return 0, io.EOF
+		}
+		e.i++
+	}
+	return e.buf.Read(p)
+}
+
+func TestParseStreamMemory(t *testing.T) {
+	var before runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	var n, peak int
+	err := ParseStream(&endlessTrack{n: 200000}, func(p StreamPoint) error {
+		n++
+		if n%50000 == 0 {
+			runtime.GC()
+			var m runtime.MemStats
+			runtime.ReadMemStats(&m)
+			peak = max(peak, int(m.HeapAlloc))
+		}
+		return nil
+	})
+	if err != nil || n != 200000 {
+		t.Fatalf("streamed %d trackpoints, %v", n, err)
+	}
+	if grown := peak - int(before.HeapAlloc); grown > 4<<20 {
+		t.Errorf("heap grew by %d bytes while streaming", grown)
+	}
+}
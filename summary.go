@@ -0,0 +1,155 @@
+package tcx
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Summary gathers the usual figures of an activity or a lap.
+type Summary struct {
+	Duration   time.Duration
+	MovingTime time.Duration
+	Distance   float64 // meters
+	// AverageSpeed, in m/s, and Pace are over the moving time.
+	AverageSpeed     float64
+	MaxSpeed         float64 // m/s
+	Pace             Pace
+	AverageHeartRate float64 // bpm
+	MaxHeartRate     int     // bpm
+	AverageCadence   float64
+	Ascent           float64 // meters
+	Descent          float64 // meters
+	Calories         float64
+	// CalorieModel names the model of Calories: "device" when recorded,
+	// that of the estimate otherwise. Models of laps that differ are joined
+	// with "+".
+	CalorieModel string
+	// Surfaces is the distance by surface and road class, filled by
+	// SurfaceSummary only.
+	Surfaces *SurfaceBreakdown
+}
+
+// Summary returns the summary of the activity, computed in a single pass
+// over its trackpoints. Duration, distance and calories are the lap totals;
+// the distance follows the track when the laps don't record it.
+func (a *Activity) Summary() Summary {
+	var s Summary
+	for _, l := range a.Laps {
+		s.Duration += time.Duration(l.TotalTimeInSeconds * float64(time.Second))
+		s.Distance += l.DistanceInMeters
+		s.Calories += l.Calories
+		s.MaxSpeed = math.Max(s.MaxSpeed, l.MaximumSpeedInMetersPerSec)
+		s.MaxHeartRate = max(s.MaxHeartRate, l.MaximumHeartRateInBpm)
+		if m := l.calorieModelName(); m != "" && !slices.Contains(strings.Split(s.CalorieModel, "+"), m) {
+			if s.CalorieModel != "" {
+				s.CalorieModel += "+"
+			}
+			s.CalorieModel += m
+		}
+	}
+	summarize(&s, a.trackpoints(), !a.IsIndoor())
+	return s
+}
+
+// EstimatedSummary returns the summary of the activity with its calories
+// estimated by m for the athlete, rather than the lap totals, and the model
+// used.
+func (a *Activity) EstimatedSummary(ath Athlete, m CalorieModels) Summary {
+	s := a.Summary()
+	e := m.Estimate(a, ath)
+	s.Calories, s.CalorieModel = e.Calories, e.Model
+	return s
+}
+
+// Summary returns the summary of the lap, computed in a single pass over
+// its track.
+func (l *Lap) Summary() Summary {
+	s := Summary{
+		Duration:     time.Duration(l.TotalTimeInSeconds * float64(time.Second)),
+		Distance:     l.DistanceInMeters,
+		Calories:     l.Calories,
+		CalorieModel: l.calorieModelName(),
+		MaxSpeed:     l.MaximumSpeedInMetersPerSec,
+		MaxHeartRate: l.MaximumHeartRateInBpm,
+	}
+	summarize(&s, pointers(l.Track), true)
+	if s.AverageHeartRate == 0 {
+		s.AverageHeartRate = float64(l.AverageHeartRateInBpm)
+	}
+	if s.AverageCadence == 0 {
+		s.AverageCadence = float64(l.Cadence)
+	}
+	return s
+}
+
+// summarize completes s with the figures of pts, measuring distances with
+// segmentDistance. Averages are weighted by the time each reading holds, up
+// to 10 s; moving time follows DefaultMovingOptions and elevation
+// DefaultElevationHysteresis.
+func summarize(s *Summary, pts []*Trackpoint, gps bool) {
+	var hr, hrTime, cad, cadTime, moving, dist float64
+	var ref float64
+	var hasRef bool
+	for i, p := range pts {
+		s.MaxSpeed = math.Max(s.MaxSpeed, p.SpeedInMetersPerSec)
+		if p.HasHeartRate() {
+			s.MaxHeartRate = max(s.MaxHeartRate, p.HeartRateInBpm)
+		}
+		if p.HasAltitude() {
+			switch alt := p.AltitudeInMeters; {
+			case !hasRef:
+				ref, hasRef = alt, true
+			case alt-ref >= DefaultElevationHysteresis:
+				s.Ascent += alt - ref
+				ref = alt
+			case ref-alt >= DefaultElevationHysteresis:
+				s.Descent += ref - alt
+				ref = alt
+			}
+		}
+		if i+1 == len(pts) {
+			break
+		}
+		q := pts[i+1]
+		dt := q.Time.Sub(p.Time).Seconds()
+		if dt <= 0 {
+			continue
+		}
+		d := segmentDistance(p, q, gps)
+		dist += d
+		if dt > maxHoldGap.Seconds() {
+			continue
+		}
+		if p.HasHeartRate() {
+			hr += float64(p.HeartRateInBpm) * dt
+			hrTime += dt
+		}
+		if p.HasCadence() {
+			cad += float64(p.Cadence) * dt
+			cadTime += dt
+		}
+		speed := p.SpeedInMetersPerSec
+		if speed == 0 {
+			speed = d / dt
+		}
+		if speed >= DefaultMovingOptions.MinSpeed {
+			moving += dt
+		}
+	}
+	if hrTime > 0 {
+		s.AverageHeartRate = hr / hrTime
+	}
+	if cadTime > 0 {
+		s.AverageCadence = cad / cadTime
+	}
+	if s.Distance == 0 {
+		s.Distance = dist
+	}
+	s.MovingTime = time.Duration(moving * float64(time.Second))
+	if moving > 0 {
+		s.AverageSpeed = s.Distance / moving
+		s.Pace = PaceFromSpeed(s.AverageSpeed)
+	}
+}
@@ -0,0 +1,100 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSummary(t *testing.T) {
+	hrs := repeat(140, 601)
+	hrs[200] = 180
+	a := testActivity(SportRunning, repeat(4.0, 601), hrs)
+	track := a.Laps[0].Track
+	for i := range track {
+		track[i].SetAltitude(100 + float64(min(i, 300))/10)
+		track[i].SetCadence(85)
+	}
+	// A 2 minute stop at the end.
+	for i := 480; i < len(track); i++ {
+		track[i].SpeedInMetersPerSec = 0
+		track[i].LatitudeInDegrees = track[480].LatitudeInDegrees
+	}
+	a.Laps[0].Calories = 120
+	a.Laps[0].DistanceInMeters = 1920
+
+	s := a.Summary()
+	if s.Duration != 600*time.Second || math.Abs(s.Distance-1920) > 1 || s.Calories != 120 {
+		t.Errorf("totals = %v, %v m, %v kcal", s.Duration, s.Distance, s.Calories)
+	}
+	if s.MovingTime != 480*time.Second || math.Abs(s.AverageSpeed-4) > 0.01 || s.Pace.String() != "4:10" {
+		t.Errorf("moving %v at %v m/s, %v /km", s.MovingTime, s.AverageSpeed, s.Pace)
+	}
+	if s.MaxHeartRate != 180 || math.Abs(s.AverageHeartRate-140.07) > 0.01 || s.AverageCadence != 85 {
+		t.Errorf("heart rate %v max %v, cadence %v", s.AverageHeartRate, s.MaxHeartRate, s.AverageCadence)
+	}
+	if s.MaxSpeed != 4 || s.Ascent != 30 || s.Descent != 0 {
+		t.Errorf("max speed %v, ascent %v, descent %v", s.MaxSpeed, s.Ascent, s.Descent)
+	}
+	if ls := a.Laps[0].Summary(); ls != s {
+		t.Errorf("lap summary = %+v, want %+v", ls, s)
+	}
+	if s.CalorieModel != "device" {
+		t.Errorf("calorie model = %q, want device", s.CalorieModel)
+	}
+}
+
+func TestSummaryCalorieModel(t *testing.T) {
+	a := testActivity(SportRunning, repeat(3.0, 601), repeat(150, 601))
+	if err := a.SplitLapAt(a.Laps[0].Track[300].Time); err != nil {
+		t.Fatal(err)
+	}
+	a.Laps[0].Calories = 80
+	ath := Athlete{Weight: 70, Age: 35, Sex: SexMale}
+	if n := a.FillCalories(ath, DefaultCalorieModels); n != 1 {
+		t.Fatalf("filled %d laps, want 1", n)
+	}
+	if m := a.Summary().CalorieModel; m != "device+heart rate" {
+		t.Errorf("calorie model = %q", m)
+	}
+	if m := a.Laps[1].Summary().CalorieModel; m != "heart rate" {
+		t.Errorf("lap calorie model = %q", m)
+	}
+	if s := a.EstimatedSummary(Athlete{Weight: 70}, CalorieModels{Default: []CalorieModel{METCalories{}}}); s.CalorieModel != "MET" || s.Calories <= 0 {
+		t.Errorf("estimated summary = %v kcal by %q", s.Calories, s.CalorieModel)
+	}
+}
+
+// TestTreadmill covers a footpod recording, with distances and heart rate
+// but neither GPS nor speed.
+func TestTreadmill(t *testing.T) {
+	db, err := ParseFile("testdata/treadmill.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &db.Activities[0]
+	s := a.Summary()
+	if s.Distance != 1800 || s.MovingTime != 10*time.Minute || math.Abs(s.AverageSpeed-3) > 1e-9 || s.Pace.String() != "5:33" {
+		t.Errorf("Summary() = %+v", s)
+	}
+	if ls := a.Laps[0].Summary(); ls.MovingTime != 10*time.Minute {
+		t.Errorf("lap moving time = %v", ls.MovingTime)
+	}
+	if p, n := a.AveragePace(); p.String() != "5:33" || n != 600 {
+		t.Errorf("AveragePace() = %v over %d samples", p, n)
+	}
+	times, speeds := a.Series(MetricSpeed)
+	if len(times) != 121 {
+		t.Fatalf("speed series of %d samples", len(times))
+	}
+	for i, v := range speeds {
+		if math.Abs(v-3) > 1e-9 {
+			t.Fatalf("speed %d = %v, want 3", i, v)
+		}
+	}
+	l := a.Laps[0]
+	l.update()
+	if l.DistanceInMeters != 1800 {
+		t.Errorf("updated lap distance = %v", l.DistanceInMeters)
+	}
+}
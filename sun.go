@@ -0,0 +1,141 @@
+package tcx
+
+import (
+	"math"
+	"time"
+)
+
+// The solar computations below follow the NOAA solar calculator
+// approximations, accurate to about a minute outside the polar regions.
+
+// sunriseElevation is the solar elevation, in degrees, at which the sun is
+// considered to rise or set (refraction and the solar disc radius included).
+const sunriseElevation = -0.833
+
+func deg2rad(d float64) float64 { return d * math.Pi / 180 }
+func rad2deg(r float64) float64 { return r * 180 / math.Pi }
+
+// solarParams returns the solar declination (degrees) and the equation of
+// time (minutes) at t.
+func solarParams(t time.Time) (decl, eqTime float64) {
+	jd := float64(t.UTC().UnixNano())/float64(24*time.Hour) + 2440587.5
+	jc := (jd - 2451545) / 36525
+
+	l0 := math.Mod(280.46646+jc*(36000.76983+jc*0.0003032), 360)
+	m := 357.52911 + jc*(35999.05029-0.0001537*jc)
+	e := 0.016708634 - jc*(0.000042037+0.0000001267*jc)
+	c := math.Sin(deg2rad(m))*(1.914602-jc*(0.004817+0.000014*jc)) +
+		math.Sin(deg2rad(2*m))*(0.019993-0.000101*jc) +
+		math.Sin(deg2rad(3*m))*0.000289
+	omega := 125.04 - 1934.136*jc
+	appLong := l0 + c - 0.00569 - 0.00478*math.Sin(deg2rad(omega))
+	meanObliq := 23 + (26+(21.448-jc*(46.815+jc*(0.00059-jc*0.001813)))/60)/60
+	obliq := meanObliq + 0.00256*math.Cos(deg2rad(omega))
+
+	decl = rad2deg(math.Asin(math.Sin(deg2rad(obliq)) * math.Sin(deg2rad(appLong))))
+
+	y := math.Pow(math.Tan(deg2rad(obliq/2)), 2)
+	l0r, mr := deg2rad(l0), deg2rad(m)
+	eqTime = 4 * rad2deg(y*math.Sin(2*l0r)-2*e*math.Sin(mr)+
+		4*e*y*math.Sin(mr)*math.Cos(2*l0r)-
+		0.5*y*y*math.Sin(4*l0r)-1.25*e*e*math.Sin(2*mr))
+	return decl, eqTime
+}
+
+// SolarElevation returns the elevation of the sun above the horizon, in
+// degrees, at time t seen from the given position.
+func SolarElevation(t time.Time, lat, lon float64) float64 {
+	decl, eqTime := solarParams(t)
+	t = t.UTC()
+	minutes := float64(t.Hour()*60+t.Minute()) + float64(t.Second())/60
+	ha := math.Mod(minutes+eqTime+4*lon, 1440)/4 - 180
+	if ha < -180 {
+		ha += 360
+	}
+	latr, declr := deg2rad(lat), deg2rad(decl)
+	cosZenith := math.Sin(latr)*math.Sin(declr) + math.Cos(latr)*math.Cos(declr)*math.Cos(deg2rad(ha))
+	return 90 - rad2deg(math.Acos(math.Max(-1, math.Min(1, cosZenith))))
+}
+
+// SunTimes returns the sunrise and sunset, in UTC, for the UTC day of date at
+// the given position. ok is false when the sun stays above or below the
+// horizon for the whole day.
+func SunTimes(date time.Time, lat, lon float64) (sunrise, sunset time.Time, ok bool) {
+	date = date.UTC()
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	decl, eqTime := solarParams(day.Add(12*time.Hour - time.Duration(lon*4)*time.Minute))
+
+	latr, declr := deg2rad(lat), deg2rad(decl)
+	cosHA := math.Cos(deg2rad(90-sunriseElevation))/(math.Cos(latr)*math.Cos(declr)) - math.Tan(latr)*math.Tan(declr)
+	if cosHA < -1 || cosHA > 1 {
+		return time.Time{}, time.Time{}, false
+	}
+	ha := rad2deg(math.Acos(cosHA))
+	noon := 720 - 4*lon - eqTime
+	minute := func(m float64) time.Time {
+		return day.Add(time.Duration(m * float64(time.Minute)))
+	}
+	return minute(noon - 4*ha), minute(noon + 4*ha), true
+}
+
+// SunTimes returns the sunrise and sunset for the day the activity started,
+// at its first recorded position.
+func (a *Activity) SunTimes() (sunrise, sunset time.Time, ok bool) {
+	for _, p := range a.trackpoints() {
+		if p.HasPosition() {
+			return SunTimes(p.Time, p.LatitudeInDegrees, p.LongitudeInDegrees)
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// Daylight returns a day/night flag for every trackpoint of the activity, in
+// lap order. Points without a GPS fix use the last known position; points
+// recorded before the first fix use the first one.
+func (a *Activity) Daylight() []bool {
+	pts := a.trackpoints()
+	flags := make([]bool, len(pts))
+	var lat, lon float64
+	found := false
+	for _, p := range pts {
+		if p.HasPosition() {
+			lat, lon, found = p.LatitudeInDegrees, p.LongitudeInDegrees, true
+			break
+		}
+	}
+	if !found {
+		return flags
+	}
+	for i, p := range pts {
+		if p.HasPosition() {
+			lat, lon = p.LatitudeInDegrees, p.LongitudeInDegrees
+		}
+		flags[i] = SolarElevation(p.Time, lat, lon) > sunriseElevation
+	}
+	return flags
+}
+
+// DaylightFraction returns the fraction of the activity's recorded time spent
+// in daylight, between 0 and 1.
+func (a *Activity) DaylightFraction() float64 {
+	pts := a.trackpoints()
+	flags := a.Daylight()
+	var day, total float64
+	for i := 1; i < len(pts); i++ {
+		dt := pts[i].Time.Sub(pts[i-1].Time).Seconds()
+		if dt <= 0 {
+			continue
+		}
+		total += dt
+		if flags[i-1] {
+			day += dt
+		}
+	}
+	if total == 0 {
+		if len(flags) > 0 && flags[0] {
+			return 1
+		}
+		return 0
+	}
+	return day / total
+}
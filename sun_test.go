@@ -0,0 +1,44 @@
+package tcx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSunTimes(t *testing.T) {
+	// Nantes, 12 April 2015: sunrise 05:24 UTC, sunset 18:50 UTC.
+	date := time.Date(2015, 4, 12, 0, 0, 0, 0, time.UTC)
+	rise, set, ok := SunTimes(date, 47.2184, -1.5536)
+	if !ok {
+		t.Fatal("expected sunrise and sunset")
+	}
+	want := time.Date(2015, 4, 12, 5, 24, 0, 0, time.UTC)
+	if d := rise.Sub(want); d < -5*time.Minute || d > 5*time.Minute {
+		t.Errorf("sunrise = %v, want about %v", rise, want)
+	}
+	want = time.Date(2015, 4, 12, 18, 50, 0, 0, time.UTC)
+	if d := set.Sub(want); d < -5*time.Minute || d > 5*time.Minute {
+		t.Errorf("sunset = %v, want about %v", set, want)
+	}
+
+	// Tromsø has midnight sun in June.
+	if _, _, ok := SunTimes(time.Date(2015, 6, 21, 0, 0, 0, 0, time.UTC), 69.65, 18.96); ok {
+		t.Error("expected no sunset at Tromsø in June")
+	}
+}
+
+func TestDaylightFraction(t *testing.T) {
+	tcx, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal("Error parsing TCX file: ", err)
+	}
+	a := &tcx.Activities[0]
+	if f := a.DaylightFraction(); f != 1 {
+		t.Errorf("DaylightFraction() = %v, want 1", f)
+	}
+
+	night := a.trackpoints()[0].Time.Add(-6 * time.Hour)
+	if e := SolarElevation(night, 47.2184, -1.5536); e > sunriseElevation {
+		t.Errorf("SolarElevation() = %v at night", e)
+	}
+}
@@ -0,0 +1,229 @@
+package tcx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Surface categories.
+const (
+	SurfacePaved   = "paved"
+	SurfaceGravel  = "gravel"
+	SurfaceTrail   = "trail"
+	SurfaceUnknown = "unknown"
+)
+
+// WayInfo describes the way under a position with its OSM tags.
+type WayInfo struct {
+	// Surface is the OSM surface tag, possibly empty.
+	Surface string
+	// Class is the OSM highway tag, such as residential, cycleway or path.
+	Class string
+}
+
+// SurfaceProvider looks up the way at a position of a matched route. ok is
+// false when there is no way there.
+type SurfaceProvider interface {
+	WayAt(ctx context.Context, lat, lon float64) (w WayInfo, ok bool, err error)
+}
+
+// Surface categories of OSM surface values.
+var surfaceCategories = map[string]string{
+	"paved": SurfacePaved, "asphalt": SurfacePaved, "concrete": SurfacePaved,
+	"concrete:plates": SurfacePaved, "paving_stones": SurfacePaved, "sett": SurfacePaved,
+	"cobblestone": SurfacePaved, "metal": SurfacePaved, "wood": SurfacePaved,
+	"compacted": SurfaceGravel, "fine_gravel": SurfaceGravel, "gravel": SurfaceGravel,
+	"pebblestone": SurfaceGravel, "unpaved": SurfaceGravel,
+	"dirt": SurfaceTrail, "earth": SurfaceTrail, "ground": SurfaceTrail, "grass": SurfaceTrail,
+	"mud": SurfaceTrail, "sand": SurfaceTrail, "rock": SurfaceTrail, "woodchips": SurfaceTrail,
+}
+
+// SurfaceCategory returns the category, paved, gravel, trail or unknown, of
+// a way. Ways without a surface tag are categorized by their class.
+func SurfaceCategory(w WayInfo) string {
+	if c, ok := surfaceCategories[w.Surface]; ok {
+		return c
+	}
+	switch w.Class {
+	case "motorway", "trunk", "primary", "secondary", "tertiary", "unclassified",
+		"residential", "service", "living_street", "cycleway":
+		return SurfacePaved
+	case "track":
+		return SurfaceGravel
+	case "path", "footway", "bridleway":
+		return SurfaceTrail
+	}
+	return SurfaceUnknown
+}
+
+// SurfaceBreakdown is the distance covered, in meters, by surface category
+// and by road class.
+type SurfaceBreakdown struct {
+	Surface map[string]float64
+	Class   map[string]float64
+}
+
+// surfaceSampleDistance is the spacing of way lookups along the track.
+const surfaceSampleDistance = 50
+
+// SurfaceBreakdown splits the distance of a matched activity between the
+// surfaces and road classes of the ways reported by p. Ways are looked up
+// every 50 m; distance off any way counts as unknown.
+func (a *Activity) SurfaceBreakdown(ctx context.Context, p SurfaceProvider) (SurfaceBreakdown, error) {
+	b := SurfaceBreakdown{Surface: make(map[string]float64), Class: make(map[string]float64)}
+	var track []*Trackpoint
+	for _, pt := range a.trackpoints() {
+		if pt.HasPosition() {
+			track = append(track, pt)
+		}
+	}
+	if len(track) < 2 {
+		return b, nil
+	}
+	dist := cumulativeDistances(track)
+	add := func(from, to int) error {
+		d := dist[to] - dist[from]
+		if d <= 0 {
+			return nil
+		}
+		mid := track[(from+to+1)/2]
+		w, ok, err := p.WayAt(ctx, mid.LatitudeInDegrees, mid.LongitudeInDegrees)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			b.Surface[SurfaceUnknown] += d
+			b.Class[""] += d
+			return nil
+		}
+		b.Surface[SurfaceCategory(w)] += d
+		b.Class[w.Class] += d
+		return nil
+	}
+	from := 0
+	for i := 1; i < len(track); i++ {
+		if dist[i]-dist[from] >= surfaceSampleDistance {
+			if err := add(from, i); err != nil {
+				return b, err
+			}
+			from = i
+		}
+	}
+	if from < len(track)-1 {
+		if err := add(from, len(track)-1); err != nil {
+			return b, err
+		}
+	}
+	return b, nil
+}
+
+// SurfaceSummary returns the summary of the activity with its surface
+// breakdown, looked up from p as SurfaceBreakdown does.
+func (a *Activity) SurfaceSummary(ctx context.Context, p SurfaceProvider) (Summary, error) {
+	s := a.Summary()
+	b, err := a.SurfaceBreakdown(ctx, p)
+	if err != nil {
+		return s, err
+	}
+	s.Surfaces = &b
+	return s, nil
+}
+
+// WayAt returns the tags of the way of the network nearest to the position,
+// within 20 m.
+func (n *RoadNetwork) WayAt(ctx context.Context, lat, lon float64) (WayInfo, bool, error) {
+	best, bestWay := 20.0, -1
+	for w, way := range n.Ways {
+		for k := 1; k < len(way.Nodes); k++ {
+			plat, plon := projectOnSegment(lat, lon, way.Nodes[k-1], way.Nodes[k])
+			if d := distance(lat, lon, plat, plon); d <= best {
+				best, bestWay = d, w
+			}
+		}
+	}
+	if bestWay < 0 {
+		return WayInfo{}, false, nil
+	}
+	tags := n.Ways[bestWay].Tags
+	return WayInfo{Surface: tags["surface"], Class: tags["highway"]}, true, nil
+}
+
+// OverpassProvider looks ways up with the OSM Overpass API. Answers are
+// cached by position rounded to about 10 m.
+type OverpassProvider struct {
+	// URL of the interpreter, such as https://overpass-api.de/api/interpreter.
+	URL string
+	// Radius of the search around positions, in meters.
+	Radius float64
+	Client *http.Client
+
+	mu    sync.Mutex
+	cache map[[2]int64]*WayInfo
+}
+
+// NewOverpassProvider returns a provider querying the interpreter at url.
+func NewOverpassProvider(url string) *OverpassProvider {
+	return &OverpassProvider{URL: url, Radius: 15, Client: http.DefaultClient}
+}
+
+func (o *OverpassProvider) WayAt(ctx context.Context, lat, lon float64) (WayInfo, bool, error) {
+	key := [2]int64{int64(math.Round(lat * 1e4)), int64(math.Round(lon * 1e4))}
+	o.mu.Lock()
+	cached, hit := o.cache[key]
+	o.mu.Unlock()
+	if hit {
+		if cached == nil {
+			return WayInfo{}, false, nil
+		}
+		return *cached, true, nil
+	}
+
+	query := fmt.Sprintf("[out:json];way(around:%g,%f,%f)[highway];out tags 1;", o.Radius, lat, lon)
+	req, err := http.NewRequestWithContext(ctx, "POST", o.URL, strings.NewReader(url.Values{"data": {query}}.Encode()))
+	if err != nil {
+		return WayInfo{}, false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return WayInfo{}, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return WayInfo{}, false, fmt.Errorf("overpass query failed: %s", resp.Status)
+	}
+	var r struct {
+		Elements []struct {
+			Tags map[string]string `json:"tags"`
+		} `json:"elements"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return WayInfo{}, false, fmt.Errorf("couldn't parse overpass response: %v", err)
+	}
+
+	var w *WayInfo
+	if len(r.Elements) > 0 {
+		tags := r.Elements[0].Tags
+		w = &WayInfo{Surface: tags["surface"], Class: tags["highway"]}
+	}
+	o.mu.Lock()
+	if o.cache == nil {
+		o.cache = make(map[[2]int64]*WayInfo)
+	}
+	o.cache[key] = w
+	o.mu.Unlock()
+	if w == nil {
+		return WayInfo{}, false, nil
+	}
+	return *w, true, nil
+}
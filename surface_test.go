@@ -0,0 +1,81 @@
+package tcx
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSurfaceBreakdown(t *testing.T) {
+	// 900 m north from lat 47: a paved road for the first 500 m, then a
+	// dirt path.
+	a := testActivity(SportRunning, repeat(3.0, 301), repeat(140, 301))
+	split := 47 + 500/111194.93
+	net := &RoadNetwork{Ways: []Way{
+		{Name: "Main Street", Nodes: [][2]float64{{46.99, -1.5}, {split, -1.5}},
+			Tags: map[string]string{"highway": "residential"}},
+		{Name: "Forest path", Nodes: [][2]float64{{split, -1.5}, {47.02, -1.5}},
+			Tags: map[string]string{"highway": "path", "surface": "dirt"}},
+	}}
+	b, err := a.SurfaceBreakdown(context.Background(), net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(b.Surface[SurfacePaved]-500) > 50 || math.Abs(b.Surface[SurfaceTrail]-400) > 50 {
+		t.Errorf("surfaces = %v", b.Surface)
+	}
+	if math.Abs(b.Class["residential"]+b.Class["path"]-900) > 1 {
+		t.Errorf("classes = %v", b.Class)
+	}
+
+	s, err := a.SurfaceSummary(context.Background(), net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Surfaces == nil || s.Surfaces.Surface[SurfaceTrail] != b.Surface[SurfaceTrail] || s.Distance != a.Summary().Distance {
+		t.Errorf("surface summary = %+v", s)
+	}
+}
+
+func TestSurfaceCategory(t *testing.T) {
+	for _, tc := range []struct {
+		w    WayInfo
+		want string
+	}{
+		{WayInfo{Surface: "asphalt", Class: "path"}, SurfacePaved},
+		{WayInfo{Surface: "fine_gravel"}, SurfaceGravel},
+		{WayInfo{Class: "track"}, SurfaceGravel},
+		{WayInfo{Class: "footway"}, SurfaceTrail},
+		{WayInfo{Class: "steps"}, SurfaceUnknown},
+	} {
+		if got := SurfaceCategory(tc.w); got != tc.want {
+			t.Errorf("SurfaceCategory(%+v) = %s, want %s", tc.w, got, tc.want)
+		}
+	}
+}
+
+func TestOverpassProvider(t *testing.T) {
+	var queries int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries++
+		if !strings.Contains(r.FormValue("data"), "way(around:15,") {
+			t.Errorf("unexpected query %s", r.FormValue("data"))
+		}
+		w.Write([]byte(`{"elements": [{"type": "way", "tags": {"highway": "track", "surface": "gravel"}}]}`))
+	}))
+	defer srv.Close()
+
+	o := NewOverpassProvider(srv.URL)
+	for i := 0; i < 3; i++ {
+		w, ok, err := o.WayAt(context.Background(), 47.00001, -1.5)
+		if err != nil || !ok || w.Surface != "gravel" || w.Class != "track" {
+			t.Fatalf("got %+v, %v, %v", w, ok, err)
+		}
+	}
+	if queries != 1 {
+		t.Errorf("made %d queries, want 1", queries)
+	}
+}
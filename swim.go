@@ -0,0 +1,71 @@
+package tcx
+
+import "time"
+
+// SwimUnit is the length unit of swim paces, given in meters.
+type SwimUnit float64
+
+// Swim units: paces are per 100 m or per 100 yd.
+const (
+	SwimMeters SwimUnit = 1
+	SwimYards  SwimUnit = 0.9144
+)
+
+// SwimPace is the time taken to swim 100 units.
+type SwimPace time.Duration
+
+// String formats the pace as minutes and seconds, such as 1:45.
+func (p SwimPace) String() string {
+	return formatPace(time.Duration(p))
+}
+
+// swimPace returns the pace of swimming meters in d.
+func swimPace(d time.Duration, meters float64, unit SwimUnit) SwimPace {
+	if meters <= 0 {
+		return 0
+	}
+	return SwimPace(float64(d) * 100 * float64(unit) / meters)
+}
+
+// SwimInterval is a swum lap.
+type SwimInterval struct {
+	Lap      int
+	Start    time.Time
+	Duration time.Duration
+	Distance float64 // meters
+	Pace     SwimPace
+}
+
+// SwimSummary holds the paces of a swim, rest excluded.
+type SwimSummary struct {
+	Intervals []SwimInterval
+	Distance  float64 // meters
+	SwimTime  time.Duration
+	RestTime  time.Duration
+	Pace      SwimPace
+}
+
+// SwimPaces returns the pace of every swum lap of a pool or open water
+// activity and the overall pace, in unit. Laps marked Resting or covering no
+// distance are rest and excluded from the paces.
+func (a *Activity) SwimPaces(unit SwimUnit) SwimSummary {
+	var s SwimSummary
+	for i, l := range a.Laps {
+		d := time.Duration(l.TotalTimeInSeconds * float64(time.Second))
+		if l.Intensity == "Resting" || l.DistanceInMeters <= 0 {
+			s.RestTime += d
+			continue
+		}
+		s.Intervals = append(s.Intervals, SwimInterval{
+			Lap:      i,
+			Start:    l.StartTime,
+			Duration: d,
+			Distance: l.DistanceInMeters,
+			Pace:     swimPace(d, l.DistanceInMeters, unit),
+		})
+		s.Distance += l.DistanceInMeters
+		s.SwimTime += d
+	}
+	s.Pace = swimPace(s.SwimTime, s.Distance, unit)
+	return s
+}
@@ -0,0 +1,30 @@
+package tcx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSwimPaces(t *testing.T) {
+	start := time.Date(2015, 4, 12, 7, 0, 0, 0, time.UTC)
+	a := &Activity{Sport: SportOther, Laps: []Lap{
+		{StartTime: start, TotalTimeInSeconds: 100, DistanceInMeters: 100, Intensity: "Active"},
+		{StartTime: start.Add(100 * time.Second), TotalTimeInSeconds: 30, Intensity: "Resting"},
+		{StartTime: start.Add(130 * time.Second), TotalTimeInSeconds: 220, DistanceInMeters: 200, Intensity: "Active"},
+		{StartTime: start.Add(350 * time.Second), TotalTimeInSeconds: 15, DistanceInMeters: 0, Intensity: "Active"},
+	}}
+
+	s := a.SwimPaces(SwimMeters)
+	if len(s.Intervals) != 2 || s.Distance != 300 || s.RestTime != 45*time.Second || s.SwimTime != 320*time.Second {
+		t.Fatalf("summary = %+v", s)
+	}
+	if got := s.Intervals[1].Pace.String(); got != "1:50" {
+		t.Errorf("second interval pace = %s, want 1:50", got)
+	}
+	if got := s.Pace.String(); got != "1:47" {
+		t.Errorf("overall pace = %s, want 1:47", got)
+	}
+	if got := a.SwimPaces(SwimYards).Pace.String(); got != "1:38" {
+		t.Errorf("overall pace per 100 yd = %s, want 1:38", got)
+	}
+}
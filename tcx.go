@@ -1,9 +1,11 @@
 package tcx
 
 import (
+	"context"
 	"encoding/xml"
-	"fmt"
 	"io"
+	"io/fs"
+	"iter"
 	"math"
 	"os"
 	"time"
@@ -11,61 +13,95 @@ import (
 
 // Tcx represents the root of a TCX file
 type Tcx struct {
-	XMLName      xml.Name   `xml:"TrainingCenterDatabase"`
-	XMLNs        string     `xml:"xmlns,attr"`
-	XMLNsXsi     string     `xml:"xsi,attr,omitempty"`
-	XMLNsXsd     string     `xml:"xsd,attr,omitempty"`
-	XMLSchemaLoc string     `xml:"schemaLocation,attr,omitempty"`
-	Activities   []Activity `xml:"Activities>Activity"`
+	XMLName      xml.Name   `xml:"TrainingCenterDatabase" json:"-"`
+	XMLNs        string     `xml:"xmlns,attr" json:"-"`
+	XMLNsXsi     string     `xml:"xsi,attr,omitempty" json:"-"`
+	XMLNsXsd     string     `xml:"xsd,attr,omitempty" json:"-"`
+	XMLSchemaLoc string     `xml:"schemaLocation,attr,omitempty" json:"-"`
+	Activities   []Activity `xml:"Activities>Activity" json:"activities,omitempty"`
+
+	MultiSportSessions []MultiSportSession `xml:"Activities>MultiSportSession" json:"multiSportSessions,omitempty"`
+	Workouts           []Workout           `xml:"Workouts>Workout" json:"workouts,omitempty"`
+	Courses            []Course            `xml:"Courses>Course" json:"courses,omitempty"`
+	Author             *Author             `xml:"Author" json:"author,omitempty"`
+
+	// Warnings lists the problems recovered from by a lenient parse.
+	Warnings []Warning `xml:"-" json:"-"`
 }
 
 type Activity struct {
-	Sport   string    `xml:"Sport,attr"`
-	ID      time.Time `xml:"Id"`
-	Creator Creator   `xml:"Creator"`
-	Laps    []Lap     `xml:"Lap"`
+	Sport   string    `xml:"Sport,attr" json:"sport"`
+	ID      time.Time `xml:"Id" json:"id"`
+	Creator Creator   `xml:"Creator" json:"creator"`
+	Laps    []Lap     `xml:"Lap" json:"laps,omitempty"`
+
+	Extensions *ActivityExtensions `xml:"Extensions" json:"extensions,omitempty"`
+
+	// stats caches the summary returned by Stats, computed when statsGen
+	// had the value of statsGen.
+	stats    *Summary
+	statsGen uint64
 }
 
 type Creator struct {
-	Name      string `xml:"Name"`
-	UnitID    int    `xml:"UnitId"`
-	ProductID int    `xml:"ProductID"`
+	Name         string `xml:"Name" json:"name,omitempty"`
+	UnitID       int    `xml:"UnitId" json:"unitId,omitempty"`
+	ProductID    int    `xml:"ProductID" json:"productId,omitempty"`
+	VersionMajor int    `xml:"Version>VersionMajor" json:"versionMajor,omitempty"`
+	VersionMinor int    `xml:"Version>VersionMinor" json:"versionMinor,omitempty"`
+	BuildMajor   int    `xml:"Version>BuildMajor" json:"buildMajor,omitempty"`
+	BuildMinor   int    `xml:"Version>BuildMinor" json:"buildMinor,omitempty"`
 }
 
 type Lap struct {
-	StartTime                  time.Time    `xml:"StartTime,attr"`
-	TotalTimeInSeconds         float64      `xml:"TotalTimeSeconds"`
-	DistanceInMeters           float64      `xml:"DistanceMeters"`
-	MaximumSpeedInMetersPerSec float64      `xml:"MaximumSpeed"`
-	Calories                   float64      `xml:"Calories"`
-	Intensity                  string       `xml:"Intensity"`
-	TriggerMethod              string       `xml:"TriggerMethod"`
-	Track                      []Trackpoint `xml:"Track>Trackpoint"`
+	StartTime                  time.Time      `xml:"StartTime,attr" json:"startTime"`
+	TotalTimeInSeconds         float64        `xml:"TotalTimeSeconds" json:"totalTimeSeconds"`
+	DistanceInMeters           float64        `xml:"DistanceMeters" json:"distanceMeters"`
+	MaximumSpeedInMetersPerSec float64        `xml:"MaximumSpeed" json:"maximumSpeed,omitempty"`
+	Calories                   float64        `xml:"Calories" json:"calories,omitempty"`
+	AverageHeartRateInBpm      int            `xml:"AverageHeartRateBpm>Value" json:"averageHeartRateBpm,omitempty"`
+	MaximumHeartRateInBpm      int            `xml:"MaximumHeartRateBpm>Value" json:"maximumHeartRateBpm,omitempty"`
+	Intensity                  string         `xml:"Intensity" json:"intensity,omitempty"`
+	Cadence                    int            `xml:"Cadence" json:"cadence,omitempty"`
+	TriggerMethod              string         `xml:"TriggerMethod" json:"triggerMethod,omitempty"`
+	Track                      []Trackpoint   `xml:"Track>Trackpoint" json:"track,omitempty"`
+	Notes                      string         `xml:"Notes" json:"notes,omitempty"`
+	Extensions                 *LapExtensions `xml:"Extensions" json:"extensions,omitempty"`
+
+	// calorieModel names the model FillCalories estimated Calories with;
+	// empty when the device recorded them.
+	calorieModel string
 }
 
 type Trackpoint struct {
 	Time                time.Time `xml:"Time"`
-	LatitudeInDegrees   float64   `xml:"LatitudeDegrees"`
-	LongitudeInDegrees  float64   `xml:"LongitudeDegrees"`
+	LatitudeInDegrees   float64   `xml:"Position>LatitudeDegrees"`
+	LongitudeInDegrees  float64   `xml:"Position>LongitudeDegrees"`
 	AltitudeInMeters    float64   `xml:"AltitudeMeters"`
+	DistanceInMeters    float64   `xml:"DistanceMeters"`
 	HeartRateInBpm      int       `xml:"HeartRateBpm>Value"`
 	Cadence             int       `xml:"Cadence"`
 	SpeedInMetersPerSec float64   `xml:"Extensions>TPX>Speed"`
-}
+	RunCadence          int       `xml:"Extensions>TPX>RunCadence"`
+	PowerInWatts        int       `xml:"Extensions>TPX>Watts"`
+	// RRIntervals holds the R-R intervals ending at the trackpoint, in
+	// milliseconds, kept in an HRV extension.
+	RRIntervals []int `xml:"-"`
 
-type Pace struct {
-	float64
+	// OtherExtensions holds the extension elements besides TPX.
+	OtherExtensions []ExtensionElement `xml:"-"`
+
+	// zeros flags the values recorded as zero, as opposed to missing.
+	zeros presence
 }
 
-// Parse parses a TCX reader and return a Tcx object.
-func Parse(r io.Reader) (*Tcx, error) {
-	g := NewTcx()
-	d := xml.NewDecoder(r)
-	err := d.Decode(g)
-	if err != nil {
-		return nil, fmt.Errorf("couldn't parse tcx data: %v", err)
-	}
-	return g, nil
+// TrainingCenterNS is the namespace of TCX version 2 documents.
+const TrainingCenterNS = "http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+
+// Parse parses a TCX reader and return a Tcx object. Gzip compressed data
+// is detected and decompressed.
+func Parse(r io.Reader, opts ...Option) (*Tcx, error) {
+	return ParseContext(context.Background(), r, opts...)
 }
 
 // ParseFile reads a TCX file and parses it.
@@ -78,12 +114,49 @@ func ParseFile(filepath string) (*Tcx, error) {
 	return Parse(f)
 }
 
+// ParseFS reads the TCX file name of fsys, such as an embed.FS of test
+// fixtures or a zip archive opened with zip.NewReader, and parses it.
+func ParseFS(fsys fs.FS, name string) (*Tcx, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
 // NewTcx creates and returns a new Gpx objects.
 func NewTcx() *Tcx {
 	tcx := new(Tcx)
 	return tcx
 }
 
+// Trackpoints iterates over every trackpoint of the activity, in lap order,
+// with its index across laps. The trackpoints may be changed through the
+// pointers.
+func (a *Activity) Trackpoints() iter.Seq2[int, *Trackpoint] {
+	return func(yield func(int, *Trackpoint) bool) {
+		n := 0
+		for i := range a.Laps {
+			for j := range a.Laps[i].Track {
+				if !yield(n, &a.Laps[i].Track[j]) {
+					return
+				}
+				n++
+			}
+		}
+	}
+}
+
+// trackpoints returns pointers to every trackpoint of the activity, in lap order.
+func (a *Activity) trackpoints() []*Trackpoint {
+	var pts []*Trackpoint
+	for _, p := range a.Trackpoints() {
+		pts = append(pts, p)
+	}
+	return pts
+}
+
 func (a *Activity) TotalDuration() time.Duration {
 	var duration time.Duration = 0
 	for _, l := range a.Laps {
@@ -100,37 +173,93 @@ func (a *Activity) TotalDistance() float64 {
 	return d
 }
 
-func (a *Activity) AverageHeartbeat() float64 {
+// AverageHeartbeat returns the time-weighted average heart rate of the
+// activity, in bpm, and the number of samples it was computed from. Seconds
+// without a heart rate reading are left out rather than counted as zero; the
+// average is zero when there are none.
+func (a *Activity) AverageHeartbeat() (float64, int) {
 	var totalhr int = 0
 	var nbhr int = 0
-	for _, l := range a.Laps {
-		for _, p := range l.Track {
-			totalhr += p.HeartRateInBpm
-			nbhr += 1
+	for _, p := range a.perSecond() {
+		if !p.HasHeartRate() {
+			continue
 		}
+		totalhr += p.HeartRateInBpm
+		nbhr += 1
+	}
+	if nbhr == 0 {
+		return 0, 0
 	}
-	return float64(totalhr) / float64(nbhr)
+	return float64(totalhr) / float64(nbhr), nbhr
 }
 
-func (p *Pace) String() string {
-	intpart, fracpart := math.Modf(p.float64)
-	return fmt.Sprintf("%.f:%.f", intpart, fracpart*60)
+// MaxHeartRate returns the highest heart rate of the activity, in bpm, from
+// the lap maximums and the trackpoints. It is zero without heart rate data.
+func (a *Activity) MaxHeartRate() int {
+	var hr int
+	for _, l := range a.Laps {
+		hr = max(hr, l.MaximumHeartRateInBpm)
+		for _, p := range l.Track {
+			hr = max(hr, p.HeartRateInBpm)
+		}
+	}
+	return hr
 }
 
-func GetPaceFromSpeedInMs(speed float64) *Pace {
-	var p *Pace = new(Pace)
-	p.float64 = 50 / (speed * 3)
-	return p
+// AverageCadence returns the average cadence of the activity, from the
+// trackpoints or, when they carry none, the lap averages weighted by time.
+// It is zero without cadence data.
+func (a *Activity) AverageCadence() float64 {
+	var total, n float64
+	for _, p := range a.perSecond() {
+		if p.HasCadence() {
+			total += float64(p.Cadence)
+			n++
+		}
+	}
+	if n > 0 {
+		return total / n
+	}
+	for _, l := range a.Laps {
+		if l.Cadence > 0 {
+			total += float64(l.Cadence) * l.TotalTimeInSeconds
+			n += l.TotalTimeInSeconds
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return total / n
 }
 
-func (a *Activity) AveragePace() *Pace {
+// AveragePace returns the pace at the time-weighted average speed of the
+// activity and the number of samples it was computed from. Samples without a
+// speed reading take the speed measured to the next one, as in intervals;
+// seconds without a speed are left out and the pace is zero when there are
+// none.
+func (a *Activity) AveragePace() (Pace, int) {
+	pts := a.trackpoints()
+	ivs := a.intervals()
+	smart := a.Recording().Smart
 	var totals float64 = 0
 	var nbs int = 0
-	for _, l := range a.Laps {
-		for _, p := range l.Track {
-			totals += p.SpeedInMetersPerSec
-			nbs += 1
+	for i, p := range pts {
+		// Count the seconds each sample holds, as perSecond does.
+		speed, n := p.SpeedInMetersPerSec, 1
+		if i < len(ivs) {
+			speed = ivs[i].speed()
+			if smart && ivs[i].dt <= maxHoldGap.Seconds() {
+				n = max(int(math.Ceil(ivs[i].dt)), 1)
+			}
 		}
+		if speed <= 0 {
+			continue
+		}
+		totals += speed * float64(n)
+		nbs += n
+	}
+	if nbs == 0 {
+		return 0, 0
 	}
-	return GetPaceFromSpeedInMs(totals / float64(nbs))
+	return PaceFromSpeed(totals / float64(nbs)), nbs
 }
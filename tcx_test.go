@@ -1,7 +1,15 @@
 package tcx
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"time"
 
 	"testing"
 )
@@ -15,8 +23,170 @@ func TestParse(t *testing.T) {
 	if err != nil {
 		t.Error("Error parsing TCX file: ", err)
 	}
+	// Positions are nested in a Position element.
+	if p := tcx.Activities[0].Laps[2].Track[0]; p.LatitudeInDegrees == 0 || p.LongitudeInDegrees == 0 {
+		t.Errorf("first trackpoint read without its position: %+v", p)
+	}
 
 	fmt.Println(tcx.Activities[0].TotalDuration())
 	fmt.Println(tcx.Activities[0].AverageHeartbeat())
 	fmt.Println(tcx.Activities[0].AveragePace())
 }
+
+func TestAveragesSkipMissingSamples(t *testing.T) {
+	// The heart rate strap drops out for the second half of the run.
+	hrs := append(repeat(150, 60), repeat(0, 61)...)
+	speeds := append(repeat(4.0, 100), repeat(0.0, 21)...)
+	a := testActivity(SportRunning, speeds, hrs)
+	if hr, n := a.AverageHeartbeat(); hr != 150 || n != 60 {
+		t.Errorf("AverageHeartbeat() = %v over %d samples, want 150 over 60", hr, n)
+	}
+	if p, n := a.AveragePace(); p.String() != "4:10" || n != 100 {
+		t.Errorf("AveragePace() = %v over %d samples, want 4:10 over 100", p, n)
+	}
+
+	none := testActivity(SportRunning, repeat(0.0, 10), nil)
+	if hr, n := none.AverageHeartbeat(); hr != 0 || n != 0 {
+		t.Errorf("AverageHeartbeat() without heart rate = %v, %d", hr, n)
+	}
+	if p, n := none.AveragePace(); p != 0 || n != 0 {
+		t.Errorf("AveragePace() without speed = %v, %d", p, n)
+	}
+}
+
+func TestLapSummary(t *testing.T) {
+	db, err := Parse(strings.NewReader(`<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+  <Activities>
+    <Activity Sport="Biking">
+      <Id>2015-04-12T07:00:00Z</Id>
+      <Lap StartTime="2015-04-12T07:00:00Z">
+        <TotalTimeSeconds>600</TotalTimeSeconds>
+        <DistanceMeters>5000</DistanceMeters>
+        <Calories>120</Calories>
+        <AverageHeartRateBpm><Value>142</Value></AverageHeartRateBpm>
+        <MaximumHeartRateBpm><Value>171</Value></MaximumHeartRateBpm>
+        <Intensity>Active</Intensity>
+        <Cadence>85</Cadence>
+        <TriggerMethod>Manual</TriggerMethod>
+        <Notes>Warm up</Notes>
+      </Lap>
+      <Lap StartTime="2015-04-12T07:10:00Z">
+        <TotalTimeSeconds>1200</TotalTimeSeconds>
+        <DistanceMeters>12000</DistanceMeters>
+        <Calories>300</Calories>
+        <AverageHeartRateBpm><Value>158</Value></AverageHeartRateBpm>
+        <MaximumHeartRateBpm><Value>176</Value></MaximumHeartRateBpm>
+        <Intensity>Active</Intensity>
+        <Cadence>91</Cadence>
+        <TriggerMethod>Distance</TriggerMethod>
+      </Lap>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &db.Activities[0]
+	l := a.Laps[0]
+	if l.AverageHeartRateInBpm != 142 || l.MaximumHeartRateInBpm != 171 || l.Cadence != 85 || l.Notes != "Warm up" {
+		t.Errorf("lap = %+v", l)
+	}
+	if hr := a.MaxHeartRate(); hr != 176 {
+		t.Errorf("MaxHeartRate() = %d, want 176", hr)
+	}
+	if c := a.AverageCadence(); c != 89 {
+		t.Errorf("AverageCadence() = %v, want 89", c)
+	}
+
+	var b bytes.Buffer
+	if err := db.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	back, err := Parse(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.Activities[0].Laps, a.Laps) {
+		t.Errorf("laps changed through Write:\n%s", b.String())
+	}
+
+	run := testActivity(SportRunning, repeat(3.0, 10), []int{120, 150, 131})
+	if hr := run.MaxHeartRate(); hr != 150 {
+		t.Errorf("MaxHeartRate() = %d, want 150", hr)
+	}
+}
+
+func TestTrackpoints(t *testing.T) {
+	db, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &db.Activities[0]
+	var n int
+	for i, p := range a.Trackpoints() {
+		if i != n {
+			t.Fatalf("index %d, want %d", i, n)
+		}
+		p.PowerInWatts = i
+		n++
+	}
+	if n != 1937 || a.Laps[len(a.Laps)-1].Track[0].PowerInWatts == 0 {
+		t.Errorf("iterated over %d trackpoints", n)
+	}
+	for i := range a.Trackpoints() {
+		if i == 10 {
+			break
+		}
+	}
+}
+
+// testActivity builds a single-lap activity with one trackpoint per second.
+// Each trackpoint takes its speed and heart rate from the given series, and
+// positions advance northwards consistently with the speed.
+func testActivity(sport string, speeds []float64, hrs []int) *Activity {
+	start := time.Date(2015, 4, 12, 7, 0, 0, 0, time.UTC)
+	lat, lon := 47.0, -1.5
+	lap := Lap{StartTime: start, Intensity: "Active", TriggerMethod: "Manual"}
+	for i, s := range speeds {
+		if i > 0 {
+			lat += s / 111194.93
+			lap.DistanceInMeters += s
+		}
+		p := Trackpoint{
+			Time:                start.Add(time.Duration(i) * time.Second),
+			LatitudeInDegrees:   lat,
+			LongitudeInDegrees:  lon,
+			AltitudeInMeters:    10,
+			SpeedInMetersPerSec: s,
+		}
+		if i < len(hrs) {
+			p.HeartRateInBpm = hrs[i]
+		}
+		lap.MaximumSpeedInMetersPerSec = math.Max(lap.MaximumSpeedInMetersPerSec, s)
+		lap.Track = append(lap.Track, p)
+	}
+	lap.TotalTimeInSeconds = float64(len(speeds) - 1)
+	return &Activity{Sport: sport, ID: start, Laps: []Lap{lap}}
+}
+
+// repeat returns a series holding n copies of v.
+func repeat[T any](v T, n int) []T {
+	s := make([]T, n)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
+
+func TestParseFS(t *testing.T) {
+	db, err := ParseFS(os.DirFS("testdata"), "test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(db.Activities[0].trackpoints()); n != 1937 {
+		t.Errorf("parsed %d trackpoints", n)
+	}
+	if _, err := ParseFS(os.DirFS("testdata"), "missing.tcx"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ParseFS() of a missing file = %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+// Package tcxtest helps downstream tests compare TCX documents. Documents
+// are compared semantically: namespace prefixes, float formatting, time zone
+// notation and the order of differently named sibling elements do not
+// matter, while repeated elements such as laps and trackpoints are compared
+// in order.
+package tcxtest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+// Tolerances bounds the differences accepted between values.
+type Tolerances struct {
+	// Float is the absolute tolerance of numbers.
+	Float float64
+	// Degrees is the absolute tolerance of latitudes and longitudes.
+	Degrees float64
+	// Time is the tolerance of timestamps.
+	Time time.Duration
+}
+
+// DefaultTolerances accepts the rounding of common TCX writers.
+var DefaultTolerances = Tolerances{Float: 1e-3, Degrees: 1e-7, Time: time.Millisecond}
+
+// maxDiffs is the number of differences reported by AssertEquivalent.
+const maxDiffs = 20
+
+// AssertEquivalent fails t when got and want are not equivalent TCX
+// documents within tol. Documents may be given as a *tcx.Tcx, a []byte, a
+// string or an io.Reader.
+func AssertEquivalent(t testing.TB, got, want any, tol Tolerances) {
+	t.Helper()
+	diffs, err := Diff(got, want, tol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, d := range diffs {
+		if i == maxDiffs {
+			t.Errorf("... and %d more differences", len(diffs)-maxDiffs)
+			break
+		}
+		t.Error(d)
+	}
+}
+
+// Diff returns the differences between two TCX documents within tol, one
+// line per difference, each starting with the path of the element.
+func Diff(got, want any, tol Tolerances) ([]string, error) {
+	g, err := parse(got)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read got: %v", err)
+	}
+	w, err := parse(want)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read want: %v", err)
+	}
+	var diffs []string
+	compare("/"+g.name.Local, g, w, tol, &diffs)
+	return diffs, nil
+}
+
+// node is an XML element.
+type node struct {
+	name     xml.Name
+	attrs    map[xml.Name]string
+	text     string
+	children []*node
+}
+
+func parse(doc any) (*node, error) {
+	var r io.Reader
+	switch d := doc.(type) {
+	case *tcx.Tcx:
+		var b bytes.Buffer
+		if err := d.Write(&b); err != nil {
+			return nil, err
+		}
+		r = &b
+	case []byte:
+		r = bytes.NewReader(d)
+	case string:
+		r = strings.NewReader(d)
+	case io.Reader:
+		r = d
+	default:
+		return nil, fmt.Errorf("unsupported document type %T", doc)
+	}
+
+	dec := xml.NewDecoder(r)
+	var stack []*node
+	var root *node
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: tok.Name, attrs: make(map[xml.Name]string)}
+			for _, a := range tok.Attr {
+				if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+					continue
+				}
+				n.attrs[a.Name] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(tok)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("empty document")
+	}
+	return root, nil
+}
+
+// equalValues compares two texts as times, numbers or strings.
+func equalValues(name string, a, b string, tol Tolerances) bool {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if a == b {
+		return true
+	}
+	if ta, err := time.Parse(time.RFC3339Nano, a); err == nil {
+		tb, err := time.Parse(time.RFC3339Nano, b)
+		if err != nil {
+			return false
+		}
+		d := ta.Sub(tb)
+		return d <= tol.Time && d >= -tol.Time
+	}
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA != nil || errB != nil {
+		return false
+	}
+	limit := tol.Float
+	if strings.HasSuffix(name, "Degrees") {
+		limit = tol.Degrees
+	}
+	return math.Abs(fa-fb) <= limit
+}
+
+func compare(path string, g, w *node, tol Tolerances, diffs *[]string) {
+	report := func(format string, args ...any) {
+		*diffs = append(*diffs, path+": "+fmt.Sprintf(format, args...))
+	}
+	if g.name.Space != w.name.Space {
+		report("namespace %q, want %q", g.name.Space, w.name.Space)
+	}
+	for name, wv := range w.attrs {
+		gv, ok := g.attrs[name]
+		if !ok {
+			report("missing attribute %s", name.Local)
+		} else if !equalValues(name.Local, gv, wv, tol) {
+			report("attribute %s = %q, want %q", name.Local, gv, wv)
+		}
+	}
+	for name := range g.attrs {
+		if _, ok := w.attrs[name]; !ok {
+			report("unexpected attribute %s", name.Local)
+		}
+	}
+	if len(g.children) == 0 && len(w.children) == 0 && !equalValues(g.name.Local, g.text, w.text, tol) {
+		report("%q, want %q", strings.TrimSpace(g.text), strings.TrimSpace(w.text))
+	}
+
+	gc, wc := group(g.children), group(w.children)
+	var names []xml.Name
+	for name := range wc {
+		names = append(names, name)
+	}
+	for name := range gc {
+		if _, ok := wc[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i].Local != names[j].Local {
+			return names[i].Local < names[j].Local
+		}
+		return names[i].Space < names[j].Space
+	})
+	for _, name := range names {
+		gs, ws := gc[name], wc[name]
+		if len(gs) != len(ws) {
+			report("%d %s elements, want %d", len(gs), name.Local, len(ws))
+		}
+		for i := 0; i < len(gs) && i < len(ws); i++ {
+			p := path + "/" + name.Local
+			if len(gs) > 1 || len(ws) > 1 {
+				p += "[" + strconv.Itoa(i) + "]"
+			}
+			compare(p, gs[i], ws[i], tol, diffs)
+		}
+	}
+}
+
+// group returns the children by name, in document order.
+func group(children []*node) map[xml.Name][]*node {
+	m := make(map[xml.Name][]*node)
+	for _, c := range children {
+		m[c.name] = append(m[c.name], c)
+	}
+	return m
+}
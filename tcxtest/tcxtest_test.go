@@ -0,0 +1,100 @@
+package tcxtest
+
+import (
+	"strings"
+	"testing"
+
+	tcx "github.com/rdifrango/go-tcx"
+)
+
+const want = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+    xmlns:ns3="http://www.garmin.com/xmlschemas/ActivityExtension/v2">
+  <Activities>
+    <Activity Sport="Running">
+      <Id>2015-04-12T07:28:19Z</Id>
+      <Lap StartTime="2015-04-12T07:28:19Z">
+        <TotalTimeSeconds>60.0</TotalTimeSeconds>
+        <DistanceMeters>200.5</DistanceMeters>
+        <Track>
+          <Trackpoint>
+            <Time>2015-04-12T07:28:19Z</Time>
+            <Position><LatitudeDegrees>47.123456789</LatitudeDegrees><LongitudeDegrees>-1.5</LongitudeDegrees></Position>
+            <Extensions><ns3:TPX><ns3:Speed>3.25</ns3:Speed></ns3:TPX></Extensions>
+          </Trackpoint>
+          <Trackpoint>
+            <Time>2015-04-12T07:29:19Z</Time>
+          </Trackpoint>
+        </Track>
+      </Lap>
+      <Creator><Name>Forerunner</Name></Creator>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestAssertEquivalent(t *testing.T) {
+	// Other prefixes, float formatting, time zone notation and sibling
+	// order.
+	got := `<tcd:TrainingCenterDatabase xmlns:tcd="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+    xmlns:ax="http://www.garmin.com/xmlschemas/ActivityExtension/v2">
+  <tcd:Activities>
+    <tcd:Activity Sport="Running">
+      <tcd:Id>2015-04-12T09:28:19.000+02:00</tcd:Id>
+      <tcd:Creator><tcd:Name>Forerunner</tcd:Name></tcd:Creator>
+      <tcd:Lap StartTime="2015-04-12T07:28:19Z">
+        <tcd:DistanceMeters>200.50000</tcd:DistanceMeters>
+        <tcd:TotalTimeSeconds>60</tcd:TotalTimeSeconds>
+        <tcd:Track>
+          <tcd:Trackpoint>
+            <tcd:Position><tcd:LongitudeDegrees>-1.50</tcd:LongitudeDegrees><tcd:LatitudeDegrees>47.12345679</tcd:LatitudeDegrees></tcd:Position>
+            <tcd:Time>2015-04-12T07:28:19Z</tcd:Time>
+            <tcd:Extensions><ax:TPX><ax:Speed>3.250</ax:Speed></ax:TPX></tcd:Extensions>
+          </tcd:Trackpoint>
+          <tcd:Trackpoint>
+            <tcd:Time>2015-04-12T07:29:19Z</tcd:Time>
+          </tcd:Trackpoint>
+        </tcd:Track>
+      </tcd:Lap>
+    </tcd:Activity>
+  </tcd:Activities>
+</tcd:TrainingCenterDatabase>`
+	AssertEquivalent(t, got, []byte(want), DefaultTolerances)
+
+	db, err := tcx.Parse(strings.NewReader(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	round, err := tcx.Parse(strings.NewReader(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertEquivalent(t, round, db, DefaultTolerances)
+}
+
+func TestDiff(t *testing.T) {
+	got := strings.NewReplacer(
+		"<DistanceMeters>200.5<", "<DistanceMeters>201<",
+		`Sport="Running"`, `Sport="Biking"`,
+		"ns3:Speed", "Speed",
+	).Replace(want)
+	got = strings.Replace(got, "<Trackpoint>\n            <Time>2015-04-12T07:29:19Z</Time>\n          </Trackpoint>", "", 1)
+
+	diffs, err := Diff(got, want, DefaultTolerances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`/TrainingCenterDatabase/Activities/Activity: attribute Sport = "Biking", want "Running"`,
+		`/TrainingCenterDatabase/Activities/Activity/Lap/DistanceMeters: "201", want "200.5"`,
+		`/TrainingCenterDatabase/Activities/Activity/Lap/Track: 1 Trackpoint elements, want 2`,
+		`/TrainingCenterDatabase/Activities/Activity/Lap/Track/Trackpoint[0]/Extensions/TPX: 0 Speed elements, want 1`,
+		`/TrainingCenterDatabase/Activities/Activity/Lap/Track/Trackpoint[0]/Extensions/TPX: 1 Speed elements, want 0`,
+	}
+	if strings.Join(diffs, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Diff() =\n%s\nwant\n%s", strings.Join(diffs, "\n"), strings.Join(expected, "\n"))
+	}
+
+	if _, err := Diff(42, want, DefaultTolerances); err == nil {
+		t.Error("Diff() accepted an int")
+	}
+}
@@ -0,0 +1,82 @@
+package tcx
+
+import "time"
+
+// Threshold detection follows Joe Friel's field test: the hardest sustained
+// 30 minutes of an activity is treated as a threshold effort, its mean speed
+// as the threshold pace and the mean heart rate of its last 20 minutes as the
+// lactate threshold heart rate (LTHR). Functional threshold power (FTP)
+// follows Hunter Allen and Andrew Coggan: 95% of the best 20 minute power.
+
+const (
+	thresholdWindow   = 30 * 60.0 // seconds
+	thresholdHRWindow = 20 * 60.0 // seconds
+
+	ftpWindow = 20 * time.Minute
+	ftpFactor = 0.95
+)
+
+// Thresholds holds an athlete's lactate threshold markers. Zero values mean
+// unknown.
+type Thresholds struct {
+	HeartRate int     // lactate threshold heart rate, bpm
+	Speed     float64 // threshold speed, m/s
+	Power     float64 // functional threshold power, W
+}
+
+// EstimateThresholds estimates lactate threshold heart rate, threshold speed
+// and functional threshold power from the hardest efforts of the activity.
+// LTHR is taken from the hardest 30 minutes by speed, or by power when the
+// activity carries no speed data. ok is false when neither a 30 minute speed
+// effort nor a 20 minute power effort could be found.
+func (a *Activity) EstimateThresholds() (th Thresholds, ok bool) {
+	pts := a.trackpoints()
+	if best, found := a.PowerCurve([]time.Duration{ftpWindow})[ftpWindow]; found && best > 0 {
+		th.Power = ftpFactor * best
+	}
+	speed := integrate(pts, func(p *Trackpoint) float64 { return p.SpeedInMetersPerSec })
+	end, best, found := speed.bestWindow(thresholdWindow)
+	if found && best > 0 {
+		th.Speed = best
+	} else {
+		power := integrate(pts, func(p *Trackpoint) float64 { return float64(p.PowerInWatts) })
+		if end, best, found = power.bestWindow(thresholdWindow); !found || best <= 0 {
+			return th, th.Power > 0
+		}
+	}
+
+	// Heart rate dropouts are left out of the mean rather than counted as 0.
+	hr := integrate(pts, func(p *Trackpoint) float64 { return float64(p.HeartRateInBpm) })
+	hasHR := integrate(pts, func(p *Trackpoint) float64 {
+		if p.HasHeartRate() {
+			return 1
+		}
+		return 0
+	})
+	start := end - thresholdHRWindow
+	if secs := hasHR.at(end) - hasHR.at(start); secs > 0 {
+		th.HeartRate = int((hr.at(end)-hr.at(start))/secs + 0.5)
+	}
+	return th, true
+}
+
+// EstimateThresholds estimates thresholds from the hardest efforts found
+// across the given activities, typically the recent races and time trials of
+// an athlete. Heart rate and speed come from the fastest activity, power from
+// the most powerful one.
+func EstimateThresholds(activities []*Activity) (th Thresholds, ok bool) {
+	for _, a := range activities {
+		t, found := a.EstimateThresholds()
+		if !found {
+			continue
+		}
+		ok = true
+		if t.Speed > th.Speed || (th.Speed == 0 && th.HeartRate == 0) {
+			th.HeartRate, th.Speed = t.HeartRate, t.Speed
+		}
+		if t.Power > th.Power {
+			th.Power = t.Power
+		}
+	}
+	return th, ok
+}
@@ -0,0 +1,57 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateThresholds(t *testing.T) {
+	speeds := append(repeat(2.5, 300), repeat(4.0, 2101)...)
+	hrs := append(repeat(130, 300), repeat(165, 2101)...)
+	a := testActivity("Running", speeds, hrs)
+
+	th, ok := a.EstimateThresholds()
+	if !ok {
+		t.Fatal("expected thresholds")
+	}
+	if th.HeartRate != 165 || math.Abs(th.Speed-4.0) > 1e-9 {
+		t.Errorf("EstimateThresholds() = %+v", th)
+	}
+
+	short := testActivity("Running", repeat(4.0, 600), repeat(165, 600))
+	if _, ok := short.EstimateThresholds(); ok {
+		t.Error("expected no thresholds from a 10 minute activity")
+	}
+	if th, ok := EstimateThresholds([]*Activity{short, a}); !ok || th.HeartRate != 165 {
+		t.Errorf("EstimateThresholds() = %+v, %v", th, ok)
+	}
+}
+
+func TestEstimateThresholdsHeartRateDropouts(t *testing.T) {
+	hrs := repeat(165, 2401)
+	for i := 0; i < len(hrs); i += 10 {
+		hrs[i] = 0
+	}
+	a := testActivity("Running", repeat(4.0, 2401), hrs)
+	if th, ok := a.EstimateThresholds(); !ok || th.HeartRate != 165 {
+		t.Errorf("EstimateThresholds() = %+v, %v, want LTHR 165", th, ok)
+	}
+}
+
+func TestEstimateThresholdsPower(t *testing.T) {
+	watts := append(repeat(150, 600), repeat(250, 1801)...)
+	trainer := withPower(testActivity("Biking", repeat(0.0, 2401), repeat(150, 2401)), watts)
+	th, ok := trainer.EstimateThresholds()
+	if !ok {
+		t.Fatal("expected thresholds from power")
+	}
+	if math.Abs(th.Power-0.95*250) > 1e-9 || th.Speed != 0 || th.HeartRate != 150 {
+		t.Errorf("EstimateThresholds() = %+v", th)
+	}
+
+	run := testActivity("Running", repeat(4.0, 2401), repeat(165, 2401))
+	th, ok = EstimateThresholds([]*Activity{trainer, run})
+	if !ok || th.HeartRate != 165 || th.Speed != 4 || math.Abs(th.Power-0.95*250) > 1e-9 {
+		t.Errorf("EstimateThresholds() = %+v, %v", th, ok)
+	}
+}
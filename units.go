@@ -0,0 +1,43 @@
+package tcx
+
+import "github.com/rdifrango/go-tcx/units"
+
+// UnitSystem selects the units of exported and displayed quantities.
+type UnitSystem = units.System
+
+const (
+	// MetricUnits uses meters, kilometers and km/h.
+	MetricUnits = units.Metric
+	// ImperialUnits uses feet, miles and mph.
+	ImperialUnits = units.Imperial
+)
+
+// DistanceIn returns the total distance of the activity in kilometers or
+// miles.
+func (a *Activity) DistanceIn(u UnitSystem) float64 {
+	return u.Distance(units.Meters(a.TotalDistance()))
+}
+
+// AscentIn returns the total ascent of the activity in meters or feet.
+func (a *Activity) AscentIn(u UnitSystem) float64 {
+	return u.Elevation(units.Meters(a.TotalAscent()))
+}
+
+// DistanceIn returns the distance of the lap in kilometers or miles.
+func (l *Lap) DistanceIn(u UnitSystem) float64 {
+	return u.Distance(units.Meters(l.DistanceInMeters))
+}
+
+// AverageSpeedIn returns the average speed of the lap in km/h or mph, or
+// zero for a lap without time.
+func (l *Lap) AverageSpeedIn(u UnitSystem) float64 {
+	if l.TotalTimeInSeconds <= 0 {
+		return 0
+	}
+	return u.Speed(units.MetersPerSecond(l.DistanceInMeters / l.TotalTimeInSeconds))
+}
+
+// MaximumSpeedIn returns the maximum speed of the lap in km/h or mph.
+func (l *Lap) MaximumSpeedIn(u UnitSystem) float64 {
+	return u.Speed(units.MetersPerSecond(l.MaximumSpeedInMetersPerSec))
+}
@@ -0,0 +1,134 @@
+// Package units converts the SI quantities of training files, meters and
+// meters per second, to and from the units athletes read them in.
+package units
+
+import "time"
+
+// Conversion factors to meters.
+const (
+	MetersPerKilometer = 1000
+	MetersPerMile      = 1609.344
+	MetersPerFoot      = 0.3048
+)
+
+// Distance is a length in meters.
+type Distance float64
+
+// Meters returns a distance of m meters.
+func Meters(m float64) Distance { return Distance(m) }
+
+// Kilometers returns a distance of km kilometers.
+func Kilometers(km float64) Distance { return Distance(km * MetersPerKilometer) }
+
+// Miles returns a distance of mi miles.
+func Miles(mi float64) Distance { return Distance(mi * MetersPerMile) }
+
+// Feet returns a distance of ft feet.
+func Feet(ft float64) Distance { return Distance(ft * MetersPerFoot) }
+
+// Meters returns d in meters.
+func (d Distance) Meters() float64 { return float64(d) }
+
+// Kilometers returns d in kilometers.
+func (d Distance) Kilometers() float64 { return float64(d) / MetersPerKilometer }
+
+// Miles returns d in miles.
+func (d Distance) Miles() float64 { return float64(d) / MetersPerMile }
+
+// Feet returns d in feet.
+func (d Distance) Feet() float64 { return float64(d) / MetersPerFoot }
+
+// Speed is a speed in meters per second.
+type Speed float64
+
+// MetersPerSecond returns a speed of v m/s.
+func MetersPerSecond(v float64) Speed { return Speed(v) }
+
+// KilometersPerHour returns a speed of v km/h.
+func KilometersPerHour(v float64) Speed { return Speed(v * MetersPerKilometer / 3600) }
+
+// MilesPerHour returns a speed of v mph.
+func MilesPerHour(v float64) Speed { return Speed(v * MetersPerMile / 3600) }
+
+// MetersPerSecond returns v in m/s.
+func (v Speed) MetersPerSecond() float64 { return float64(v) }
+
+// KilometersPerHour returns v in km/h.
+func (v Speed) KilometersPerHour() float64 { return float64(v) * 3600 / MetersPerKilometer }
+
+// MilesPerHour returns v in mph.
+func (v Speed) MilesPerHour() float64 { return float64(v) * 3600 / MetersPerMile }
+
+// PerKilometer returns the time taken to cover a kilometer at v, or zero
+// when v is not positive.
+func (v Speed) PerKilometer() time.Duration { return v.per(MetersPerKilometer) }
+
+// PerMile returns the time taken to cover a mile at v, or zero when v is
+// not positive.
+func (v Speed) PerMile() time.Duration { return v.per(MetersPerMile) }
+
+// MinutesPerKilometer returns the pace at v in decimal minutes per
+// kilometer, or zero when v is not positive.
+func (v Speed) MinutesPerKilometer() float64 { return v.PerKilometer().Minutes() }
+
+func (v Speed) per(meters float64) time.Duration {
+	if v <= 0 {
+		return 0
+	}
+	return time.Duration(meters / float64(v) * float64(time.Second))
+}
+
+// System is a system of units for display.
+type System int
+
+const (
+	// Metric uses meters, kilometers and km/h.
+	Metric System = iota
+	// Imperial uses feet, miles and mph.
+	Imperial
+)
+
+// Distance returns d in kilometers or miles.
+func (s System) Distance(d Distance) float64 {
+	if s == Imperial {
+		return d.Miles()
+	}
+	return d.Kilometers()
+}
+
+// Elevation returns d in meters or feet.
+func (s System) Elevation(d Distance) float64 {
+	if s == Imperial {
+		return d.Feet()
+	}
+	return d.Meters()
+}
+
+// Speed returns v in km/h or mph.
+func (s System) Speed(v Speed) float64 {
+	if s == Imperial {
+		return v.MilesPerHour()
+	}
+	return v.KilometersPerHour()
+}
+
+// Pace returns the time taken to cover a kilometer or a mile at v.
+func (s System) Pace(v Speed) time.Duration {
+	if s == Imperial {
+		return v.PerMile()
+	}
+	return v.PerKilometer()
+}
+
+// DistanceUnit, ElevationUnit and SpeedUnit return the abbreviations of the
+// units of the system.
+func (s System) DistanceUnit() string  { return s.pick("km", "mi") }
+func (s System) ElevationUnit() string { return s.pick("m", "ft") }
+func (s System) SpeedUnit() string     { return s.pick("kmh", "mph") }
+
+func (s System) pick(metric, imperial string) string {
+	if s == Imperial {
+		return imperial
+	}
+	return metric
+}
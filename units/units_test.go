@@ -0,0 +1,53 @@
+package units
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestDistance(t *testing.T) {
+	if d := Miles(1); !near(d.Meters(), 1609.344) || !near(d.Kilometers(), 1.609344) {
+		t.Errorf("a mile = %v m", d.Meters())
+	}
+	if d := Kilometers(10); !near(d.Miles(), 6.213711922373339) {
+		t.Errorf("10 km = %v mi", d.Miles())
+	}
+	if d := Feet(1000); !near(d.Meters(), 304.8) || !near(Meters(304.8).Feet(), 1000) {
+		t.Errorf("1000 ft = %v m", d.Meters())
+	}
+}
+
+func TestSpeed(t *testing.T) {
+	v := MetersPerSecond(4)
+	if !near(v.KilometersPerHour(), 14.4) || !near(KilometersPerHour(14.4).MetersPerSecond(), 4) {
+		t.Errorf("4 m/s = %v km/h", v.KilometersPerHour())
+	}
+	if !near(MilesPerHour(v.MilesPerHour()).MetersPerSecond(), 4) {
+		t.Errorf("mph round trip of 4 m/s = %v", MilesPerHour(v.MilesPerHour()))
+	}
+	if v.PerKilometer() != 250*time.Second || !near(v.MinutesPerKilometer(), 250.0/60) {
+		t.Errorf("pace at 4 m/s = %v", v.PerKilometer())
+	}
+	if v.PerMile() != 402336*time.Millisecond {
+		t.Errorf("mile pace at 4 m/s = %v", v.PerMile())
+	}
+	if MetersPerSecond(0).PerKilometer() != 0 {
+		t.Error("standing still should have no pace")
+	}
+}
+
+func TestSystem(t *testing.T) {
+	d, v := Kilometers(5), MetersPerSecond(4)
+	if !near(Metric.Distance(d), 5) || !near(Imperial.Distance(d), d.Miles()) {
+		t.Errorf("distances = %v, %v", Metric.Distance(d), Imperial.Distance(d))
+	}
+	if !near(Imperial.Elevation(Meters(100)), 328.0839895013123) || !near(Metric.Speed(v), 14.4) {
+		t.Errorf("elevation %v, speed %v", Imperial.Elevation(Meters(100)), Metric.Speed(v))
+	}
+	if Imperial.Pace(v) != v.PerMile() || Metric.DistanceUnit() != "km" || Imperial.SpeedUnit() != "mph" {
+		t.Error("system units mismatch")
+	}
+}
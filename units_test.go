@@ -0,0 +1,29 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUnitHelpers(t *testing.T) {
+	a := testActivity(SportRunning, repeat(4.0, 101), nil)
+	a.Laps[0].DistanceInMeters = 1609.344
+	a.Laps[0].TotalTimeInSeconds = 400
+	a.Laps[0].MaximumSpeedInMetersPerSec = 5
+	l := &a.Laps[0]
+	if d := a.DistanceIn(ImperialUnits); math.Abs(d-1) > 1e-9 {
+		t.Errorf("DistanceIn(ImperialUnits) = %v, want 1 mi", d)
+	}
+	if d := l.DistanceIn(MetricUnits); math.Abs(d-1.609344) > 1e-9 {
+		t.Errorf("DistanceIn(MetricUnits) = %v km", d)
+	}
+	if v := l.AverageSpeedIn(ImperialUnits); math.Abs(v-9) > 1e-9 {
+		t.Errorf("AverageSpeedIn(ImperialUnits) = %v, want 9 mph", v)
+	}
+	if v := l.MaximumSpeedIn(MetricUnits); math.Abs(v-18) > 1e-9 {
+		t.Errorf("MaximumSpeedIn(MetricUnits) = %v, want 18 km/h", v)
+	}
+	if (&Lap{}).AverageSpeedIn(MetricUnits) != 0 || a.AscentIn(ImperialUnits) != 0 {
+		t.Error("empty lap or flat activity should give zero")
+	}
+}
@@ -0,0 +1,138 @@
+package tcx
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ValidationError is a problem found by Validate, located by a path such
+// as Activities[0].Laps[2].Track[15].
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// lapTimeTolerance is how much shorter than its trackpoints a lap may be,
+// for devices rounding lap times.
+const lapTimeTolerance = time.Second
+
+// schemaSports are the sports allowed by the TCX schema; the other sports
+// of this package are written as Other.
+var schemaSports = []string{SportRunning, SportBiking, SportOther}
+
+// finite reports whether none of vals is NaN or infinite.
+func finite(vals ...float64) bool {
+	for _, v := range vals {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks t against the TCX schema and for consistency: required
+// times, sports allowed by the schema, finite numbers, known intensities and trigger methods, positions within
+// range, trackpoints in time order and laps lasting at least as long as
+// their trackpoints. It returns every problem found, or nil.
+func (t *Tcx) Validate() []ValidationError {
+	var v validator
+	for i := range t.Activities {
+		v.activity(fmt.Sprintf("Activities[%d]", i), &t.Activities[i])
+	}
+	for i := range t.MultiSportSessions {
+		for j, a := range t.MultiSportSessions[i].Legs() {
+			v.activity(fmt.Sprintf("MultiSportSessions[%d].Legs[%d]", i, j), a)
+		}
+	}
+	for i := range t.Courses {
+		c := &t.Courses[i]
+		path := fmt.Sprintf("Courses[%d]", i)
+		if c.Name == "" {
+			v.add(path, "missing Name")
+		}
+		var last time.Time
+		v.track(path, c.Track, &last)
+	}
+	return v.errs
+}
+
+type validator struct {
+	errs []ValidationError
+}
+
+func (v *validator) add(path, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) activity(path string, a *Activity) {
+	if a.ID.IsZero() {
+		v.add(path, "missing Id")
+	}
+	if !oneOf(a.Sport, schemaSports) {
+		v.add(path, "unknown Sport %q", a.Sport)
+	}
+	if len(a.Laps) == 0 {
+		v.add(path, "no laps")
+	}
+	var last time.Time
+	for i := range a.Laps {
+		l := &a.Laps[i]
+		lp := fmt.Sprintf("%s.Laps[%d]", path, i)
+		if l.StartTime.IsZero() {
+			v.add(lp, "missing StartTime")
+		}
+		if l.Intensity != "" && !oneOf(l.Intensity, knownIntensities) {
+			v.add(lp, "unknown Intensity %q", l.Intensity)
+		}
+		if l.TriggerMethod != "" && !oneOf(l.TriggerMethod, knownTriggerMethods) {
+			v.add(lp, "unknown TriggerMethod %q", l.TriggerMethod)
+		}
+		if !finite(l.TotalTimeInSeconds, l.DistanceInMeters, l.MaximumSpeedInMetersPerSec, l.Calories) {
+			v.add(lp, "NaN or infinite value")
+		}
+		if l.TotalTimeInSeconds < 0 || l.DistanceInMeters < 0 {
+			v.add(lp, "negative TotalTimeSeconds or DistanceMeters")
+		}
+		if n := len(l.Track); n > 0 {
+			first := l.Track[0].Time
+			if !l.StartTime.IsZero() && first.Before(l.StartTime) {
+				v.add(lp, "first trackpoint at %v is before the lap start at %v", first, l.StartTime)
+			}
+			span := l.Track[n-1].Time.Sub(first)
+			if total := time.Duration(l.TotalTimeInSeconds * float64(time.Second)); span-total > lapTimeTolerance {
+				v.add(lp, "TotalTimeSeconds %v is shorter than the trackpoints span of %v", l.TotalTimeInSeconds, span.Seconds())
+			}
+		}
+		v.track(lp, l.Track, &last)
+	}
+}
+
+// track checks the trackpoints of a track, which must come after last.
+func (v *validator) track(path string, track []Trackpoint, last *time.Time) {
+	for i := range track {
+		p := &track[i]
+		pp := fmt.Sprintf("%s.Track[%d]", path, i)
+		if p.Time.IsZero() {
+			v.add(pp, "missing Time")
+		} else {
+			if p.Time.Before(*last) {
+				v.add(pp, "time %v is before the previous trackpoint at %v", p.Time, *last)
+			}
+			*last = p.Time
+		}
+		if !finite(p.LatitudeInDegrees, p.LongitudeInDegrees, p.AltitudeInMeters, p.DistanceInMeters, p.SpeedInMetersPerSec) {
+			v.add(pp, "NaN or infinite value")
+		}
+		if p.LatitudeInDegrees < -90 || p.LatitudeInDegrees > 90 || p.LongitudeInDegrees < -180 || p.LongitudeInDegrees > 180 {
+			v.add(pp, "position %v, %v out of range", p.LatitudeInDegrees, p.LongitudeInDegrees)
+		}
+		if p.HeartRateInBpm < 0 || p.Cadence < 0 || p.PowerInWatts < 0 || p.SpeedInMetersPerSec < 0 {
+			v.add(pp, "negative reading")
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package tcx
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	db, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := db.Validate(); errs != nil {
+		t.Fatalf("test1.tcx: %v", errs)
+	}
+
+	a := &db.Activities[0]
+	a.Sport = "Rowing"
+	a.Laps[2].TriggerMethod = "Button"
+	a.Laps[3].TotalTimeInSeconds = 10
+	a.Laps[4].Track[5].Time = a.Laps[4].Track[4].Time.Add(-time.Second)
+	a.Laps[4].Track[6].LatitudeInDegrees = 123
+	a.Laps[5].Track[0].Time = time.Time{}
+	a.Laps[6].DistanceInMeters = math.Inf(1)
+	a.Laps[6].Track[1].AltitudeInMeters = math.NaN()
+	want := []string{
+		`Activities[0]: unknown Sport "Rowing"`,
+		`Activities[0].Laps[2]: unknown TriggerMethod "Button"`,
+		`Activities[0].Laps[3]: TotalTimeSeconds 10 is shorter`,
+		`Activities[0].Laps[4].Track[5]: time`,
+		`Activities[0].Laps[4].Track[6]: position 123`,
+		`Activities[0].Laps[5].Track[0]: missing Time`,
+		`Activities[0].Laps[6]: NaN or infinite value`,
+		`Activities[0].Laps[6].Track[1]: NaN or infinite value`,
+	}
+	errs := db.Validate()
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			found = found || strings.HasPrefix(g, w)
+		}
+		if !found {
+			t.Errorf("no error %q in:\n%s", w, strings.Join(got, "\n"))
+		}
+	}
+
+	// Walking is a sport of this package, not of the schema.
+	a = &db.Activities[0]
+	a.Sport = SportWalking
+	if errs := db.Validate(); len(errs) == 0 || errs[0].Error() != `Activities[0]: unknown Sport "Walking"` {
+		t.Errorf("Validate of a walk = %v", errs)
+	}
+}
@@ -0,0 +1,93 @@
+package tcx
+
+import (
+	"sort"
+	"time"
+)
+
+// VO2max estimation relies on the linear relation between the fraction of
+// heart rate reserve and the fraction of VO2 reserve (Swain): every
+// submaximal sample yields VO2max = 3.5 + (VO2 - 3.5) / %HRR, where VO2 is the
+// ACSM metabolic cost of running at the sample speed or, for rides, of
+// cycling at the sample power.
+
+const (
+	vo2Rest = 3.5 // ml/kg/min
+
+	// Samples outside this heart rate reserve range are not steady
+	// submaximal efforts and are ignored.
+	vo2MinReserve = 0.5
+	vo2MaxReserve = 0.9
+
+	// vo2Warmup is skipped at the start of the activity while heart rate
+	// catches up with the effort.
+	vo2Warmup = 5 * time.Minute
+
+	vo2MinSamples = 60
+)
+
+// VO2maxEstimate is the VO2max estimated from one activity.
+type VO2maxEstimate struct {
+	Date    time.Time
+	VO2max  float64 // ml/kg/min
+	Samples int
+}
+
+// EstimateVO2max estimates the athlete's VO2max from the submaximal heart rate
+// and speed samples of a running activity, or the heart rate and power
+// samples of a ride, which also need the athlete's weight. ok is false when
+// the activity or the athlete profile does not carry enough data.
+func (a *Activity) EstimateVO2max(ath Athlete) (est VO2maxEstimate, ok bool) {
+	// cost returns the VO2 of the effort at p, in ml/kg/min.
+	cost := func(p *Trackpoint) (float64, bool) {
+		return vo2Rest + 0.2*p.SpeedInMetersPerSec*60, p.SpeedInMetersPerSec > 0
+	}
+	if a.Sport == SportBiking {
+		if ath.Weight <= 0 {
+			return est, false
+		}
+		// ACSM leg cycling: 1.8 ml of O2 per kg·m of work, plus 3.5 for
+		// unloaded cycling, with 1 W = 6.12 kg·m/min.
+		cost = func(p *Trackpoint) (float64, bool) {
+			return 2*vo2Rest + 1.8*6.12*float64(p.PowerInWatts)/ath.Weight, p.PowerInWatts > 0
+		}
+	}
+	pts := a.trackpoints()
+	if len(pts) == 0 {
+		return est, false
+	}
+	start := pts[0].Time
+	var values []float64
+	for _, p := range pts {
+		vo2, moving := cost(p)
+		if p.Time.Sub(start) < vo2Warmup || p.HeartRateInBpm == 0 || !moving {
+			continue
+		}
+		hrr, ok := ath.heartRateReserve(p.HeartRateInBpm)
+		if !ok {
+			return est, false
+		}
+		if hrr < vo2MinReserve || hrr > vo2MaxReserve {
+			continue
+		}
+		values = append(values, vo2Rest+(vo2-vo2Rest)/hrr)
+	}
+	if len(values) < vo2MinSamples {
+		return est, false
+	}
+	sort.Float64s(values)
+	return VO2maxEstimate{Date: a.ID, VO2max: values[len(values)/2], Samples: len(values)}, true
+}
+
+// VO2maxTrend estimates VO2max for every activity that allows it and returns
+// the estimates in chronological order.
+func VO2maxTrend(activities []*Activity, ath Athlete) []VO2maxEstimate {
+	var trend []VO2maxEstimate
+	for _, a := range activities {
+		if est, ok := a.EstimateVO2max(ath); ok {
+			trend = append(trend, est)
+		}
+	}
+	sort.Slice(trend, func(i, j int) bool { return trend[i].Date.Before(trend[j].Date) })
+	return trend
+}
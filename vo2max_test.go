@@ -0,0 +1,54 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEstimateVO2max(t *testing.T) {
+	ath := Athlete{MaxHeartRate: 190, RestingHeartRate: 50}
+	a := testActivity("Running", repeat(3.0, 900), repeat(155, 900))
+
+	est, ok := a.EstimateVO2max(ath)
+	if !ok {
+		t.Fatal("expected an estimate")
+	}
+	// 180 m/min costs 39.5 ml/kg/min at 75% of heart rate reserve.
+	if math.Abs(est.VO2max-51.5) > 0.01 {
+		t.Errorf("VO2max = %v, want 51.5", est.VO2max)
+	}
+
+	if _, ok := a.EstimateVO2max(Athlete{}); ok {
+		t.Error("expected no estimate without heart rate profile")
+	}
+
+	b := testActivity("Running", repeat(3.3, 900), repeat(155, 900))
+	b.ID = a.ID.Add(-24 * time.Hour)
+	trend := VO2maxTrend([]*Activity{a, b}, ath)
+	if len(trend) != 2 || !trend[0].Date.Equal(b.ID) {
+		t.Errorf("VO2maxTrend() = %v", trend)
+	}
+}
+
+func TestEstimateVO2maxCycling(t *testing.T) {
+	ath := Athlete{MaxHeartRate: 190, RestingHeartRate: 50, Weight: 70}
+	ride := withPower(testActivity(SportBiking, repeat(9.0, 900), repeat(155, 900)), repeat(250, 900))
+
+	est, ok := ride.EstimateVO2max(ath)
+	if !ok {
+		t.Fatal("expected an estimate from power")
+	}
+	// 250 W costs 46.3 ml/kg/min for 70 kg at 75% of heart rate reserve.
+	if want := 3.5 + (7+1.8*6.12*250/70-3.5)/0.75; math.Abs(est.VO2max-want) > 0.01 {
+		t.Errorf("VO2max = %v, want %v", est.VO2max, want)
+	}
+
+	ath.Weight = 0
+	if _, ok := ride.EstimateVO2max(ath); ok {
+		t.Error("expected no estimate without weight")
+	}
+	if _, ok := testActivity(SportBiking, repeat(9.0, 900), repeat(155, 900)).EstimateVO2max(Athlete{MaxHeartRate: 190, RestingHeartRate: 50, Weight: 70}); ok {
+		t.Error("expected no estimate from a ride without power")
+	}
+}
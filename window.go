@@ -0,0 +1,69 @@
+package tcx
+
+import "sort"
+
+// timeIntegral holds the running time integral of a trackpoint value, used to
+// compute time-weighted averages over arbitrary windows in constant time.
+type timeIntegral struct {
+	t   []float64 // seconds since the first trackpoint
+	sum []float64 // integral of the value from the first trackpoint to t[i]
+}
+
+// integrate builds the time integral of value over pts. Each value holds until
+// the next trackpoint.
+func integrate(pts []*Trackpoint, value func(*Trackpoint) float64) timeIntegral {
+	in := timeIntegral{t: make([]float64, len(pts)), sum: make([]float64, len(pts))}
+	for i := 1; i < len(pts); i++ {
+		dt := pts[i].Time.Sub(pts[i-1].Time).Seconds()
+		if dt < 0 {
+			dt = 0
+		}
+		in.t[i] = in.t[i-1] + dt
+		in.sum[i] = in.sum[i-1] + value(pts[i-1])*dt
+	}
+	return in
+}
+
+// at returns the integral up to time t (seconds since the first trackpoint),
+// interpolating between trackpoints.
+func (in timeIntegral) at(t float64) float64 {
+	n := len(in.t)
+	if n == 0 {
+		return 0
+	}
+	i := sort.SearchFloat64s(in.t, t)
+	if i >= n {
+		return in.sum[n-1]
+	}
+	if i == 0 || in.t[i] == t {
+		return in.sum[i]
+	}
+	frac := (t - in.t[i-1]) / (in.t[i] - in.t[i-1])
+	return in.sum[i-1] + frac*(in.sum[i]-in.sum[i-1])
+}
+
+// mean returns the time-weighted mean of the value between from and to.
+func (in timeIntegral) mean(from, to float64) float64 {
+	if to <= from {
+		return 0
+	}
+	return (in.at(to) - in.at(from)) / (to - from)
+}
+
+// bestWindow returns the end time of the window of the given length (seconds)
+// with the highest mean, and that mean. ok is false when the series is
+// shorter than the window.
+func (in timeIntegral) bestWindow(length float64) (end, best float64, ok bool) {
+	if len(in.t) == 0 || in.t[len(in.t)-1] < length {
+		return 0, 0, false
+	}
+	for _, t := range in.t {
+		if t < length {
+			continue
+		}
+		if m := in.mean(t-length, t); !ok || m > best {
+			end, best, ok = t, m, true
+		}
+	}
+	return end, best, ok
+}
@@ -0,0 +1,208 @@
+package tcx
+
+import "encoding/xml"
+
+// Workout is a structured training session, as defined by the Workouts
+// element of the TCX schema.
+type Workout struct {
+	Sport string        `xml:"Sport,attr"`
+	Name  string        `xml:"Name"`
+	Steps []WorkoutStep `xml:"Step"`
+	Notes string        `xml:"Notes,omitempty"`
+}
+
+// Workout step types, as found in the xsi:type attribute of a step.
+const (
+	StepTypeStep   = "Step_t"
+	StepTypeRepeat = "Repeat_t"
+)
+
+// WorkoutStep is either a single step or, when Type is StepTypeRepeat, a
+// block of child steps repeated Repetitions times.
+type WorkoutStep struct {
+	Type        string        `xml:"type,attr,omitempty"`
+	StepID      int           `xml:"StepId"`
+	Name        string        `xml:"Name,omitempty"`
+	Duration    *StepDuration `xml:"Duration"`
+	Intensity   string        `xml:"Intensity,omitempty"`
+	Target      *StepTarget   `xml:"Target"`
+	Repetitions int           `xml:"Repetitions,omitempty"`
+	Children    []WorkoutStep `xml:"Child"`
+}
+
+// Step duration types.
+const (
+	DurationTime           = "Time_t"
+	DurationDistance       = "Distance_t"
+	DurationHeartRateAbove = "HeartRateAbove_t"
+	DurationHeartRateBelow = "HeartRateBelow_t"
+	DurationCaloriesBurned = "CaloriesBurned_t"
+	DurationUserInitiated  = "UserInitiated_t"
+)
+
+// StepDuration tells when a step ends. Only the field matching Type is set.
+type StepDuration struct {
+	Type      string `xml:"type,attr,omitempty"`
+	Seconds   int    `xml:"Seconds,omitempty"`
+	Meters    int    `xml:"Meters,omitempty"`
+	HeartRate int    `xml:"HeartRate>Value,omitempty"`
+	Calories  int    `xml:"Calories,omitempty"`
+}
+
+// Step target types.
+const (
+	TargetSpeed     = "Speed_t"
+	TargetHeartRate = "HeartRate_t"
+	TargetCadence   = "Cadence_t"
+	TargetNone      = "None_t"
+)
+
+// StepTarget is the intensity to hold during a step. Only the zone matching
+// Type is set.
+type StepTarget struct {
+	Type          string         `xml:"type,attr,omitempty"`
+	SpeedZone     *SpeedZone     `xml:"SpeedZone"`
+	HeartRateZone *HeartRateZone `xml:"HeartRateZone"`
+	CadenceZone   *CadenceZone   `xml:"CadenceZone"`
+}
+
+// Zone types of step targets.
+const (
+	PredefinedSpeedZone     = "PredefinedSpeedZone_t"
+	CustomSpeedZone         = "CustomSpeedZone_t"
+	PredefinedHeartRateZone = "PredefinedHeartRateZone_t"
+	CustomHeartRateZone     = "CustomHeartRateZone_t"
+)
+
+// SpeedZone is either one of the device's predefined speed zones (Number) or
+// a custom range in m/s.
+type SpeedZone struct {
+	Type   string  `xml:"type,attr,omitempty"`
+	Number int     `xml:"Number,omitempty"`
+	ViewAs string  `xml:"ViewAs,omitempty"`
+	Low    float64 `xml:"LowInMetersPerSecond,omitempty"`
+	High   float64 `xml:"HighInMetersPerSecond,omitempty"`
+}
+
+// HeartRateZone is either one of the device's predefined heart rate zones
+// (Number) or a custom range in bpm.
+type HeartRateZone struct {
+	Type   string `xml:"type,attr,omitempty"`
+	Number int    `xml:"Number,omitempty"`
+	Low    int    `xml:"Low>Value,omitempty"`
+	High   int    `xml:"High>Value,omitempty"`
+}
+
+// CadenceZone is a cadence range.
+type CadenceZone struct {
+	Low  float64 `xml:"Low"`
+	High float64 `xml:"High"`
+}
+
+// Limits on the expansion of repeat blocks, so that a hostile file cannot
+// nest repeats into billions of steps. The schema allows up to 99
+// repetitions.
+const (
+	maxRepetitions = 99
+	maxFlatSteps   = 10000
+)
+
+// FlattenSteps returns the steps of the workout in execution order, with
+// repeat blocks expanded. Repetitions are capped at 99 and the result at
+// 10000 steps.
+func (w *Workout) FlattenSteps() []WorkoutStep {
+	return flattenSteps(w.Steps, maxFlatSteps)
+}
+
+// flattenSteps expands steps into at most limit steps.
+func flattenSteps(steps []WorkoutStep, limit int) []WorkoutStep {
+	var flat []WorkoutStep
+	for _, s := range steps {
+		if len(flat) >= limit {
+			break
+		}
+		if s.Type == StepTypeRepeat {
+			children := flattenSteps(s.Children, limit-len(flat))
+			if len(children) == 0 {
+				continue
+			}
+			for i := 0; i < min(s.Repetitions, maxRepetitions) && len(flat) < limit; i++ {
+				flat = append(flat, children[:min(len(children), limit-len(flat))]...)
+			}
+			continue
+		}
+		flat = append(flat, s)
+	}
+	return flat
+}
+
+// Workout returns the workout of t named name, or nil.
+func (t *Tcx) Workout(name string) *Workout {
+	for i := range t.Workouts {
+		if t.Workouts[i].Name == name {
+			return &t.Workouts[i]
+		}
+	}
+	return nil
+}
+
+// encodeTyped encodes v as the element start, with typ as its xsi:type.
+func encodeTyped(e *xml.Encoder, start xml.StartElement, typ string, v any) error {
+	if typ != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xsi:type"}, Value: typ})
+	}
+	return e.EncodeElement(v, start)
+}
+
+// MarshalXML writes the step with its type as an xsi:type.
+func (s WorkoutStep) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain WorkoutStep
+	v := plain(s)
+	v.Type = ""
+	return encodeTyped(e, start, s.Type, v)
+}
+
+// MarshalXML writes the duration with its type as an xsi:type.
+func (d StepDuration) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain StepDuration
+	v := plain(d)
+	v.Type = ""
+	return encodeTyped(e, start, d.Type, v)
+}
+
+// MarshalXML writes the target with its type as an xsi:type.
+func (t StepTarget) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain StepTarget
+	v := plain(t)
+	v.Type = ""
+	return encodeTyped(e, start, t.Type, v)
+}
+
+// MarshalXML writes the zone with its type as an xsi:type.
+func (z SpeedZone) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain SpeedZone
+	v := plain(z)
+	v.Type = ""
+	return encodeTyped(e, start, z.Type, v)
+}
+
+// heartRateValueXML is a heart rate in bpm, written as the schema types it.
+type heartRateValueXML struct {
+	Type  string `xml:"xsi:type,attr"`
+	Value int    `xml:"Value"`
+}
+
+// MarshalXML writes the zone with its type and the type of its bounds as
+// xsi:types.
+func (z HeartRateZone) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	v := struct {
+		Number int                `xml:"Number,omitempty"`
+		Low    *heartRateValueXML `xml:"Low,omitempty"`
+		High   *heartRateValueXML `xml:"High,omitempty"`
+	}{Number: z.Number}
+	if z.Type == CustomHeartRateZone {
+		v.Low = &heartRateValueXML{"HeartRateInBeatsPerMinute_t", z.Low}
+		v.High = &heartRateValueXML{"HeartRateInBeatsPerMinute_t", z.High}
+	}
+	return encodeTyped(e, start, z.Type, v)
+}
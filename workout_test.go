@@ -0,0 +1,72 @@
+package tcx
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWorkouts(t *testing.T) {
+	doc := `<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <Workouts>` + strings.Replace(testWorkout, ` xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"`, "", 1) + `</Workouts>
+</TrainingCenterDatabase>`
+	db, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := db.Workout("Intervals")
+	if w == nil || db.Workout("Tempo") != nil {
+		t.Fatal("Workout() lookup failed")
+	}
+	if len(w.Steps) != 2 || w.Steps[1].Type != StepTypeRepeat || w.Steps[1].Children[0].Target.SpeedZone.High != 4.5 {
+		t.Fatalf("workout = %+v", w)
+	}
+	if z := w.Steps[0].Target.HeartRateZone; z.Type != CustomHeartRateZone || z.Low != 120 || z.High != 140 {
+		t.Errorf("heart rate zone = %+v", z)
+	}
+
+	db.Activities = append(db.Activities, *testActivity(SportRunning, repeat(4.0, 10), nil))
+	db.Courses = append(db.Courses, Course{Name: "Loop"})
+	var b bytes.Buffer
+	if err := db.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, s := range []string{
+		`<Step xsi:type="Repeat_t">`, `<Duration xsi:type="Distance_t">`, `<Target xsi:type="None_t">`,
+		`<SpeedZone xsi:type="CustomSpeedZone_t">`, `<Low xsi:type="HeartRateInBeatsPerMinute_t">`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output lacks %s", s)
+		}
+	}
+	if a, w, c := strings.Index(out, "<Activities>"), strings.Index(out, "<Workouts>"), strings.Index(out, "<Courses>"); !(a < w && w < c) {
+		t.Error("workouts not written between activities and courses")
+	}
+	if strings.Contains(out, ` type="`) {
+		t.Error("types written without the xsi prefix")
+	}
+	back, err := Parse(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.Workouts, db.Workouts) {
+		t.Errorf("workouts changed through Write:\n%s", out)
+	}
+}
+
+func TestFlattenStepsLimits(t *testing.T) {
+	steps := []WorkoutStep{{Type: StepTypeStep}}
+	for i := 0; i < 8; i++ {
+		steps = []WorkoutStep{{Type: StepTypeRepeat, Repetitions: 1000000, Children: steps}}
+	}
+	w := &Workout{Steps: steps}
+	if n := len(w.FlattenSteps()); n != maxFlatSteps {
+		t.Errorf("FlattenSteps() of nested repeats gave %d steps, want %d", n, maxFlatSteps)
+	}
+	w.Steps = []WorkoutStep{{Type: StepTypeRepeat, Repetitions: 500, Children: []WorkoutStep{{Type: StepTypeStep}}}}
+	if n := len(w.FlattenSteps()); n != maxRepetitions {
+		t.Errorf("FlattenSteps() of 500 repetitions gave %d steps, want %d", n, maxRepetitions)
+	}
+}
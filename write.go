@@ -0,0 +1,417 @@
+package tcx
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// Schema instance namespace and the location of the TCX v2 schema.
+const (
+	xsiNS             = "http://www.w3.org/2001/XMLSchema-instance"
+	trainingCenterXSD = "http://www.garmin.com/xmlschemas/TrainingCenterDatabasev2.xsd"
+)
+
+// Write writes t as a TCX document, indented by two spaces.
+func (t *Tcx) Write(w io.Writer) error {
+	return t.WriteIndent(w, "", "  ")
+}
+
+// WriteIndent writes t as a TCX document, starting each line with prefix
+// and indenting nested elements with indent. Empty strings write the whole
+// document on one line. Elements holding character data are not indented
+// inside, so that the mixed content of extensions is kept as is.
+func (t *Tcx) WriteIndent(w io.Writer, prefix, indent string) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	iw := &indentWriter{w: bufio.NewWriter(w), prefix: prefix, indent: indent}
+	if err := xml.NewEncoder(iw).Encode(t); err != nil {
+		return err
+	}
+	if err := iw.w.WriteByte('\n'); err != nil {
+		return err
+	}
+	return iw.w.Flush()
+}
+
+// indentWriter indents the XML written by an encoder without indentation,
+// as the encoder would, but for the content of the elements holding
+// character data, which is left as written.
+type indentWriter struct {
+	w              *bufio.Writer
+	prefix, indent string
+
+	depth   int  // elements open
+	flat    int  // depth of the element holding character data, 0 if none
+	opened  bool // an element was just opened
+	started bool // an element was written
+	lt      bool // a < was read, not yet written
+	inTag   bool // a tag is being written
+}
+
+func (iw *indentWriter) Write(b []byte) (int, error) {
+	if iw.prefix == "" && iw.indent == "" {
+		return iw.w.Write(b)
+	}
+	for _, c := range b {
+		switch {
+		case iw.lt:
+			// The byte after < tells an end tag from a start tag.
+			if c == '/' {
+				iw.close()
+			} else {
+				iw.open()
+			}
+			iw.w.WriteByte('<')
+			iw.lt, iw.inTag = false, true
+		case iw.inTag:
+			iw.inTag = c != '>'
+		case c == '<':
+			iw.lt = true
+			continue
+		case iw.flat == 0:
+			iw.flat = iw.depth
+		}
+		if err := iw.w.WriteByte(c); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// open starts a line for a start tag, outside elements holding character
+// data.
+func (iw *indentWriter) open() {
+	if iw.flat == 0 {
+		iw.newline()
+		iw.opened = true
+	}
+	iw.depth++
+}
+
+// close starts a line for an end tag, unless the element is empty or holds
+// character data.
+func (iw *indentWriter) close() {
+	iw.depth--
+	if iw.flat == 0 && !iw.opened {
+		iw.newline()
+	}
+	iw.opened = false
+	if iw.depth < iw.flat {
+		iw.flat = 0
+	}
+}
+
+func (iw *indentWriter) newline() {
+	if iw.started {
+		iw.w.WriteByte('\n')
+	}
+	iw.started = true
+	iw.w.WriteString(iw.prefix)
+	for i := 0; i < iw.depth; i++ {
+		iw.w.WriteString(iw.indent)
+	}
+}
+
+// WriteFile writes t as a TCX file.
+func (t *Tcx) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := t.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// MarshalXML writes the root element with the TCX v2 namespace and schema
+// location, whatever the namespace attributes held by t.
+func (t *Tcx) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	loc := t.XMLSchemaLoc
+	if loc == "" {
+		loc = TrainingCenterNS + " " + trainingCenterXSD
+	}
+	root := xml.StartElement{
+		Name: xml.Name{Local: "TrainingCenterDatabase"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "xmlns"}, Value: TrainingCenterNS},
+			{Name: xml.Name{Local: "xmlns:xsi"}, Value: xsiNS},
+			{Name: xml.Name{Local: "xsi:schemaLocation"}, Value: loc},
+		},
+	}
+	if err := e.EncodeToken(root); err != nil {
+		return err
+	}
+	if len(t.Activities) > 0 || len(t.MultiSportSessions) > 0 {
+		activities := xml.StartElement{Name: xml.Name{Local: "Activities"}}
+		if err := e.EncodeToken(activities); err != nil {
+			return err
+		}
+		for i := range t.Activities {
+			if err := e.EncodeElement(&t.Activities[i], xml.StartElement{Name: xml.Name{Local: "Activity"}}); err != nil {
+				return err
+			}
+		}
+		for i := range t.MultiSportSessions {
+			if err := e.EncodeElement(&t.MultiSportSessions[i], xml.StartElement{Name: xml.Name{Local: "MultiSportSession"}}); err != nil {
+				return err
+			}
+		}
+		if err := e.EncodeToken(activities.End()); err != nil {
+			return err
+		}
+	}
+	if len(t.Workouts) > 0 {
+		workouts := struct {
+			Workouts []Workout `xml:"Workout"`
+		}{t.Workouts}
+		if err := e.EncodeElement(workouts, xml.StartElement{Name: xml.Name{Local: "Workouts"}}); err != nil {
+			return err
+		}
+	}
+	if len(t.Courses) > 0 {
+		courses := struct {
+			Courses []Course `xml:"Course"`
+		}{t.Courses}
+		if err := e.EncodeElement(courses, xml.StartElement{Name: xml.Name{Local: "Courses"}}); err != nil {
+			return err
+		}
+	}
+	if t.Author != nil {
+		if err := e.EncodeElement(t.Author, xml.StartElement{Name: xml.Name{Local: "Author"}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(root.End())
+}
+
+// activityXML is an Activity in schema order.
+type activityXML struct {
+	Sport      string              `xml:"Sport,attr"`
+	ID         time.Time           `xml:"Id"`
+	Laps       []Lap               `xml:"Lap"`
+	Creator    Creator             `xml:"Creator"`
+	Extensions *ActivityExtensions `xml:"Extensions,omitempty"`
+}
+
+// MarshalXML writes the activity in schema order. Sports outside the schema
+// are written as Other.
+func (a Activity) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	v := activityXML{Sport: a.Sport, ID: a.ID, Laps: a.Laps, Creator: a.Creator, Extensions: a.Extensions}
+	if v.Sport != SportRunning && v.Sport != SportBiking {
+		v.Sport = SportOther
+	}
+	if v.Extensions != nil && v.Extensions.Metadata == nil && len(v.Extensions.Other) == 0 {
+		v.Extensions = nil
+	}
+	return e.EncodeElement(v, start)
+}
+
+// creatorXML is a Creator written as a Device_t.
+type creatorXML struct {
+	Type         string `xml:"xsi:type,attr"`
+	Name         string `xml:"Name"`
+	UnitID       int    `xml:"UnitId"`
+	ProductID    int    `xml:"ProductID"`
+	VersionMajor int    `xml:"Version>VersionMajor"`
+	VersionMinor int    `xml:"Version>VersionMinor"`
+	BuildMajor   int    `xml:"Version>BuildMajor,omitempty"`
+	BuildMinor   int    `xml:"Version>BuildMinor,omitempty"`
+}
+
+// MarshalXML writes the creator as a device, or nothing when it is empty.
+func (c Creator) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if c == (Creator{}) {
+		return nil
+	}
+	return e.EncodeElement(creatorXML{
+		Type:         "Device_t",
+		Name:         c.Name,
+		UnitID:       c.UnitID,
+		ProductID:    c.ProductID,
+		VersionMajor: c.VersionMajor,
+		VersionMinor: c.VersionMinor,
+		BuildMajor:   c.BuildMajor,
+		BuildMinor:   c.BuildMinor,
+	}, start)
+}
+
+// lapXML is a Lap in schema order, with its required elements defaulted.
+type lapXML struct {
+	StartTime        time.Time         `xml:"StartTime,attr"`
+	TotalTimeSeconds float64           `xml:"TotalTimeSeconds"`
+	DistanceMeters   float64           `xml:"DistanceMeters"`
+	MaximumSpeed     float64           `xml:"MaximumSpeed,omitempty"`
+	Calories         int               `xml:"Calories"`
+	AverageHeartRate *heartRateXML     `xml:"AverageHeartRateBpm,omitempty"`
+	MaximumHeartRate *heartRateXML     `xml:"MaximumHeartRateBpm,omitempty"`
+	Intensity        string            `xml:"Intensity"`
+	Cadence          int               `xml:"Cadence,omitempty"`
+	TriggerMethod    string            `xml:"TriggerMethod"`
+	Track            []Trackpoint      `xml:"Track>Trackpoint,omitempty"`
+	Notes            string            `xml:"Notes,omitempty"`
+	Extensions       *lapExtensionsXML `xml:"Extensions,omitempty"`
+}
+
+type lapExtensionsXML struct {
+	LX          *lxXML             `xml:",omitempty"`
+	FatCalories *fatCaloriesXML    `xml:",omitempty"`
+	Other       []ExtensionElement `xml:",any"`
+}
+
+type lxXML struct {
+	XMLName xml.Name `xml:"http://www.garmin.com/xmlschemas/ActivityExtension/v2 LX"`
+	*LapExtension
+}
+
+type fatCaloriesXML struct {
+	XMLName xml.Name `xml:"http://www.garmin.com/xmlschemas/FatCalories/v1 FatCalories"`
+	Value   int      `xml:"Value"`
+}
+
+// MarshalXML writes the lap in schema order, with the extension elements in
+// their namespaces.
+func (l Lap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	v := lapXML{
+		StartTime:        l.StartTime,
+		TotalTimeSeconds: l.TotalTimeInSeconds,
+		DistanceMeters:   l.DistanceInMeters,
+		MaximumSpeed:     l.MaximumSpeedInMetersPerSec,
+		Calories:         int(math.Round(l.Calories)),
+		Intensity:        l.Intensity,
+		Cadence:          l.Cadence,
+		TriggerMethod:    l.TriggerMethod,
+		Track:            l.Track,
+		Notes:            l.Notes,
+	}
+	if l.AverageHeartRateInBpm > 0 {
+		v.AverageHeartRate = &heartRateXML{l.AverageHeartRateInBpm}
+	}
+	if l.MaximumHeartRateInBpm > 0 {
+		v.MaximumHeartRate = &heartRateXML{l.MaximumHeartRateInBpm}
+	}
+	if v.Intensity == "" {
+		v.Intensity = "Active"
+	}
+	if v.TriggerMethod == "" {
+		v.TriggerMethod = "Manual"
+	}
+	if ext := l.Extensions; ext != nil && (ext.LX != nil || ext.FatCalories != nil || len(ext.Other) > 0) {
+		v.Extensions = &lapExtensionsXML{Other: ext.Other}
+		if ext.LX != nil {
+			v.Extensions.LX = &lxXML{LapExtension: ext.LX}
+		}
+		if ext.FatCalories != nil {
+			v.Extensions.FatCalories = &fatCaloriesXML{Value: *ext.FatCalories}
+		}
+	}
+	return e.EncodeElement(v, start)
+}
+
+// trackpointXML is a Trackpoint in schema order. Missing values are left
+// out; values recorded as zero are written.
+type trackpointXML struct {
+	Time           time.Time                `xml:"Time"`
+	Position       *positionXML             `xml:"Position,omitempty"`
+	AltitudeMeters *float64                 `xml:"AltitudeMeters,omitempty"`
+	DistanceMeters *float64                 `xml:"DistanceMeters,omitempty"`
+	HeartRateBpm   *heartRateXML            `xml:"HeartRateBpm,omitempty"`
+	Cadence        *int                     `xml:"Cadence,omitempty"`
+	Extensions     *trackpointExtensionsXML `xml:"Extensions,omitempty"`
+}
+
+type positionXML struct {
+	LatitudeDegrees  float64 `xml:"LatitudeDegrees"`
+	LongitudeDegrees float64 `xml:"LongitudeDegrees"`
+}
+
+type heartRateXML struct {
+	Value int `xml:"Value"`
+}
+
+type trackpointExtensionsXML struct {
+	TPX   *tpxXML            `xml:",omitempty"`
+	HRV   *hrvXML            `xml:",omitempty"`
+	Other []ExtensionElement `xml:",any"`
+}
+
+type tpxXML struct {
+	XMLName    xml.Name `xml:"http://www.garmin.com/xmlschemas/ActivityExtension/v2 TPX"`
+	Speed      *float64 `xml:"Speed,omitempty"`
+	RunCadence int      `xml:"RunCadence,omitempty"`
+	Watts      *int     `xml:"Watts,omitempty"`
+}
+
+type hrvXML struct {
+	XMLName xml.Name `xml:"https://github.com/rdifrango/go-tcx/hrv/v1 HRV"`
+	RR      []int    `xml:"RR"`
+}
+
+// MarshalXML writes the trackpoint in schema order, with its speed, run
+// cadence and power in a TPX extension and its R-R intervals in an HRV
+// extension, followed by its other extensions.
+func (p Trackpoint) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	v := trackpointXML{Time: p.Time}
+	if p.HasPosition() {
+		v.Position = &positionXML{p.LatitudeInDegrees, p.LongitudeInDegrees}
+	}
+	if p.HasAltitude() {
+		v.AltitudeMeters = &p.AltitudeInMeters
+	}
+	if p.HasDistance() {
+		v.DistanceMeters = &p.DistanceInMeters
+	}
+	if p.HasHeartRate() {
+		v.HeartRateBpm = &heartRateXML{p.HeartRateInBpm}
+	}
+	if p.HasCadence() {
+		v.Cadence = &p.Cadence
+	}
+	ext := trackpointExtensionsXML{Other: p.OtherExtensions}
+	if p.HasSpeed() || p.RunCadence != 0 || p.HasPower() {
+		ext.TPX = &tpxXML{RunCadence: p.RunCadence}
+		if p.HasSpeed() {
+			ext.TPX.Speed = &p.SpeedInMetersPerSec
+		}
+		if p.HasPower() {
+			ext.TPX.Watts = &p.PowerInWatts
+		}
+	}
+	if len(p.RRIntervals) > 0 {
+		ext.HRV = &hrvXML{RR: p.RRIntervals}
+	}
+	if ext.TPX != nil || ext.HRV != nil || len(ext.Other) > 0 {
+		v.Extensions = &ext
+	}
+	return e.EncodeElement(v, start)
+}
+
+// courseLapXML is a CourseLap without the positions it lacks.
+type courseLapXML struct {
+	TotalTimeSeconds float64      `xml:"TotalTimeSeconds"`
+	DistanceMeters   float64      `xml:"DistanceMeters"`
+	BeginPosition    *positionXML `xml:"BeginPosition,omitempty"`
+	EndPosition      *positionXML `xml:"EndPosition,omitempty"`
+	Intensity        string       `xml:"Intensity"`
+}
+
+// MarshalXML writes the course lap, leaving out missing positions.
+func (l CourseLap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	v := courseLapXML{TotalTimeSeconds: l.TotalTimeInSeconds, DistanceMeters: l.DistanceInMeters, Intensity: l.Intensity}
+	if l.BeginLatitudeInDegrees != 0 || l.BeginLongitudeInDegrees != 0 {
+		v.BeginPosition = &positionXML{l.BeginLatitudeInDegrees, l.BeginLongitudeInDegrees}
+	}
+	if l.EndLatitudeInDegrees != 0 || l.EndLongitudeInDegrees != 0 {
+		v.EndPosition = &positionXML{l.EndLatitudeInDegrees, l.EndLongitudeInDegrees}
+	}
+	if v.Intensity == "" {
+		v.Intensity = "Active"
+	}
+	return e.EncodeElement(v, start)
+}
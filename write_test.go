@@ -0,0 +1,59 @@
+package tcx
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteRoundTrip(t *testing.T) {
+	orig, err := ParseFile("testdata/test1.tcx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "out.tcx")
+	if err := orig.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	back, err := ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.Activities, orig.Activities) {
+		t.Error("activities changed through WriteFile and ParseFile")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	a := testActivity(SportWalking, repeat(1.4, 3), []int{0, 95, 96})
+	a.Laps[0].Track[1].PowerInWatts = 120
+	a.Laps[0].Track[2].LatitudeInDegrees, a.Laps[0].Track[2].LongitudeInDegrees = 0, 0
+	a.Laps[0].Calories = 12.6
+	a.Creator = Creator{Name: "Forerunner 245", UnitID: 1, ProductID: 3076, VersionMajor: 6}
+	db := &Tcx{Activities: []Activity{*a}}
+
+	var b bytes.Buffer
+	if err := db.WriteIndent(&b, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="`,
+		`<Activity Sport="Other"><Id>2015-04-12T07:00:00Z</Id><Lap StartTime="2015-04-12T07:00:00Z">`,
+		`<Calories>13</Calories><Intensity>Active</Intensity><TriggerMethod>Manual</TriggerMethod><Track>`,
+		`<Time>2015-04-12T07:00:00Z</Time><Position><LatitudeDegrees>47</LatitudeDegrees><LongitudeDegrees>-1.5</LongitudeDegrees></Position><AltitudeMeters>10</AltitudeMeters><Extensions>`,
+		`<HeartRateBpm><Value>95</Value></HeartRateBpm><Extensions><TPX xmlns="http://www.garmin.com/xmlschemas/ActivityExtension/v2"><Speed>1.4</Speed><Watts>120</Watts></TPX></Extensions>`,
+		`<Time>2015-04-12T07:00:02Z</Time><AltitudeMeters>10</AltitudeMeters><HeartRateBpm>`,
+		`</Track></Lap><Creator xsi:type="Device_t"><Name>Forerunner 245</Name><UnitId>1</UnitId><ProductID>3076</ProductID><Version><VersionMajor>6</VersionMajor><VersionMinor>0</VersionMinor></Version></Creator></Activity>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %s", want)
+		}
+	}
+	if strings.Contains(out, "<HeartRateBpm><Value>0</Value>") {
+		t.Error("missing heart rate written as 0")
+	}
+}
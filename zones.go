@@ -0,0 +1,161 @@
+package tcx
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Zone is a named range of a metric (bpm, m/s or watts). Min is inclusive
+// and Max exclusive.
+type Zone struct {
+	Name string
+	Min  float64
+	Max  float64
+}
+
+// Zones is an ordered list of contiguous zones.
+type Zones []Zone
+
+// Find returns the index of the zone containing v, or -1 when v is outside
+// all zones.
+func (z Zones) Find(v float64) int {
+	for i, zone := range z {
+		if v >= zone.Min && v < zone.Max {
+			return i
+		}
+	}
+	return -1
+}
+
+// zonesFromRatios builds contiguous zones from a reference value and the
+// ratios of that value separating them. The first zone starts at 0 and the
+// last one is unbounded.
+func zonesFromRatios(ref float64, names []string, bounds []float64) Zones {
+	zones := make(Zones, len(names))
+	lo := 0.0
+	for i, name := range names {
+		hi := math.Inf(1)
+		if i < len(bounds) {
+			hi = ref * bounds[i]
+		}
+		zones[i] = Zone{Name: name, Min: lo, Max: hi}
+		lo = hi
+	}
+	return zones
+}
+
+var fiveZones = []string{"Z1", "Z2", "Z3", "Z4", "Z5"}
+
+// HeartRateZonesFromMax returns the classic five heart rate zones at 60, 70,
+// 80 and 90% of maximum heart rate.
+func HeartRateZonesFromMax(maxHR int) Zones {
+	return zonesFromRatios(float64(maxHR), fiveZones, []float64{0.6, 0.7, 0.8, 0.9})
+}
+
+// HeartRateZonesFromBounds returns heart rate zones from explicit
+// boundaries in bpm, in increasing order. The first zone starts at 0 and the
+// last one is unbounded, so n boundaries make n+1 zones named Z1 onwards.
+func HeartRateZonesFromBounds(bounds ...int) Zones {
+	names := make([]string, len(bounds)+1)
+	ratios := make([]float64, len(bounds))
+	for i := range names {
+		names[i] = fmt.Sprintf("Z%d", i+1)
+	}
+	for i, b := range bounds {
+		ratios[i] = float64(b)
+	}
+	return zonesFromRatios(1, names, ratios)
+}
+
+// HeartRateZonesFromLTHR returns Joe Friel's seven heart rate zones relative
+// to lactate threshold heart rate.
+func HeartRateZonesFromLTHR(lthr int) Zones {
+	return zonesFromRatios(float64(lthr),
+		[]string{"Z1", "Z2", "Z3", "Z4", "Z5a", "Z5b", "Z5c"},
+		[]float64{0.85, 0.90, 0.95, 1.00, 1.03, 1.07})
+}
+
+// PaceZones returns Joe Friel's running pace zones, expressed as speeds in
+// m/s, relative to threshold speed.
+func PaceZones(thresholdSpeed float64) Zones {
+	return zonesFromRatios(thresholdSpeed,
+		[]string{"Z1", "Z2", "Z3", "Z4", "Z5a", "Z5b", "Z5c"},
+		[]float64{1 / 1.29, 1 / 1.14, 1 / 1.06, 1 / 0.99, 1 / 0.97, 1 / 0.90})
+}
+
+// PowerZones returns Andrew Coggan's seven power zones relative to
+// functional threshold power.
+func PowerZones(ftp float64) Zones {
+	return zonesFromRatios(ftp,
+		[]string{"Active Recovery", "Endurance", "Tempo", "Threshold", "VO2max", "Anaerobic", "Neuromuscular"},
+		[]float64{0.56, 0.76, 0.91, 1.06, 1.21, 1.51})
+}
+
+// HeartRateZones returns the heart rate zones derived from the lactate
+// threshold heart rate.
+func (th Thresholds) HeartRateZones() Zones {
+	return HeartRateZonesFromLTHR(th.HeartRate)
+}
+
+// PaceZones returns the pace zones derived from the threshold speed.
+func (th Thresholds) PaceZones() Zones {
+	return PaceZones(th.Speed)
+}
+
+// ZoneTime is the time spent in one zone.
+type ZoneTime struct {
+	Zone Zone
+	Time time.Duration
+	// Fraction is the share of the zoned time spent in the zone.
+	Fraction float64
+}
+
+// ZoneBreakdown is the time spent in each of a list of zones, in the order
+// of the zones.
+type ZoneBreakdown struct {
+	Zones []ZoneTime
+	// Unzoned is the time when the metric was not recorded or fell
+	// outside all zones.
+	Unzoned time.Duration
+}
+
+// timeInZones weights each value of metric by the time until the next
+// trackpoint and adds it to its zone.
+func timeInZones(ivs []interval, metric Metric, z Zones) ZoneBreakdown {
+	b := ZoneBreakdown{Zones: make([]ZoneTime, len(z))}
+	secs := make([]float64, len(z))
+	var zoned, unzoned float64
+	for _, iv := range ivs {
+		i := -1
+		if v, ok := metric.value(iv); ok {
+			i = z.Find(v)
+		}
+		if i < 0 {
+			unzoned += iv.dt
+			continue
+		}
+		secs[i] += iv.dt
+		zoned += iv.dt
+	}
+	for i := range z {
+		b.Zones[i] = ZoneTime{Zone: z[i], Time: time.Duration(secs[i] * float64(time.Second))}
+		if zoned > 0 {
+			b.Zones[i].Fraction = secs[i] / zoned
+		}
+	}
+	b.Unzoned = time.Duration(unzoned * float64(time.Second))
+	return b
+}
+
+// TimeInZones returns the time the activity spent in each zone of metric,
+// such as heart rate zones with MetricHeartRate.
+func (a *Activity) TimeInZones(metric Metric, z Zones) ZoneBreakdown {
+	return timeInZones(a.intervals(), metric, z)
+}
+
+// TimeInZones returns the time the lap spent in each zone of metric.
+func (l *Lap) TimeInZones(metric Metric, z Zones) ZoneBreakdown {
+	lap := Activity{Laps: []Lap{*l}}
+	return timeInZones(lap.intervals(), metric, z)
+}
@@ -0,0 +1,62 @@
+package tcx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestZones(t *testing.T) {
+	hr := HeartRateZonesFromMax(200)
+	if len(hr) != 5 || hr[1].Min != 120 || hr[1].Max != 140 || !math.IsInf(hr[4].Max, 1) {
+		t.Errorf("HeartRateZonesFromMax() = %v", hr)
+	}
+	if i := hr.Find(150); i != 2 {
+		t.Errorf("Find(150) = %d, want 2", i)
+	}
+
+	lt := Thresholds{HeartRate: 170, Speed: 4}.HeartRateZones()
+	if lt.Find(169) != 3 || lt.Find(171) != 4 {
+		t.Errorf("HeartRateZonesFromLTHR() = %v", lt)
+	}
+	if z := PaceZones(4); z.Find(4) != 3 || z.Find(3.2) != 1 {
+		t.Errorf("PaceZones() = %v", z)
+	}
+	if z := PowerZones(250); z.Find(250) != 3 || z.Find(100) != 0 || z.Find(-1) != -1 {
+		t.Errorf("PowerZones() = %v", z)
+	}
+}
+
+func TestTimeInZones(t *testing.T) {
+	hrs := append(repeat(110, 60), repeat(135, 120)...)
+	hrs = append(hrs, repeat(0, 30)...)
+	hrs = append(hrs, repeat(185, 31)...)
+	a := testActivity(SportRunning, repeat(3.0, len(hrs)), hrs)
+	z := HeartRateZonesFromMax(200)
+	b := a.TimeInZones(MetricHeartRate, z)
+	want := []time.Duration{60 * time.Second, 120 * time.Second, 0, 0, 30 * time.Second}
+	for i, zt := range b.Zones {
+		if zt.Zone != z[i] || zt.Time != want[i] {
+			t.Errorf("zone %d = %+v, want %v", i, zt, want[i])
+		}
+	}
+	if b.Unzoned != 30*time.Second || math.Abs(b.Zones[1].Fraction-120.0/210) > 1e-9 {
+		t.Errorf("unzoned %v, Z2 fraction %v", b.Unzoned, b.Zones[1].Fraction)
+	}
+
+	// Intervals are weighted by time, so a sparse track counts as much.
+	track := a.Laps[0].Track
+	var sparse []Trackpoint
+	for i := 0; i < len(track); i += 30 {
+		sparse = append(sparse, track[i])
+	}
+	a.Laps[0].Track = sparse
+	if got := a.Laps[0].TimeInZones(MetricHeartRate, z); got.Zones[1].Time != 120*time.Second {
+		t.Errorf("sparse Z2 time = %v", got.Zones[1].Time)
+	}
+
+	ex := HeartRateZonesFromBounds(120, 140, 160)
+	if len(ex) != 4 || ex[3].Name != "Z4" || ex.Find(130) != 1 || ex.Find(100) != 0 || ex.Find(170) != 3 {
+		t.Errorf("HeartRateZonesFromBounds() = %v", ex)
+	}
+}